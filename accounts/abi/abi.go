@@ -22,7 +22,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -288,23 +287,11 @@ func UnpackRevert(data []byte) (string, error) {
 		}
 		return unpacked[0].(string), nil
 	case bytes.Equal(data[:4], panicSelector):
-		typ, err := NewType("uint256", "", nil)
+		code, err := unpackPanicCode(data)
 		if err != nil {
 			return "", err
 		}
-		unpacked, err := (Arguments{{Type: typ}}).Unpack(data[4:])
-		if err != nil {
-			return "", err
-		}
-		pCode := unpacked[0].(*big.Int)
-		// uint64 safety check for future
-		// but the code is not bigger than MAX(uint64) now
-		if pCode.IsUint64() {
-			if reason, ok := panicReasons[pCode.Uint64()]; ok {
-				return reason, nil
-			}
-		}
-		return fmt.Sprintf("unknown panic code: %#x", pCode), nil
+		return (&PanicError{Code: code}).Reason(), nil
 	default:
 		return "", errors.New("invalid data for unpacking")
 	}
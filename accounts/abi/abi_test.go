@@ -1218,3 +1218,50 @@ func TestUnpackRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestUnpackRevertError(t *testing.T) {
+	t.Parallel()
+
+	abi, err := JSON(strings.NewReader(`[{"inputs":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}],"name":"MyError","type":"error"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := abi.Errors["MyError"].Inputs.Pack(big.NewInt(1), "fail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revert := abi.UnpackRevertError(append(abi.Errors["MyError"].ID.Bytes()[:4], data...))
+
+	var custom *CustomError
+	if !errors.As(revert, &custom) {
+		t.Fatalf("revert does not unwrap into custom error: %v", revert)
+	}
+	var decoded struct {
+		A *big.Int
+		B string
+	}
+	if err := custom.Copy(&decoded); err != nil {
+		t.Fatalf("failed to copy arguments: %v", err)
+	}
+	if decoded.A.Int64() != 1 || decoded.B != "fail" {
+		t.Fatalf("wrong arguments: %+v", decoded)
+	}
+	if want := "execution reverted: MyError(1, fail)"; revert.Error() != want {
+		t.Fatalf("wrong message: have %q, want %q", revert, want)
+	}
+	// Built-in errors are decoded regardless of the ABI
+	revert = abi.UnpackRevertError(common.Hex2Bytes("4e487b710000000000000000000000000000000000000000000000000000000000000012"))
+	var panicErr *PanicError
+	if !errors.As(revert, &panicErr) || panicErr.Reason() != "division or modulo by zero" {
+		t.Fatalf("wrong panic: %v", revert)
+	}
+	revert = abi.UnpackRevertError(common.Hex2Bytes("08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000000d72657665727420726561736f6e00000000000000000000000000000000000000"))
+	if want := "execution reverted: revert reason"; revert.Error() != want {
+		t.Fatalf("wrong message: have %q, want %q", revert, want)
+	}
+	// Unknown errors keep the raw data
+	revert = abi.UnpackRevertError([]byte{0x01, 0x02, 0x03, 0x04})
+	if errors.Unwrap(revert) != nil || !bytes.Equal(revert.Data, []byte{0x01, 0x02, 0x03, 0x04}) {
+		t.Fatalf("wrong unknown revert: %v %x", revert, revert.Data)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
@@ -93,6 +94,9 @@ type MetaData struct {
 	Bin  string
 	ABI  string
 	ab   *abi.ABI
+
+	ID   string      // Identifier of the contract, the link pattern of libraries
+	Deps []*MetaData // Libraries the bytecode has to be linked against
 }
 
 func (m *MetaData) GetAbi() (*abi.ABI, error) {
@@ -180,7 +184,7 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 		}
 		output, err = pb.PendingCallContract(ctx, msg)
 		if err != nil {
-			return err
+			return c.unpackRevert(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
@@ -197,7 +201,7 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 		}
 		output, err = bh.CallContractAtHash(ctx, msg, opts.BlockHash)
 		if err != nil {
-			return err
+			return c.unpackRevert(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
@@ -210,7 +214,7 @@ func (c *BoundContract) Call(opts *CallOpts, results *[]interface{}, method stri
 	} else {
 		output, err = c.caller.CallContract(ctx, msg, opts.BlockNumber)
 		if err != nil {
-			return err
+			return c.unpackRevert(err)
 		}
 		if len(output) == 0 {
 			// Make sure we have a contract to operate on, and bail out otherwise.
@@ -372,7 +376,11 @@ func (c *BoundContract) estimateGasLimit(opts *TransactOpts, contract *common.Ad
 		Value:     value,
 		Data:      input,
 	}
-	return c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	gas, err := c.transactor.EstimateGas(ensureContext(opts.Context), msg)
+	if err != nil {
+		return 0, c.unpackRevert(err)
+	}
+	return gas, nil
 }
 
 func (c *BoundContract) getNonce(opts *TransactOpts) (uint64, error) {
@@ -508,20 +516,26 @@ func (c *BoundContract) WatchLogs(opts *WatchOpts, name string, query ...[]inter
 
 // UnpackLog unpacks a retrieved log into the provided output structure.
 func (c *BoundContract) UnpackLog(out interface{}, event string, log types.Log) error {
+	return UnpackLog(&c.abi, out, event, log)
+}
+
+// UnpackLog unpacks a retrieved log of the given contract event into the
+// provided output structure.
+func UnpackLog(contractABI *abi.ABI, out interface{}, event string, log types.Log) error {
 	// Anonymous events are not supported.
 	if len(log.Topics) == 0 {
 		return errNoEventSignature
 	}
-	if log.Topics[0] != c.abi.Events[event].ID {
+	if log.Topics[0] != contractABI.Events[event].ID {
 		return errEventSignatureMismatch
 	}
 	if len(log.Data) > 0 {
-		if err := c.abi.UnpackIntoInterface(out, event, log.Data); err != nil {
+		if err := contractABI.UnpackIntoInterface(out, event, log.Data); err != nil {
 			return err
 		}
 	}
 	var indexed abi.Arguments
-	for _, arg := range c.abi.Events[event].Inputs {
+	for _, arg := range contractABI.Events[event].Inputs {
 		if arg.Indexed {
 			indexed = append(indexed, arg)
 		}
@@ -552,6 +566,51 @@ func (c *BoundContract) UnpackLogIntoMap(out map[string]interface{}, event strin
 	return abi.ParseTopicsIntoMap(out, indexed, log.Topics[1:])
 }
 
+// revertError is a reverted contract execution reported by the backend, decoded
+// using the contract ABI. It unwraps into both the decoded revert and the error
+// reported by the backend.
+type revertError struct {
+	*abi.RevertError
+	err error // Error reported by the backend
+}
+
+// Error implements error, falling back to the error reported by the backend if
+// the revert data could not be decoded.
+func (e *revertError) Error() string {
+	if errors.Unwrap(e.RevertError) == nil {
+		return e.err.Error()
+	}
+	return e.RevertError.Error()
+}
+
+// Unwrap returns the decoded revert and the error reported by the backend.
+func (e *revertError) Unwrap() []error {
+	return []error{e.RevertError, e.err}
+}
+
+// unpackRevert decodes the revert data carried by an error of the backend. The
+// error is returned as is if it carries no revert data.
+func (c *BoundContract) unpackRevert(err error) error {
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	var data []byte
+	switch raw := dataErr.ErrorData().(type) {
+	case string:
+		decoded, decodeErr := hexutil.Decode(raw)
+		if decodeErr != nil {
+			return err
+		}
+		data = decoded
+	case []byte:
+		data = raw
+	default:
+		return err
+	}
+	return &revertError{RevertError: c.abi.UnpackRevertError(data), err: err}
+}
+
 // ensureContext is a helper method to ensure a context is not nil, even if the
 // user specified it as such.
 func ensureContext(ctx context.Context) context.Context {
@@ -30,7 +30,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 )
@@ -579,6 +582,79 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// revertingCode returns contract code reverting with the given error selector
+// and a single word argument.
+func revertingCode(selector []byte, arg byte) []byte {
+	code := append([]byte{byte(vm.PUSH4)}, selector...)
+	return append(code,
+		byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0x00, byte(vm.MSTORE), // mem[0:4] = selector
+		byte(vm.PUSH1), arg, byte(vm.PUSH1), 0x04, byte(vm.MSTORE), // mem[4:36] = arg
+		byte(vm.PUSH1), 0x24, byte(vm.PUSH1), 0x00, byte(vm.REVERT),
+	)
+}
+
+// TestCallRevertError checks that reverted calls unwrap into custom and panic errors.
+func TestCallRevertError(t *testing.T) {
+	t.Parallel()
+
+	const abiJSON = `[{"inputs":[{"name":"code","type":"uint256"}],"name":"MyError","type":"error"},{"inputs":[],"name":"something","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		sender   = crypto.PubkeyToAddress(testKey.PublicKey)
+		custom   = common.Address{0x01}
+		panicked = common.Address{0x02}
+	)
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		sender:   {Balance: big.NewInt(params.Ether)},
+		custom:   {Code: revertingCode(parsed.Errors["MyError"].ID.Bytes()[:4], 0x07)},
+		panicked: {Code: revertingCode(crypto.Keccak256([]byte("Panic(uint256)"))[:4], 0x11)},
+	})
+	defer backend.Close()
+
+	// Custom errors unwrap into the decoded error
+	contract := bind.NewBoundContract(custom, parsed, backend.Client(), backend.Client(), backend.Client())
+	err = contract.Call(nil, nil, "something")
+
+	var customErr *abi.CustomError
+	if !errors.As(err, &customErr) {
+		t.Fatalf("call error does not unwrap into custom error: %v", err)
+	}
+	var decoded struct{ Code *big.Int }
+	if err := customErr.Copy(&decoded); err != nil {
+		t.Fatalf("failed to copy error arguments: %v", err)
+	}
+	if customErr.Definition.Name != "MyError" || decoded.Code.Int64() != 7 {
+		t.Fatalf("wrong custom error: %v", customErr)
+	}
+	if want := "execution reverted: MyError(7)"; err.Error() != want {
+		t.Fatalf("wrong error message: have %q, want %q", err, want)
+	}
+	// The error reported by the backend is still accessible
+	var dataErr interface{ ErrorData() interface{} }
+	if !errors.As(err, &dataErr) {
+		t.Fatalf("call error does not unwrap into backend error: %v", err)
+	}
+	// Gas estimation of transactions reverts the same way
+	auth, _ := bind.NewKeyedTransactorWithChainID(testKey, big.NewInt(1337))
+	if _, err := contract.Transact(auth, "something"); !errors.As(err, &customErr) {
+		t.Fatalf("transaction error does not unwrap into custom error: %v", err)
+	}
+	// Panics unwrap into the panic code
+	contract = bind.NewBoundContract(panicked, parsed, backend.Client(), backend.Client(), backend.Client())
+	err = contract.Call(nil, nil, "something")
+
+	var panicErr *abi.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("call error does not unwrap into panic error: %v", err)
+	}
+	if panicErr.Code.Int64() != 0x11 || panicErr.Reason() != "arithmetic underflow or overflow" {
+		t.Fatalf("wrong panic: code %#x reason %q", panicErr.Code, panicErr.Reason())
+	}
+}
+
 // TestCrashers contains some strings which previously caused the abi codec to crash.
 func TestCrashers(t *testing.T) {
 	t.Parallel()
@@ -82,6 +82,22 @@ func isKeyWord(arg string) bool {
 // enforces compile time type safety and naming convention as opposed to having to
 // manually maintain hard coded strings that break on runtime.
 func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string) (string, error) {
+	return bind(types, abis, bytecodes, fsigs, pkg, lang, libs, aliases, tmplSource[lang], false)
+}
+
+// BindV2 generates stateless Go bindings around a contract ABI. Instead of
+// wrapping a contract backend, the bindings pack the call data of methods and
+// unpack their return values, decode event logs and custom errors, leaving it
+// up to the caller how to reach the chain. Contracts linking against libraries
+// carry their dependencies in their metadata, to be deployed with LinkAndDeploy.
+func BindV2(types []string, abis []string, bytecodes []string, pkg string, libs map[string]string, aliases map[string]string) (string, error) {
+	return bind(types, abis, bytecodes, nil, pkg, LangGo, libs, aliases, tmplSourceGoV2, true)
+}
+
+// bind generates the contract bindings from the given template. The stateless
+// bindings additionally need the custom errors of the contracts, and share a
+// single namespace between calls and transactions.
+func bind(types []string, abis []string, bytecodes []string, fsigs []map[string]string, pkg string, lang Lang, libs map[string]string, aliases map[string]string, source string, stateless bool) (string, error) {
 	var (
 		// contracts is the map of each individual contract requested binding
 		contracts = make(map[string]*tmplContract)
@@ -111,6 +127,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			calls     = make(map[string]*tmplMethod)
 			transacts = make(map[string]*tmplMethod)
 			events    = make(map[string]*tmplEvent)
+			errs      = make(map[string]*tmplError)
 			fallback  *tmplMethod
 			receive   *tmplMethod
 
@@ -135,7 +152,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			normalizedName := methodNormalizer[lang](alias(aliases, original.Name))
 			// Ensure there is no duplicated identifier
 			var identifiers = callIdentifiers
-			if !original.IsConstant() {
+			if !original.IsConstant() && !stateless {
 				identifiers = transactIdentifiers
 			}
 			// Name shouldn't start with a digit. It will make the generated code invalid.
@@ -226,6 +243,44 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			// Append the event to the accumulator list
 			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
 		}
+		// Custom errors are only decoded by the stateless bindings. Their structs
+		// share the namespace of the event ones.
+		if stateless {
+			for _, original := range evmABI.Errors {
+				normalized := original
+
+				normalizedName := methodNormalizer[lang](alias(aliases, original.Name))
+				// Name shouldn't start with a digit. It will make the generated code invalid.
+				if len(normalizedName) > 0 && unicode.IsDigit(rune(normalizedName[0])) {
+					normalizedName = fmt.Sprintf("E%s", normalizedName)
+					normalizedName = abi.ResolveNameConflict(normalizedName, func(name string) bool {
+						_, ok := eventIdentifiers[name]
+						return ok
+					})
+				}
+				if eventIdentifiers[normalizedName] {
+					return "", fmt.Errorf("duplicated identifier \"%s\"(normalized \"%s\"), use --alias for renaming", original.Name, normalizedName)
+				}
+				eventIdentifiers[normalizedName] = true
+				normalized.Name = normalizedName
+
+				used := make(map[string]bool)
+				normalized.Inputs = make([]abi.Argument, len(original.Inputs))
+				copy(normalized.Inputs, original.Inputs)
+				for j, input := range normalized.Inputs {
+					if input.Name == "" || isKeyWord(input.Name) {
+						normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
+					}
+					normalized.Inputs[j].Name = abi.ResolveNameConflict(capitalise(normalized.Inputs[j].Name), func(name string) bool { return used[name] })
+					used[normalized.Inputs[j].Name] = true
+
+					if hasStruct(input.Type) {
+						bindStructType[lang](input.Type, structs)
+					}
+				}
+				errs[original.Name] = &tmplError{Original: original, Normalized: normalized}
+			}
+		}
 		// Add two special fallback functions if they exist
 		if evmABI.HasFallback() {
 			fallback = &tmplMethod{Original: evmABI.Fallback}
@@ -243,6 +298,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			Fallback:    fallback,
 			Receive:     receive,
 			Events:      events,
+			Errors:      errs,
 			Libraries:   make(map[string]string),
 		}
 		// Function 4-byte signatures are stored in the same sequence
@@ -265,10 +321,21 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 			}
 		}
 	}
-	// Check if that type has already been identified as a library
+	// Check if that type has already been identified as a library, identifying
+	// the libraries by their link pattern
+	ids := make(map[string]string)
+	for pattern, name := range libs {
+		ids[name] = pattern
+	}
 	for i := 0; i < len(types); i++ {
 		_, ok := isLib[types[i]]
 		contracts[types[i]].Library = ok
+
+		if id, ok := ids[types[i]]; ok {
+			contracts[types[i]].ID = id
+		} else {
+			contracts[types[i]].ID = types[i]
+		}
 	}
 	// Generate the contract template data content and render it
 	data := &tmplData{
@@ -286,7 +353,7 @@ func Bind(types []string, abis []string, bytecodes []string, fsigs []map[string]
 		"capitalise":    capitalise,
 		"decapitalise":  decapitalise,
 	}
-	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
+	tmpl := template.Must(template.New("").Funcs(funcs).Parse(source))
 	if err := tmpl.Execute(buffer, data); err != nil {
 		return "", err
 	}
@@ -2121,8 +2121,14 @@ func TestGolangBindings(t *testing.T) {
 			}
 		})
 	}
+	runBindingTests(t, gocmd, pkg)
+}
+
+// runBindingTests converts the package of generated bindings to a Go module using
+// the current source for go-ethereum and runs its tests.
+func runBindingTests(t *testing.T, gocmd string, pkg string) {
 	// Convert the package to go modules and use the current source for go-ethereum
-	moder := exec.Command(gocmd, "mod", "init", "bindtest")
+	moder := exec.Command(gocmd, "mod", "init", filepath.Base(pkg))
 	moder.Dir = pkg
 	if out, err := moder.CombinedOutput(); err != nil {
 		t.Fatalf("failed to convert binding test to modules: %v\n%s", err, out)
@@ -0,0 +1,235 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// bindV2Tests are the tests of the stateless bindings, generated from the
+// contracts of the bindTests with the same name.
+var bindV2Tests = []struct {
+	name    string
+	imports string
+	tester  string
+}{
+	{
+		`Tupler`,
+		`
+			"math/big"
+		`,
+		`
+			tupler := NewTupler()
+			if input, err := tupler.PackTuple(); err != nil || len(input) != 4 {
+				t.Fatalf("Failed to pack call: %x %v", input, err)
+			}
+			parsed, _ := TuplerMetaData.GetAbi()
+			data, err := parsed.Methods["tuple"].Outputs.Pack("Hi", big.NewInt(1), [32]byte{1})
+			if err != nil {
+				t.Fatalf("Failed to pack return data: %v", err)
+			}
+			res, err := tupler.UnpackTuple(data)
+			if err != nil {
+				t.Fatalf("Failed to unpack return data: %v", err)
+			}
+			if res.A != "Hi" || res.B.Cmp(big.NewInt(1)) != 0 || res.C != [32]byte{1} {
+				t.Fatalf("Invalid result: %+v", res)
+			}
+		`,
+	},
+	{
+		`Eventer`,
+		`
+			"math/big"
+
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+		`,
+		`
+			eventer := NewEventer()
+			parsed, _ := EventerMetaData.GetAbi()
+			data, err := parsed.Events["SimpleEvent"].Inputs.NonIndexed().Pack(big.NewInt(42))
+			if err != nil {
+				t.Fatalf("Failed to pack event data: %v", err)
+			}
+			log := &types.Log{
+				Topics: []common.Hash{eventer.SimpleEventEventID(), common.BytesToHash(common.Address{0x01}.Bytes()), {0x02}, common.BigToHash(common.Big1)},
+				Data:   data,
+			}
+			event, err := eventer.UnpackSimpleEventEvent(log)
+			if err != nil {
+				t.Fatalf("Failed to unpack event: %v", err)
+			}
+			if event.Addr != (common.Address{0x01}) || event.Id != [32]byte{0x02} || !event.Flag || event.Value.Cmp(big.NewInt(42)) != 0 || event.Raw != log {
+				t.Fatalf("Invalid event: %+v", event)
+			}
+			// Logs of other events are rejected
+			log.Topics[0] = eventer.NodataEventEventID()
+			if _, err := eventer.UnpackSimpleEventEvent(log); err == nil {
+				t.Fatal("Unpacked log of a different event")
+			}
+		`,
+	},
+	{
+		`NewErrors`,
+		`
+			"math/big"
+		`,
+		`
+			contract := NewNewErrors()
+			parsed, _ := NewErrorsMetaData.GetAbi()
+			myError := parsed.Errors["MyError3"]
+			data, err := myError.Inputs.Pack(big.NewInt(1), big.NewInt(2), big.NewInt(3))
+			if err != nil {
+				t.Fatalf("Failed to pack error data: %v", err)
+			}
+			raw := append(myError.ID.Bytes()[:4], data...)
+
+			res, err := contract.UnpackError(raw)
+			if err != nil {
+				t.Fatalf("Failed to unpack error: %v", err)
+			}
+			unpacked, ok := res.(*NewErrorsMyError3)
+			if !ok {
+				t.Fatalf("Unpacked wrong error: %T", res)
+			}
+			if unpacked.A.Int64() != 1 || unpacked.B.Int64() != 2 || unpacked.C.Int64() != 3 {
+				t.Fatalf("Invalid error: %+v", unpacked)
+			}
+			if _, err := contract.UnpackMyErrorError(raw); err == nil {
+				t.Fatal("Unpacked data of a different error")
+			}
+			if _, err := contract.UnpackError([]byte{0x01, 0x02, 0x03, 0x04}); err == nil {
+				t.Fatal("Unpacked unknown error")
+			}
+		`,
+	},
+	{
+		`UseLibrary`,
+		`
+			"math/big"
+			"strings"
+
+			"github.com/ethereum/go-ethereum/accounts/abi/bind"
+			"github.com/ethereum/go-ethereum/common"
+			"github.com/ethereum/go-ethereum/core/types"
+		`,
+		`
+			var deployed [][]byte
+			deploy := func(input, bytecode []byte) (common.Address, *types.Transaction, error) {
+				deployed = append(deployed, bytecode)
+				return common.Address{byte(len(deployed))}, nil, nil
+			}
+			// Deploy the contract, the library first
+			res, err := bind.LinkAndDeploy(&bind.DeploymentParams{Contracts: []*bind.MetaData{UseLibraryMetaData}}, deploy)
+			if err != nil {
+				t.Fatalf("Failed to deploy contract: %v", err)
+			}
+			if len(deployed) != 2 {
+				t.Fatalf("Deployed %d contracts, want 2", len(deployed))
+			}
+			if res.Addresses[MathMetaData.ID] != (common.Address{0x01}) || res.Addresses[UseLibraryMetaData.ID] != (common.Address{0x02}) {
+				t.Fatalf("Invalid deployment addresses: %v", res.Addresses)
+			}
+			linked := strings.ReplaceAll(UseLibraryMetaData.Bin, "__$"+MathMetaData.ID+"$__", common.Address{0x01}.Hex()[2:])
+			if common.Bytes2Hex(deployed[1]) != strings.ToLower(linked[2:]) {
+				t.Fatalf("Invalid linked bytecode: %x", deployed[1])
+			}
+			// Deploy the contract against an existing library
+			deployed = nil
+			params := &bind.DeploymentParams{
+				Contracts: []*bind.MetaData{UseLibraryMetaData},
+				Overrides: map[string]common.Address{MathMetaData.ID: {0xaa}},
+			}
+			if _, err := bind.LinkAndDeploy(params, deploy); err != nil {
+				t.Fatalf("Failed to deploy contract: %v", err)
+			}
+			if len(deployed) != 1 {
+				t.Fatalf("Deployed %d contracts, want 1", len(deployed))
+			}
+			// Call the contract method
+			contract := NewUseLibrary()
+			if _, err := contract.PackAdd(big.NewInt(1), big.NewInt(2)); err != nil {
+				t.Fatalf("Failed to pack call: %v", err)
+			}
+			sum, err := contract.UnpackAdd(common.LeftPadBytes([]byte{3}, 32))
+			if err != nil || sum.Int64() != 3 {
+				t.Fatalf("Invalid result: %v %v", sum, err)
+			}
+		`,
+	},
+}
+
+func TestGolangBindingsV2(t *testing.T) {
+	t.Parallel()
+	// Skip the test if no Go command can be found
+	gocmd := runtime.GOROOT() + "/bin/go"
+	if !common.FileExist(gocmd) {
+		t.Skip("go sdk not found for testing")
+	}
+	// Create a temporary workspace for the test suite
+	pkg := filepath.Join(t.TempDir(), "bindtestv2")
+	if err := os.MkdirAll(pkg, 0700); err != nil {
+		t.Fatalf("failed to create package: %v", err)
+	}
+	// Generate the test suite for all the contracts
+	for i, tt := range bindV2Tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var contract int
+			for contract = 0; bindTests[contract].name != tt.name; contract++ {
+			}
+			source := bindTests[contract]
+
+			types := source.types
+			if types == nil {
+				types = []string{source.name}
+			}
+			// Generate the binding and create a Go source file in the workspace
+			bind, err := BindV2(types, source.abi, source.bytecode, "bindtestv2", source.libs, source.aliases)
+			if err != nil {
+				t.Fatalf("test %d: failed to generate binding: %v", i, err)
+			}
+			if err = os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+".go"), []byte(bind), 0600); err != nil {
+				t.Fatalf("test %d: failed to write binding: %v", i, err)
+			}
+			// Generate the test file with the injected test code
+			code := fmt.Sprintf(`
+			package bindtestv2
+
+			import (
+				"testing"
+				%s
+			)
+
+			func Test%s(t *testing.T) {
+				%s
+			}
+		`, tt.imports, tt.name, tt.tester)
+			if err := os.WriteFile(filepath.Join(pkg, strings.ToLower(tt.name)+"_test.go"), []byte(code), 0600); err != nil {
+				t.Fatalf("test %d: failed to write tests: %v", i, err)
+			}
+		})
+	}
+	runBindingTests(t, gocmd, pkg)
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var errNoBytecode = errors.New("contract has no bytecode")
+
+// DeployFn deploys a contract given its linked bytecode and packed constructor
+// input, returning the address of the contract and the deployment transaction.
+type DeployFn func(input, bytecode []byte) (common.Address, *types.Transaction, error)
+
+// DeploymentParams are the contracts to deploy with LinkAndDeploy.
+type DeploymentParams struct {
+	Contracts []*MetaData               // Contracts to deploy, along with the libraries they depend on
+	Inputs    map[string][]byte         // Packed constructor input of the contracts, by ID
+	Overrides map[string]common.Address // Libraries already deployed, by ID
+}
+
+// DeploymentResult contains the contracts deployed by LinkAndDeploy, including
+// the libraries they depend on.
+type DeploymentResult struct {
+	Txs       map[string]*types.Transaction // Deployment transactions, by contract ID
+	Addresses map[string]common.Address     // Contract addresses, by contract ID
+}
+
+// DefaultDeployFn returns a deployer sending the deployment transactions from
+// the given account through the backend.
+func DefaultDeployFn(opts *TransactOpts, backend ContractBackend) DeployFn {
+	return func(input, bytecode []byte) (common.Address, *types.Transaction, error) {
+		c := NewBoundContract(common.Address{}, abi.ABI{}, backend, backend, backend)
+		tx, err := c.transact(opts, nil, append(bytecode, input...))
+		if err != nil {
+			return common.Address{}, nil, err
+		}
+		return crypto.CreateAddress(opts.From, tx.Nonce()), tx, nil
+	}
+}
+
+// LinkAndDeploy deploys the given contracts, first deploying the libraries they
+// depend on and linking their bytecode against them. Libraries shared between
+// contracts are deployed only once, libraries with an override are not deployed
+// at all. If a deployment fails, the contracts deployed so far are returned
+// along with the error.
+func LinkAndDeploy(params *DeploymentParams, deploy DeployFn) (*DeploymentResult, error) {
+	linker := &linker{
+		params: params,
+		deploy: deploy,
+		result: &DeploymentResult{
+			Txs:       make(map[string]*types.Transaction),
+			Addresses: make(map[string]common.Address),
+		},
+	}
+	for _, contract := range params.Contracts {
+		if _, err := linker.link(contract); err != nil {
+			return linker.result, err
+		}
+	}
+	return linker.result, nil
+}
+
+// linker tracks the progress of a LinkAndDeploy run.
+type linker struct {
+	params *DeploymentParams
+	deploy DeployFn
+	result *DeploymentResult
+}
+
+// link deploys the contract after its dependencies, returning its address.
+func (l *linker) link(contract *MetaData) (common.Address, error) {
+	if addr, ok := l.params.Overrides[contract.ID]; ok {
+		return addr, nil
+	}
+	if addr, ok := l.result.Addresses[contract.ID]; ok {
+		return addr, nil
+	}
+	bytecode := strings.TrimPrefix(contract.Bin, "0x")
+	if bytecode == "" {
+		return common.Address{}, fmt.Errorf("%w: %s", errNoBytecode, contract.ID)
+	}
+	for _, dep := range contract.Deps {
+		addr, err := l.link(dep)
+		if err != nil {
+			return common.Address{}, err
+		}
+		bytecode = strings.ReplaceAll(bytecode, "__$"+dep.ID+"$__", addr.Hex()[2:])
+	}
+	addr, tx, err := l.deploy(l.params.Inputs[contract.ID], common.FromHex(bytecode))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy %s: %w", contract.ID, err)
+	}
+	l.result.Txs[contract.ID] = tx
+	l.result.Addresses[contract.ID] = addr
+	return addr, nil
+}
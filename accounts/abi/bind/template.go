@@ -29,6 +29,7 @@ type tmplData struct {
 // tmplContract contains the data needed to generate an individual contract binding.
 type tmplContract struct {
 	Type        string                 // Type name of the main contract binding
+	ID          string                 // Identifier of the contract, the link pattern for libraries
 	InputABI    string                 // JSON ABI used as the input to generate the binding from
 	InputBin    string                 // Optional EVM bytecode used to generate deploy code from
 	FuncSigs    map[string]string      // Optional map: string signature -> 4-byte signature
@@ -38,10 +39,24 @@ type tmplContract struct {
 	Fallback    *tmplMethod            // Additional special fallback function
 	Receive     *tmplMethod            // Additional special receive function
 	Events      map[string]*tmplEvent  // Contract events accessors
+	Errors      map[string]*tmplError  // Contract custom errors (stateless bindings only)
 	Libraries   map[string]string      // Same as tmplData, but filtered to only keep what the contract needs
 	Library     bool                   // Indicator whether the contract is a library
 }
 
+// Methods returns all the methods of the contract, regardless of whether they
+// write state data.
+func (c *tmplContract) Methods() map[string]*tmplMethod {
+	methods := make(map[string]*tmplMethod, len(c.Calls)+len(c.Transacts))
+	for name, method := range c.Calls {
+		methods[name] = method
+	}
+	for name, method := range c.Transacts {
+		methods[name] = method
+	}
+	return methods
+}
+
 // tmplMethod is a wrapper around an abi.Method that contains a few preprocessed
 // and cached data fields.
 type tmplMethod struct {
@@ -57,6 +72,13 @@ type tmplEvent struct {
 	Normalized abi.Event // Normalized version of the parsed fields
 }
 
+// tmplError is a wrapper around an abi.Error that contains a few preprocessed
+// and cached data fields.
+type tmplError struct {
+	Original   abi.Error // Original error as parsed by the abi package
+	Normalized abi.Error // Normalized version of the parsed fields
+}
+
 // tmplField is a wrapper around a struct field with binding language
 // struct type definition and relative filed name.
 type tmplField struct {
@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+// tmplSourceGoV2 is the Go source template that the generated stateless Go
+// contract binding is based on.
+const tmplSourceGoV2 = `
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = bytes.Equal
+	_ = errors.New
+	_ = big.NewInt
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = abi.ConvertType
+)
+
+{{$structs := .Structs}}
+{{range $structs}}
+	// {{.Name}} is an auto generated low-level Go binding around an user-defined struct.
+	type {{.Name}} struct {
+	{{range $field := .Fields}}
+	{{$field.Name}} {{$field.Type}}{{end}}
+	}
+{{end}}
+
+{{range $contract := .Contracts}}
+	// {{.Type}}MetaData contains all meta data concerning the {{.Type}} contract.
+	var {{.Type}}MetaData = &bind.MetaData{
+		ABI: "{{.InputABI}}",
+		ID: "{{.ID}}",
+		{{if .InputBin -}}
+		Bin: "0x{{.InputBin}}",
+		{{end -}}
+		{{if .Libraries -}}
+		Deps: []*bind.MetaData{
+			{{range $pattern, $name := .Libraries}}{{capitalise $name}}MetaData,
+			{{end}}
+		},
+		{{end}}
+	}
+
+	// {{.Type}} is an auto generated stateless Go binding around an Ethereum contract,
+	// packing and unpacking the data exchanged with it.
+	type {{.Type}} struct {
+		abi abi.ABI
+	}
+
+	// New{{.Type}} creates a new stateless binding of the {{.Type}} contract.
+	func New{{.Type}}() *{{.Type}} {
+		parsed, err := {{.Type}}MetaData.GetAbi()
+		if err != nil {
+			panic(errors.New("invalid ABI: " + err.Error()))
+		}
+		return &{{.Type}}{abi: *parsed}
+	}
+
+	{{if .Constructor.Inputs}}
+		// PackConstructor packs the constructor arguments, to be appended to the
+		// bytecode of the contract when deploying it.
+		//
+		// Solidity: {{.Constructor.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) PackConstructor({{range $i, $_ := .Constructor.Inputs}}{{if ne $i 0}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+			return _{{$contract.Type}}.abi.Pack(""{{range .Constructor.Inputs}}, {{.Name}}{{end}})
+		}
+	{{end}}
+
+	{{range .Methods}}
+		// Pack{{.Normalized.Name}} packs the call data of the contract method 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Pack{{.Normalized.Name}}({{range $i, $_ := .Normalized.Inputs}}{{if ne $i 0}}, {{end}}{{.Name}} {{bindtype .Type $structs}}{{end}}) ([]byte, error) {
+			return _{{$contract.Type}}.abi.Pack("{{.Original.Name}}"{{range .Normalized.Inputs}}, {{.Name}}{{end}})
+		}
+
+		{{if .Normalized.Outputs}}
+			{{if .Structured}}
+				// {{$contract.Type}}{{.Normalized.Name}}Output is the return data of the contract method {{.Normalized.Name}}.
+				type {{$contract.Type}}{{.Normalized.Name}}Output struct { {{range .Normalized.Outputs}}
+					{{.Name}} {{bindtype .Type $structs}}; {{end}}
+				}
+			{{end}}
+
+			// Unpack{{.Normalized.Name}} unpacks the return data of the contract method 0x{{printf "%x" .Original.ID}}.
+			//
+			// Solidity: {{.Original.String}}
+			func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}(data []byte) ({{if .Structured}}{{$contract.Type}}{{.Normalized.Name}}Output, {{else}}{{range .Normalized.Outputs}}{{bindtype .Type $structs}}, {{end}}{{end}}error) {
+				out, err := _{{$contract.Type}}.abi.Unpack("{{.Original.Name}}", data)
+				{{if .Structured}}
+				outstruct := new({{$contract.Type}}{{.Normalized.Name}}Output)
+				if err != nil {
+					return *outstruct, err
+				}
+				{{range $i, $t := .Normalized.Outputs}}
+				outstruct.{{.Name}} = *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+				return *outstruct, nil
+				{{else}}
+				if err != nil {
+					return {{range $i, $_ := .Normalized.Outputs}}*new({{bindtype .Type $structs}}), {{end}}err
+				}
+				{{range $i, $t := .Normalized.Outputs}}
+				out{{$i}} := *abi.ConvertType(out[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+				return {{range $i, $t := .Normalized.Outputs}}out{{$i}}, {{end}}nil
+				{{end}}
+			}
+		{{end}}
+	{{end}}
+
+	{{range .Events}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Normalized.Name}} event raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{capitalise .Name}} {{if .Indexed}}{{bindtopictype .Type $structs}}{{else}}{{bindtype .Type $structs}}{{end}}; {{end}}
+			Raw *types.Log // Blockchain specific contextual infos
+		}
+
+		// {{.Normalized.Name}}EventID returns the topic identifying the logs of the contract event 0x{{printf "%x" .Original.ID}}.
+		func (_{{$contract.Type}} *{{$contract.Type}}) {{.Normalized.Name}}EventID() common.Hash {
+			return _{{$contract.Type}}.abi.Events["{{.Original.Name}}"].ID
+		}
+
+		// Unpack{{.Normalized.Name}}Event unpacks a log of the contract event 0x{{printf "%x" .Original.ID}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}Event(log *types.Log) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			event := new({{$contract.Type}}{{.Normalized.Name}})
+			if err := bind.UnpackLog(&_{{$contract.Type}}.abi, event, "{{.Original.Name}}", *log); err != nil {
+				return nil, err
+			}
+			event.Raw = log
+			return event, nil
+		}
+	{{end}}
+
+	{{range .Errors}}
+		// {{$contract.Type}}{{.Normalized.Name}} represents a {{.Original.Name}} error raised by the {{$contract.Type}} contract.
+		type {{$contract.Type}}{{.Normalized.Name}} struct { {{range .Normalized.Inputs}}
+			{{.Name}} {{bindtype .Type $structs}}; {{end}}
+		}
+
+		// Unpack{{.Normalized.Name}}Error unpacks the revert data of the contract error {{.Original.Name}}.
+		//
+		// Solidity: {{.Original.String}}
+		func (_{{$contract.Type}} *{{$contract.Type}}) Unpack{{.Normalized.Name}}Error(raw []byte) (*{{$contract.Type}}{{.Normalized.Name}}, error) {
+			errABI := _{{$contract.Type}}.abi.Errors["{{.Original.Name}}"]
+			if !bytes.HasPrefix(raw, errABI.ID.Bytes()[:4]) {
+				return nil, errors.New("error signature mismatch")
+			}
+			values, err := errABI.Inputs.Unpack(raw[4:])
+			if err != nil {
+				return nil, err
+			}
+			out := new({{$contract.Type}}{{.Normalized.Name}})
+			{{range $i, $t := .Normalized.Inputs}}
+			out.{{.Name}} = *abi.ConvertType(values[{{$i}}], new({{bindtype .Type $structs}})).(*{{bindtype .Type $structs}}){{end}}
+
+			return out, nil
+		}
+	{{end}}
+
+	{{if .Errors}}
+		// UnpackError unpacks the revert data of a call into the matching custom error
+		// of the {{$contract.Type}} contract.
+		func (_{{$contract.Type}} *{{$contract.Type}}) UnpackError(raw []byte) (any, error) { {{range .Errors}}
+			if bytes.HasPrefix(raw, _{{$contract.Type}}.abi.Errors["{{.Original.Name}}"].ID.Bytes()[:4]) {
+				return _{{$contract.Type}}.Unpack{{.Normalized.Name}}Error(raw)
+			}{{end}}
+			return nil, errors.New("unknown error")
+		}
+	{{end}}
+{{end}}
+`
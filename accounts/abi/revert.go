@@ -0,0 +1,138 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package abi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RevertError is the decoded revert data of a failed contract execution. It
+// unwraps into the reason of the revert: a *CustomError if the data matches a
+// custom error of the contract ABI, a *PanicError for panics, or a plain error
+// holding the message of a revert with reason string.
+type RevertError struct {
+	Data   []byte // Raw revert data
+	reason error  // Decoded reason of the revert, nil if unknown
+}
+
+// Error implements error.
+func (e *RevertError) Error() string {
+	if e.reason == nil {
+		return "execution reverted"
+	}
+	return "execution reverted: " + e.reason.Error()
+}
+
+// Unwrap returns the decoded reason of the revert, nil if the revert data could
+// not be decoded.
+func (e *RevertError) Unwrap() error {
+	return e.reason
+}
+
+// CustomError is a custom Solidity error raised by a contract, decoded according
+// to its definition in the contract ABI.
+type CustomError struct {
+	Definition Error         // Definition of the error in the contract ABI
+	Args       []interface{} // Decoded arguments of the error
+}
+
+// Error implements error.
+func (e *CustomError) Error() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return fmt.Sprintf("%s(%s)", e.Definition.Name, strings.Join(args, ", "))
+}
+
+// Copy copies the decoded arguments of the error into the provided struct,
+// matching the argument names with the fields.
+func (e *CustomError) Copy(v interface{}) error {
+	return e.Definition.Inputs.Copy(v, e.Args)
+}
+
+// PanicError is a Solidity panic, raised by the compiler generated checks, e.g.
+// failed assertions or arithmetic overflows.
+type PanicError struct {
+	Code *big.Int // Panic code identifying the failed check
+}
+
+// Reason returns the human readable reason of the panic code.
+func (e *PanicError) Reason() string {
+	// uint64 safety check for future
+	// but the code is not bigger than MAX(uint64) now
+	if e.Code.IsUint64() {
+		if reason, ok := panicReasons[e.Code.Uint64()]; ok {
+			return reason
+		}
+	}
+	return fmt.Sprintf("unknown panic code: %#x", e.Code)
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return e.Reason()
+}
+
+// UnpackRevertError decodes the revert data of a failed contract execution.
+// Besides the custom errors defined in the ABI, the built-in Error(string) and
+// Panic(uint256) errors are decoded too. Revert data not matching any of them
+// results in an error without reason.
+func (abi *ABI) UnpackRevertError(data []byte) *RevertError {
+	revert := &RevertError{Data: data}
+	if len(data) < 4 {
+		return revert
+	}
+	switch {
+	case bytes.Equal(data[:4], revertSelector):
+		if reason, err := UnpackRevert(data); err == nil {
+			revert.reason = errors.New(reason)
+		}
+	case bytes.Equal(data[:4], panicSelector):
+		if code, err := unpackPanicCode(data); err == nil {
+			revert.reason = &PanicError{Code: code}
+		}
+	default:
+		for _, def := range abi.Errors {
+			if !bytes.Equal(data[:4], def.ID[:4]) {
+				continue
+			}
+			if args, err := def.Inputs.Unpack(data[4:]); err == nil {
+				revert.reason = &CustomError{Definition: def, Args: args}
+			}
+			break
+		}
+	}
+	return revert
+}
+
+// unpackPanicCode decodes the code of a Panic(uint256) revert.
+func unpackPanicCode(data []byte) (*big.Int, error) {
+	typ, err := NewType("uint256", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	unpacked, err := (Arguments{{Type: typ}}).Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return unpacked[0].(*big.Int), nil
+}
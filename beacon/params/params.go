@@ -43,4 +43,8 @@ const (
 	StateIndexExecHead          = 908
 
 	BodyIndexExecPayload = 25
+
+	BlockIndexExecBlockHashBellatrix = 3228
+	BlockIndexExecBlockHashCapella   = 3228 // Same depth as Bellatrix, the withdrawals fit in the padding
+	BlockIndexExecBlockHashDeneb     = 6444
 )
@@ -20,8 +20,11 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
 	"github.com/protolambda/zrnt/eth2/beacon/capella"
 	zrntcommon "github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/deneb"
@@ -47,6 +50,8 @@ func BlockFromJSON(forkName string, data []byte) (*BeaconBlock, error) {
 		obj = new(deneb.BeaconBlock)
 	case "capella":
 		obj = new(capella.BeaconBlock)
+	case "bellatrix":
+		obj = new(bellatrix.BeaconBlock)
 	default:
 		return nil, fmt.Errorf("unsupported fork: " + forkName)
 	}
@@ -59,6 +64,8 @@ func BlockFromJSON(forkName string, data []byte) (*BeaconBlock, error) {
 // NewBeaconBlock wraps a ZRNT block.
 func NewBeaconBlock(obj blockObject) *BeaconBlock {
 	switch obj := obj.(type) {
+	case *bellatrix.BeaconBlock:
+		return &BeaconBlock{obj}
 	case *capella.BeaconBlock:
 		return &BeaconBlock{obj}
 	case *deneb.BeaconBlock:
@@ -71,6 +78,8 @@ func NewBeaconBlock(obj blockObject) *BeaconBlock {
 // Slot returns the slot number of the block.
 func (b *BeaconBlock) Slot() uint64 {
 	switch obj := b.blockObj.(type) {
+	case *bellatrix.BeaconBlock:
+		return uint64(obj.Slot)
 	case *capella.BeaconBlock:
 		return uint64(obj.Slot)
 	case *deneb.BeaconBlock:
@@ -83,6 +92,8 @@ func (b *BeaconBlock) Slot() uint64 {
 // ExecutionPayload parses and returns the execution payload of the block.
 func (b *BeaconBlock) ExecutionPayload() (*types.Block, error) {
 	switch obj := b.blockObj.(type) {
+	case *bellatrix.BeaconBlock:
+		return convertPayload(&obj.Body.ExecutionPayload, &obj.ParentRoot)
 	case *capella.BeaconBlock:
 		return convertPayload(&obj.Body.ExecutionPayload, &obj.ParentRoot)
 	case *deneb.BeaconBlock:
@@ -95,6 +106,8 @@ func (b *BeaconBlock) ExecutionPayload() (*types.Block, error) {
 // Header returns the block's header data.
 func (b *BeaconBlock) Header() Header {
 	switch obj := b.blockObj.(type) {
+	case *bellatrix.BeaconBlock:
+		return headerFromZRNT(obj.Header(configs.Mainnet))
 	case *capella.BeaconBlock:
 		return headerFromZRNT(obj.Header(configs.Mainnet))
 	case *deneb.BeaconBlock:
@@ -108,3 +121,93 @@ func (b *BeaconBlock) Header() Header {
 func (b *BeaconBlock) Root() common.Hash {
 	return common.Hash(b.blockObj.HashTreeRoot(configs.Mainnet, tree.GetHashFn()))
 }
+
+// BlockHashProof returns a merkle proof of the execution block hash included in
+// the payload of the block, along with the generalized index of the block hash
+// relative to the block root.
+func (b *BeaconBlock) BlockHashProof() (uint64, merkle.Values) {
+	var (
+		spec    = configs.Mainnet
+		payload []tree.HTR
+		body    []tree.HTR
+		index   uint64
+	)
+	switch obj := b.blockObj.(type) {
+	case *bellatrix.BeaconBlock:
+		h := obj.Body.ExecutionPayload.Header(spec)
+		payload = []tree.HTR{&h.ParentHash, &h.FeeRecipient, &h.StateRoot,
+			&h.ReceiptsRoot, &h.LogsBloom, &h.PrevRandao, &h.BlockNumber, &h.GasLimit,
+			&h.GasUsed, &h.Timestamp, &h.ExtraData, &h.BaseFeePerGas, &h.BlockHash,
+			&h.TransactionsRoot}
+		body = []tree.HTR{obj.Body.RandaoReveal, &obj.Body.Eth1Data,
+			obj.Body.Graffiti, spec.Wrap(&obj.Body.ProposerSlashings),
+			spec.Wrap(&obj.Body.AttesterSlashings), spec.Wrap(&obj.Body.Attestations),
+			spec.Wrap(&obj.Body.Deposits), spec.Wrap(&obj.Body.VoluntaryExits),
+			spec.Wrap(&obj.Body.SyncAggregate), h}
+		index = params.BlockIndexExecBlockHashBellatrix
+	case *capella.BeaconBlock:
+		h := obj.Body.ExecutionPayload.Header(spec)
+		payload = []tree.HTR{&h.ParentHash, &h.FeeRecipient, &h.StateRoot,
+			&h.ReceiptsRoot, &h.LogsBloom, &h.PrevRandao, &h.BlockNumber, &h.GasLimit,
+			&h.GasUsed, &h.Timestamp, &h.ExtraData, &h.BaseFeePerGas, &h.BlockHash,
+			&h.TransactionsRoot, &h.WithdrawalsRoot}
+		body = []tree.HTR{obj.Body.RandaoReveal, &obj.Body.Eth1Data,
+			obj.Body.Graffiti, spec.Wrap(&obj.Body.ProposerSlashings),
+			spec.Wrap(&obj.Body.AttesterSlashings), spec.Wrap(&obj.Body.Attestations),
+			spec.Wrap(&obj.Body.Deposits), spec.Wrap(&obj.Body.VoluntaryExits),
+			spec.Wrap(&obj.Body.SyncAggregate), h,
+			spec.Wrap(&obj.Body.BLSToExecutionChanges)}
+		index = params.BlockIndexExecBlockHashCapella
+	case *deneb.BeaconBlock:
+		h := obj.Body.ExecutionPayload.Header(spec)
+		payload = []tree.HTR{&h.ParentHash, &h.FeeRecipient, &h.StateRoot,
+			&h.ReceiptsRoot, &h.LogsBloom, &h.PrevRandao, &h.BlockNumber, &h.GasLimit,
+			&h.GasUsed, &h.Timestamp, &h.ExtraData, &h.BaseFeePerGas, &h.BlockHash,
+			&h.TransactionsRoot, &h.WithdrawalsRoot, &h.BlobGasUsed, &h.ExcessBlobGas}
+		body = []tree.HTR{obj.Body.RandaoReveal, &obj.Body.Eth1Data,
+			obj.Body.Graffiti, spec.Wrap(&obj.Body.ProposerSlashings),
+			spec.Wrap(&obj.Body.AttesterSlashings), spec.Wrap(&obj.Body.Attestations),
+			spec.Wrap(&obj.Body.Deposits), spec.Wrap(&obj.Body.VoluntaryExits),
+			spec.Wrap(&obj.Body.SyncAggregate), h,
+			spec.Wrap(&obj.Body.BLSToExecutionChanges),
+			spec.Wrap(&obj.Body.BlobKZGCommitments)}
+		index = params.BlockIndexExecBlockHashDeneb
+	default:
+		panic(fmt.Errorf("unsupported block type %T", b.blockObj))
+	}
+	header := b.blockObj.Header(spec)
+	block := []tree.HTR{header.Slot, header.ProposerIndex, header.ParentRoot, header.StateRoot, header.BodyRoot}
+
+	// The block hash is the 13th field of the payload, which is the 10th field
+	// of the body, which is the 5th field of the block.
+	var branch merkle.Values
+	branch = append(branch, fieldProof(payload, 12)...)
+	branch = append(branch, fieldProof(body, 9)...)
+	branch = append(branch, fieldProof(block, 4)...)
+	return index, branch
+}
+
+// fieldProof returns the merkle branch of a field of an SSZ container, given
+// the values of its fields.
+func fieldProof(fields []tree.HTR, index int) merkle.Values {
+	var (
+		hFn   = tree.GetHashFn()
+		nodes = make([]tree.Root, 1)
+	)
+	for len(nodes) < len(fields) {
+		nodes = append(nodes, nodes...)
+	}
+	for i, field := range fields {
+		nodes[i] = field.HashTreeRoot(hFn)
+	}
+	var branch merkle.Values
+	for ; len(nodes) > 1; index /= 2 {
+		branch = append(branch, merkle.Value(nodes[index^1]))
+		parents := make([]tree.Root, len(nodes)/2)
+		for i := range parents {
+			parents[i] = hFn(nodes[2*i], nodes[2*i+1])
+		}
+		nodes = parents
+	}
+	return branch
+}
@@ -17,11 +17,16 @@
 package types
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	zrntcommon "github.com/protolambda/zrnt/eth2/beacon/common"
 )
 
 func TestBlockFromJSON(t *testing.T) {
@@ -72,6 +77,35 @@ func TestBlockFromJSON(t *testing.T) {
 			if execBlock.Hash() != test.wantBlockHash {
 				t.Errorf("wrong block hash: %v", execBlock.Hash())
 			}
+			index, branch := beaconBlock.BlockHashProof()
+			if err := merkle.VerifyProof(beaconBlock.Root(), index, branch, merkle.Value(test.wantBlockHash)); err != nil {
+				t.Errorf("invalid block hash proof: %v", err)
+			}
 		})
 	}
 }
+
+func TestBlockHashProofBellatrix(t *testing.T) {
+	obj := &bellatrix.BeaconBlock{Slot: 4700013, ProposerIndex: 1}
+	obj.Body.ExecutionPayload.BlockNumber = 15537394
+	obj.Body.ExecutionPayload.BlockHash = zrntcommon.Hash32{0x56, 0xa9}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beaconBlock, err := BlockFromJSON("bellatrix", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beaconBlock.Slot() != 4700013 {
+		t.Errorf("wrong slot number %d", beaconBlock.Slot())
+	}
+	index, branch := beaconBlock.BlockHashProof()
+	if index != params.BlockIndexExecBlockHashBellatrix {
+		t.Errorf("wrong block hash index %d", index)
+	}
+	if err := merkle.VerifyProof(beaconBlock.Root(), index, branch, merkle.Value(obj.Body.ExecutionPayload.BlockHash)); err != nil {
+		t.Errorf("invalid block hash proof: %v", err)
+	}
+}
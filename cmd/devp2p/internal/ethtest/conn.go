@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
@@ -66,13 +67,25 @@ func (s *Suite) dialAs(key *ecdsa.PrivateKey) (*Conn, error) {
 		return nil, err
 	}
 	conn.caps = []p2p.Cap{
-		{Name: "eth", Version: 67},
 		{Name: "eth", Version: 68},
+		{Name: "eth", Version: 69},
 	}
-	conn.ourHighestProtoVersion = 68
+	conn.ourHighestProtoVersion = 69
 	return &conn, nil
 }
 
+// dialVersion creates a connection only advertising the given eth protocol
+// version.
+func (s *Suite) dialVersion(version uint) (*Conn, error) {
+	conn, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	conn.caps = []p2p.Cap{{Name: "eth", Version: version}}
+	conn.ourHighestProtoVersion = version
+	return conn, nil
+}
+
 // dialSnap creates a connection with snap/1 capability.
 func (s *Suite) dialSnap() (*Conn, error) {
 	conn, err := s.dial()
@@ -151,7 +164,11 @@ func (c *Conn) ReadEth() (any, error) {
 		var msg any
 		switch int(code) {
 		case eth.StatusMsg:
-			msg = new(eth.StatusPacket)
+			if c.negotiatedProtoVersion >= eth.ETH69 {
+				msg = new(eth.StatusPacket69)
+			} else {
+				msg = new(eth.StatusPacket68)
+			}
 		case eth.GetBlockHeadersMsg:
 			msg = new(eth.GetBlockHeadersPacket)
 		case eth.BlockHeadersMsg:
@@ -172,6 +189,8 @@ func (c *Conn) ReadEth() (any, error) {
 			msg = new(eth.GetPooledTransactionsPacket)
 		case eth.PooledTransactionsMsg:
 			msg = new(eth.PooledTransactionsPacket)
+		case eth.BlockRangeUpdateMsg:
+			msg = new(eth.BlockRangeUpdatePacket)
 		default:
 			panic(fmt.Sprintf("unhandled eth msg code %d", code))
 		}
@@ -225,8 +244,9 @@ func (c *Conn) ReadSnap() (any, error) {
 }
 
 // peer performs both the protocol handshake and the status message
-// exchange with the node in order to peer with it.
-func (c *Conn) peer(chain *Chain, status *eth.StatusPacket) error {
+// exchange with the node in order to peer with it. The status message to
+// send is picked based on the negotiated version if none is given.
+func (c *Conn) peer(chain *Chain, status any) error {
 	if err := c.handshake(); err != nil {
 		return fmt.Errorf("handshake failed: %v", err)
 	}
@@ -299,7 +319,7 @@ func (c *Conn) negotiateEthProtocol(caps []p2p.Cap) {
 }
 
 // statusExchange performs a `Status` message exchange with the given node.
-func (c *Conn) statusExchange(chain *Chain, status *eth.StatusPacket) error {
+func (c *Conn) statusExchange(chain *Chain, status any) error {
 loop:
 	for {
 		code, data, err := c.Read()
@@ -308,22 +328,8 @@ loop:
 		}
 		switch code {
 		case eth.StatusMsg + protoOffset(ethProto):
-			msg := new(eth.StatusPacket)
-			if err := rlp.DecodeBytes(data, &msg); err != nil {
-				return fmt.Errorf("error decoding status packet: %w", err)
-			}
-			if have, want := msg.Head, chain.blocks[chain.Len()-1].Hash(); have != want {
-				return fmt.Errorf("wrong head block in status, want:  %#x (block %d) have %#x",
-					want, chain.blocks[chain.Len()-1].NumberU64(), have)
-			}
-			if have, want := msg.TD.Cmp(chain.TD()), 0; have != want {
-				return fmt.Errorf("wrong TD in status: have %v want %v", have, want)
-			}
-			if have, want := msg.ForkID, chain.ForkID(); !reflect.DeepEqual(have, want) {
-				return fmt.Errorf("wrong fork ID in status: have %v, want %v", have, want)
-			}
-			if have, want := msg.ProtocolVersion, c.ourHighestProtoVersion; have != uint32(want) {
-				return fmt.Errorf("wrong protocol version: have %v, want %v", have, want)
+			if err := c.checkStatus(chain, data); err != nil {
+				return err
 			}
 			break loop
 		case discMsg:
@@ -346,13 +352,26 @@ loop:
 	}
 	if status == nil {
 		// default status message
-		status = &eth.StatusPacket{
-			ProtocolVersion: uint32(c.negotiatedProtoVersion),
-			NetworkID:       chain.config.ChainID.Uint64(),
-			TD:              chain.TD(),
-			Head:            chain.blocks[chain.Len()-1].Hash(),
-			Genesis:         chain.blocks[0].Hash(),
-			ForkID:          chain.ForkID(),
+		if c.negotiatedProtoVersion >= eth.ETH69 {
+			head := chain.Head()
+			status = &eth.StatusPacket69{
+				ProtocolVersion: uint32(c.negotiatedProtoVersion),
+				NetworkID:       chain.config.ChainID.Uint64(),
+				Genesis:         chain.blocks[0].Hash(),
+				ForkID:          chain.ForkID(),
+				EarliestBlock:   0,
+				LatestBlock:     head.NumberU64(),
+				LatestBlockHash: head.Hash(),
+			}
+		} else {
+			status = &eth.StatusPacket68{
+				ProtocolVersion: uint32(c.negotiatedProtoVersion),
+				NetworkID:       chain.config.ChainID.Uint64(),
+				TD:              chain.TD(),
+				Head:            chain.blocks[chain.Len()-1].Hash(),
+				Genesis:         chain.blocks[0].Hash(),
+				ForkID:          chain.ForkID(),
+			}
 		}
 	}
 	if err := c.Write(ethProto, eth.StatusMsg, status); err != nil {
@@ -360,3 +379,50 @@ loop:
 	}
 	return nil
 }
+
+// checkStatus decodes the status message received from the node according to
+// the negotiated protocol version and verifies it against the test chain.
+func (c *Conn) checkStatus(chain *Chain, data []byte) error {
+	var (
+		head    = chain.blocks[chain.Len()-1]
+		version uint32
+		forkID  forkid.ID
+	)
+	if c.negotiatedProtoVersion >= eth.ETH69 {
+		msg := new(eth.StatusPacket69)
+		if err := rlp.DecodeBytes(data, &msg); err != nil {
+			return fmt.Errorf("error decoding status packet: %w", err)
+		}
+		if have, want := msg.LatestBlockHash, head.Hash(); have != want {
+			return fmt.Errorf("wrong latest block in status, want: %#x (block %d) have %#x",
+				want, head.NumberU64(), have)
+		}
+		if have, want := msg.LatestBlock, head.NumberU64(); have != want {
+			return fmt.Errorf("wrong latest block number in status: have %d want %d", have, want)
+		}
+		if msg.EarliestBlock > msg.LatestBlock {
+			return fmt.Errorf("invalid block range in status: earliest %d > latest %d", msg.EarliestBlock, msg.LatestBlock)
+		}
+		version, forkID = msg.ProtocolVersion, msg.ForkID
+	} else {
+		msg := new(eth.StatusPacket68)
+		if err := rlp.DecodeBytes(data, &msg); err != nil {
+			return fmt.Errorf("error decoding status packet: %w", err)
+		}
+		if have, want := msg.Head, head.Hash(); have != want {
+			return fmt.Errorf("wrong head block in status, want:  %#x (block %d) have %#x",
+				want, head.NumberU64(), have)
+		}
+		if have, want := msg.TD.Cmp(chain.TD()), 0; have != want {
+			return fmt.Errorf("wrong TD in status: have %v want %v", have, want)
+		}
+		version, forkID = msg.ProtocolVersion, msg.ForkID
+	}
+	if have, want := forkID, chain.ForkID(); !reflect.DeepEqual(have, want) {
+		return fmt.Errorf("wrong fork ID in status: have %v, want %v", have, want)
+	}
+	if have, want := version, c.ourHighestProtoVersion; have != uint32(want) {
+		return fmt.Errorf("wrong protocol version: have %v, want %v", have, want)
+	}
+	return nil
+}
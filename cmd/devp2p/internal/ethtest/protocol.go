@@ -28,10 +28,11 @@ const (
 	pongMsg      = 0x03
 )
 
-// Unexported devp2p protocol lengths from p2p package.
+// Unexported devp2p protocol lengths from p2p package. The eth protocol length
+// is the one of eth/69, snap message codes are only valid on top of it.
 const (
 	baseProtoLen = 16
-	ethProtoLen  = 17
+	ethProtoLen  = 18
 	snapProtoLen = 8
 )
 
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/utesting"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/trie"
 	"github.com/holiman/uint256"
 )
 
@@ -65,6 +66,7 @@ func (s *Suite) EthTests() []utesting.Test {
 	return []utesting.Test{
 		// status
 		{Name: "Status", Fn: s.TestStatus},
+		{Name: "Status68", Fn: s.TestStatus68},
 		// get block headers
 		{Name: "GetBlockHeaders", Fn: s.TestGetBlockHeaders},
 		{Name: "SimultaneousRequests", Fn: s.TestSimultaneousRequests},
@@ -72,9 +74,16 @@ func (s *Suite) EthTests() []utesting.Test {
 		{Name: "ZeroRequestID", Fn: s.TestZeroRequestID},
 		// get block bodies
 		{Name: "GetBlockBodies", Fn: s.TestGetBlockBodies},
+		// get receipts
+		{Name: "GetReceipts", Fn: s.TestGetReceipts},
+		{Name: "GetReceipts68", Fn: s.TestGetReceipts68},
+		// block range updates
+		{Name: "BlockRangeUpdate", Fn: s.TestBlockRangeUpdate},
+		{Name: "BlockRangeUpdateInvalid", Fn: s.TestBlockRangeUpdateInvalid},
 		// // malicious handshakes + status
 		{Name: "MaliciousHandshake", Fn: s.TestMaliciousHandshake},
 		{Name: "MaliciousStatus", Fn: s.TestMaliciousStatus},
+		{Name: "InvalidBlockRangeStatus", Fn: s.TestInvalidBlockRangeStatus},
 		// test transactions
 		{Name: "LargeTxRequest", Fn: s.TestLargeTxRequest, Slow: true},
 		{Name: "Transaction", Fn: s.TestTransaction},
@@ -107,6 +116,19 @@ func (s *Suite) TestStatus(t *utesting.T) {
 	}
 }
 
+func (s *Suite) TestStatus68(t *utesting.T) {
+	t.Log(`This test performs an eth protocol handshake at protocol version eth/68.`)
+
+	conn, err := s.dialVersion(eth.ETH68)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+}
+
 // headersMatch returns whether the received headers match the given request
 func headersMatch(expected []*types.Header, headers []*types.Header) bool {
 	return reflect.DeepEqual(expected, headers)
@@ -456,7 +478,7 @@ func (s *Suite) TestMaliciousHandshake(t *utesting.T) {
 func (s *Suite) TestMaliciousStatus(t *utesting.T) {
 	t.Log(`This test sends a malicious eth Status message to the node and expects a disconnect.`)
 
-	conn, err := s.dial()
+	conn, err := s.dialVersion(eth.ETH68)
 	if err != nil {
 		t.Fatalf("dial failed: %v", err)
 	}
@@ -465,7 +487,7 @@ func (s *Suite) TestMaliciousStatus(t *utesting.T) {
 		t.Fatalf("handshake failed: %v", err)
 	}
 	// Create status with large total difficulty.
-	status := &eth.StatusPacket{
+	status := &eth.StatusPacket68{
 		ProtocolVersion: uint32(conn.negotiatedProtoVersion),
 		NetworkID:       s.chain.config.ChainID.Uint64(),
 		TD:              new(big.Int).SetBytes(randBuf(2048)),
@@ -489,6 +511,181 @@ func (s *Suite) TestMaliciousStatus(t *utesting.T) {
 	}
 }
 
+func (s *Suite) TestInvalidBlockRangeStatus(t *utesting.T) {
+	t.Log(`This test sends an eth/69 Status message with an invalid block range to the
+node and expects a disconnect.`)
+
+	conn, err := s.dialVersion(eth.ETH69)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.handshake(); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	// Create status with the earliest block above the latest one.
+	head := s.chain.Head()
+	status := &eth.StatusPacket69{
+		ProtocolVersion: uint32(conn.negotiatedProtoVersion),
+		NetworkID:       s.chain.config.ChainID.Uint64(),
+		Genesis:         s.chain.GetBlock(0).Hash(),
+		ForkID:          s.chain.ForkID(),
+		EarliestBlock:   head.NumberU64() + 1,
+		LatestBlock:     head.NumberU64(),
+		LatestBlockHash: head.Hash(),
+	}
+	if err := conn.statusExchange(s.chain, status); err != nil {
+		t.Fatalf("status exchange failed: %v", err)
+	}
+	// Wait for disconnect.
+	code, _, err := conn.Read()
+	if err != nil {
+		t.Fatalf("error reading from connection: %v", err)
+	}
+	switch code {
+	case discMsg:
+		break
+	default:
+		t.Fatalf("expected disconnect, got: %d", code)
+	}
+}
+
+func (s *Suite) TestGetReceipts(t *utesting.T) {
+	t.Log(`This test sends GetReceipts requests to the node over eth/69 for known blocks
+in the test chain and checks that the bloom-less receipts match the receipt roots.`)
+	s.testGetReceipts(t, eth.ETH69)
+}
+
+func (s *Suite) TestGetReceipts68(t *utesting.T) {
+	t.Log(`This test sends GetReceipts requests to the node over eth/68 for known blocks
+in the test chain and checks that the receipts match the receipt roots.`)
+	s.testGetReceipts(t, eth.ETH68)
+}
+
+func (s *Suite) testGetReceipts(t *utesting.T, version uint) {
+	conn, err := s.dialVersion(version)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	// Create receipts request for a range of blocks.
+	var hashes []common.Hash
+	for i := 1; i < s.chain.Len() && len(hashes) < 32; i++ {
+		hashes = append(hashes, s.chain.blocks[i].Hash())
+	}
+	req := &eth.GetReceiptsPacket{
+		RequestId:          66,
+		GetReceiptsRequest: hashes,
+	}
+	if err := conn.Write(ethProto, eth.GetReceiptsMsg, req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	// Wait for response.
+	var (
+		id       uint64
+		receipts eth.ReceiptsResponse
+	)
+	if version >= eth.ETH69 {
+		resp := new(eth.ReceiptsPacket69)
+		if err := conn.ReadMsg(ethProto, eth.ReceiptsMsg, &resp); err != nil {
+			t.Fatalf("error reading receipts msg: %v", err)
+		}
+		if receipts, err = resp.ReceiptsResponse69.Unpack(); err != nil {
+			t.Fatalf("invalid receipts in response: %v", err)
+		}
+		id = resp.RequestId
+	} else {
+		resp := new(eth.ReceiptsPacket)
+		if err := conn.ReadMsg(ethProto, eth.ReceiptsMsg, &resp); err != nil {
+			t.Fatalf("error reading receipts msg: %v", err)
+		}
+		id, receipts = resp.RequestId, resp.ReceiptsResponse
+	}
+	if id != req.RequestId {
+		t.Fatalf("unexpected request id in response: got %d, want %d", id, req.RequestId)
+	}
+	if len(receipts) != len(hashes) {
+		t.Fatalf("wrong receipts in response: expected %d lists, got %d", len(hashes), len(receipts))
+	}
+	for i, list := range receipts {
+		header := s.chain.blocks[i+1].Header()
+		if root := types.DeriveSha(types.Receipts(list), trie.NewStackTrie(nil)); root != header.ReceiptHash {
+			t.Fatalf("receipt root mismatch for block %d: have %x, want %x", header.Number, root, header.ReceiptHash)
+		}
+	}
+}
+
+func (s *Suite) TestBlockRangeUpdate(t *utesting.T) {
+	t.Log(`This test sends a valid eth/69 BlockRangeUpdate message to the node and expects
+the connection to remain usable.`)
+
+	conn, err := s.dialVersion(eth.ETH69)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	head := s.chain.Head()
+	update := &eth.BlockRangeUpdatePacket{
+		EarliestBlock:   1,
+		LatestBlock:     head.NumberU64(),
+		LatestBlockHash: head.Hash(),
+	}
+	if err := conn.Write(ethProto, eth.BlockRangeUpdateMsg, update); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	// Make sure the node still serves requests.
+	req := &eth.GetBlockHeadersPacket{
+		RequestId: 77,
+		GetBlockHeadersRequest: &eth.GetBlockHeadersRequest{
+			Origin: eth.HashOrNumber{Number: 1},
+			Amount: 1,
+		},
+	}
+	if err := conn.Write(ethProto, eth.GetBlockHeadersMsg, req); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	headers := new(eth.BlockHeadersPacket)
+	if err := conn.ReadMsg(ethProto, eth.BlockHeadersMsg, &headers); err != nil {
+		t.Fatalf("error reading msg: %v", err)
+	}
+	if got, want := headers.RequestId, req.RequestId; got != want {
+		t.Fatalf("unexpected request id: got %d, want %d", got, want)
+	}
+}
+
+func (s *Suite) TestBlockRangeUpdateInvalid(t *utesting.T) {
+	t.Log(`This test sends an eth/69 BlockRangeUpdate message with an invalid range to
+the node and expects a disconnect.`)
+
+	conn, err := s.dialVersion(eth.ETH69)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.peer(s.chain, nil); err != nil {
+		t.Fatalf("peering failed: %v", err)
+	}
+	head := s.chain.Head()
+	update := &eth.BlockRangeUpdatePacket{
+		EarliestBlock:   head.NumberU64() + 1,
+		LatestBlock:     head.NumberU64(),
+		LatestBlockHash: head.Hash(),
+	}
+	if err := conn.Write(ethProto, eth.BlockRangeUpdateMsg, update); err != nil {
+		t.Fatalf("could not write to connection: %v", err)
+	}
+	// Wait for disconnect.
+	if err := conn.ReadMsg(baseProto, discMsg, new([]p2p.DiscReason)); err != nil {
+		t.Fatalf("expected disconnect, got: %v", err)
+	}
+}
+
 func (s *Suite) TestTransaction(t *utesting.T) {
 	t.Log(`This test sends a valid transaction to the node and checks if the
 transaction gets propagated.`)
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/erae"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/params"
@@ -42,12 +43,12 @@ var app = flags.NewApp("go-ethereum era tool")
 var (
 	dirFlag = &cli.StringFlag{
 		Name:  "dir",
-		Usage: "directory storing all relevant era1 files",
+		Usage: "directory storing all relevant era1 and erae files",
 		Value: "eras",
 	}
 	networkFlag = &cli.StringFlag{
 		Name:  "network",
-		Usage: "network name associated with era1 files",
+		Usage: "network name associated with era1 and erae files",
 		Value: "mainnet",
 	}
 	eraSizeFlag = &cli.IntFlag{
@@ -80,7 +81,7 @@ var (
 	verifyCommand = &cli.Command{
 		Name:      "verify",
 		ArgsUsage: "<expected>",
-		Usage:     "verifies each era1 against expected accumulator root",
+		Usage:     "verifies each era1 and erae against expected accumulator root",
 		Action:    verify,
 	}
 )
@@ -202,13 +203,16 @@ func verify(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("error reading %s: %w", dir, err)
 	}
-
-	if len(entries) != len(roots) {
-		return errors.New("number of era1 files should match the number of accumulator hashes")
+	postEntries, err := erae.ReadDir(dir, network)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", dir, err)
+	}
+	if len(entries)+len(postEntries) != len(roots) {
+		return errors.New("number of era1 and erae files should match the number of accumulator hashes")
 	}
 
 	// Verify each epoch matches the expected root.
-	for i, want := range roots {
+	for i, want := range roots[:len(entries)] {
 		// Wrap in function so defers don't stack.
 		err := func() error {
 			name := entries[i]
@@ -238,10 +242,109 @@ func verify(ctx *cli.Context) error {
 			return err
 		}
 	}
-
+	// Verify each post-merge epoch matches the expected root, carrying the
+	// beacon root of the last block over to check the next file against.
+	var beaconRoot *common.Hash
+	for i, want := range roots[len(entries):] {
+		err := func() error {
+			name := postEntries[i]
+			e, err := erae.Open(filepath.Join(dir, name))
+			if err != nil {
+				return fmt.Errorf("error opening erae file %s: %w", name, err)
+			}
+			defer e.Close()
+			// Read accumulator and check against expected.
+			if got, err := e.Accumulator(); err != nil {
+				return fmt.Errorf("error retrieving accumulator for %s: %w", name, err)
+			} else if got != want {
+				return fmt.Errorf("invalid root %s: got %s, want %s", name, got, want)
+			}
+			// Verify the blocks and their proofs.
+			if beaconRoot, err = checkProofs(e, beaconRoot); err != nil {
+				return fmt.Errorf("error verify erae file %s: %w", name, err)
+			}
+			if time.Since(reported) >= 8*time.Second {
+				fmt.Printf("Verifying EraE files \t\t verified=%d,\t elapsed=%s\n", i, common.PrettyDuration(time.Since(start)))
+				reported = time.Now()
+			}
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// checkProofs verifies the blocks in the EraE are anchored to the beacon chain
+// by their proofs, given the beacon root of the block preceding them if known.
+// It returns the beacon root of the last block.
+func checkProofs(e *erae.Era, beaconRoot *common.Hash) (*common.Hash, error) {
+	want, err := e.Accumulator()
+	if err != nil {
+		return nil, fmt.Errorf("error reading accumulator: %w", err)
+	}
+	it, err := erae.NewIterator(e)
+	if err != nil {
+		return nil, fmt.Errorf("error making erae iterator: %w", err)
+	}
+	// To fully verify an erae the following attributes must be checked:
+	//   1) the block index is constructed correctly
+	//   2) the tx, withdrawal and receipt roots match the values in the block
+	//   3) the proof anchors the block hash to its beacon root
+	//   4) the beacon root matches the one committed to by the next block
+	//   5) the accumulator is correct by recomputing it locally
+	var roots []common.Hash
+	for it.Next() {
+		// 1) next() walks the block index, so we're able to implicitly verify it.
+		if it.Error() != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), it.Error())
+		}
+		block, receipts, err := it.BlockAndReceipts()
+		if err != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), err)
+		}
+		// 2) recompute the roots and verify them against the header.
+		if tr := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); tr != block.TxHash() {
+			return nil, fmt.Errorf("tx root in block %d mismatch: want %s, got %s", block.NumberU64(), block.TxHash(), tr)
+		}
+		if rr := types.DeriveSha(receipts, trie.NewStackTrie(nil)); rr != block.ReceiptHash() {
+			return nil, fmt.Errorf("receipt root in block %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), rr)
+		}
+		if wh := block.Header().WithdrawalsHash; wh != nil {
+			if wr := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); wr != *wh {
+				return nil, fmt.Errorf("withdrawal root in block %d mismatch: want %s, got %s", block.NumberU64(), *wh, wr)
+			}
+		}
+		// 3) verify the proof of the block.
+		proof, err := it.Proof()
+		if err != nil {
+			return nil, fmt.Errorf("error reading proof %d: %w", it.Number(), err)
+		}
+		if err := proof.Verify(block.Hash()); err != nil {
+			return nil, fmt.Errorf("invalid proof of block %d: %w", block.NumberU64(), err)
+		}
+		// 4) check the beacon root of the parent against the proven one.
+		if root := block.BeaconRoot(); root != nil && beaconRoot != nil && *root != *beaconRoot {
+			return nil, fmt.Errorf("parent beacon root in block %d mismatch: want %s, got %s", block.NumberU64(), *beaconRoot, *root)
+		}
+		beaconRoot = &proof.BeaconRoot
+		roots = append(roots, proof.BeaconRoot)
+	}
+	if it.Error() != nil {
+		return nil, it.Error()
+	}
+	// 5) Verify accumulator.
+	got, err := erae.ComputeAccumulator(roots)
+	if err != nil {
+		return nil, fmt.Errorf("error computing accumulator: %w", err)
+	}
+	if got != want {
+		return nil, fmt.Errorf("expected accumulator root does not match calculated: got %s, want %s", got, want)
+	}
+	return beaconRoot, nil
+}
+
 // checkAccumulator verifies the accumulator matches the data in the Era.
 func checkAccumulator(e *era.Era) error {
 	var (
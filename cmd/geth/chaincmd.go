@@ -23,9 +23,11 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/beacon/light/api"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -140,7 +142,8 @@ be gzipped.`,
 		),
 		Description: `
 The import-history command will import blocks and their corresponding receipts
-from Era archives.
+from Era archives. The blocks after the merge are imported from EraE archives,
+after verifying their proofs of inclusion in the beacon chain.
 `,
 	}
 	exportHistoryCommand = &cli.Command{
@@ -148,10 +151,30 @@ from Era archives.
 		Name:      "export-history",
 		Usage:     "Export blockchain history to Era archives",
 		ArgsUsage: "<dir> <first> <last>",
-		Flags:     flags.Merge(utils.DatabaseFlags),
+		Flags: flags.Merge([]cli.Flag{
+			utils.BeaconApiFlag,
+			utils.BeaconApiHeaderFlag,
+		}, utils.DatabaseFlags),
 		Description: `
 The export-history command will export blocks and their corresponding receipts
 into Era archives. Eras are typically packaged in steps of 8192 blocks.
+
+The blocks after the merge are exported into EraE archives, along with proofs of
+their inclusion in the beacon chain. The proofs are built from the beacon blocks
+retrieved from the beacon node API given by --beacon.api, walking the beacon
+chain backwards from the root committed to by the first descendant of the last
+exported block. Proofs are supported for Bellatrix and later beacon blocks.
+`,
+	}
+	pruneHistoryCommand = &cli.Command{
+		Action: pruneHistory,
+		Name:   "prune-history",
+		Usage:  "Prune the pre-merge chain history",
+		Flags:  flags.Merge(utils.DatabaseFlags, utils.NetworkFlags),
+		Description: `
+The prune-history command discards the block bodies and receipts of the blocks
+before the merge from the ancient store. The block headers are retained. The
+pruned history can be restored from Era archives with the import-history command.
 `,
 	}
 	importPreimagesCommand = &cli.Command{
@@ -485,7 +508,19 @@ func exportHistory(ctx *cli.Context) error {
 	if head := chain.CurrentSnapBlock(); uint64(last) > head.Number.Uint64() {
 		utils.Fatalf("Export error: block number %d larger than head block %d\n", uint64(last), head.Number.Uint64())
 	}
-	err := utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size))
+	var source utils.BeaconBlockSource
+	if urls := ctx.StringSlice(utils.BeaconApiFlag.Name); len(urls) > 0 {
+		headers := make(map[string]string)
+		for _, s := range ctx.StringSlice(utils.BeaconApiHeaderFlag.Name) {
+			kv := strings.Split(s, ":")
+			if len(kv) != 2 {
+				utils.Fatalf("Invalid custom API header entry: %s", s)
+			}
+			headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+		source = api.NewBeaconLightApi(urls[0], headers)
+	}
+	err := utils.ExportHistory(chain, dir, uint64(first), uint64(last), uint64(era.MaxEra1Size), source)
 	if err != nil {
 		utils.Fatalf("Export error: %v\n", err)
 	}
@@ -493,6 +528,23 @@ func exportHistory(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneHistory discards the pre-merge chain history from the ancient store.
+func pruneHistory(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+	start := time.Now()
+
+	cutoff, err := core.PruneChainHistory(db)
+	if err != nil {
+		utils.Fatalf("Prune error: %v\n", err)
+	}
+	fmt.Printf("Pruned history before block %d in %v\n", cutoff, time.Since(start))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 // it is deprecated, and the export function has been removed, but
 // the import function is kept around for the time being so that
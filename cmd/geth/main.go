@@ -70,6 +70,7 @@ var (
 		utils.TxPoolLocalsFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
+		utils.TxPoolRemoteJournalFlag,
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
@@ -78,6 +79,9 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolAllowSendersFlag,
+		utils.TxPoolAllowRecipientsFlag,
+		utils.TxPoolDenySelectorsFlag,
 		utils.BlobPoolDataDirFlag,
 		utils.BlobPoolDataCapFlag,
 		utils.BlobPoolPriceBumpFlag,
@@ -88,7 +92,13 @@ var (
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
+		utils.LogHistoryFlag,
+		utils.LogNoHistoryFlag,
 		utils.StateHistoryFlag,
+		utils.StateIndexingFlag,
+		utils.StatePruningFlag,
+		utils.StatePruningThrottleFlag,
+		utils.StatePruningIntervalFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
 		utils.LightEgressFlag,   // deprecated
@@ -121,6 +131,9 @@ var (
 		utils.MinerEtherbaseFlag, // deprecated
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
+		utils.MinerOrderingFlag,
+		utils.MinerSenderCapFlag,
+		utils.MinerBundlesFlag,
 		utils.MinerPendingFeeRecipientFlag,
 		utils.MinerNewPayloadTimeoutFlag, // deprecated
 		utils.NATFlag,
@@ -184,10 +197,15 @@ var (
 		utils.InsecureUnlockAllowedFlag,
 		utils.RPCGlobalGasCapFlag,
 		utils.RPCGlobalEVMTimeoutFlag,
+		utils.RPCTraceFilterCapFlag,
 		utils.RPCGlobalTxFeeCapFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateLimitBurstFlag,
+		utils.RPCRateLimitCostsFlag,
+		utils.RPCRateLimitAuthFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -220,6 +238,7 @@ func init() {
 		exportCommand,
 		importHistoryCommand,
 		exportHistoryCommand,
+		pruneHistoryCommand,
 		importPreimagesCommand,
 		removedbCommand,
 		dumpCommand,
@@ -29,10 +29,13 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
+	btypes "github.com/ethereum/go-ethereum/beacon/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -43,6 +46,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/erae"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
@@ -242,30 +246,66 @@ func readList(filename string) ([]string, error) {
 }
 
 // ImportHistory imports Era1 files containing historical block information,
-// starting from genesis.
+// starting from genesis. The post-merge blocks are imported from EraE files
+// following them, after checking their proofs against the beacon chain.
+//
+// If the pre-merge chain history has been pruned, the import backfills the
+// bodies and receipts of the pruned blocks instead, resuming from the first
+// block still missing them.
 func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, network string) error {
-	if chain.CurrentSnapBlock().Number.BitLen() != 0 {
-		return errors.New("history import only supported when starting from genesis")
+	cutoff := chain.HistoryPruningCutoff()
+	if cutoff == 0 && chain.CurrentSnapBlock().Number.BitLen() != 0 {
+		return errors.New("history import only supported when starting from genesis or backfilling pruned history")
 	}
 	entries, err := era.ReadDir(dir, network)
 	if err != nil {
 		return fmt.Errorf("error reading %s: %w", dir, err)
 	}
+	postEntries, err := erae.ReadDir(dir, network)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", dir, err)
+	}
 	checksums, err := readList(filepath.Join(dir, "checksums.txt"))
 	if err != nil {
 		return fmt.Errorf("unable to read checksums.txt: %w", err)
 	}
-	if len(checksums) != len(entries) {
-		return fmt.Errorf("expected equal number of checksums and entries, have: %d checksums, %d entries", len(checksums), len(entries))
+	if len(checksums) != len(entries)+len(postEntries) {
+		return fmt.Errorf("expected equal number of checksums and entries, have: %d checksums, %d entries", len(checksums), len(entries)+len(postEntries))
 	}
 	var (
 		start    = time.Now()
 		reported = time.Now()
 		imported = 0
 		forker   = core.NewForkChoice(chain, nil)
-		h        = sha256.New()
-		buf      = bytes.NewBuffer(nil)
+		next     uint64 // First block with missing history when backfilling
 	)
+	if cutoff != 0 {
+		if next, err = rawdb.ReadAncientBackfillProgress(db); err != nil {
+			return fmt.Errorf("unable to read backfill progress: %w", err)
+		}
+		next = max(next, 1)
+		log.Info("Backfilling pruned chain history", "first", next, "cutoff", cutoff)
+	}
+	// insert imports a block along with its receipts.
+	insert := func(block *types.Block, receipts types.Receipts) error {
+		if status, err := chain.HeaderChain().InsertHeaderChain([]*types.Header{block.Header()}, start, forker); err != nil {
+			return fmt.Errorf("error inserting header %d: %w", block.NumberU64(), err)
+		} else if status != core.CanonStatTy {
+			return fmt.Errorf("error inserting header %d, not canon: %v", block.NumberU64(), status)
+		}
+		if _, err := chain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{receipts}, 2^64-1); err != nil {
+			return fmt.Errorf("error inserting body %d: %w", block.NumberU64(), err)
+		}
+		imported += 1
+
+		// Give the user some feedback that something is happening.
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Importing Era files", "head", block.NumberU64(), "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
+			imported = 0
+			reported = time.Now()
+		}
+		return nil
+	}
 	for i, filename := range entries {
 		err := func() error {
 			f, err := os.Open(filepath.Join(dir, filename))
@@ -274,16 +314,22 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 			}
 			defer f.Close()
 
-			// Validate checksum.
-			if _, err := io.Copy(h, f); err != nil {
-				return fmt.Errorf("unable to recalculate checksum: %w", err)
+			// Skip the archives whose blocks don't need to be backfilled.
+			if cutoff != 0 {
+				e, err := era.From(f)
+				if err != nil {
+					return fmt.Errorf("error opening era: %w", err)
+				}
+				if e.Start()+e.Count() <= next || e.Start() >= cutoff {
+					return nil
+				}
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					return fmt.Errorf("unable to rewind era: %w", err)
+				}
 			}
-			if have, want := common.BytesToHash(h.Sum(buf.Bytes()[:])).Hex(), checksums[i]; have != want {
-				return fmt.Errorf("checksum mismatch: have %s, want %s", have, want)
+			if err := verifyChecksum(f, checksums[i]); err != nil {
+				return err
 			}
-			h.Reset()
-			buf.Reset()
-
 			// Import all block data from Era1.
 			e, err := era.From(f)
 			if err != nil {
@@ -293,6 +339,10 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 			if err != nil {
 				return fmt.Errorf("error making era reader: %w", err)
 			}
+			var (
+				history         []*types.Block // Blocks to backfill
+				historyReceipts []types.Receipts
+			)
 			for it.Next() {
 				block, err := it.Block()
 				if err != nil {
@@ -301,25 +351,89 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 				if block.Number().BitLen() == 0 {
 					continue // skip genesis
 				}
+				if cutoff != 0 && (block.NumberU64() < next || block.NumberU64() >= cutoff) {
+					continue // skip blocks not pruned
+				}
 				receipts, err := it.Receipts()
 				if err != nil {
 					return fmt.Errorf("error reading receipts %d: %w", it.Number(), err)
 				}
-				if status, err := chain.HeaderChain().InsertHeaderChain([]*types.Header{block.Header()}, start, forker); err != nil {
-					return fmt.Errorf("error inserting header %d: %w", it.Number(), err)
-				} else if status != core.CanonStatTy {
-					return fmt.Errorf("error inserting header %d, not canon: %v", it.Number(), status)
+				if cutoff != 0 {
+					history = append(history, block)
+					historyReceipts = append(historyReceipts, receipts)
+					continue
+				}
+				if err := insert(block, receipts); err != nil {
+					return err
+				}
+			}
+			if len(history) == 0 {
+				return nil
+			}
+			last := history[len(history)-1].NumberU64()
+			if _, err := chain.InsertHistory(history, historyReceipts); err != nil {
+				return fmt.Errorf("error backfilling blocks %d-%d: %w", history[0].NumberU64(), last, err)
+			}
+			next = last + 1
+			if chain.HistoryPruningCutoff() == 0 {
+				next = cutoff // The whole history is restored
+			}
+			log.Info("Backfilled chain history", "number", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+	if cutoff != 0 {
+		// The post-merge history is never pruned, there's nothing to backfill
+		// from the EraE archives.
+		if next < cutoff {
+			log.Warn("Chain history partially backfilled, available once complete", "first", next, "cutoff", cutoff)
+		}
+		return nil
+	}
+	var beaconRoot *common.Hash // Beacon root of the last imported block
+	for i, filename := range postEntries {
+		err := func() error {
+			f, err := os.Open(filepath.Join(dir, filename))
+			if err != nil {
+				return fmt.Errorf("unable to open erae: %w", err)
+			}
+			defer f.Close()
+
+			if err := verifyChecksum(f, checksums[len(entries)+i]); err != nil {
+				return err
+			}
+			// Import all block data from EraE, checking that the blocks are
+			// anchored to the beacon chain they commit to.
+			e, err := erae.From(f)
+			if err != nil {
+				return fmt.Errorf("error opening erae: %w", err)
+			}
+			it, err := erae.NewIterator(e)
+			if err != nil {
+				return fmt.Errorf("error making erae reader: %w", err)
+			}
+			for it.Next() {
+				block, receipts, err := it.BlockAndReceipts()
+				if err != nil {
+					return fmt.Errorf("error reading block %d: %w", it.Number(), err)
+				}
+				proof, err := it.Proof()
+				if err != nil {
+					return fmt.Errorf("error reading proof %d: %w", it.Number(), err)
 				}
-				if _, err := chain.InsertReceiptChain([]*types.Block{block}, []types.Receipts{receipts}, 2^64-1); err != nil {
-					return fmt.Errorf("error inserting body %d: %w", it.Number(), err)
+				if err := proof.Verify(block.Hash()); err != nil {
+					return fmt.Errorf("invalid proof of block %d: %w", it.Number(), err)
 				}
-				imported += 1
+				if root := block.BeaconRoot(); root != nil && beaconRoot != nil && *root != *beaconRoot {
+					return fmt.Errorf("block %d beacon root mismatch: have %x, proven %x", it.Number(), *root, *beaconRoot)
+				}
+				beaconRoot = &proof.BeaconRoot
 
-				// Give the user some feedback that something is happening.
-				if time.Since(reported) >= 8*time.Second {
-					log.Info("Importing Era files", "head", it.Number(), "imported", imported, "elapsed", common.PrettyDuration(time.Since(start)))
-					imported = 0
-					reported = time.Now()
+				if err := insert(block, receipts); err != nil {
+					return err
 				}
 			}
 			return nil
@@ -328,7 +442,18 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 			return err
 		}
 	}
+	return nil
+}
 
+// verifyChecksum checks the sha256 checksum of an archive.
+func verifyChecksum(f *os.File, want string) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("unable to recalculate checksum: %w", err)
+	}
+	if have := common.BytesToHash(h.Sum(nil)).Hex(); have != want {
+		return fmt.Errorf("checksum mismatch: have %s, want %s", have, want)
+	}
 	return nil
 }
 
@@ -401,9 +526,16 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// BeaconBlockSource retrieves beacon blocks by their root. It's used to prove
+// the inclusion of the post-merge blocks exported into EraE archives.
+type BeaconBlockSource interface {
+	GetBeaconBlock(root common.Hash) (*btypes.BeaconBlock, error)
+}
+
 // ExportHistory exports blockchain history into the specified directory,
-// following the Era format.
-func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
+// following the Era format. The blocks produced after the merge are exported
+// into EraE archives, proven by the beacon blocks retrieved from the source.
+func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64, beacon BeaconBlockSource) error {
 	log.Info("Exporting blockchain history", "dir", dir)
 	if head := bc.CurrentBlock().Number.Uint64(); head < last {
 		log.Warn("Last block beyond head, setting last = head", "head", head, "last", last)
@@ -416,6 +548,15 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 		return fmt.Errorf("error creating output directory: %w", err)
 	}
+	// Split the range at the first post-merge block, the genesis is always
+	// exported into an Era1 archive.
+	merge := first + uint64(sort.Search(int(last-first+1), func(i int) bool {
+		header := bc.GetHeaderByNumber(first + uint64(i))
+		return header.Number.Sign() > 0 && header.Difficulty.Sign() == 0
+	}))
+	if merge <= last && beacon == nil {
+		return fmt.Errorf("beacon API required to export post-merge blocks from #%d", merge)
+	}
 	var (
 		start     = time.Now()
 		reported  = time.Now()
@@ -423,7 +564,7 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 		buf       = bytes.NewBuffer(nil)
 		checksums []string
 	)
-	for i := first; i <= last; i += step {
+	for i := first; i < merge; i += step {
 		err := func() error {
 			filename := filepath.Join(dir, era.Filename(network, int(i/step), common.Hash{}))
 			f, err := os.Create(filename)
@@ -433,7 +574,7 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 			defer f.Close()
 
 			w := era.NewBuilder(f)
-			for j := uint64(0); j < step && j <= last-i; j++ {
+			for j := uint64(0); j < step && j < merge-i; j++ {
 				var (
 					n     = i + j
 					block = bc.GetBlockByNumber(n)
@@ -480,7 +621,13 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 			reported = time.Now()
 		}
 	}
-
+	if merge <= last {
+		postChecksums, err := exportPostMergeHistory(bc, dir, network, merge, last, step, beacon)
+		if err != nil {
+			return err
+		}
+		checksums = append(checksums, postChecksums...)
+	}
 	os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(strings.Join(checksums, "\n")), os.ModePerm)
 
 	log.Info("Exported blockchain to", "dir", dir)
@@ -488,6 +635,135 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 	return nil
 }
 
+// exportPostMergeHistory exports the post-merge blocks of the range into EraE
+// archives, returning their checksums. The epochs of the EraE archives are
+// aligned to the step, so the first one might be partial.
+//
+// The beacon chain can only be walked backwards from the root committed to by
+// a descendant of the range, hence the archives are written in reverse order.
+func exportPostMergeHistory(bc *core.BlockChain, dir, network string, first, last, step uint64, beacon BeaconBlockSource) ([]string, error) {
+	prover, err := newHistoryProver(bc, beacon, last)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		start     = time.Now()
+		reported  = time.Now()
+		checksums []string
+	)
+	for epoch := last / step; epoch >= first/step; epoch-- {
+		var (
+			from   = max(epoch*step, first)
+			to     = min((epoch+1)*step-1, last)
+			proofs = make([]*erae.BlockProof, to-from+1)
+		)
+		for i := len(proofs) - 1; i >= 0; i-- {
+			if proofs[i], err = prover.prove(from + uint64(i)); err != nil {
+				return nil, err
+			}
+		}
+		err := func() error {
+			filename := filepath.Join(dir, erae.Filename(network, int(epoch), common.Hash{}))
+			f, err := os.Create(filename)
+			if err != nil {
+				return fmt.Errorf("could not create erae file: %w", err)
+			}
+			defer f.Close()
+
+			w := erae.NewBuilder(f)
+			for i, proof := range proofs {
+				n := from + uint64(i)
+				block := bc.GetBlockByNumber(n)
+				if block == nil {
+					return fmt.Errorf("export failed on #%d: not found", n)
+				}
+				receipts := bc.GetReceiptsByHash(block.Hash())
+				if receipts == nil {
+					return fmt.Errorf("export failed on #%d: receipts not found", n)
+				}
+				if err := w.Add(block, receipts, proof); err != nil {
+					return err
+				}
+			}
+			root, err := w.Finalize()
+			if err != nil {
+				return fmt.Errorf("export failed to finalize epoch %d: %w", epoch, err)
+			}
+			// Set correct filename with root.
+			os.Rename(filename, filepath.Join(dir, erae.Filename(network, int(epoch), root)))
+
+			// Compute checksum of entire EraE.
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			h := sha256.New()
+			if _, err := io.Copy(h, f); err != nil {
+				return fmt.Errorf("unable to calculate checksum: %w", err)
+			}
+			checksums = append(checksums, common.BytesToHash(h.Sum(nil)).Hex())
+			return nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+		if time.Since(reported) >= 8*time.Second {
+			log.Info("Exporting post-merge blocks", "epoch", epoch, "elapsed", common.PrettyDuration(time.Since(start)))
+			reported = time.Now()
+		}
+		if epoch == 0 {
+			break
+		}
+	}
+	slices.Reverse(checksums)
+	return checksums, nil
+}
+
+// historyProver proves the inclusion of post-merge blocks in the beacon chain,
+// walking it backwards from the root committed to by a descendant block.
+type historyProver struct {
+	bc     *core.BlockChain
+	beacon BeaconBlockSource
+	number uint64      // Number of the block included in the next beacon block
+	root   common.Hash // Root of the next beacon block to walk
+}
+
+// newHistoryProver creates a prover for the blocks up to the given one, starting
+// from the first descendant block which carries the root of its parent's beacon
+// block.
+func newHistoryProver(bc *core.BlockChain, beacon BeaconBlockSource, last uint64) (*historyProver, error) {
+	for n := last + 1; ; n++ {
+		header := bc.GetHeaderByNumber(n)
+		if header == nil {
+			return nil, fmt.Errorf("no descendant of block #%d commits to a beacon root", last)
+		}
+		if header.ParentBeaconRoot != nil {
+			return &historyProver{bc: bc, beacon: beacon, number: n - 1, root: *header.ParentBeaconRoot}, nil
+		}
+	}
+}
+
+// prove returns the proof of the given block, which must be lower than the ones
+// proven before.
+func (p *historyProver) prove(number uint64) (*erae.BlockProof, error) {
+	for ; p.number >= number; p.number-- {
+		block, err := p.beacon.GetBeaconBlock(p.root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve beacon block %x of #%d: %w", p.root, p.number, err)
+		}
+		index, branch := block.BlockHashProof()
+		proof := &erae.BlockProof{BeaconRoot: p.root, Index: index, Branch: branch}
+		if err := proof.Verify(p.bc.GetCanonicalHash(p.number)); err != nil {
+			return nil, fmt.Errorf("beacon block %x doesn't include #%d: %w", p.root, p.number, err)
+		}
+		p.root = block.Header().ParentRoot
+		if p.number == number {
+			p.number--
+			return proof, nil
+		}
+	}
+	return nil, fmt.Errorf("block #%d already proven", number)
+}
+
 // ImportPreimages imports a batch of exported hash preimages into the database.
 // It's a part of the deprecated functionality, should be removed in the future.
 func ImportPreimages(db ethdb.Database, fn string) error {
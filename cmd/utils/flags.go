@@ -40,8 +40,11 @@ import (
 	bparams "github.com/ethereum/go-ethereum/beacon/params"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/pruner"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -110,6 +113,11 @@ var (
 		Usage:    "Root directory for ancient data (default = inside chaindata)",
 		Category: flags.EthCategory,
 	}
+	EraFlag = &flags.DirectoryFlag{
+		Name:     "datadir.era",
+		Usage:    "Directory of era1 archives serving the pruned chain history (read-only, can be shared)",
+		Category: flags.EthCategory,
+	}
 	MinFreeDiskSpaceFlag = &flags.DirectoryFlag{
 		Name:     "datadir.minfreedisk",
 		Usage:    "Minimum free disk space in MB, once reached triggers auto shut down (default = --cache.gc converted to MB, 0 = disabled)",
@@ -277,12 +285,45 @@ var (
 		Value:    ethconfig.Defaults.StateHistory,
 		Category: flags.StateCategory,
 	}
+	StateIndexingFlag = &cli.BoolFlag{
+		Name:     "history.stateindex",
+		Usage:    "Enable indexing of state histories for serving historical state requests (path scheme only)",
+		Category: flags.StateCategory,
+	}
+	StatePruningFlag = &cli.BoolFlag{
+		Name:     "state.prune",
+		Usage:    "Prune the stale state in the background while running, sized by --bloomfilter.size (hash scheme only)",
+		Category: flags.StateCategory,
+	}
+	StatePruningThrottleFlag = &cli.DurationFlag{
+		Name:     "state.prune.throttle",
+		Usage:    "Pause between two batches of deletions of the background state pruning",
+		Value:    pruner.DefaultOnlineConfig.Throttle,
+		Category: flags.StateCategory,
+	}
+	StatePruningIntervalFlag = &cli.DurationFlag{
+		Name:     "state.prune.interval",
+		Usage:    "Time to wait between two cycles of the background state pruning",
+		Value:    pruner.DefaultOnlineConfig.Interval,
+		Category: flags.StateCategory,
+	}
 	TransactionHistoryFlag = &cli.Uint64Flag{
 		Name:     "history.transactions",
 		Usage:    "Number of recent blocks to maintain transactions index for (default = about one year, 0 = entire chain)",
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	LogHistoryFlag = &cli.Uint64Flag{
+		Name:     "history.logs",
+		Usage:    "Number of recent blocks to maintain log search index for (default = about one year, 0 = entire chain)",
+		Value:    ethconfig.Defaults.LogHistory,
+		Category: flags.StateCategory,
+	}
+	LogNoHistoryFlag = &cli.BoolFlag{
+		Name:     "history.logs.disable",
+		Usage:    "Do not maintain log search index",
+		Category: flags.StateCategory,
+	}
 	// Beacon client light sync settings
 	BeaconApiFlag = &cli.StringSliceFlag{
 		Name:     "beacon.api",
@@ -352,6 +393,11 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Journal,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolRemoteJournalFlag = &cli.StringFlag{
+		Name:     "txpool.remotejournal",
+		Usage:    "Disk journal for remote transactions to survive node restarts (disabled if empty)",
+		Category: flags.TxPoolCategory,
+	}
 	TxPoolRejournalFlag = &cli.DurationFlag{
 		Name:     "txpool.rejournal",
 		Usage:    "Time interval to regenerate the local transaction journal",
@@ -400,6 +446,21 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolAllowSendersFlag = &cli.StringFlag{
+		Name:     "txpool.allowsenders",
+		Usage:    "Comma separated accounts allowed to send transactions into the pool (disabled if empty)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolAllowRecipientsFlag = &cli.StringFlag{
+		Name:     "txpool.allowrecipients",
+		Usage:    "Comma separated accounts allowed to be called by pooled transactions (disabled if empty)",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolDenySelectorsFlag = &cli.StringFlag{
+		Name:     "txpool.denyselectors",
+		Usage:    "Comma separated 4-byte function selectors rejected from the pool",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -502,6 +563,22 @@ var (
 		Value:    ethconfig.Defaults.Miner.Recommit,
 		Category: flags.MinerCategory,
 	}
+	MinerOrderingFlag = &cli.StringFlag{
+		Name:     "miner.ordering",
+		Usage:    "Transaction ordering policy for built blocks (price, fifo, deterministic)",
+		Value:    miner.OrderingPrice,
+		Category: flags.MinerCategory,
+	}
+	MinerSenderCapFlag = &cli.IntFlag{
+		Name:     "miner.sendercap",
+		Usage:    "Maximum number of transactions per sender in a built block (0 = unlimited)",
+		Category: flags.MinerCategory,
+	}
+	MinerBundlesFlag = &cli.BoolFlag{
+		Name:     "miner.bundles",
+		Usage:    "Accept transaction bundles for inclusion in built blocks over the mev namespace",
+		Category: flags.MinerCategory,
+	}
 	MinerPendingFeeRecipientFlag = &cli.StringFlag{
 		Name:     "miner.pending.feeRecipient",
 		Usage:    "0x prefixed public address for the pending block producer (not used for actual block production)",
@@ -562,6 +639,12 @@ var (
 		Value:    ethconfig.Defaults.RPCEVMTimeout,
 		Category: flags.APICategory,
 	}
+	RPCTraceFilterCapFlag = &cli.Uint64Flag{
+		Name:     "rpc.tracefiltercap",
+		Usage:    "Sets a cap on the number of blocks trace_filter can trace in a single request (0=infinite)",
+		Value:    ethconfig.Defaults.RPCTraceFilterCap,
+		Category: flags.APICategory,
+	}
 	RPCGlobalTxFeeCapFlag = &cli.Float64Flag{
 		Name:     "rpc.txfeecap",
 		Usage:    "Sets a cap on transaction fee (in ether) that can be sent via the RPC APIs (0 = no cap)",
@@ -744,6 +827,26 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Number of request tokens replenished per second for each RPC client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitBurstFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum number of request tokens an RPC client can accumulate (default = rate)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitCostsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.costs",
+		Usage:    "Comma separated request token costs of RPC methods, e.g. eth_getLogs=10,debug_trace*=50 (default = 1)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitAuthFlag = &cli.BoolFlag{
+		Name:     "rpc.ratelimit.auth",
+		Usage:    "Apply the RPC rate limits to the authenticated endpoints too, keying clients by JWT subject",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -970,6 +1073,7 @@ var (
 	DatabaseFlags = []cli.Flag{
 		DataDirFlag,
 		AncientFlag,
+		EraFlag,
 		RemoteDBFlag,
 		DBEngineFlag,
 		StateSchemeFlag,
@@ -1185,6 +1289,27 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCRateLimit.Rate = ctx.Float64(RPCRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		cfg.RPCRateLimit.Burst = ctx.Float64(RPCRateLimitBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitCostsFlag.Name) {
+		cfg.RPCRateLimit.MethodCosts = make(map[string]float64)
+		for _, entry := range SplitAndTrim(ctx.String(RPCRateLimitCostsFlag.Name)) {
+			method, value, ok := strings.Cut(entry, "=")
+			cost, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if !ok || err != nil || cost < 0 {
+				Fatalf("Invalid method cost in --%s: %s", RPCRateLimitCostsFlag.Name, entry)
+			}
+			cfg.RPCRateLimit.MethodCosts[strings.TrimSpace(method)] = cost
+		}
+	}
+	if ctx.IsSet(RPCRateLimitAuthFlag.Name) {
+		cfg.RPCRateLimit.Auth = ctx.Bool(RPCRateLimitAuthFlag.Name)
+	}
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1443,6 +1568,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		log.Info(fmt.Sprintf("Using %s as db engine", dbEngine))
 		cfg.DBEngine = dbEngine
 	}
+	if ctx.IsSet(EraFlag.Name) {
+		cfg.EraDirectory = ctx.String(EraFlag.Name)
+	}
 	// deprecation notice for log debug flags (TODO: find a more appropriate place to put these?)
 	if ctx.IsSet(LogBacktraceAtFlag.Name) {
 		log.Warn("log.backtrace flag is deprecated")
@@ -1519,6 +1647,9 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	if ctx.IsSet(TxPoolJournalFlag.Name) {
 		cfg.Journal = ctx.String(TxPoolJournalFlag.Name)
 	}
+	if ctx.IsSet(TxPoolRemoteJournalFlag.Name) {
+		cfg.RemoteJournal = ctx.String(TxPoolRemoteJournalFlag.Name)
+	}
 	if ctx.IsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.Duration(TxPoolRejournalFlag.Name)
 	}
@@ -1545,6 +1676,48 @@ func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
 	}
 }
 
+// setTxPolicies creates the transaction admission policies from the command line
+// flags, and configures them for all the subpools.
+func setTxPolicies(ctx *cli.Context, cfg *ethconfig.Config) {
+	var policies []txpool.TxPolicy
+	if ctx.IsSet(TxPoolAllowSendersFlag.Name) {
+		senders := SplitAndTrim(ctx.String(TxPoolAllowSendersFlag.Name))
+		policies = append(policies, txpool.NewSenderAllowlist(parseAddresses(TxPoolAllowSendersFlag.Name, senders)))
+	}
+	if ctx.IsSet(TxPoolAllowRecipientsFlag.Name) {
+		recipients := SplitAndTrim(ctx.String(TxPoolAllowRecipientsFlag.Name))
+		policies = append(policies, txpool.NewRecipientAllowlist(parseAddresses(TxPoolAllowRecipientsFlag.Name, recipients)))
+	}
+	if ctx.IsSet(TxPoolDenySelectorsFlag.Name) {
+		var selectors [][4]byte
+		for _, hex := range SplitAndTrim(ctx.String(TxPoolDenySelectorsFlag.Name)) {
+			sel, err := hexutil.Decode(hex)
+			if err != nil || len(sel) != 4 {
+				Fatalf("Invalid selector in --%s: %s", TxPoolDenySelectorsFlag.Name, hex)
+			}
+			selectors = append(selectors, [4]byte(sel))
+		}
+		policies = append(policies, txpool.NewSelectorDenylist(selectors))
+	}
+	if len(policies) > 0 {
+		cfg.TxPool.Policies = policies
+		cfg.BlobPool.Policies = policies
+	}
+}
+
+// parseAddresses converts the given hex strings into addresses, failing if any
+// of them is invalid.
+func parseAddresses(flag string, hexes []string) []common.Address {
+	addrs := make([]common.Address, 0, len(hexes))
+	for _, hex := range hexes {
+		if !common.IsHexAddress(hex) {
+			Fatalf("Invalid account in --%s: %s", flag, hex)
+		}
+		addrs = append(addrs, common.HexToAddress(hex))
+	}
+	return addrs
+}
+
 func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.Bool(MiningEnabledFlag.Name) {
 		log.Warn("The flag --mine is deprecated and will be removed")
@@ -1565,6 +1738,18 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 		log.Warn("The flag --miner.newpayload-timeout is deprecated and will be removed, please use --miner.recommit")
 		cfg.Recommit = ctx.Duration(MinerNewPayloadTimeoutFlag.Name)
 	}
+	if ctx.IsSet(MinerOrderingFlag.Name) {
+		cfg.Ordering = ctx.String(MinerOrderingFlag.Name)
+	}
+	if ctx.IsSet(MinerSenderCapFlag.Name) {
+		cfg.SenderCap = ctx.Int(MinerSenderCapFlag.Name)
+	}
+	if ctx.IsSet(MinerBundlesFlag.Name) {
+		cfg.Bundles = ctx.Bool(MinerBundlesFlag.Name)
+	}
+	if _, err := miner.NewTransactionOrderer(cfg.Ordering, cfg.SenderCap); err != nil {
+		Fatalf("Invalid miner configuration: %v", err)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1646,6 +1831,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setTxPolicies(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
 	setLes(ctx, cfg)
@@ -1704,9 +1890,28 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(StateIndexingFlag.Name) {
+		cfg.StateIndexing = ctx.Bool(StateIndexingFlag.Name)
+	}
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
+	if ctx.IsSet(StatePruningFlag.Name) {
+		if cfg.NoPruning {
+			Fatalf("--%s is not compatible with --%s=archive", StatePruningFlag.Name, GCModeFlag.Name)
+		}
+		if ctx.Bool(StatePruningFlag.Name) && !ctx.Bool(SnapshotFlag.Name) {
+			Fatalf("--%s is not compatible with --%s=false", StatePruningFlag.Name, SnapshotFlag.Name)
+		}
+		cfg.StatePruning = ctx.Bool(StatePruningFlag.Name)
+		cfg.StatePruningBloomSize = ctx.Uint64(BloomFilterSizeFlag.Name)
+	}
+	if ctx.IsSet(StatePruningThrottleFlag.Name) {
+		cfg.StatePruningThrottle = ctx.Duration(StatePruningThrottleFlag.Name)
+	}
+	if ctx.IsSet(StatePruningIntervalFlag.Name) {
+		cfg.StatePruningInterval = ctx.Duration(StatePruningIntervalFlag.Name)
+	}
 	// Parse transaction history flag, if user is still using legacy config
 	// file with 'TxLookupLimit' configured, copy the value to 'TransactionHistory'.
 	if cfg.TransactionHistory == ethconfig.Defaults.TransactionHistory && cfg.TxLookupLimit != ethconfig.Defaults.TxLookupLimit {
@@ -1719,6 +1924,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		log.Warn("The flag --txlookuplimit is deprecated and will be removed, please use --history.transactions")
 		cfg.TransactionHistory = ctx.Uint64(TxLookupLimitFlag.Name)
 	}
+	if ctx.IsSet(LogHistoryFlag.Name) {
+		cfg.LogHistory = ctx.Uint64(LogHistoryFlag.Name)
+	}
+	if ctx.IsSet(LogNoHistoryFlag.Name) {
+		cfg.LogNoHistory = true
+	}
 	if ctx.String(GCModeFlag.Name) == "archive" && cfg.TransactionHistory != 0 {
 		cfg.TransactionHistory = 0
 		log.Warn("Disabled transaction unindexing for archive node")
@@ -1772,6 +1983,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalEVMTimeoutFlag.Name) {
 		cfg.RPCEVMTimeout = ctx.Duration(RPCGlobalEVMTimeoutFlag.Name)
 	}
+	if ctx.IsSet(RPCTraceFilterCapFlag.Name) {
+		cfg.RPCTraceFilterCap = ctx.Uint64(RPCTraceFilterCapFlag.Name)
+	}
 	if ctx.IsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.Float64(RPCGlobalTxFeeCapFlag.Name)
 	}
@@ -2171,6 +2385,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		StateIndexing:       ctx.Bool(StateIndexingFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
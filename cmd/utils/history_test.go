@@ -19,6 +19,7 @@ package utils
 import (
 	"bytes"
 	"crypto/sha256"
+	"errors"
 	"io"
 	"math/big"
 	"os"
@@ -26,7 +27,9 @@ import (
 	"strings"
 	"testing"
 
+	btypes "github.com/ethereum/go-ethereum/beacon/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -34,9 +37,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/erae"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	zrntcommon "github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/ztyp/view"
 )
 
 var (
@@ -94,7 +102,7 @@ func TestHistoryImportAndExport(t *testing.T) {
 	defer os.RemoveAll(dir)
 
 	// Export history to temp directory.
-	if err := ExportHistory(chain, dir, 0, count, step); err != nil {
+	if err := ExportHistory(chain, dir, 0, count, step, nil); err != nil {
 		t.Fatalf("error exporting history: %v", err)
 	}
 
@@ -182,3 +190,156 @@ func TestHistoryImportAndExport(t *testing.T) {
 		t.Fatalf("imported chain does not match expected, have (%d, %s) want (%d, %s)", have.Number, have.Hash(), want.Number, want.Hash())
 	}
 }
+
+// testBeaconSource is a beacon block source serving made up Bellatrix blocks
+// before Shanghai and Deneb blocks afterwards.
+type testBeaconSource map[common.Hash]*btypes.BeaconBlock
+
+func (s testBeaconSource) GetBeaconBlock(root common.Hash) (*btypes.BeaconBlock, error) {
+	if block, ok := s[root]; ok {
+		return block, nil
+	}
+	return nil, errors.New("unknown beacon block")
+}
+
+// add creates a beacon block including the given execution block, returning
+// its root.
+func (s testBeaconSource) add(slot uint64, parent common.Hash, block *types.Block) common.Hash {
+	var beaconBlock *btypes.BeaconBlock
+	if block.Withdrawals() == nil {
+		obj := &bellatrix.BeaconBlock{
+			Slot:       zrntcommon.Slot(slot),
+			ParentRoot: zrntcommon.Root(parent),
+		}
+		obj.Body.ExecutionPayload.BlockNumber = view.Uint64View(block.NumberU64())
+		obj.Body.ExecutionPayload.BlockHash = zrntcommon.Hash32(block.Hash())
+		beaconBlock = btypes.NewBeaconBlock(obj)
+	} else {
+		obj := &deneb.BeaconBlock{
+			Slot:       zrntcommon.Slot(slot),
+			ParentRoot: zrntcommon.Root(parent),
+		}
+		obj.Body.ExecutionPayload.BlockNumber = view.Uint64View(block.NumberU64())
+		obj.Body.ExecutionPayload.BlockHash = zrntcommon.Hash32(block.Hash())
+		beaconBlock = btypes.NewBeaconBlock(obj)
+	}
+	s[beaconBlock.Root()] = beaconBlock
+	return beaconBlock.Root()
+}
+
+func TestHistoryImportAndExportPostMerge(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.TestChainConfig
+		genesis = &core.Genesis{
+			Config:  &config,
+			Alloc:   types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(genesis.Config)
+		source = make(testBeaconSource)
+	)
+	addTx := func(i int, g *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(g.TxNonce(address), common.Address{0xaa}, big.NewInt(1), params.TxGas, g.BaseFee(), nil), signer, key)
+		g.AddTx(tx)
+	}
+	// Generate a chain transitioning to proof-of-stake, activating Shanghai and
+	// Cancun 20 blocks after the merge like on mainnet. The blocks only commit
+	// to the beacon roots from Cancun onwards.
+	genDb, preBlocks, _ := core.GenerateChainWithGenesis(genesis, engine, 20, addTx)
+
+	td := params.GenesisDifficulty.Uint64()
+	for _, block := range preBlocks {
+		td += block.Difficulty().Uint64()
+	}
+	var (
+		forkTime   = preBlocks[len(preBlocks)-1].Time() + 210
+		beaconRoot common.Hash
+	)
+	config.TerminalTotalDifficulty = new(big.Int).SetUint64(td)
+	config.ShanghaiTime, config.CancunTime = &forkTime, &forkTime
+
+	postBlocks, _ := core.GenerateChain(genesis.Config, preBlocks[len(preBlocks)-1], engine, genDb, 40, func(i int, g *core.BlockGen) {
+		g.SetPoS()
+		if i > 0 {
+			parent := g.PrevBlock(i - 1)
+			beaconRoot = source.add(parent.NumberU64(), beaconRoot, parent)
+		}
+		if g.Timestamp() >= forkTime {
+			g.SetParentBeaconRoot(beaconRoot)
+			g.AddWithdrawal(&types.Withdrawal{Validator: uint64(i), Address: common.Address{0xbb}, Amount: 1})
+		}
+		addTx(i, g)
+	})
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(append(preBlocks, postBlocks...)); err != nil {
+		t.Fatalf("error inserting chain: %v", err)
+	}
+	// Export the history, the blocks after the merge require beacon proofs.
+	var (
+		dir  = t.TempDir()
+		last = chain.CurrentBlock().Number.Uint64() - 1
+	)
+	if err := ExportHistory(chain, t.TempDir(), 0, last, step, nil); err == nil {
+		t.Fatal("exported post-merge history without beacon proofs")
+	}
+	if err := ExportHistory(chain, t.TempDir(), 0, last+1, step, source); err == nil {
+		t.Fatal("exported chain head without beacon proof")
+	}
+	if err := ExportHistory(chain, dir, 0, last, step, source); err != nil {
+		t.Fatalf("error exporting history: %v", err)
+	}
+	preEntries, _ := era.ReadDir(dir, "mainnet")
+	postEntries, _ := erae.ReadDir(dir, "mainnet")
+	if len(preEntries) != 2 || len(postEntries) != 3 {
+		t.Fatalf("wrong number of archives: have %d era1 and %d erae, want 2 and 3", len(preEntries), len(postEntries))
+	}
+	for _, filename := range postEntries {
+		e, err := erae.Open(filepath.Join(dir, filename))
+		if err != nil {
+			t.Fatalf("error opening erae: %v", err)
+		}
+		for n := e.Start(); n < e.Start()+e.Count(); n++ {
+			block, err := e.GetBlockByNumber(n)
+			if err != nil {
+				t.Fatalf("error reading block %d: %v", n, err)
+			}
+			if block.Hash() != chain.GetCanonicalHash(n) {
+				t.Fatalf("block %d: hash mismatch", n)
+			}
+			if block.Difficulty().Sign() != 0 {
+				t.Fatalf("block %d: not a post-merge block", n)
+			}
+			if shanghai := block.Time() >= forkTime; shanghai != (len(block.Withdrawals()) == 1) {
+				t.Fatalf("block %d: wrong withdrawals for fork, shanghai %t", n, shanghai)
+			}
+		}
+		e.Close()
+	}
+	// Import the history and check it's anchored to the same beacon chain.
+	db2, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db2.Close()
+
+	imported, err := core.NewBlockChain(db2, nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("unable to initialize chain: %v", err)
+	}
+	defer imported.Stop()
+
+	if err := ImportHistory(imported, db2, dir, "mainnet"); err != nil {
+		t.Fatalf("failed to import chain: %v", err)
+	}
+	if have, want := imported.CurrentSnapBlock().Hash(), chain.GetCanonicalHash(last); have != want {
+		t.Fatalf("imported chain does not match expected, have %x want %x", have, want)
+	}
+}
@@ -290,6 +290,14 @@ func (beacon *Beacon) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 			return err
 		}
 	}
+	// Verify existence / non-existence of requestsHash.
+	prague := chain.Config().IsPrague(header.Number, header.Time)
+	if prague && header.RequestsHash == nil {
+		return errors.New("missing requestsHash")
+	}
+	if !prague && header.RequestsHash != nil {
+		return fmt.Errorf("invalid requestsHash: have %x, expected nil", header.RequestsHash)
+	}
 	return nil
 }
 
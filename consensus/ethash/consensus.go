@@ -283,6 +283,8 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, pa
 		return fmt.Errorf("invalid blobGasUsed: have %d, expected nil", header.BlobGasUsed)
 	case header.ParentBeaconRoot != nil:
 		return fmt.Errorf("invalid parentBeaconRoot, have %#x, expected nil", header.ParentBeaconRoot)
+	case header.RequestsHash != nil:
+		return fmt.Errorf("invalid requestsHash, have %#x, expected nil", header.RequestsHash)
 	}
 	// Add some fake checks for tests
 	if ethash.fakeDelay != nil {
@@ -557,6 +559,9 @@ func (ethash *Ethash) SealHash(header *types.Header) (hash common.Hash) {
 	if header.ParentBeaconRoot != nil {
 		panic("parent beacon root set on ethash")
 	}
+	if header.RequestsHash != nil {
+		panic("requests hash set on ethash")
+	}
 	rlp.Encode(hasher, enc)
 	hasher.Sum(hash[:0])
 	return hash
@@ -568,10 +573,10 @@ var (
 	u256_32 = uint256.NewInt(32)
 )
 
-// accumulateRewards credits the coinbase of the given block with the mining
-// reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
-func accumulateRewards(config *params.ChainConfig, stateDB *state.StateDB, header *types.Header, uncles []*types.Header) {
+// Rewards returns the mining rewards of the given block: the reward credited to
+// its coinbase, consisting of the static block reward and the rewards for the
+// included uncles, and the rewards credited to the coinbase of each uncle.
+func Rewards(config *params.ChainConfig, header *types.Header, uncles []*types.Header) (*uint256.Int, []*uint256.Int) {
 	// Select the correct block reward based on chain progression
 	blockReward := FrontierBlockReward
 	if config.IsByzantium(header.Number) {
@@ -581,19 +586,32 @@ func accumulateRewards(config *params.ChainConfig, stateDB *state.StateDB, heade
 		blockReward = ConstantinopleBlockReward
 	}
 	// Accumulate the rewards for the miner and any included uncles
-	reward := new(uint256.Int).Set(blockReward)
-	r := new(uint256.Int)
+	var (
+		reward       = new(uint256.Int).Set(blockReward)
+		uncleRewards = make([]*uint256.Int, len(uncles))
+		r            = new(uint256.Int)
+	)
 	hNum, _ := uint256.FromBig(header.Number)
-	for _, uncle := range uncles {
+	for i, uncle := range uncles {
 		uNum, _ := uint256.FromBig(uncle.Number)
-		r.AddUint64(uNum, 8)
-		r.Sub(r, hNum)
-		r.Mul(r, blockReward)
-		r.Div(r, u256_8)
-		stateDB.AddBalance(uncle.Coinbase, r, tracing.BalanceIncreaseRewardMineUncle)
+		uncleRewards[i] = new(uint256.Int).AddUint64(uNum, 8)
+		uncleRewards[i].Sub(uncleRewards[i], hNum)
+		uncleRewards[i].Mul(uncleRewards[i], blockReward)
+		uncleRewards[i].Div(uncleRewards[i], u256_8)
 
 		r.Div(blockReward, u256_32)
 		reward.Add(reward, r)
 	}
+	return reward, uncleRewards
+}
+
+// accumulateRewards credits the coinbase of the given block with the mining
+// reward. The total reward consists of the static block reward and rewards for
+// included uncles. The coinbase of each uncle block is also rewarded.
+func accumulateRewards(config *params.ChainConfig, stateDB *state.StateDB, header *types.Header, uncles []*types.Header) {
+	reward, uncleRewards := Rewards(config, header, uncles)
+	for i, uncle := range uncles {
+		stateDB.AddBalance(uncle.Coinbase, uncleRewards[i], tracing.BalanceIncreaseRewardMineUncle)
+	}
 	stateDB.AddBalance(header.Coinbase, reward, tracing.BalanceIncreaseRewardMineBlock)
 }
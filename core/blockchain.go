@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -138,6 +139,7 @@ type CacheConfig struct {
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
+	StateIndexing       bool          // Whether to index the state histories for serving historical states
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
@@ -157,9 +159,10 @@ func (c *CacheConfig) triedbConfig(isVerkle bool) *triedb.Config {
 	}
 	if c.StateScheme == rawdb.PathScheme {
 		config.PathDB = &pathdb.Config{
-			StateHistory:   c.StateHistory,
-			CleanCacheSize: c.TrieCleanLimit * 1024 * 1024,
-			DirtyCacheSize: c.TrieDirtyLimit * 1024 * 1024,
+			StateHistory:        c.StateHistory,
+			EnableStateIndexing: c.StateIndexing,
+			CleanCacheSize:      c.TrieCleanLimit * 1024 * 1024,
+			DirtyCacheSize:      c.TrieDirtyLimit * 1024 * 1024,
 		}
 	}
 	return config
@@ -1916,16 +1919,16 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 
 	// Process block using the parent state as reference point
 	pstart := time.Now()
-	receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+	res, err := bc.processor.Process(block, statedb, bc.vmConfig)
 	if err != nil {
-		bc.reportBlock(block, receipts, err)
+		bc.reportBlock(block, nil, err)
 		return nil, err
 	}
 	ptime := time.Since(pstart)
 
 	vstart := time.Now()
-	if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
-		bc.reportBlock(block, receipts, err)
+	if err := bc.validator.ValidateState(block, statedb, res); err != nil {
+		bc.reportBlock(block, res.Receipts, err)
 		return nil, err
 	}
 	vtime := time.Since(vstart)
@@ -1953,9 +1956,9 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 	)
 	if !setHead {
 		// Don't set the head, only insert the block
-		err = bc.writeBlockWithState(block, receipts, statedb)
+		err = bc.writeBlockWithState(block, res.Receipts, statedb)
 	} else {
-		status, err = bc.writeBlockAndSetHead(block, receipts, logs, statedb, false)
+		status, err = bc.writeBlockAndSetHead(block, res.Receipts, res.Logs, statedb, false)
 	}
 	if err != nil {
 		return nil, err
@@ -1969,7 +1972,7 @@ func (bc *BlockChain) processBlock(block *types.Block, statedb *state.StateDB, s
 	blockWriteTimer.Update(time.Since(wstart) - max(statedb.AccountCommits, statedb.StorageCommits) /* concurrent */ - statedb.SnapshotCommits - statedb.TrieDBCommits)
 	blockInsertTimer.UpdateSince(start)
 
-	return &blockProcessingResult{usedGas: usedGas, procTime: proctime, status: status}, nil
+	return &blockProcessingResult{usedGas: res.GasUsed, procTime: proctime, status: status}, nil
 }
 
 // insertSideChain is called when an import batch hits upon a pruned ancestor
@@ -2378,6 +2381,38 @@ func (bc *BlockChain) InsertBlockWithoutSetHead(block *types.Block) error {
 	return err
 }
 
+// GenerateWitness executes the given block on top of the state of its parent,
+// collecting all the state, code and ancestor headers accessed into a witness,
+// which is sufficient for executing the block statelessly. The block is not
+// persisted into the chain.
+func (bc *BlockChain) GenerateWitness(block *types.Block) (*stateless.Witness, error) {
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	witness, err := stateless.NewWitness(block.Header(), bc)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := state.NewWithWitness(parent.Root, bc.stateCache, witness)
+	if err != nil {
+		return nil, err
+	}
+	// The block is replayed outside of the chain import, it must not be reported
+	// to the live tracer.
+	config := bc.vmConfig
+	config.Tracer = nil
+
+	res, err := bc.processor.Process(block, statedb, config)
+	if err != nil {
+		return nil, err
+	}
+	if err := bc.validator.ValidateState(block, statedb, res); err != nil {
+		return nil, err
+	}
+	return witness, nil
+}
+
 // SetCanonical rewinds the chain to set the new head block as the specified
 // block. It's possible that the state of the new head is missing, and it will
 // be recovered in this function as well.
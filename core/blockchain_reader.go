@@ -359,6 +359,13 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache, bc.snaps)
 }
 
+// HistoricState returns a historical state based on a particular point in time,
+// which is resolved from the indexed state histories. The returned state is
+// only meant for reading, the state root computed on top is meaningless.
+func (bc *BlockChain) HistoricState(root common.Hash) (*state.StateDB, error) {
+	return state.NewHistoric(root, bc.stateCache)
+}
+
 // Config retrieves the chain's fork configuration.
 func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 
@@ -408,6 +415,21 @@ func (bc *BlockChain) TxIndexProgress() (TxIndexProgress, error) {
 	return bc.txIndexer.txIndexProgress()
 }
 
+// HistoryPruningCutoff returns the number of the first block whose body and
+// receipts are retained, or zero if the chain history is complete.
+func (bc *BlockChain) HistoryPruningCutoff() uint64 {
+	tail := rawdb.ReadChainHistoryTail(bc.db)
+	if tail == nil {
+		return 0
+	}
+	// The pruned history might be served from era1 archives, which always start
+	// at the genesis and are contiguous.
+	if number := *tail - 1; rawdb.HasBody(bc.db, rawdb.ReadCanonicalHash(bc.db, number), number) {
+		return 0
+	}
+	return *tail
+}
+
 // TrieDB retrieves the low level trie database used for data storage.
 func (bc *BlockChain) TrieDB() *triedb.Database {
 	return bc.triedb
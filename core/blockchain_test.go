@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -163,14 +164,14 @@ func testBlockChainImport(chain types.Blocks, blockchain *BlockChain) error {
 		if err != nil {
 			return err
 		}
-		receipts, _, usedGas, err := blockchain.processor.Process(block, statedb, vm.Config{})
+		res, err := blockchain.processor.Process(block, statedb, vm.Config{})
 		if err != nil {
-			blockchain.reportBlock(block, receipts, err)
+			blockchain.reportBlock(block, nil, err)
 			return err
 		}
-		err = blockchain.validator.ValidateState(block, statedb, receipts, usedGas)
+		err = blockchain.validator.ValidateState(block, statedb, res)
 		if err != nil {
-			blockchain.reportBlock(block, receipts, err)
+			blockchain.reportBlock(block, res.Receipts, err)
 			return err
 		}
 
@@ -4221,3 +4222,116 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// TestEIP7702 deploys two delegation designations and calls them. It writes one
+// value to storage which is verified after.
+func TestEIP7702(t *testing.T) {
+	var (
+		config  = *params.MergedTestChainConfig
+		engine  = beacon.NewFaker()
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		aa      = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb      = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+	)
+	config.PragueTime = u64(0)
+	signer := types.LatestSigner(&config)
+
+	// The address 0xAAAA sstores 1 into slot 1.
+	aaCode := []byte{
+		byte(vm.PUSH1), 0x01, // value
+		byte(vm.PUSH1), 0x01, // key
+		byte(vm.SSTORE),
+	}
+	// The address 0xBBBB calls addr1 (delegated to 0xAAAA) and then sstores
+	// 0x42 into slot 0x42.
+	bbCode := []byte{
+		byte(vm.PUSH1), 0, // out size
+		byte(vm.DUP1),     // out offset
+		byte(vm.DUP1),     // in size
+		byte(vm.DUP1),     // in offset
+		byte(vm.DUP1),     // value
+		byte(vm.PUSH20),
+	}
+	bbCode = append(bbCode, addr1.Bytes()...)
+	bbCode = append(bbCode,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.POP),
+		byte(vm.PUSH1), 0x42, // value
+		byte(vm.PUSH1), 0x42, // key
+		byte(vm.SSTORE),
+	)
+	gspec := &Genesis{
+		Config: &config,
+		Alloc: types.GenesisAlloc{
+			addr1: {Balance: funds},
+			addr2: {Balance: funds},
+			aa:    {Code: aaCode, Balance: common.Big0},
+			bb:    {Code: bbCode, Balance: common.Big0},
+		},
+	}
+	// Sign authorizations from both accounts. Note the sender's nonce is
+	// increased before the authorizations are applied.
+	auth1, _ := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(config.ChainID),
+		Address: aa,
+		Nonce:   1,
+	})
+	auth2, _ := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: bb,
+		Nonce:   0,
+	})
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(aa)
+		txdata := &types.SetCodeTx{
+			ChainID:   uint256.MustFromBig(config.ChainID),
+			Nonce:     0,
+			To:        addr2,
+			Gas:       500000,
+			GasFeeCap: uint256.MustFromBig(newGwei(5)),
+			GasTipCap: uint256.NewInt(2),
+			AuthList:  []types.SetCodeAuthorization{auth1, auth2},
+		}
+		tx := types.MustSignNewTx(key1, signer, txdata)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// Verify delegation designations were deployed.
+	state, _ := chain.State()
+	code, want := state.GetCode(addr1), types.AddressToDelegation(aa)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %x, want %x", code, want)
+	}
+	code, want = state.GetCode(addr2), types.AddressToDelegation(bb)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %x, want %x", code, want)
+	}
+	if nonce := state.GetNonce(addr1); nonce != 2 {
+		t.Fatalf("addr1 nonce incorrect: got %d, want 2", nonce)
+	}
+	if nonce := state.GetNonce(addr2); nonce != 1 {
+		t.Fatalf("addr2 nonce incorrect: got %d, want 1", nonce)
+	}
+	// Verify delegation executed the correct code.
+	var (
+		fortyTwo = common.BytesToHash([]byte{0x42})
+		actual   = state.GetState(addr2, fortyTwo)
+	)
+	if actual.Cmp(fortyTwo) != 0 {
+		t.Fatalf("addr2 storage wrong: expected %d, got %d", fortyTwo, actual)
+	}
+	if actual := state.GetState(addr1, common.BytesToHash([]byte{0x01})); actual != common.BytesToHash([]byte{0x01}) {
+		t.Fatalf("addr1 storage wrong: expected 1, got %x", actual)
+	}
+}
@@ -46,8 +46,9 @@ func TestGeneratePOSChain(t *testing.T) {
 		gspec   = &Genesis{
 			Config: &config,
 			Alloc: types.GenesisAlloc{
-				address:                   {Balance: funds},
-				params.BeaconRootsAddress: {Code: params.BeaconRootsCode},
+				address:                      {Balance: funds},
+				params.BeaconRootsAddress:    {Code: params.BeaconRootsCode},
+				params.HistoryStorageAddress: {Code: params.HistoryStorageCode},
 			},
 			BaseFee:    big.NewInt(params.InitialBaseFee),
 			Difficulty: common.Big1,
@@ -61,6 +62,7 @@ func TestGeneratePOSChain(t *testing.T) {
 	config.TerminalTotalDifficulty = common.Big0
 	config.ShanghaiTime = u64(0)
 	config.CancunTime = u64(0)
+	config.PragueTime = u64(0)
 
 	// init 0xaa with some storage elements
 	storage := make(map[common.Hash]common.Hash)
@@ -183,6 +185,27 @@ func TestGeneratePOSChain(t *testing.T) {
 		if got != want {
 			t.Fatalf("block %d, wrong parent beacon root in state: got %s, want %s", i, got, want)
 		}
+		// Verify parent block hash in the history contract.
+		slot := (blocknum - 1) % params.HistoryServeWindow
+		if got := state.GetState(params.HistoryStorageAddress, common.BigToHash(new(big.Int).SetUint64(slot))); got != block.ParentHash() {
+			t.Fatalf("block %d, wrong parent hash in state: got %s, want %s", i, got, block.ParentHash())
+		}
+	}
+	// Verify that the history contract serves the hashes of the ancestors.
+	var (
+		head     = blockchain.CurrentBlock()
+		state, _ = blockchain.State()
+		evm      = vm.NewEVM(NewEVMBlockContext(head, blockchain, nil), vm.TxContext{}, state, &config, vm.Config{})
+	)
+	for number := uint64(0); number < head.Number.Uint64(); number++ {
+		input := common.BigToHash(new(big.Int).SetUint64(number))
+		ret, _, err := evm.StaticCall(vm.AccountRef(address), params.HistoryStorageAddress, input[:], 100_000)
+		if err != nil {
+			t.Fatalf("failed to query block hash %d: %v", number, err)
+		}
+		if want := blockchain.GetHeaderByNumber(number).Hash(); common.BytesToHash(ret) != want {
+			t.Fatalf("wrong block hash %d from history contract: got %x, want %s", number, ret, want)
+		}
 	}
 }
 
@@ -0,0 +1,187 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// findMergeBlock returns the number of the first canonical block produced after
+// the transition to proof-of-stake, i.e. the first block with zero difficulty.
+func findMergeBlock(db ethdb.Reader) (uint64, error) {
+	head := rawdb.ReadHeadHeaderHash(db)
+	number := rawdb.ReadHeaderNumber(db, head)
+	if number == nil {
+		return 0, errors.New("missing chain head")
+	}
+	header := func(n uint64) *types.Header {
+		return rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n)
+	}
+	if h := header(*number); h == nil || h.Difficulty.Sign() != 0 {
+		return 0, errors.New("chain has not transitioned to proof-of-stake")
+	}
+	merge := sort.Search(int(*number+1), func(i int) bool {
+		h := header(uint64(i))
+		return h == nil || h.Difficulty.Sign() == 0
+	})
+	if header(uint64(merge)) == nil {
+		return 0, fmt.Errorf("missing canonical header #%d", merge)
+	}
+	return uint64(merge), nil
+}
+
+// PruneChainHistory discards the bodies and receipts of the blocks before the
+// merge from the ancient store, returning the number of the first block whose
+// history is retained. The block headers and the genesis block are kept.
+//
+// The pruned blocks can be restored from era1 archives with InsertHistory.
+func PruneChainHistory(db ethdb.Database) (uint64, error) {
+	cutoff, err := findMergeBlock(db)
+	if err != nil {
+		return 0, err
+	}
+	if cutoff <= 1 {
+		return 0, errors.New("no pre-merge history to prune")
+	}
+	frozen, err := db.Ancients()
+	if err != nil {
+		return 0, err
+	}
+	if frozen < cutoff {
+		return 0, fmt.Errorf("pre-merge history not yet frozen: frozen %d, merge block %d", frozen, cutoff)
+	}
+	// The genesis block is read at startup, move it into the key-value store
+	// before it's discarded from the ancients.
+	var (
+		batch   = db.NewBatch()
+		genesis = rawdb.ReadCanonicalHash(db, 0)
+	)
+	if !rawdb.HasBody(db, genesis, 0) || !rawdb.HasReceipts(db, genesis, 0) {
+		return 0, errors.New("missing genesis block")
+	}
+	rawdb.WriteBodyRLP(batch, genesis, 0, rawdb.ReadBodyRLP(db, genesis, 0))
+	rawdb.WriteReceipts(batch, genesis, 0, rawdb.ReadRawReceipts(db, genesis, 0))
+
+	// Drop the transaction indexes of the pruned blocks, which can't be served
+	// without their bodies.
+	if tail := rawdb.ReadTxIndexTail(db); tail != nil && *tail < cutoff {
+		rawdb.UnindexTransactions(db, *tail, cutoff, nil, false)
+	}
+	// Mark the history as pruned before it's actually discarded, so that a crash
+	// in between leaves no blocks which are missing without being marked as such.
+	rawdb.WriteChainHistoryTail(batch, cutoff)
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	if _, err := db.TruncateTail(cutoff); err != nil {
+		return 0, err
+	}
+	if err := db.Sync(); err != nil {
+		return 0, err
+	}
+	log.Info("Pruned pre-merge chain history", "cutoff", cutoff)
+	return cutoff, nil
+}
+
+// InsertHistory restores the bodies and receipts of canonical blocks discarded
+// by PruneChainHistory into the ancient store. The blocks must be contiguous,
+// continue the ones restored before, starting at block 1, and precede the
+// history pruning cutoff.
+//
+// The restored history only becomes available once the whole history is
+// restored, when the ancient tail is re-extended and the cutoff lifted.
+func (bc *BlockChain) InsertHistory(blocks types.Blocks, receipts []types.Receipts) (int, error) {
+	if len(blocks) == 0 {
+		return 0, nil
+	}
+	if len(blocks) != len(receipts) {
+		return 0, fmt.Errorf("mismatching block and receipt count: %d != %d", len(blocks), len(receipts))
+	}
+	bc.wg.Add(1)
+	defer bc.wg.Done()
+
+	cutoff := bc.HistoryPruningCutoff()
+	if last := blocks[len(blocks)-1].NumberU64(); last >= cutoff {
+		return 0, fmt.Errorf("block #%d is not pruned, cutoff #%d", last, cutoff)
+	}
+	first, count := blocks[0], len(blocks)
+	if first.NumberU64() == 0 {
+		return 0, errors.New("genesis block is never pruned")
+	}
+	next, err := rawdb.ReadAncientBackfillProgress(bc.db)
+	if err != nil {
+		return 0, err
+	}
+	if first.NumberU64() != max(next, 1) {
+		return 0, fmt.Errorf("missing history of parent block #%d [%x..]", first.NumberU64()-1, first.ParentHash().Bytes()[:4])
+	}
+	for i, block := range blocks {
+		var (
+			hash   = block.Hash()
+			number = block.NumberU64()
+			header = block.Header()
+		)
+		if i > 0 && (number != blocks[i-1].NumberU64()+1 || block.ParentHash() != blocks[i-1].Hash()) {
+			return i, fmt.Errorf("non contiguous insert: item %d is #%d, item %d is #%d [%x..] (parent [%x..])",
+				i-1, blocks[i-1].NumberU64(), i, number, hash.Bytes()[:4], block.ParentHash().Bytes()[:4])
+		}
+		if bc.GetCanonicalHash(number) != hash {
+			return i, fmt.Errorf("block #%d [%x..] is not canonical", number, hash.Bytes()[:4])
+		}
+		if root := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); root != header.TxHash {
+			return i, fmt.Errorf("block #%d: transaction root hash mismatch (header value %x, calculated %x)", number, header.TxHash, root)
+		}
+		if uncles := types.CalcUncleHash(block.Uncles()); uncles != header.UncleHash {
+			return i, fmt.Errorf("block #%d: uncle root hash mismatch (header value %x, calculated %x)", number, header.UncleHash, uncles)
+		}
+		if root := types.DeriveSha(receipts[i], trie.NewStackTrie(nil)); root != header.ReceiptHash {
+			return i, fmt.Errorf("block #%d: receipt root hash mismatch (header value %x, calculated %x)", number, header.ReceiptHash, root)
+		}
+	}
+	// The genesis block was moved into the key-value store when pruning, restore
+	// it along with the first blocks.
+	if next == 0 {
+		genesis := bc.GetBlockByNumber(0)
+		if genesis == nil {
+			return 0, errors.New("missing genesis block")
+		}
+		blocks = append([]*types.Block{genesis}, blocks...)
+		receipts = append([]types.Receipts{rawdb.ReadRawReceipts(bc.db, genesis.Hash(), 0)}, receipts...)
+	}
+	if err := rawdb.WriteAncientHistory(bc.db, blocks, receipts); err != nil {
+		return 0, err
+	}
+	// Lift the cutoff once the tail of the ancient store is re-extended
+	tail, err := bc.db.Tail()
+	if err != nil {
+		return 0, err
+	}
+	if tail == 0 {
+		rawdb.DeleteChainHistoryTail(bc.db)
+		log.Info("Restored pruned chain history", "cutoff", cutoff)
+	}
+	log.Debug("Inserted chain history", "count", count, "number", blocks[len(blocks)-1].NumberU64())
+	return count, nil
+}
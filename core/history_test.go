@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestPruneChainHistory(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.TestChainConfig
+		gspec   = &Genesis{
+			Config:  &config,
+			Alloc:   types.GenesisAlloc{address: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(gspec.Config)
+	)
+	addTx := func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(address), common.Address{0xaa}, big.NewInt(1), params.TxGas, gen.header.BaseFee, nil), signer, key)
+		gen.AddTx(tx)
+	}
+	genDb, preBlocks, preReceipts := GenerateChainWithGenesis(gspec, engine, 8, addTx)
+
+	td := params.GenesisDifficulty.Uint64()
+	for _, block := range preBlocks {
+		td += block.Difficulty().Uint64()
+	}
+	gspec.Config.TerminalTotalDifficulty = new(big.Int).SetUint64(td)
+	postBlocks, postReceipts := GenerateChain(gspec.Config, preBlocks[len(preBlocks)-1], engine, genDb, 8, func(i int, gen *BlockGen) {
+		gen.SetPoS()
+		addTx(i, gen)
+	})
+	var (
+		blocks   = append(preBlocks, postBlocks...)
+		receipts = append(preReceipts, postReceipts...)
+		headers  = make([]*types.Header, len(blocks))
+	)
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	// Import the chain into the ancient store
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	chain, _ := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, engine, vm.Config{}, nil, nil)
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, uint64(len(blocks))); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	chain.Stop()
+
+	// Prune the pre-merge history and check it's no longer available
+	cutoff, err := PruneChainHistory(db)
+	if err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if cutoff != uint64(len(preBlocks)+1) {
+		t.Fatalf("wrong cutoff: have %d, want %d", cutoff, len(preBlocks)+1)
+	}
+	chain, err = NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to reopen pruned chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if have := chain.HistoryPruningCutoff(); have != cutoff {
+		t.Fatalf("wrong chain cutoff: have %d, want %d", have, cutoff)
+	}
+	if chain.GetBlockByNumber(0) == nil {
+		t.Fatal("genesis block pruned")
+	}
+	checkHistory := func(from, to uint64, available bool) {
+		t.Helper()
+
+		for _, block := range blocks[from-1 : to] {
+			hash, number := block.Hash(), block.NumberU64()
+			if chain.GetHeaderByNumber(number) == nil {
+				t.Fatalf("block #%d: header missing", number)
+			}
+			if have := chain.GetBlockByNumber(number) != nil; have != available {
+				t.Fatalf("block #%d: block availability mismatch: have %v, want %v", number, have, available)
+			}
+			if have := rawdb.HasBody(db, hash, number); have != available {
+				t.Fatalf("block #%d: body availability mismatch: have %v, want %v", number, have, available)
+			}
+			if have := rawdb.HasReceipts(db, hash, number); have != available {
+				t.Fatalf("block #%d: receipts availability mismatch: have %v, want %v", number, have, available)
+			}
+			if have := rawdb.ReadReceipts(db, hash, number, block.Time(), chain.Config()) != nil; have != available {
+				t.Fatalf("block #%d: receipts availability mismatch: have %v, want %v", number, have, available)
+			}
+		}
+	}
+	checkHistory(1, cutoff-1, false)
+	checkHistory(cutoff, uint64(len(blocks)), true)
+
+	// Backfill the pruned history in two steps, it only becomes available once
+	// complete
+	if _, err := chain.InsertHistory(preBlocks[4:], preReceipts[4:]); err == nil {
+		t.Fatal("inserted history without parent")
+	}
+	if _, err := chain.InsertHistory(preBlocks[:4], append(preReceipts[:3:3], types.Receipts{})); err == nil {
+		t.Fatal("inserted history with mismatching receipts")
+	}
+	if _, err := chain.InsertHistory(postBlocks[:1], postReceipts[:1]); err == nil {
+		t.Fatal("inserted history above the cutoff")
+	}
+	if _, err := chain.InsertHistory(preBlocks[:4], preReceipts[:4]); err != nil {
+		t.Fatalf("failed to insert history: %v", err)
+	}
+	checkHistory(1, cutoff-1, false)
+	if have := chain.HistoryPruningCutoff(); have != cutoff {
+		t.Fatalf("wrong chain cutoff: have %d, want %d", have, cutoff)
+	}
+	if _, err := chain.InsertHistory(preBlocks[4:], preReceipts[4:]); err != nil {
+		t.Fatalf("failed to insert history: %v", err)
+	}
+	checkHistory(1, uint64(len(blocks)), true)
+	if have := chain.HistoryPruningCutoff(); have != 0 {
+		t.Fatalf("history not restored: cutoff %d", have)
+	}
+	if tail, _ := db.Tail(); tail != 0 {
+		t.Fatalf("ancient tail not re-extended: tail %d", tail)
+	}
+}
@@ -0,0 +1,545 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package logindex implements a log index for searching the logs of the
+// canonical chain by address and topic.
+//
+// Every indexed log is assigned a sequential global position. The positions are
+// grouped into log maps of 2^16 logs each, and for every address and topic value
+// occurring in a map, a row holding the positions of the logs containing that
+// value is stored. Searching a block range therefore only requires reading the
+// rows of the queried values in the maps covering the range, which is fast
+// regardless of how sparse the matches are.
+package logindex
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// logIndexVersion is the version of the log index structure.
+	logIndexVersion = uint8(0)
+
+	// indexerBatchSize is the maximum number of blocks that can be indexed in a
+	// single database batch.
+	indexerBatchSize = 256
+
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+)
+
+// logMapBits is the number of bits of a log position addressing the log within
+// its log map. It must not exceed 16 as the positions are stored as uint16, and
+// is only meant to be changed in tests.
+var logMapBits = 16
+
+// errMissingReceipts is returned if the receipts of a block to be indexed are
+// not available in the database.
+var errMissingReceipts = errors.New("missing receipts")
+
+// Chain defines the methods of the blockchain required by the indexer.
+type Chain interface {
+	// CurrentBlock retrieves the head block of the canonical chain.
+	CurrentBlock() *types.Header
+
+	// GetCanonicalHash returns the canonical hash for a given block number.
+	GetCanonicalHash(number uint64) common.Hash
+
+	// SubscribeChainHeadEvent subscribes to the changes of the canonical head.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+
+	// HistoryPruningCutoff returns the number of the first block whose receipts
+	// are retained, zero if the chain history is complete.
+	HistoryPruningCutoff() uint64
+}
+
+// addressValue returns the index value of a log address.
+func addressValue(address common.Address) common.Hash {
+	return crypto.Keccak256Hash([]byte{0}, address.Bytes())
+}
+
+// topicValue returns the index value of a log topic at the given position.
+// The position is part of the value, so that topics are only matched at the
+// position they are queried for.
+func topicValue(position int, topic common.Hash) common.Hash {
+	return crypto.Keccak256Hash([]byte{byte(position + 1)}, topic.Bytes())
+}
+
+// indexMetadata describes the range of blocks whose logs have been indexed.
+type indexMetadata struct {
+	Version uint8  // Version tag of the index structure
+	Tail    uint64 // The number of the first indexed block
+	Head    uint64 // The number of the last indexed block
+	NextLog uint64 // The position assigned to the next indexed log
+}
+
+// loadIndexMetadata reads the metadata of the log index, nil is returned if
+// it's not available or corrupted.
+func loadIndexMetadata(db ethdb.KeyValueReader) *indexMetadata {
+	blob := rawdb.ReadLogIndexMetadata(db)
+	if len(blob) == 0 {
+		return nil
+	}
+	var m indexMetadata
+	if err := rlp.DecodeBytes(blob, &m); err != nil {
+		log.Error("Failed to decode log index metadata", "err", err)
+		return nil
+	}
+	return &m
+}
+
+// storeIndexMetadata writes the metadata of the log index.
+func storeIndexMetadata(db ethdb.KeyValueWriter, m *indexMetadata) {
+	blob, err := rlp.EncodeToBytes(m)
+	if err != nil {
+		log.Crit("Failed to encode log index metadata", "err", err)
+	}
+	rawdb.WriteLogIndexMetadata(db, blob)
+}
+
+// deleteAll removes all the entries iterated over, committing the deletions
+// into the batch and flushing it whenever it grows too large.
+func deleteAll(batch ethdb.Batch, it ethdb.Iterator) error {
+	defer it.Release()
+
+	for it.Next() {
+		batch.Delete(it.Key())
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return it.Error()
+}
+
+// purgeLogIndex removes the entire log index from the database.
+func purgeLogIndex(db ethdb.KeyValueStore) error {
+	var (
+		start = time.Now()
+		batch = db.NewBatch()
+	)
+	if err := deleteAll(batch, db.NewIterator(rawdb.LogIndexRowPrefix, nil)); err != nil {
+		return err
+	}
+	if err := deleteAll(batch, rawdb.IterateLogIndexBlocks(db)); err != nil {
+		return err
+	}
+	rawdb.DeleteLogIndexMetadata(batch)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Purged log index", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// rowKey identifies a row of the log index.
+type rowKey struct {
+	mapIndex uint32
+	value    common.Hash
+}
+
+// Indexer maintains the log index of the canonical chain. It follows the chain
+// head, indexing the logs of the newly imported blocks, rolling back the ones
+// reorged out of the canonical chain and unindexing the ones which fall out of
+// the configured history window.
+type Indexer struct {
+	db      ethdb.Database
+	chain   Chain
+	history uint64         // The number of recent blocks to index, zero means the entire chain
+	meta    *indexMetadata // The metadata of the index, nil if nothing is indexed
+	lock    sync.RWMutex   // Lock to serialize the index mutations and searches
+
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewIndexer constructs the log indexer and starts indexing the logs of the
+// canonical chain in background. The legacy bloombits data left in the database
+// is removed before the indexing starts.
+func NewIndexer(db ethdb.Database, chain Chain, history uint64) *Indexer {
+	m := loadIndexMetadata(db)
+
+	// Purge the stale index if it's not compatible with the current version,
+	// or if it's incapable of covering the entire available chain as requested.
+	// The index can only ever grow at its head, so a full reindex is required
+	// for it.
+	if (m == nil && rawdb.ReadLogIndexMetadata(db) != nil) ||
+		(m != nil && m.Version != logIndexVersion) ||
+		(m != nil && history == 0 && m.Tail > chain.HistoryPruningCutoff()) {
+		if err := purgeLogIndex(db); err != nil {
+			log.Crit("Failed to purge log index", "err", err)
+		}
+		m = nil
+	}
+	indexer := &Indexer{
+		db:      db,
+		chain:   chain,
+		history: history,
+		meta:    m,
+		headCh:  make(chan core.ChainHeadEvent, chainHeadChanSize),
+		closed:  make(chan struct{}),
+	}
+	indexer.headSub = chain.SubscribeChainHeadEvent(indexer.headCh)
+
+	indexer.wg.Add(1)
+	go indexer.loop()
+	return indexer
+}
+
+// loop is the main event loop of the indexer, which updates the index whenever
+// the chain head is changed.
+func (i *Indexer) loop() {
+	defer i.wg.Done()
+	defer i.headSub.Unsubscribe()
+
+	// Drop the data of the superseded bloombits indexer, it's of no use
+	// anymore and can't be converted as bloom filters are lossy.
+	if rawdb.HasLegacyBloomBits(i.db) {
+		log.Info("Deleting legacy bloombits, superseded by the log index")
+		done, err := rawdb.DeleteLegacyBloomBits(i.db, i.closed)
+		if err != nil {
+			log.Error("Failed to delete legacy bloombits", "err", err)
+		}
+		if !done {
+			return
+		}
+	}
+	for {
+		if err := i.run(); err != nil {
+			log.Error("Failed to index logs", "err", err)
+		}
+		select {
+		case <-i.headCh:
+		case <-i.headSub.Err():
+			return
+		case <-i.closed:
+			return
+		}
+	}
+}
+
+// run updates the index batch by batch until it's in sync with the chain head,
+// or until the indexer is closed.
+func (i *Indexer) run() error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		indexed uint64
+	)
+	for {
+		select {
+		case <-i.closed:
+			return nil
+		default:
+		}
+		n, head, done, err := i.update()
+		if err != nil {
+			return err
+		}
+		indexed += n
+		if done {
+			if indexed > 0 {
+				log.Debug("Indexed logs", "blocks", indexed, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			}
+			return nil
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing logs", "blocks", indexed, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+}
+
+// update performs a single step of the index maintenance: the blocks which are
+// no longer canonical are rolled back first, then the next batch of blocks is
+// indexed and finally the blocks out of the history window are unindexed. It
+// returns the number of newly indexed blocks, the number of the last indexed
+// block and the flag whether the index has caught up with the chain head.
+func (i *Indexer) update() (uint64, uint64, bool, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	current := i.chain.CurrentBlock()
+	if current == nil {
+		return 0, 0, true, nil
+	}
+	head := current.Number.Uint64()
+
+	// Roll back the indexed blocks reorged out of the canonical chain
+	if err := i.revert(); err != nil {
+		return 0, 0, false, err
+	}
+	var tail uint64
+	if i.history != 0 && head+1 > i.history {
+		tail = head + 1 - i.history
+	}
+	// The receipts of the pruned chain history can't be indexed
+	tail = max(tail, i.chain.HistoryPruningCutoff())
+	// Drop the index if it's entirely out of the history window, e.g. the
+	// node has been offline for a long time.
+	if i.meta != nil && i.meta.Head+1 < tail {
+		if err := purgeLogIndex(i.db); err != nil {
+			return 0, 0, false, err
+		}
+		i.meta = nil
+	}
+	var (
+		from uint64
+		m    *indexMetadata
+	)
+	if i.meta == nil {
+		from, m = tail, &indexMetadata{Version: logIndexVersion, Tail: tail}
+	} else {
+		from, m = i.meta.Head+1, i.meta
+	}
+	var indexed uint64
+	if from <= head {
+		to := min(head, from+indexerBatchSize-1)
+		n, err := i.index(m, from, to)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		indexed = n
+	}
+	// Unindex the blocks which fall out of the history window
+	if i.meta != nil && i.meta.Tail < tail {
+		if err := i.unindexTail(min(tail, i.meta.Head)); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	if i.meta == nil {
+		return indexed, 0, true, nil
+	}
+	return indexed, i.meta.Head, i.meta.Head >= head, nil
+}
+
+// index indexes the logs of the canonical blocks in range [from, to] on top of
+// the index described by the given metadata. The blocks are indexed as long as
+// they link to the index head, any block violating it means the chain has been
+// reorged in the meantime and it's left for the next round. The number of the
+// indexed blocks is returned.
+func (i *Indexer) index(m *indexMetadata, from, to uint64) (uint64, error) {
+	var (
+		batch  = i.db.NewBatch()
+		rows   = make(map[rowKey][]byte)
+		next   = m.NextLog
+		parent common.Hash
+		count  uint64
+	)
+	if i.meta != nil {
+		_, parent, _ = rawdb.ReadLogIndexBlock(i.db, m.Head)
+	}
+	for number := from; number <= to; number++ {
+		hash := i.chain.GetCanonicalHash(number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		header := rawdb.ReadHeader(i.db, hash, number)
+		if header == nil {
+			return 0, fmt.Errorf("missing header #%d [%x]", number, hash)
+		}
+		if (i.meta != nil || number > from) && header.ParentHash != parent {
+			break
+		}
+		receipts := rawdb.ReadRawReceipts(i.db, hash, number)
+		if receipts == nil && header.ReceiptHash != types.EmptyReceiptsHash {
+			return 0, fmt.Errorf("%w #%d [%x]", errMissingReceipts, number, hash)
+		}
+		rawdb.WriteLogIndexBlock(batch, number, next, hash)
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				var (
+					mapIndex = uint32(next >> logMapBits)
+					offset   = uint16(next)
+				)
+				key := rowKey{mapIndex, addressValue(l.Address)}
+				rows[key] = appendPosition(rows[key], offset)
+				for j, topic := range l.Topics {
+					key := rowKey{mapIndex, topicValue(j, topic)}
+					rows[key] = appendPosition(rows[key], offset)
+				}
+				next++
+			}
+		}
+		parent = hash
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	for key, positions := range rows {
+		row := rawdb.ReadLogIndexRow(i.db, key.mapIndex, key.value)
+		rawdb.WriteLogIndexRow(batch, key.mapIndex, key.value, append(row, positions...))
+	}
+	meta := *m
+	meta.Head, meta.NextLog = from+count-1, next
+	storeIndexMetadata(batch, &meta)
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	i.meta = &meta
+	return count, nil
+}
+
+// revert rolls back the indexed blocks which are no longer part of the
+// canonical chain. The entire index is dropped if no indexed block is
+// canonical anymore.
+func (i *Indexer) revert() error {
+	if i.meta == nil {
+		return nil
+	}
+	head, ok := i.canonicalHead()
+	if !ok {
+		if err := purgeLogIndex(i.db); err != nil {
+			return err
+		}
+		i.meta = nil
+		return nil
+	}
+	if head == i.meta.Head {
+		return nil
+	}
+	return i.unindexHead(head)
+}
+
+// canonicalHead returns the number of the last indexed block which is still
+// part of the canonical chain.
+func (i *Indexer) canonicalHead() (uint64, bool) {
+	for number := i.meta.Head; ; number-- {
+		_, hash, ok := rawdb.ReadLogIndexBlock(i.db, number)
+		if ok && hash == i.chain.GetCanonicalHash(number) {
+			return number, true
+		}
+		if number == i.meta.Tail {
+			return 0, false
+		}
+	}
+}
+
+// unindexHead removes the blocks above the given number from the index.
+func (i *Indexer) unindexHead(head uint64) error {
+	next, _, ok := rawdb.ReadLogIndexBlock(i.db, head+1)
+	if !ok {
+		return fmt.Errorf("missing log index block pointer #%d", head+1)
+	}
+	batch := i.db.NewBatch()
+	if next < i.meta.NextLog {
+		var (
+			first = uint32(next >> logMapBits)
+			last  = uint32((i.meta.NextLog - 1) >> logMapBits)
+		)
+		for mapIndex := first; mapIndex <= last; mapIndex++ {
+			it := rawdb.IterateLogIndexRows(i.db, mapIndex)
+			if mapIndex != first {
+				// The map contains no positions below the new head, drop it
+				if err := deleteAll(batch, it); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := truncateRows(batch, it, mapIndex, uint16(next)); err != nil {
+				return err
+			}
+		}
+	}
+	for number := head + 1; number <= i.meta.Head; number++ {
+		rawdb.DeleteLogIndexBlock(batch, number)
+	}
+	meta := *i.meta
+	meta.Head, meta.NextLog = head, next
+	storeIndexMetadata(batch, &meta)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Debug("Reverted log index", "from", i.meta.Head, "to", head)
+	i.meta = &meta
+	return nil
+}
+
+// truncateRows cuts off the positions not below the given offset from all the
+// rows of the map iterated over.
+func truncateRows(batch ethdb.Batch, it ethdb.Iterator, mapIndex uint32, offset uint16) error {
+	defer it.Release()
+
+	for it.Next() {
+		var (
+			row   = it.Value()
+			value = common.BytesToHash(it.Key()[len(it.Key())-common.HashLength:])
+			keep  = searchPosition(row, offset)
+		)
+		switch {
+		case keep == 0:
+			rawdb.DeleteLogIndexRow(batch, mapIndex, value)
+		case keep < len(row)/2:
+			rawdb.WriteLogIndexRow(batch, mapIndex, value, common.CopyBytes(row[:keep*2]))
+		}
+	}
+	return it.Error()
+}
+
+// unindexTail removes the blocks below the given number from the index. The
+// log maps are only dropped once all the logs in them are out of the index, the
+// stale positions in the first map are filtered out by the searches.
+func (i *Indexer) unindexTail(tail uint64) error {
+	oldFirst, _, ok := rawdb.ReadLogIndexBlock(i.db, i.meta.Tail)
+	if !ok {
+		return fmt.Errorf("missing log index block pointer #%d", i.meta.Tail)
+	}
+	newFirst, _, ok := rawdb.ReadLogIndexBlock(i.db, tail)
+	if !ok {
+		return fmt.Errorf("missing log index block pointer #%d", tail)
+	}
+	batch := i.db.NewBatch()
+	for mapIndex := uint32(oldFirst >> logMapBits); mapIndex < uint32(newFirst>>logMapBits); mapIndex++ {
+		if err := deleteAll(batch, rawdb.IterateLogIndexRows(i.db, mapIndex)); err != nil {
+			return err
+		}
+	}
+	for number := i.meta.Tail; number < tail; number++ {
+		rawdb.DeleteLogIndexBlock(batch, number)
+	}
+	meta := *i.meta
+	meta.Tail = tail
+	storeIndexMetadata(batch, &meta)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	i.meta = &meta
+	return nil
+}
+
+// Close terminates the background indexing and waits until it's stopped.
+func (i *Indexer) Close() {
+	close(i.closed)
+	i.wg.Wait()
+}
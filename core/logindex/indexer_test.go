@@ -0,0 +1,342 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"encoding/binary"
+	"math/big"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+var (
+	testAddresses = []common.Address{{0x01}, {0x02}, {0x03}}
+	testTopics    = []common.Hash{{0x0a}, {0x0b}, {0x0c}, {0x0d}}
+)
+
+// testChain is a canonical chain written directly into the database, along with
+// the generated logs of each block.
+type testChain struct {
+	db     ethdb.Database
+	head   atomic.Pointer[types.Header]
+	feed   event.Feed
+	logs   map[uint64][]*types.Log
+	cutoff atomic.Uint64
+}
+
+func newTestChain(db ethdb.Database) *testChain {
+	return &testChain{db: db, logs: make(map[uint64][]*types.Log)}
+}
+
+func (c *testChain) CurrentBlock() *types.Header {
+	return c.head.Load()
+}
+
+func (c *testChain) GetCanonicalHash(number uint64) common.Hash {
+	return rawdb.ReadCanonicalHash(c.db, number)
+}
+
+func (c *testChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+func (c *testChain) HistoryPruningCutoff() uint64 {
+	return c.cutoff.Load()
+}
+
+// extend generates the canonical blocks in range [from, to] on top of the
+// current canonical block from-1. The seed is mixed into the logs and the block
+// hashes, making it possible to generate forks.
+func (c *testChain) extend(from, to uint64, seed byte) {
+	var parent common.Hash
+	if from > 0 {
+		parent = rawdb.ReadCanonicalHash(c.db, from-1)
+	}
+	for n := from; n <= to; n++ {
+		var (
+			receipts types.Receipts
+			logs     []*types.Log
+		)
+		// Every third block is empty, the others contain a varying number
+		// of logs with a varying number of topics.
+		if (n+uint64(seed))%3 != 0 {
+			for i := uint64(0); i < (n+uint64(seed))%7+1; i++ {
+				l := &types.Log{Address: testAddresses[(n+i)%uint64(len(testAddresses))]}
+				for j := uint64(0); j < (n+i+uint64(seed))%4; j++ {
+					l.Topics = append(l.Topics, testTopics[(n+i+j)%uint64(len(testTopics))])
+				}
+				logs = append(logs, l)
+			}
+			receipts = types.Receipts{{Logs: logs}}
+		}
+		header := &types.Header{
+			ParentHash:  parent,
+			Number:      new(big.Int).SetUint64(n),
+			Extra:       []byte{seed},
+			ReceiptHash: types.EmptyReceiptsHash,
+		}
+		if receipts != nil {
+			header.ReceiptHash = common.Hash{0xff}
+		}
+		hash := header.Hash()
+		rawdb.WriteHeader(c.db, header)
+		rawdb.WriteCanonicalHash(c.db, hash, n)
+		if receipts != nil {
+			rawdb.WriteReceipts(c.db, hash, n, receipts)
+		}
+		c.logs[n] = logs
+		c.head.Store(header)
+		parent = hash
+	}
+	// Drop the stale canonical mappings of a longer replaced chain
+	for n := to + 1; rawdb.ReadCanonicalHash(c.db, n) != (common.Hash{}); n++ {
+		rawdb.DeleteCanonicalHash(c.db, n)
+		delete(c.logs, n)
+	}
+	c.feed.Send(core.ChainHeadEvent{})
+}
+
+// search returns the numbers of the blocks in range [begin, end] containing
+// logs which match the given criteria.
+func (c *testChain) search(begin, end uint64, addresses []common.Address, topics [][]common.Hash) []uint64 {
+	var numbers []uint64
+	for n := begin; n <= end; n++ {
+		for _, l := range c.logs[n] {
+			if len(addresses) > 0 && !slices.Contains(addresses, l.Address) {
+				continue
+			}
+			matched := true
+			for i, list := range topics {
+				if len(list) == 0 {
+					continue
+				}
+				if i >= len(l.Topics) || !slices.Contains(list, l.Topics[i]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				numbers = append(numbers, n)
+				break
+			}
+		}
+	}
+	return numbers
+}
+
+func waitIndexed(t *testing.T, index *Indexer, tail, head uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		if first, last, ok := index.Range(); ok && first == tail && last == head {
+			index.lock.RLock()
+			synced := index.meta.Head == head
+			index.lock.RUnlock()
+			if synced {
+				return
+			}
+		}
+	}
+	t.Fatalf("Logs are not indexed in time")
+}
+
+func checkSearch(t *testing.T, chain *testChain, index *Indexer, tail, head uint64) {
+	t.Helper()
+
+	queries := []struct {
+		addresses []common.Address
+		topics    [][]common.Hash
+	}{
+		{nil, nil},
+		{testAddresses[:1], nil},
+		{testAddresses[1:], nil},
+		{[]common.Address{{0xff}}, nil},
+		{nil, [][]common.Hash{{testTopics[0]}}},
+		{nil, [][]common.Hash{nil, {testTopics[1], testTopics[2]}}},
+		{testAddresses[2:], [][]common.Hash{{testTopics[3]}, nil, {testTopics[1]}}},
+		{testAddresses[:2], [][]common.Hash{{testTopics[0], testTopics[1]}, {testTopics[2]}}},
+	}
+	ranges := [][2]uint64{{tail, head}, {tail, tail}, {head, head}, {tail + 1, head - 1}, {(tail + head) / 2, head}}
+	for i, query := range queries {
+		for _, r := range ranges {
+			have, err := index.Search(context.Background(), r[0], r[1], query.addresses, query.topics)
+			if err != nil {
+				t.Fatalf("query %d, range %v: search failed: %v", i, r, err)
+			}
+			want := chain.search(r[0], r[1], query.addresses, query.topics)
+			if !slices.Equal(have, want) {
+				t.Fatalf("query %d, range %v: search mismatch\nhave %v\nwant %v", i, r, have, want)
+			}
+		}
+	}
+	if _, err := index.Search(context.Background(), head, head+1, nil, nil); err != ErrOutOfRange {
+		t.Fatalf("search above the head: have %v, want %v", err, ErrOutOfRange)
+	}
+	if tail > 0 {
+		if _, err := index.Search(context.Background(), tail-1, head, nil, nil); err != ErrOutOfRange {
+			t.Fatalf("search below the tail: have %v, want %v", err, ErrOutOfRange)
+		}
+	}
+}
+
+// withSmallMaps shrinks the log maps to span only a few blocks, so that the map
+// boundaries are exercised.
+func withSmallMaps(t *testing.T) {
+	logMapBits = 3
+	t.Cleanup(func() { logMapBits = 16 })
+}
+
+func TestIndexerSearch(t *testing.T) {
+	withSmallMaps(t)
+
+	db := rawdb.NewMemoryDatabase()
+	chain := newTestChain(db)
+	chain.extend(0, 600, 0)
+
+	index := NewIndexer(db, chain, 0)
+	defer index.Close()
+
+	waitIndexed(t, index, 0, 600)
+	checkSearch(t, chain, index, 0, 600)
+
+	// Extend the chain and check the index follows it
+	chain.extend(601, 700, 0)
+	waitIndexed(t, index, 0, 700)
+	checkSearch(t, chain, index, 0, 700)
+}
+
+func TestIndexerReorg(t *testing.T) {
+	withSmallMaps(t)
+
+	db := rawdb.NewMemoryDatabase()
+	chain := newTestChain(db)
+	chain.extend(0, 300, 0)
+
+	index := NewIndexer(db, chain, 0)
+	defer func() { index.Close() }()
+	waitIndexed(t, index, 0, 300)
+
+	// Replace the head with a shorter fork, the reverted blocks must be
+	// dropped from the index.
+	chain.extend(250, 280, 1)
+	waitIndexed(t, index, 0, 280)
+	checkSearch(t, chain, index, 0, 280)
+
+	// Replace the head with a longer fork
+	chain.extend(100, 400, 2)
+	waitIndexed(t, index, 0, 400)
+	checkSearch(t, chain, index, 0, 400)
+
+	// Reopen the index on top of a chain reorged while it was offline
+	index.Close()
+	chain.extend(350, 360, 3)
+
+	index = NewIndexer(db, chain, 0)
+	waitIndexed(t, index, 0, 360)
+	checkSearch(t, chain, index, 0, 360)
+}
+
+func TestIndexerHistory(t *testing.T) {
+	withSmallMaps(t)
+
+	db := rawdb.NewMemoryDatabase()
+	chain := newTestChain(db)
+	chain.extend(0, 500, 0)
+
+	index := NewIndexer(db, chain, 100)
+	defer index.Close()
+
+	waitIndexed(t, index, 401, 500)
+	checkSearch(t, chain, index, 401, 500)
+
+	// Advance the chain, the blocks out of the history window are unindexed
+	chain.extend(501, 700, 0)
+	waitIndexed(t, index, 601, 700)
+	checkSearch(t, chain, index, 601, 700)
+
+	for n := uint64(0); n < 601; n++ {
+		if _, _, ok := rawdb.ReadLogIndexBlock(db, n); ok {
+			t.Fatalf("block pointer #%d not unindexed", n)
+		}
+	}
+	first, _, _ := rawdb.ReadLogIndexBlock(db, 601)
+	it := db.NewIterator(rawdb.LogIndexRowPrefix, nil)
+	defer it.Release()
+	for it.Next() {
+		mapIndex := binary.BigEndian.Uint32(it.Key()[len(rawdb.LogIndexRowPrefix):])
+		if mapIndex < uint32(first>>logMapBits) {
+			t.Fatalf("log map %d not unindexed, first map %d", mapIndex, first>>logMapBits)
+		}
+	}
+}
+
+func TestIndexerHistoryPruning(t *testing.T) {
+	withSmallMaps(t)
+
+	db := rawdb.NewMemoryDatabase()
+	chain := newTestChain(db)
+	chain.extend(0, 300, 0)
+
+	index := NewIndexer(db, chain, 0)
+	waitIndexed(t, index, 0, 300)
+
+	// Prune the chain history, the pruned blocks are unindexed
+	chain.cutoff.Store(100)
+	chain.extend(301, 310, 0)
+	waitIndexed(t, index, 100, 310)
+	checkSearch(t, chain, index, 100, 310)
+	index.Close()
+
+	// Reopen the index, it's retained as it covers the available chain
+	index = NewIndexer(db, chain, 0)
+	waitIndexed(t, index, 100, 310)
+
+	// Restore the chain history, the index is rebuilt from the genesis
+	index.Close()
+	chain.cutoff.Store(0)
+
+	index = NewIndexer(db, chain, 0)
+	defer index.Close()
+	waitIndexed(t, index, 0, 310)
+	checkSearch(t, chain, index, 0, 310)
+}
+
+func TestIndexerLegacyBloomBits(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	db.Put(append(common.CopyBytes(rawdb.BloomBitsIndexPrefix), []byte("count")...), []byte{0x01})
+
+	chain := newTestChain(db)
+	chain.extend(0, 10, 0)
+
+	index := NewIndexer(db, chain, 0)
+	defer index.Close()
+
+	waitIndexed(t, index, 0, 10)
+	if rawdb.HasLegacyBloomBits(db) {
+		t.Fatal("Legacy bloombits are not deleted")
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package logindex
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+// ErrOutOfRange is returned if the searched block range is not covered by the
+// log index, e.g. the index has been changed since its range was queried.
+var ErrOutOfRange = errors.New("block range not indexed")
+
+// appendPosition appends a log position to the encoded row.
+func appendPosition(row []byte, offset uint16) []byte {
+	return binary.BigEndian.AppendUint16(row, offset)
+}
+
+// searchPosition returns the number of positions in the encoded row which are
+// below the given offset.
+func searchPosition(row []byte, offset uint16) int {
+	return sort.Search(len(row)/2, func(i int) bool {
+		return binary.BigEndian.Uint16(row[i*2:]) >= offset
+	})
+}
+
+// decodeRow decodes the sorted log positions of a row.
+func decodeRow(row []byte) []uint16 {
+	positions := make([]uint16, len(row)/2)
+	for i := range positions {
+		positions[i] = binary.BigEndian.Uint16(row[i*2:])
+	}
+	return positions
+}
+
+// union merges two sorted position lists.
+func union(a, b []uint16) []uint16 {
+	merged := make([]uint16, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			merged, a = append(merged, a[0]), a[1:]
+		case a[0] > b[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// intersect returns the positions present in both sorted lists.
+func intersect(a, b []uint16) []uint16 {
+	var result []uint16
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			result, a, b = append(result, a[0]), a[1:], b[1:]
+		}
+	}
+	return result
+}
+
+// Range returns the range of blocks [tail, head] whose logs are indexed and
+// which are still part of the canonical chain. False is returned if no such
+// block is available.
+func (i *Indexer) Range() (uint64, uint64, bool) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	return i.validRange()
+}
+
+// validRange is the non-locking version of Range.
+func (i *Indexer) validRange() (uint64, uint64, bool) {
+	if i.meta == nil {
+		return 0, 0, false
+	}
+	head, ok := i.canonicalHead()
+	if !ok {
+		return 0, 0, false
+	}
+	return i.meta.Tail, head, true
+}
+
+// firstLog returns the position of the first log of the given indexed block.
+func (i *Indexer) firstLog(number uint64) (uint64, error) {
+	if number == i.meta.Head+1 {
+		return i.meta.NextLog, nil
+	}
+	first, _, ok := rawdb.ReadLogIndexBlock(i.db, number)
+	if !ok {
+		return 0, fmt.Errorf("missing log index block pointer #%d", number)
+	}
+	return first, nil
+}
+
+// Search returns the numbers of the blocks in range [begin, end] which contain
+// logs potentially matching the given criteria. The addresses are matched if
+// any of them is the log address, and the topic list is matched if, at each
+// position, any of the listed topics is the log topic. An empty list at any
+// position is a wildcard.
+//
+// The returned blocks are candidates: logs are not checked against the number
+// of topics in the criteria, hence the callers need to filter the logs of the
+// blocks for exact matches. ErrOutOfRange is returned if the given range is not
+// entirely indexed.
+func (i *Indexer) Search(ctx context.Context, begin, end uint64, addresses []common.Address, topics [][]common.Hash) ([]uint64, error) {
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	tail, head, ok := i.validRange()
+	if !ok || begin > end || begin < tail || end > head {
+		return nil, ErrOutOfRange
+	}
+	first, err := i.firstLog(begin)
+	if err != nil {
+		return nil, err
+	}
+	last, err := i.firstLog(end + 1)
+	if err != nil {
+		return nil, err
+	}
+	if first == last {
+		return nil, nil // no logs in the range at all
+	}
+	// Convert the criteria into a list of clauses, each matching the logs
+	// which contain any of the values of the clause.
+	var clauses [][]common.Hash
+	if len(addresses) > 0 {
+		clause := make([]common.Hash, len(addresses))
+		for j, address := range addresses {
+			clause[j] = addressValue(address)
+		}
+		clauses = append(clauses, clause)
+	}
+	for j, list := range topics {
+		if len(list) == 0 {
+			continue // wildcard
+		}
+		clause := make([]common.Hash, len(list))
+		for k, topic := range list {
+			clause[k] = topicValue(j, topic)
+		}
+		clauses = append(clauses, clause)
+	}
+	// Without any clauses every log matches, the blocks containing any logs
+	// can be resolved from the block pointers directly.
+	if len(clauses) == 0 {
+		var (
+			numbers []uint64
+			prev    = first
+		)
+		for number := begin; number <= end; number++ {
+			next, err := i.firstLog(number + 1)
+			if err != nil {
+				return nil, err
+			}
+			if next > prev {
+				numbers = append(numbers, number)
+			}
+			prev = next
+		}
+		return numbers, nil
+	}
+	// Collect the matching positions map by map, then resolve the blocks
+	// containing them.
+	var positions []uint64
+	for mapIndex := uint32(first >> logMapBits); mapIndex <= uint32((last-1)>>logMapBits); mapIndex++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, offset := range i.matchMap(mapIndex, clauses) {
+			pos := uint64(mapIndex)<<logMapBits | uint64(offset)
+			if pos >= first && pos < last {
+				positions = append(positions, pos)
+			}
+		}
+	}
+	return i.resolveBlocks(positions, begin, end)
+}
+
+// matchMap returns the positions of the logs within the given map which are
+// matched by all the clauses.
+func (i *Indexer) matchMap(mapIndex uint32, clauses [][]common.Hash) []uint16 {
+	var matches []uint16
+	for j, clause := range clauses {
+		var list []uint16
+		for _, value := range clause {
+			if row := rawdb.ReadLogIndexRow(i.db, mapIndex, value); len(row) > 0 {
+				list = union(list, decodeRow(row))
+			}
+		}
+		if j == 0 {
+			matches = list
+		} else {
+			matches = intersect(matches, list)
+		}
+		if len(matches) == 0 {
+			return nil
+		}
+	}
+	return matches
+}
+
+// resolveBlocks converts the sorted list of log positions into the numbers of
+// the blocks containing them, the positions must belong to the blocks in range
+// [begin, end].
+func (i *Indexer) resolveBlocks(positions []uint64, begin, end uint64) ([]uint64, error) {
+	var (
+		numbers []uint64
+		number  = begin
+		err     error
+	)
+	for _, pos := range positions {
+		if len(numbers) > 0 && numbers[len(numbers)-1] == number {
+			// Skip the positions still belonging to the last resolved block
+			next, ferr := i.firstLog(number + 1)
+			if ferr != nil {
+				return nil, ferr
+			}
+			if pos < next {
+				continue
+			}
+		}
+		// Find the last block whose first log is not above the position
+		n := sort.Search(int(end-number), func(k int) bool {
+			next, ferr := i.firstLog(number + uint64(k) + 1)
+			if ferr != nil {
+				err = ferr
+				return true
+			}
+			return next > pos
+		})
+		if err != nil {
+			return nil, err
+		}
+		number += uint64(n)
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
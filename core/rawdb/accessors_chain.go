@@ -277,6 +277,33 @@ func WriteTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadChainHistoryTail retrieves the number of the oldest block whose body and
+// receipts are retained, nil is returned if the chain history was never pruned.
+func ReadChainHistoryTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(chainHistoryTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteChainHistoryTail stores the number of the oldest block whose body and
+// receipts are retained into database.
+func WriteChainHistoryTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(chainHistoryTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the chain history tail", "err", err)
+	}
+}
+
+// DeleteChainHistoryTail removes the chain history tail, marking the entire
+// chain history as available.
+func DeleteChainHistoryTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(chainHistoryTailKey); err != nil {
+		log.Crit("Failed to delete the chain history tail", "err", err)
+	}
+}
+
 // ReadHeaderRange returns the rlp-encoded headers, starting at 'number', and going
 // backwards towards genesis. This method assumes that the caller already has
 // placed a cap on count, to prevent DoS issues.
@@ -432,7 +459,11 @@ func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerBodiesTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
+			// The body was pruned from the ancients, only the genesis body
+			// is retained in leveldb.
 		}
 		// If not, try reading from leveldb
 		data, _ = db.Get(blockBodyKey(number, hash))
@@ -454,6 +485,11 @@ func ReadCanonicalBodyRLP(db ethdb.Reader, number uint64) rlp.RawValue {
 		// Note: ReadCanonicalHash cannot be used here because it also
 		// calls ReadAncients internally.
 		hash, _ := db.Get(headerHashKey(number))
+		if len(hash) == 0 {
+			// The body might have been pruned from the ancients, with the
+			// genesis body retained in leveldb.
+			hash, _ = reader.Ancient(ChainFreezerHashTable, number)
+		}
 		data, _ = db.Get(blockBodyKey(number, common.BytesToHash(hash)))
 		return nil
 	})
@@ -470,7 +506,10 @@ func WriteBodyRLP(db ethdb.KeyValueWriter, hash common.Hash, number uint64, rlp
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db ethdb.Reader, hash common.Hash, number uint64) bool {
 	if isCanon(db, number, hash) {
-		return true
+		// The body might have been pruned from the ancients
+		if has, _ := db.HasAncient(ChainFreezerBodiesTable, number); has {
+			return true
+		}
 	}
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
 		return false
@@ -560,7 +599,10 @@ func DeleteTd(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 // to a block.
 func HasReceipts(db ethdb.Reader, hash common.Hash, number uint64) bool {
 	if isCanon(db, number, hash) {
-		return true
+		// The receipts might have been pruned from the ancients
+		if has, _ := db.HasAncient(ChainFreezerReceiptTable, number); has {
+			return true
+		}
 	}
 	if has, err := db.Has(blockReceiptsKey(number, hash)); !has || err != nil {
 		return false
@@ -575,7 +617,11 @@ func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawVa
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerReceiptTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
+			// The receipts were pruned from the ancients, only the genesis
+			// receipts are retained in leveldb.
 		}
 		// If not, try reading from leveldb
 		data, _ = db.Get(blockReceiptsKey(number, hash))
@@ -785,6 +831,47 @@ func writeAncientBlock(op ethdb.AncientWriteOp, block *types.Block, header *type
 	return nil
 }
 
+// ReadAncientBackfillProgress returns the number of the first block whose body
+// and receipts were pruned from the ancient store and are not restored yet. It
+// equals the ancient tail if all of them are restored.
+func ReadAncientBackfillProgress(db ethdb.AncientReader) (uint64, error) {
+	backfiller, ok := db.(tailBackfiller)
+	if !ok {
+		return 0, errNotSupported
+	}
+	return backfiller.backfillProgress()
+}
+
+// WriteAncientHistory restores the bodies and receipts of the given blocks,
+// which were pruned from the ancient store. The blocks must continue the ones
+// restored before, blocks which are not missing are skipped.
+//
+// The restored history only becomes available once all the pruned blocks are
+// restored, when the ancient tail is re-extended to the genesis block.
+func WriteAncientHistory(db ethdb.AncientWriter, blocks []*types.Block, receipts []types.Receipts) error {
+	backfiller, ok := db.(tailBackfiller)
+	if !ok {
+		return errNotSupported
+	}
+	var stReceipts []*types.ReceiptForStorage
+	return backfiller.backfillTail(func(op ethdb.AncientWriteOp) error {
+		for i, block := range blocks {
+			num := block.NumberU64()
+			stReceipts = stReceipts[:0]
+			for _, receipt := range receipts[i] {
+				stReceipts = append(stReceipts, (*types.ReceiptForStorage)(receipt))
+			}
+			if err := op.Append(ChainFreezerBodiesTable, num, block.Body()); err != nil {
+				return fmt.Errorf("can't append block body %d: %v", num, err)
+			}
+			if err := op.Append(ChainFreezerReceiptTable, num, stReceipts); err != nil {
+				return fmt.Errorf("can't append block %d receipts: %v", num, err)
+			}
+		}
+		return nil
+	})
+}
+
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db ethdb.KeyValueWriter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
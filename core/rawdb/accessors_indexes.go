@@ -18,7 +18,9 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -145,37 +147,165 @@ func ReadReceipt(db ethdb.Reader, hash common.Hash, config *params.ChainConfig)
 	return nil, common.Hash{}, 0, 0
 }
 
-// ReadBloomBits retrieves the compressed bloom bit vector belonging to the given
-// section and bit index from the.
-func ReadBloomBits(db ethdb.KeyValueReader, bit uint, section uint64, head common.Hash) ([]byte, error) {
-	return db.Get(bloomBitsKey(bit, section, head))
+// ReadLogIndexMetadata retrieves the metadata of the log index.
+func ReadLogIndexMetadata(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(logIndexKey)
+	return data
 }
 
-// WriteBloomBits stores the compressed bloom bits vector belonging to the given
-// section and bit index.
-func WriteBloomBits(db ethdb.KeyValueWriter, bit uint, section uint64, head common.Hash, bits []byte) {
-	if err := db.Put(bloomBitsKey(bit, section, head), bits); err != nil {
-		log.Crit("Failed to store bloom bits", "err", err)
+// WriteLogIndexMetadata stores the metadata of the log index into database.
+func WriteLogIndexMetadata(db ethdb.KeyValueWriter, blob []byte) {
+	if err := db.Put(logIndexKey, blob); err != nil {
+		log.Crit("Failed to store the metadata of log index", "err", err)
 	}
 }
 
-// DeleteBloombits removes all compressed bloom bits vector belonging to the
-// given section range and bit index.
-func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
-	start, end := bloomBitsKey(bit, from, common.Hash{}), bloomBitsKey(bit, to, common.Hash{})
-	it := db.NewIterator(nil, start)
-	defer it.Release()
+// DeleteLogIndexMetadata removes the metadata of the log index.
+func DeleteLogIndexMetadata(db ethdb.KeyValueWriter) {
+	if err := db.Delete(logIndexKey); err != nil {
+		log.Crit("Failed to delete the metadata of log index", "err", err)
+	}
+}
+
+// ReadLogIndexRow retrieves the positions of the logs within the specified log
+// map that contain the given value.
+func ReadLogIndexRow(db ethdb.KeyValueReader, mapIndex uint32, value common.Hash) []byte {
+	data, _ := db.Get(logIndexRowKey(mapIndex, value))
+	return data
+}
+
+// WriteLogIndexRow stores the positions of the logs within the specified log
+// map that contain the given value.
+func WriteLogIndexRow(db ethdb.KeyValueWriter, mapIndex uint32, value common.Hash, row []byte) {
+	if err := db.Put(logIndexRowKey(mapIndex, value), row); err != nil {
+		log.Crit("Failed to store log index row", "err", err)
+	}
+}
+
+// DeleteLogIndexRow removes a single row of the specified log map.
+func DeleteLogIndexRow(db ethdb.KeyValueWriter, mapIndex uint32, value common.Hash) {
+	if err := db.Delete(logIndexRowKey(mapIndex, value)); err != nil {
+		log.Crit("Failed to delete log index row", "err", err)
+	}
+}
+
+// IterateLogIndexRows returns an iterator over all the rows of the specified
+// log map. The value hash of each row is the key suffix after the map prefix.
+func IterateLogIndexRows(db ethdb.Iteratee, mapIndex uint32) ethdb.Iterator {
+	return db.NewIterator(logIndexMapPrefix(mapIndex), nil)
+}
+
+// ReadLogIndexBlock retrieves the index of the first log of the given block
+// along with the hash of the block it was indexed with.
+func ReadLogIndexBlock(db ethdb.KeyValueReader, number uint64) (uint64, common.Hash, bool) {
+	data, _ := db.Get(logIndexBlockKey(number))
+	if len(data) != 8+common.HashLength {
+		return 0, common.Hash{}, false
+	}
+	return binary.BigEndian.Uint64(data), common.BytesToHash(data[8:]), true
+}
+
+// WriteLogIndexBlock stores the index of the first log of the given block
+// along with its hash.
+func WriteLogIndexBlock(db ethdb.KeyValueWriter, number uint64, first uint64, hash common.Hash) {
+	var data [8 + common.HashLength]byte
+	binary.BigEndian.PutUint64(data[:], first)
+	copy(data[8:], hash.Bytes())
+	if err := db.Put(logIndexBlockKey(number), data[:]); err != nil {
+		log.Crit("Failed to store log index block pointer", "err", err)
+	}
+}
 
-	for it.Next() {
-		if bytes.Compare(it.Key(), end) >= 0 {
-			break
+// DeleteLogIndexBlock removes the log index block pointer of the given block.
+func DeleteLogIndexBlock(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Delete(logIndexBlockKey(number)); err != nil {
+		log.Crit("Failed to delete log index block pointer", "err", err)
+	}
+}
+
+// IterateLogIndexBlocks returns an iterator over all the log index block pointers.
+func IterateLogIndexBlocks(db ethdb.Iteratee) ethdb.Iterator {
+	return db.NewIterator(logIndexBlockPrefix, nil)
+}
+
+// HasLegacyBloomBits reports whether the database still contains the data of
+// the legacy bloombits indexer, which has been superseded by the log index.
+func HasLegacyBloomBits(db ethdb.Iteratee) bool {
+	for _, prefix := range [][]byte{BloomBitsIndexPrefix, bloomBitsPrefix} {
+		it := db.NewIterator(prefix, nil)
+		found := false
+		for it.Next() {
+			if isLegacyBloomBitsKey(it.Key()) {
+				found = true
+				break
+			}
 		}
-		if len(it.Key()) != len(bloomBitsPrefix)+2+8+32 {
-			continue
+		it.Release()
+		if found {
+			return true
 		}
-		db.Delete(it.Key())
 	}
-	if it.Error() != nil {
-		log.Crit("Failed to delete bloom bits", "err", it.Error())
+	return false
+}
+
+// DeleteLegacyBloomBits removes all the data of the legacy bloombits indexer.
+// The deletion is aborted if a signal is received from the interrupt channel,
+// in which case false is returned.
+func DeleteLegacyBloomBits(db ethdb.KeyValueStore, interrupt chan struct{}) (bool, error) {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		batch   = db.NewBatch()
+		deleted int
+	)
+	for _, prefix := range [][]byte{BloomBitsIndexPrefix, bloomBitsPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			if !isLegacyBloomBitsKey(it.Key()) {
+				continue
+			}
+			batch.Delete(it.Key())
+			deleted++
+
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return false, err
+				}
+				batch.Reset()
+
+				select {
+				case <-interrupt:
+					it.Release()
+					log.Info("Legacy bloombits deletion interrupted", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+					return false, nil
+				default:
+				}
+				if time.Since(logged) > 8*time.Second {
+					log.Info("Deleting legacy bloombits", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+					logged = time.Now()
+				}
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return false, err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return false, err
+	}
+	if deleted > 0 {
+		log.Info("Deleted legacy bloombits", "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return true, nil
+}
+
+// isLegacyBloomBitsKey reports whether the key belongs to the legacy bloombits
+// indexer, either the bloom bit vectors or the progress table of the indexer.
+func isLegacyBloomBitsKey(key []byte) bool {
+	if bytes.HasPrefix(key, BloomBitsIndexPrefix) {
+		return true
 	}
+	return bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength
 }
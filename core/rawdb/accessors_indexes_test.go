@@ -112,45 +112,71 @@ func TestLookupStorage(t *testing.T) {
 	}
 }
 
-func TestDeleteBloomBits(t *testing.T) {
+func TestDeleteLegacyBloomBits(t *testing.T) {
 	// Prepare testing data
 	db := NewMemoryDatabase()
 	for i := uint(0); i < 2; i++ {
 		for s := uint64(0); s < 2; s++ {
-			WriteBloomBits(db, i, s, params.MainnetGenesisHash, []byte{0x01, 0x02})
-			WriteBloomBits(db, i, s, params.SepoliaGenesisHash, []byte{0x01, 0x02})
+			db.Put(bloomBitsKey(i, s, params.MainnetGenesisHash), []byte{0x01, 0x02})
+			db.Put(bloomBitsKey(i, s, params.SepoliaGenesisHash), []byte{0x01, 0x02})
 		}
 	}
-	check := func(bit uint, section uint64, head common.Hash, exist bool) {
-		bits, _ := ReadBloomBits(db, bit, section, head)
-		if exist && !bytes.Equal(bits, []byte{0x01, 0x02}) {
-			t.Fatalf("Bloombits mismatch")
-		}
-		if !exist && len(bits) > 0 {
-			t.Fatalf("Bloombits should be removed")
-		}
+	db.Put(append(common.CopyBytes(BloomBitsIndexPrefix), []byte("count")...), []byte{0x01})
+
+	// Keys sharing the prefix but of a different layout must be retained
+	unrelated := append(common.CopyBytes(bloomBitsPrefix), []byte("unrelated")...)
+	db.Put(unrelated, []byte{0x01})
+
+	if !HasLegacyBloomBits(db) {
+		t.Fatal("Legacy bloombits not detected")
+	}
+	done, err := DeleteLegacyBloomBits(db, nil)
+	if err != nil {
+		t.Fatalf("Failed to delete legacy bloombits: %v", err)
+	}
+	if !done {
+		t.Fatal("Legacy bloombits deletion not completed")
+	}
+	if HasLegacyBloomBits(db) {
+		t.Fatal("Legacy bloombits not deleted")
+	}
+	if ok, _ := db.Has(unrelated); !ok {
+		t.Fatal("Unrelated key deleted")
+	}
+}
+
+func TestLogIndexAccessors(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	// Rows of different maps must be isolated from each other
+	WriteLogIndexRow(db, 1, common.Hash{0x01}, []byte{0x00, 0x01})
+	WriteLogIndexRow(db, 1, common.Hash{0x02}, []byte{0x00, 0x02})
+	WriteLogIndexRow(db, 2, common.Hash{0x01}, []byte{0x00, 0x03})
+
+	if row := ReadLogIndexRow(db, 1, common.Hash{0x01}); !bytes.Equal(row, []byte{0x00, 0x01}) {
+		t.Fatalf("Log index row mismatch, want %x, got %x", []byte{0x00, 0x01}, row)
+	}
+	it := IterateLogIndexRows(db, 1)
+	var count int
+	for it.Next() {
+		count++
+	}
+	it.Release()
+	if count != 2 {
+		t.Fatalf("Log index map row count mismatch, want %d, got %d", 2, count)
+	}
+	DeleteLogIndexRow(db, 1, common.Hash{0x01})
+	if row := ReadLogIndexRow(db, 1, common.Hash{0x01}); len(row) != 0 {
+		t.Fatalf("Log index row not deleted")
+	}
+	// Block pointers
+	WriteLogIndexBlock(db, 10, 100, common.Hash{0xff})
+	first, hash, ok := ReadLogIndexBlock(db, 10)
+	if !ok || first != 100 || hash != (common.Hash{0xff}) {
+		t.Fatalf("Log index block pointer mismatch, got (%d, %x, %t)", first, hash, ok)
+	}
+	DeleteLogIndexBlock(db, 10)
+	if _, _, ok := ReadLogIndexBlock(db, 10); ok {
+		t.Fatalf("Log index block pointer not deleted")
 	}
-	// Check the existence of written data.
-	check(0, 0, params.MainnetGenesisHash, true)
-	check(0, 0, params.SepoliaGenesisHash, true)
-
-	// Check the existence of deleted data.
-	DeleteBloombits(db, 0, 0, 1)
-	check(0, 0, params.MainnetGenesisHash, false)
-	check(0, 0, params.SepoliaGenesisHash, false)
-	check(0, 1, params.MainnetGenesisHash, true)
-	check(0, 1, params.SepoliaGenesisHash, true)
-
-	// Check the existence of deleted data.
-	DeleteBloombits(db, 0, 0, 2)
-	check(0, 0, params.MainnetGenesisHash, false)
-	check(0, 0, params.SepoliaGenesisHash, false)
-	check(0, 1, params.MainnetGenesisHash, false)
-	check(0, 1, params.SepoliaGenesisHash, false)
-
-	// Bit1 shouldn't be affect.
-	check(1, 0, params.MainnetGenesisHash, true)
-	check(1, 0, params.SepoliaGenesisHash, true)
-	check(1, 1, params.MainnetGenesisHash, true)
-	check(1, 1, params.SepoliaGenesisHash, true)
 }
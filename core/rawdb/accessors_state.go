@@ -160,6 +160,81 @@ func DeleteTrieJournal(db ethdb.KeyValueWriter) {
 	}
 }
 
+// ReadStateHistoryIndexMetadata retrieves the metadata of the state history index.
+func ReadStateHistoryIndexMetadata(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(stateHistoryIndexKey)
+	return data
+}
+
+// WriteStateHistoryIndexMetadata stores the metadata of the state history index
+// into database.
+func WriteStateHistoryIndexMetadata(db ethdb.KeyValueWriter, blob []byte) {
+	if err := db.Put(stateHistoryIndexKey, blob); err != nil {
+		log.Crit("Failed to store the metadata of state history index", "err", err)
+	}
+}
+
+// DeleteStateHistoryIndexMetadata removes the metadata of the state history index.
+func DeleteStateHistoryIndexMetadata(db ethdb.KeyValueWriter) {
+	if err := db.Delete(stateHistoryIndexKey); err != nil {
+		log.Crit("Failed to delete the metadata of state history index", "err", err)
+	}
+}
+
+// WriteAccountHistoryIndex stores a block of the account history index, which
+// is identified by the largest history id it contains.
+func WriteAccountHistoryIndex(db ethdb.KeyValueWriter, address common.Address, last uint64, blob []byte) {
+	if err := db.Put(accountHistoryIndexKey(address, last), blob); err != nil {
+		log.Crit("Failed to store account history index", "err", err)
+	}
+}
+
+// ReadAccountHistoryIndex retrieves a block of the account history index.
+func ReadAccountHistoryIndex(db ethdb.KeyValueReader, address common.Address, last uint64) []byte {
+	data, _ := db.Get(accountHistoryIndexKey(address, last))
+	return data
+}
+
+// DeleteAccountHistoryIndex removes a block of the account history index.
+func DeleteAccountHistoryIndex(db ethdb.KeyValueWriter, address common.Address, last uint64) {
+	if err := db.Delete(accountHistoryIndexKey(address, last)); err != nil {
+		log.Crit("Failed to delete account history index", "err", err)
+	}
+}
+
+// WriteStorageHistoryIndex stores a block of the storage history index, which
+// is identified by the largest history id it contains.
+func WriteStorageHistoryIndex(db ethdb.KeyValueWriter, address common.Address, slotHash common.Hash, last uint64, blob []byte) {
+	if err := db.Put(storageHistoryIndexKey(address, slotHash, last), blob); err != nil {
+		log.Crit("Failed to store storage history index", "err", err)
+	}
+}
+
+// ReadStorageHistoryIndex retrieves a block of the storage history index.
+func ReadStorageHistoryIndex(db ethdb.KeyValueReader, address common.Address, slotHash common.Hash, last uint64) []byte {
+	data, _ := db.Get(storageHistoryIndexKey(address, slotHash, last))
+	return data
+}
+
+// DeleteStorageHistoryIndex removes a block of the storage history index.
+func DeleteStorageHistoryIndex(db ethdb.KeyValueWriter, address common.Address, slotHash common.Hash, last uint64) {
+	if err := db.Delete(storageHistoryIndexKey(address, slotHash, last)); err != nil {
+		log.Crit("Failed to delete storage history index", "err", err)
+	}
+}
+
+// IterateAccountHistoryIndex returns an iterator over the account history index
+// blocks whose largest history id is not less than the given one.
+func IterateAccountHistoryIndex(db ethdb.Iteratee, address common.Address, start uint64) ethdb.Iterator {
+	return db.NewIterator(accountHistoryIndexPrefix(address), encodeBlockNumber(start))
+}
+
+// IterateStorageHistoryIndex returns an iterator over the storage history index
+// blocks whose largest history id is not less than the given one.
+func IterateStorageHistoryIndex(db ethdb.Iteratee, address common.Address, slotHash common.Hash, start uint64) ethdb.Iterator {
+	return db.NewIterator(storageHistoryIndexPrefix(address, slotHash), encodeBlockNumber(start))
+}
+
 // ReadStateHistoryMeta retrieves the metadata corresponding to the specified
 // state history. Compute the position of state history in freezer by minus
 // one since the id of first state history starts from one(zero for initial
@@ -264,3 +339,26 @@ func WriteStateHistory(db ethdb.AncientWriter, id uint64, meta []byte, accountIn
 		return nil
 	})
 }
+
+// ReadStatePruningStatus retrieves the serialized progress of the online state
+// pruner saved at the last shutdown.
+func ReadStatePruningStatus(db ethdb.KeyValueReader) []byte {
+	data, _ := db.Get(statePruningStatusKey)
+	return data
+}
+
+// WriteStatePruningStatus stores the serialized progress of the online state
+// pruner into the database.
+func WriteStatePruningStatus(db ethdb.KeyValueWriter, status []byte) {
+	if err := db.Put(statePruningStatusKey, status); err != nil {
+		log.Crit("Failed to store state pruning status", "err", err)
+	}
+}
+
+// DeleteStatePruningStatus deletes the serialized progress of the online state
+// pruner from the database.
+func DeleteStatePruningStatus(db ethdb.KeyValueWriter) {
+	if err := db.Delete(statePruningStatusKey); err != nil {
+		log.Crit("Failed to remove state pruning status", "err", err)
+	}
+}
@@ -40,14 +40,31 @@ const (
 	ChainFreezerDifficultyTable = "diffs"
 )
 
-// chainFreezerNoSnappy configures whether compression is disabled for the ancient-tables.
-// Hashes and difficulties don't compress well.
-var chainFreezerNoSnappy = map[string]bool{
-	ChainFreezerHeaderTable:     false,
-	ChainFreezerHashTable:       true,
-	ChainFreezerBodiesTable:     false,
-	ChainFreezerReceiptTable:    false,
-	ChainFreezerDifficultyTable: true,
+// freezerTableConfig contains the settings for a freezer table.
+type freezerTableConfig struct {
+	noSnappy bool // disables item compression
+	prunable bool // true for tables that can be pruned by TruncateTail
+}
+
+// chainFreezerTableConfigs configures the settings for tables in the chain freezer.
+// Hashes and difficulties don't compress well. Only the block bodies and receipts
+// can be pruned, the headers are retained for the entire chain.
+var chainFreezerTableConfigs = map[string]freezerTableConfig{
+	ChainFreezerHeaderTable:     {noSnappy: false, prunable: false},
+	ChainFreezerHashTable:       {noSnappy: true, prunable: false},
+	ChainFreezerBodiesTable:     {noSnappy: false, prunable: true},
+	ChainFreezerReceiptTable:    {noSnappy: false, prunable: true},
+	ChainFreezerDifficultyTable: {noSnappy: true, prunable: false},
+}
+
+// prunableTableConfigs converts the compression settings of freezer tables into
+// table configs, with all the tables being prunable.
+func prunableTableConfigs(noSnappy map[string]bool) map[string]freezerTableConfig {
+	configs := make(map[string]freezerTableConfig, len(noSnappy))
+	for name, disabled := range noSnappy {
+		configs[name] = freezerTableConfig{noSnappy: disabled, prunable: true}
+	}
+	return configs
 }
 
 const (
@@ -51,7 +51,7 @@ func (info *freezerInfo) size() common.StorageSize {
 	return total
 }
 
-func inspect(name string, order map[string]bool, reader ethdb.AncientReader) (freezerInfo, error) {
+func inspect(name string, order map[string]freezerTableConfig, reader ethdb.AncientReader) (freezerInfo, error) {
 	info := freezerInfo{name: name}
 	for t := range order {
 		size, err := reader.AncientSize(t)
@@ -82,7 +82,7 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 	for _, freezer := range freezers {
 		switch freezer {
 		case ChainFreezerName:
-			info, err := inspect(ChainFreezerName, chainFreezerNoSnappy, db)
+			info, err := inspect(ChainFreezerName, chainFreezerTableConfigs, db)
 			if err != nil {
 				return nil, err
 			}
@@ -99,7 +99,7 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 			}
 			defer f.Close()
 
-			info, err := inspect(freezer, stateFreezerNoSnappy, f)
+			info, err := inspect(freezer, prunableTableConfigs(stateFreezerNoSnappy), f)
 			if err != nil {
 				return nil, err
 			}
@@ -119,17 +119,17 @@ func inspectFreezers(db ethdb.Database) ([]freezerInfo, error) {
 func InspectFreezerTable(ancient string, freezerName string, tableName string, start, end int64) error {
 	var (
 		path   string
-		tables map[string]bool
+		tables map[string]freezerTableConfig
 	)
 	switch freezerName {
 	case ChainFreezerName:
-		path, tables = resolveChainFreezerDir(ancient), chainFreezerNoSnappy
+		path, tables = resolveChainFreezerDir(ancient), chainFreezerTableConfigs
 	case StateFreezerName:
-		path, tables = filepath.Join(ancient, freezerName), stateFreezerNoSnappy
+		path, tables = filepath.Join(ancient, freezerName), prunableTableConfigs(stateFreezerNoSnappy)
 	default:
 		return fmt.Errorf("unknown freezer, supported ones: %v", freezers)
 	}
-	noSnappy, exist := tables[tableName]
+	config, exist := tables[tableName]
 	if !exist {
 		var names []string
 		for name := range tables {
@@ -137,7 +137,7 @@ func InspectFreezerTable(ancient string, freezerName string, tableName string, s
 		}
 		return fmt.Errorf("unknown table, supported ones: %v", names)
 	}
-	table, err := newFreezerTable(path, tableName, noSnappy, true)
+	table, err := newFreezerTable(path, tableName, config.noSnappy, true)
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,7 @@
 package rawdb
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -45,6 +46,10 @@ const (
 type chainFreezer struct {
 	ethdb.AncientStore // Ancient store for storing cold chain segment
 
+	// history is an optional store of era1 archives serving the items pruned
+	// from the ancient store.
+	history *EraStore
+
 	quit    chan struct{}
 	wg      sync.WaitGroup
 	trigger chan chan struct{} // Manual blocking freeze trigger, test determinism
@@ -56,24 +61,68 @@ type chainFreezer struct {
 //     state freezer (e.g. dev mode).
 //   - if non-empty directory is given, initializes the regular file-based
 //     state freezer.
-func newChainFreezer(datadir string, namespace string, readonly bool) (*chainFreezer, error) {
+//
+// If the era directory is given, the era1 archives in it are layered under the
+// freezer, serving the items pruned from it.
+func newChainFreezer(datadir string, eradir string, namespace string, readonly bool) (*chainFreezer, error) {
 	var (
 		err     error
 		freezer ethdb.AncientStore
 	)
 	if datadir == "" {
-		freezer = NewMemoryFreezer(readonly, chainFreezerNoSnappy)
+		freezer = newMemoryFreezer(readonly, chainFreezerTableConfigs)
 	} else {
-		freezer, err = NewFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerNoSnappy)
+		freezer, err = newFreezer(datadir, namespace, readonly, freezerTableSize, chainFreezerTableConfigs)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &chainFreezer{
+	cf := &chainFreezer{
 		AncientStore: freezer,
 		quit:         make(chan struct{}),
 		trigger:      make(chan chan struct{}),
-	}, nil
+	}
+	if eradir != "" {
+		if cf.history, err = NewEraStore(eradir, ""); err != nil {
+			freezer.Close()
+			return nil, err
+		}
+		if err := cf.checkHistory(); err != nil {
+			cf.history.Close()
+			freezer.Close()
+			return nil, err
+		}
+		log.Info("Opened era1 history archives", "dir", eradir, "blocks", cf.history.items)
+	}
+	return cf, nil
+}
+
+// checkHistory ensures that the era1 archives belong to the chain stored in the
+// freezer, by comparing the hash of the last block of each archive which has
+// also been frozen. The bodies and receipts are only verified on first access.
+func (f *chainFreezer) checkHistory() error {
+	frozen, err := f.AncientStore.Ancients()
+	if err != nil {
+		return err
+	}
+	for _, e := range f.history.eras {
+		last := min(e.Start()+e.Count(), frozen)
+		if last <= e.Start() {
+			break
+		}
+		have, err := f.history.hash(last - 1)
+		if err != nil {
+			return err
+		}
+		want, err := f.AncientStore.Ancient(ChainFreezerHashTable, last-1)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(have, want) {
+			return fmt.Errorf("era1 history mismatch at block #%d: have %#x, want %#x", last-1, have, want)
+		}
+	}
+	return nil
 }
 
 // Close closes the chain freezer instance and terminates the background thread.
@@ -84,9 +133,119 @@ func (f *chainFreezer) Close() error {
 		close(f.quit)
 	}
 	f.wg.Wait()
+
+	if f.history != nil {
+		if err := f.history.Close(); err != nil {
+			log.Error("Failed to close era1 history archives", "err", err)
+		}
+	}
 	return f.AncientStore.Close()
 }
 
+// HasAncient returns an indicator whether the specified data exists in the
+// freezer or in the era1 archives.
+func (f *chainFreezer) HasAncient(kind string, number uint64) (bool, error) {
+	return f.reader(f.AncientStore).HasAncient(kind, number)
+}
+
+// Ancient retrieves an ancient binary blob from the freezer, or from the era1
+// archives if it was pruned.
+func (f *chainFreezer) Ancient(kind string, number uint64) ([]byte, error) {
+	return f.reader(f.AncientStore).Ancient(kind, number)
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index
+// 'start', from the freezer or from the era1 archives if they were pruned.
+func (f *chainFreezer) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	return f.reader(f.AncientStore).AncientRange(kind, start, count, maxBytes)
+}
+
+// ReadAncients runs the given read operation while ensuring that no writes take
+// place on the underlying freezer.
+func (f *chainFreezer) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return f.AncientStore.ReadAncients(func(op ethdb.AncientReaderOp) error {
+		return fn(f.reader(op))
+	})
+}
+
+// backfillProgress returns the number of the first block pruned from the freezer
+// whose body and receipts are not restored yet, or the tail if there is none.
+func (f *chainFreezer) backfillProgress() (uint64, error) {
+	backfiller, ok := f.AncientStore.(tailBackfiller)
+	if !ok {
+		return 0, errNotSupported
+	}
+	return backfiller.backfillProgress()
+}
+
+// backfillTail runs a write operation restoring the bodies and receipts pruned
+// from the freezer.
+func (f *chainFreezer) backfillTail(fn func(ethdb.AncientWriteOp) error) error {
+	backfiller, ok := f.AncientStore.(tailBackfiller)
+	if !ok {
+		return errNotSupported
+	}
+	return backfiller.backfillTail(fn)
+}
+
+// reader returns the given freezer reader, layered on top of the era1 archives
+// if they are configured.
+func (f *chainFreezer) reader(op ethdb.AncientReaderOp) ethdb.AncientReaderOp {
+	if f.history == nil {
+		return op
+	}
+	return &historyReader{AncientReaderOp: op, history: f.history}
+}
+
+// historyReader is a freezer reader falling back to the era1 archives for the
+// items pruned from the freezer. Only the items below the frozen limit are
+// served from the archives, the freezer remains authoritative for the chain
+// segment it covers.
+type historyReader struct {
+	ethdb.AncientReaderOp
+	history *EraStore
+}
+
+// pruned returns whether the item is missing from the freezer but available in
+// the era1 archives.
+func (r *historyReader) pruned(kind string, number uint64) bool {
+	if has, _ := r.AncientReaderOp.HasAncient(kind, number); has {
+		return false
+	}
+	frozen, _ := r.AncientReaderOp.Ancients()
+	if number >= frozen {
+		return false
+	}
+	has, _ := r.history.HasAncient(kind, number)
+	return has
+}
+
+func (r *historyReader) HasAncient(kind string, number uint64) (bool, error) {
+	if r.pruned(kind, number) {
+		return true, nil
+	}
+	return r.AncientReaderOp.HasAncient(kind, number)
+}
+
+func (r *historyReader) Ancient(kind string, number uint64) ([]byte, error) {
+	if r.pruned(kind, number) {
+		return r.history.Ancient(kind, number)
+	}
+	return r.AncientReaderOp.Ancient(kind, number)
+}
+
+func (r *historyReader) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	if !r.pruned(kind, start) {
+		return r.AncientReaderOp.AncientRange(kind, start, count, maxBytes)
+	}
+	// Serve the pruned part of the range from the archives, the remainder can
+	// be retrieved from the freezer by a subsequent request.
+	if tail, _ := r.AncientReaderOp.Tail(); tail > start {
+		count = min(count, tail-start)
+	}
+	return r.history.AncientRange(kind, start, count, maxBytes)
+}
+
 // readHeadNumber returns the number of chain head block. 0 is returned if the
 // block is unknown or not available yet.
 func (f *chainFreezer) readHeadNumber(db ethdb.KeyValueReader) uint64 {
@@ -194,6 +194,12 @@ func resolveChainFreezerDir(ancient string) string {
 // storage. The passed ancient indicates the path of root ancient directory
 // where the chain freezer can be opened.
 func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace string, readonly bool) (ethdb.Database, error) {
+	return newDatabaseWithFreezer(db, ancient, "", namespace, readonly)
+}
+
+// newDatabaseWithFreezer creates a high level database with a freezer, layered
+// on top of the era1 archives in the given directory if it's not empty.
+func newDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, era string, namespace string, readonly bool) (ethdb.Database, error) {
 	// Create the idle freezer instance. If the given ancient directory is empty,
 	// in-memory chain freezer is used (e.g. dev mode); otherwise the regular
 	// file-based freezer is created.
@@ -201,7 +207,7 @@ func NewDatabaseWithFreezer(db ethdb.KeyValueStore, ancient string, namespace st
 	if chainFreezerDir != "" {
 		chainFreezerDir = resolveChainFreezerDir(chainFreezerDir)
 	}
-	frdb, err := newChainFreezer(chainFreezerDir, namespace, readonly)
+	frdb, err := newChainFreezer(chainFreezerDir, era, namespace, readonly)
 	if err != nil {
 		printChainMetadata(db)
 		return nil, err
@@ -361,6 +367,7 @@ type OpenOptions struct {
 	Directory         string // the datadir
 	AncientsDirectory string // the ancients-dir
 	Namespace         string // the namespace for database relevant metrics
+	EraDirectory      string // the directory of era1 archives serving the pruned chain history
 	Cache             int    // the capacity(in megabytes) of the data caching
 	Handles           int    // number of files to be open simultaneously
 	ReadOnly          bool
@@ -412,7 +419,7 @@ func Open(o OpenOptions) (ethdb.Database, error) {
 	if len(o.AncientsDirectory) == 0 {
 		return kvdb, nil
 	}
-	frdb, err := NewDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.Namespace, o.ReadOnly)
+	frdb, err := newDatabaseWithFreezer(kvdb, o.AncientsDirectory, o.EraDirectory, o.Namespace, o.ReadOnly)
 	if err != nil {
 		kvdb.Close()
 		return nil, err
@@ -470,6 +477,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		hashNumPairings stat
 		legacyTries     stat
 		stateLookups    stat
+		stateIndexes    stat
 		accountTries    stat
 		storageTries    stat
 		codes           stat
@@ -478,6 +486,7 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		storageSnaps    stat
 		preimages       stat
 		bloomBits       stat
+		logIndex        stat
 		beaconHeaders   stat
 		cliqueSnaps     stat
 
@@ -516,6 +525,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			legacyTries.Add(size)
 		case bytes.HasPrefix(key, stateIDPrefix) && len(key) == len(stateIDPrefix)+common.HashLength:
 			stateLookups.Add(size)
+		case bytes.HasPrefix(key, StateHistoryAccountIndexPrefix) && len(key) == len(StateHistoryAccountIndexPrefix)+common.AddressLength+8:
+			stateIndexes.Add(size)
+		case bytes.HasPrefix(key, StateHistoryStorageIndexPrefix) && len(key) == len(StateHistoryStorageIndexPrefix)+common.AddressLength+common.HashLength+8:
+			stateIndexes.Add(size)
 		case IsAccountTrieNode(key):
 			accountTries.Add(size)
 		case IsStorageTrieNode(key):
@@ -538,6 +551,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			bloomBits.Add(size)
 		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
 			bloomBits.Add(size)
+		case bytes.HasPrefix(key, LogIndexRowPrefix) && len(key) == (len(LogIndexRowPrefix)+4+common.HashLength):
+			logIndex.Add(size)
+		case bytes.HasPrefix(key, logIndexBlockPrefix) && len(key) == (len(logIndexBlockPrefix)+8):
+			logIndex.Add(size)
 		case bytes.HasPrefix(key, skeletonHeaderPrefix) && len(key) == (len(skeletonHeaderPrefix)+8):
 			beaconHeaders.Add(size)
 		case bytes.HasPrefix(key, CliqueSnapshotPrefix) && len(key) == 7+common.HashLength:
@@ -555,9 +572,10 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 			for _, meta := range [][]byte{
 				databaseVersionKey, headHeaderKey, headBlockKey, headFastBlockKey, headFinalizedBlockKey,
 				lastPivotKey, fastTrieProgressKey, snapshotDisabledKey, SnapshotRootKey, snapshotJournalKey,
-				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, fastTxLookupLimitKey,
+				snapshotGeneratorKey, snapshotRecoveryKey, txIndexTailKey, chainHistoryTailKey, fastTxLookupLimitKey,
 				uncleanShutdownKey, badBlockKey, transitionStatusKey, skeletonSyncStatusKey,
 				persistentStateIDKey, trieJournalKey, snapshotSyncStatusKey, snapSyncStatusFlagKey,
+				stateHistoryIndexKey, logIndexKey, statePruningStatusKey,
 			} {
 				if bytes.Equal(key, meta) {
 					metadata.Add(size)
@@ -584,10 +602,12 @@ func InspectDatabase(db ethdb.Database, keyPrefix, keyStart []byte) error {
 		{"Key-Value store", "Block number->hash", numHashPairings.Size(), numHashPairings.Count()},
 		{"Key-Value store", "Block hash->number", hashNumPairings.Size(), hashNumPairings.Count()},
 		{"Key-Value store", "Transaction index", txLookups.Size(), txLookups.Count()},
-		{"Key-Value store", "Bloombit index", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Bloombit index (legacy)", bloomBits.Size(), bloomBits.Count()},
+		{"Key-Value store", "Log index", logIndex.Size(), logIndex.Count()},
 		{"Key-Value store", "Contract codes", codes.Size(), codes.Count()},
 		{"Key-Value store", "Hash trie nodes", legacyTries.Size(), legacyTries.Count()},
 		{"Key-Value store", "Path trie state lookups", stateLookups.Size(), stateLookups.Count()},
+		{"Key-Value store", "Path state history indexes", stateIndexes.Size(), stateIndexes.Count()},
 		{"Key-Value store", "Path trie account nodes", accountTries.Size(), accountTries.Count()},
 		{"Key-Value store", "Path trie storage nodes", storageTries.Size(), storageTries.Count()},
 		{"Key-Value store", "Trie preimages", preimages.Size(), preimages.Count()},
@@ -658,6 +678,7 @@ func ReadChainMetadata(db ethdb.KeyValueStore) [][]string {
 		{"snapshotRecoveryNumber", pp(ReadSnapshotRecoveryNumber(db))},
 		{"snapshotRoot", fmt.Sprintf("%v", ReadSnapshotRoot(db))},
 		{"txIndexTail", pp(ReadTxIndexTail(db))},
+		{"chainHistoryTail", pp(ReadChainHistoryTail(db))},
 	}
 	if b := ReadSkeletonSyncStatus(db); b != nil {
 		data = append(data, []string{"SkeletonSyncStatus", string(b)})
@@ -0,0 +1,146 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// listHasher computes the merkle-patricia trie root of the derivable lists of
+// the archived blocks, i.e. transactions, withdrawals and receipts. The trie
+// package can't be used here, as it depends on this one.
+//
+// The items are collected in memory and the trie is built at once, which is
+// fine for lists of the size of a block.
+type listHasher struct {
+	items []listItem
+}
+
+// listItem is a key-value pair of the list trie, with the key as nibbles.
+type listItem struct {
+	key   []byte
+	value []byte
+}
+
+// Reset clears the collected items.
+func (h *listHasher) Reset() {
+	h.items = h.items[:0]
+}
+
+// Update adds an item to the trie.
+func (h *listHasher) Update(key, value []byte) error {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i], nibbles[2*i+1] = b>>4, b&0x0f
+	}
+	h.items = append(h.items, listItem{key: nibbles, value: common.CopyBytes(value)})
+	return nil
+}
+
+// Hash returns the root hash of the trie.
+func (h *listHasher) Hash() common.Hash {
+	if len(h.items) == 0 {
+		return types.EmptyRootHash
+	}
+	slices.SortFunc(h.items, func(a, b listItem) int {
+		return bytes.Compare(a.key, b.key)
+	})
+	return crypto.Keccak256Hash(encodeListNode(h.items, 0))
+}
+
+// encodeListNode returns the encoding of the trie node holding the given items,
+// sorted by key, at the given depth.
+func encodeListNode(items []listItem, depth int) []byte {
+	// A single item is stored in a leaf
+	if len(items) == 1 {
+		return encodeNode(hexPrefix(items[0].key[depth:], true), items[0].value)
+	}
+	// Items sharing a common prefix are stored behind an extension
+	var (
+		first  = items[0].key[depth:]
+		last   = items[len(items)-1].key[depth:]
+		prefix = 0
+	)
+	for prefix < len(first) && prefix < len(last) && first[prefix] == last[prefix] {
+		prefix++
+	}
+	if prefix > 0 {
+		return encodeNode(hexPrefix(first[:prefix], false), listNodeRef(encodeListNode(items, depth+prefix)))
+	}
+	// Otherwise the items are split into the children of a branch. An item
+	// ending at the branch, sorted first, is stored as its value.
+	children := make([]interface{}, 17)
+	children[16] = []byte{}
+	if len(items[0].key) == depth {
+		children[16] = items[0].value
+		items = items[1:]
+	}
+	for nibble := byte(0); nibble < 16; nibble++ {
+		n := 0
+		for n < len(items) && items[n].key[depth] == nibble {
+			n++
+		}
+		if n == 0 {
+			children[nibble] = []byte{}
+			continue
+		}
+		children[nibble] = listNodeRef(encodeListNode(items[:n], depth+1))
+		items = items[n:]
+	}
+	return encodeNode(children...)
+}
+
+// listNodeRef returns the reference to a child node from its parent, which is
+// either the node itself if it's small enough, or its hash.
+func listNodeRef(node []byte) interface{} {
+	if len(node) < 32 {
+		return rlp.RawValue(node)
+	}
+	return crypto.Keccak256(node)
+}
+
+// encodeNode encodes the fields of a trie node as an RLP list.
+func encodeNode(fields ...interface{}) []byte {
+	enc, _ := rlp.EncodeToBytes(fields)
+	return enc
+}
+
+// hexPrefix applies the compact encoding to a key of nibbles, flagging whether
+// it belongs to a leaf.
+func hexPrefix(nibbles []byte, leaf bool) []byte {
+	var flag byte
+	if leaf {
+		flag = 2
+	}
+	enc := make([]byte, len(nibbles)/2+1)
+	if len(nibbles)%2 == 1 {
+		enc[0] = (flag+1)<<4 | nibbles[0]
+		nibbles = nibbles[1:]
+	} else {
+		enc[0] = flag << 4
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		enc[i/2+1] = nibbles[i]<<4 | nibbles[i+1]
+	}
+	return enc
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// blobList is a derivable list of raw items.
+type blobList [][]byte
+
+func (l blobList) Len() int { return len(l) }
+
+func (l blobList) EncodeIndex(i int, w *bytes.Buffer) { w.Write(l[i]) }
+
+// Tests that the list hasher of the era1 archives derives the same roots as the
+// trie, for lists of short and long items across the key length boundaries.
+func TestListHasher(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 3, 15, 16, 17, 127, 128, 129, 200, 1000} {
+		for _, size := range []int{1, 8, 40} {
+			list := make(blobList, n)
+			for i := range list {
+				list[i] = make([]byte, 1+rng.Intn(size))
+				rng.Read(list[i])
+			}
+			have := types.DeriveSha(list, rawdb.NewListHasher())
+			want := types.DeriveSha(list, trie.NewStackTrie(nil))
+			if have != want {
+				t.Fatalf("%d items of up to %d bytes: root mismatch: have %x, want %x", n, size, have, want)
+			}
+		}
+	}
+}
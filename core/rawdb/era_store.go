@@ -0,0 +1,373 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// EraStore is a read-only ancient store serving the chain segment archived in
+// a directory of era1 files, laid out as the tables of the chain freezer. The
+// archives are never modified, so the directory can be shared across nodes.
+type EraStore struct {
+	eras  []*eraFile // Archives, ordered by their first block
+	items uint64     // Number of blocks in the archives
+}
+
+// eraFile is an era1 archive whose bodies and receipts are verified on first
+// access.
+type eraFile struct {
+	*era.Era
+	name string
+
+	once sync.Once // Guards the verification of the bodies and receipts
+	err  error     // Verification failure, if any
+}
+
+// NewEraStore opens the era1 archives of the given network in the directory,
+// verifying the accumulator of each of them. If the network is empty, it's
+// inferred from the names of the files.
+//
+// The bodies and receipts of an archive are only checked against the roots of
+// their headers on first access, as it requires decoding the whole file.
+func NewEraStore(dir string, network string) (*EraStore, error) {
+	if network == "" {
+		var err error
+		if network, err = eraNetwork(dir); err != nil {
+			return nil, err
+		}
+	}
+	names, err := era.ReadDir(dir, network)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no %s era1 files found in %s", network, dir)
+	}
+	store := new(EraStore)
+	for epoch, name := range names {
+		e, err := era.Open(filepath.Join(dir, name))
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		store.eras = append(store.eras, &eraFile{Era: e, name: name})
+
+		if e.Start() != store.items {
+			store.Close()
+			return nil, fmt.Errorf("era1 file %s starts at block %d, want %d", name, e.Start(), store.items)
+		}
+		root, err := verifyEra(e)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("invalid era1 file %s: %w", name, err)
+		}
+		if want := era.Filename(network, epoch, root); name != want {
+			store.Close()
+			return nil, fmt.Errorf("era1 file %s doesn't match its accumulator %x", name, root)
+		}
+		store.items += e.Count()
+	}
+	return store, nil
+}
+
+// eraNetwork returns the name of the only network whose era1 files are stored
+// in the directory.
+func eraNetwork(dir string) (string, error) {
+	var networks []string
+	for _, network := range params.NetworkNames {
+		names, err := era.ReadDir(dir, network)
+		if err != nil {
+			return "", err
+		}
+		if len(names) > 0 {
+			networks = append(networks, network)
+		}
+	}
+	switch len(networks) {
+	case 0:
+		return "", fmt.Errorf("no era1 files found in %s", dir)
+	case 1:
+		return networks[0], nil
+	default:
+		return "", fmt.Errorf("era1 files of multiple networks found in %s: %v", dir, networks)
+	}
+}
+
+// verifyEra recomputes the accumulator of the archive from its headers and
+// total difficulties, returning it if it matches the stored one. The headers
+// must be linked to each other.
+func verifyEra(e *era.Era) (common.Hash, error) {
+	it, err := era.NewRawIterator(e)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var (
+		hashes []common.Hash
+		tds    []*big.Int
+	)
+	for it.Next() {
+		if it.Error() != nil {
+			return common.Hash{}, it.Error()
+		}
+		number := it.Number()
+
+		blob, err := io.ReadAll(it.Header)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		var header types.Header
+		if err := rlp.DecodeBytes(blob, &header); err != nil {
+			return common.Hash{}, fmt.Errorf("block #%d: invalid header: %w", number, err)
+		}
+		if header.Number.Uint64() != number {
+			return common.Hash{}, fmt.Errorf("block #%d: header number mismatch: have %d", number, header.Number)
+		}
+		if len(hashes) > 0 && header.ParentHash != hashes[len(hashes)-1] {
+			return common.Hash{}, fmt.Errorf("block #%d: parent hash mismatch: have %x, want %x", number, header.ParentHash, hashes[len(hashes)-1])
+		}
+		td, err := e.GetTotalDifficultyByNumber(number)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		hashes = append(hashes, crypto.Keccak256Hash(blob))
+		tds = append(tds, td)
+	}
+	if it.Error() != nil {
+		return common.Hash{}, it.Error()
+	}
+	have, err := era.ComputeAccumulator(hashes, tds)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if have != want {
+		return common.Hash{}, fmt.Errorf("accumulator mismatch: have %x, want %x", have, want)
+	}
+	return want, nil
+}
+
+// verify checks the bodies and receipts of the archive on first access,
+// returning the cached result afterwards.
+func (s *EraStore) verify(e *eraFile) error {
+	e.once.Do(func() {
+		if err := verifyEraRoots(e.Era); err != nil {
+			e.err = fmt.Errorf("invalid era1 file %s: %w", e.name, err)
+		}
+	})
+	return e.err
+}
+
+// verifyEraRoots checks the bodies and receipts of the archive against the roots
+// of their headers.
+func verifyEraRoots(e *era.Era) error {
+	it, err := era.NewRawIterator(e)
+	if err != nil {
+		return err
+	}
+	for it.Next() {
+		if it.Error() != nil {
+			return it.Error()
+		}
+		number := it.Number()
+
+		var header types.Header
+		if err := rlp.Decode(it.Header, &header); err != nil {
+			return fmt.Errorf("block #%d: invalid header: %w", number, err)
+		}
+		var body types.Body
+		if err := rlp.Decode(it.Body, &body); err != nil {
+			return fmt.Errorf("block #%d: invalid body: %w", number, err)
+		}
+		if root := types.DeriveSha(types.Transactions(body.Transactions), new(listHasher)); root != header.TxHash {
+			return fmt.Errorf("block #%d: transaction root mismatch: have %x, want %x", number, root, header.TxHash)
+		}
+		if hash := types.CalcUncleHash(body.Uncles); hash != header.UncleHash {
+			return fmt.Errorf("block #%d: uncle hash mismatch: have %x, want %x", number, hash, header.UncleHash)
+		}
+		if header.WithdrawalsHash != nil {
+			if root := types.DeriveSha(types.Withdrawals(body.Withdrawals), new(listHasher)); root != *header.WithdrawalsHash {
+				return fmt.Errorf("block #%d: withdrawals root mismatch: have %x, want %x", number, root, *header.WithdrawalsHash)
+			}
+		}
+		var receipts types.Receipts
+		if err := rlp.Decode(it.Receipts, &receipts); err != nil {
+			return fmt.Errorf("block #%d: invalid receipts: %w", number, err)
+		}
+		if root := types.DeriveSha(receipts, new(listHasher)); root != header.ReceiptHash {
+			return fmt.Errorf("block #%d: receipt root mismatch: have %x, want %x", number, root, header.ReceiptHash)
+		}
+	}
+	return it.Error()
+}
+
+// find returns the archive containing the given block, without verifying it.
+func (s *EraStore) find(number uint64) (*eraFile, error) {
+	if number >= s.items {
+		return nil, errOutOfBounds
+	}
+	i := sort.Search(len(s.eras), func(i int) bool {
+		return s.eras[i].Start()+s.eras[i].Count() > number
+	})
+	return s.eras[i], nil
+}
+
+// hash returns the hash of the given block, without verifying its archive.
+func (s *EraStore) hash(number uint64) ([]byte, error) {
+	e, err := s.find(number)
+	if err != nil {
+		return nil, err
+	}
+	header, err := e.GetRawHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(header), nil
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// archives.
+func (s *EraStore) HasAncient(kind string, number uint64) (bool, error) {
+	if _, ok := chainFreezerTableConfigs[kind]; !ok {
+		return false, nil
+	}
+	return number < s.items, nil
+}
+
+// Ancient retrieves the item of the given chain freezer table from the archives,
+// in the encoding used by the chain freezer.
+func (s *EraStore) Ancient(kind string, number uint64) ([]byte, error) {
+	if _, ok := chainFreezerTableConfigs[kind]; !ok {
+		return nil, errUnknownTable
+	}
+	e, err := s.find(number)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(e); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case ChainFreezerHeaderTable:
+		return e.GetRawHeaderByNumber(number)
+
+	case ChainFreezerHashTable:
+		return s.hash(number)
+
+	case ChainFreezerBodiesTable:
+		return e.GetRawBodyByNumber(number)
+
+	case ChainFreezerReceiptTable:
+		// The archives contain the consensus encoding of the receipts, convert
+		// them into their storage form.
+		blob, err := e.GetRawReceiptsByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		var receipts types.Receipts
+		if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+			return nil, err
+		}
+		stored := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			stored[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		return rlp.EncodeToBytes(stored)
+
+	default: // ChainFreezerDifficultyTable
+		td, err := e.GetTotalDifficultyByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		return rlp.EncodeToBytes(td)
+	}
+}
+
+// AncientRange retrieves multiple items in sequence, starting from the index
+// 'start'. It returns at most 'count' items, and stops once 'maxBytes' is
+// exceeded if it's specified, while always returning at least one item.
+func (s *EraStore) AncientRange(kind string, start, count, maxBytes uint64) ([][]byte, error) {
+	if start >= s.items {
+		return nil, errOutOfBounds
+	}
+	var (
+		items [][]byte
+		size  uint64
+	)
+	for number := start; number < start+count && number < s.items; number++ {
+		item, err := s.Ancient(kind, number)
+		if err != nil {
+			return nil, err
+		}
+		if maxBytes != 0 && len(items) > 0 && size+uint64(len(item)) > maxBytes {
+			break
+		}
+		items = append(items, item)
+		size += uint64(len(item))
+	}
+	return items, nil
+}
+
+// Ancients returns the number of blocks in the archives.
+func (s *EraStore) Ancients() (uint64, error) {
+	return s.items, nil
+}
+
+// Tail returns the number of the first block in the archives, which is always
+// the genesis.
+func (s *EraStore) Tail() (uint64, error) {
+	return 0, nil
+}
+
+// AncientSize is not supported, the archives don't store the tables separately.
+func (s *EraStore) AncientSize(kind string) (uint64, error) {
+	return 0, errNotSupported
+}
+
+// ReadAncients runs the given read operation on the archives, which are never
+// modified.
+func (s *EraStore) ReadAncients(fn func(ethdb.AncientReaderOp) error) error {
+	return fn(s)
+}
+
+// Close closes the archives.
+func (s *EraStore) Close() error {
+	var errs []error
+	for _, e := range s.eras {
+		if err := e.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
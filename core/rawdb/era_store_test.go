@@ -0,0 +1,286 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// makeEraChain creates a chain of linked blocks with receipts, along with the
+// total difficulty of each block.
+func makeEraChain(n int, seed byte) ([]*types.Block, []types.Receipts, []*big.Int) {
+	var (
+		blocks   []*types.Block
+		receipts []types.Receipts
+		tds      []*big.Int
+		parent   common.Hash
+		td       = new(big.Int)
+	)
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     big.NewInt(int64(i)),
+			Difficulty: big.NewInt(int64(i + 1)),
+			Extra:      []byte{seed},
+		}
+		tx := types.NewTx(&types.LegacyTx{Nonce: uint64(i), To: &common.Address{seed}, Gas: 21000, GasPrice: big.NewInt(1)})
+		receipt := &types.Receipt{
+			Type:              types.LegacyTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{{Address: common.Address{seed}, Topics: []common.Hash{{byte(i)}}, Data: []byte{byte(i)}}},
+		}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		block := types.NewBlock(header, &types.Body{Transactions: []*types.Transaction{tx}}, []*types.Receipt{receipt}, new(listHasher))
+
+		td = new(big.Int).Add(td, header.Difficulty)
+		blocks, receipts, tds = append(blocks, block), append(receipts, types.Receipts{receipt}), append(tds, td)
+		parent = block.Hash()
+	}
+	return blocks, receipts, tds
+}
+
+// writeEraFiles archives the chain into era1 files of the given sizes.
+func writeEraFiles(t *testing.T, dir string, blocks []*types.Block, receipts []types.Receipts, tds []*big.Int, sizes ...int) {
+	var start int
+	for epoch, size := range sizes {
+		f, err := os.CreateTemp(dir, "era1-tmp")
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder := era.NewBuilder(f)
+		for i := start; i < start+size; i++ {
+			if err := builder.Add(blocks[i], receipts[i], tds[i]); err != nil {
+				t.Fatalf("failed to add block %d: %v", i, err)
+			}
+		}
+		root, err := builder.Finalize()
+		if err != nil {
+			t.Fatalf("failed to finalize era1 file: %v", err)
+		}
+		f.Close()
+		if err := os.Rename(f.Name(), filepath.Join(dir, era.Filename("mainnet", epoch, root))); err != nil {
+			t.Fatal(err)
+		}
+		start += size
+	}
+}
+
+func TestEraStore(t *testing.T) {
+	var (
+		dir                  = t.TempDir()
+		blocks, receipts, td = makeEraChain(20, 1)
+	)
+	writeEraFiles(t, dir, blocks, receipts, td, 8, 12)
+
+	store, err := NewEraStore(dir, "")
+	if err != nil {
+		t.Fatalf("failed to open era store: %v", err)
+	}
+	defer store.Close()
+
+	// The items must match the ones stored by the chain freezer
+	freezer := newMemoryFreezer(false, chainFreezerTableConfigs)
+	if _, err := WriteAncientBlocks(freezer, blocks, receipts, td[0]); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	if items, _ := store.Ancients(); items != 20 {
+		t.Fatalf("wrong item count: have %d, want 20", items)
+	}
+	for kind := range chainFreezerTableConfigs {
+		for number := uint64(0); number < 20; number++ {
+			have, err := store.Ancient(kind, number)
+			if err != nil {
+				t.Fatalf("%s #%d: failed to read item: %v", kind, number, err)
+			}
+			want, _ := freezer.Ancient(kind, number)
+			if !bytes.Equal(have, want) {
+				t.Fatalf("%s #%d: item mismatch\nhave %x\nwant %x", kind, number, have, want)
+			}
+		}
+		if _, err := store.Ancient(kind, 20); err == nil {
+			t.Fatalf("%s: read item out of bounds", kind)
+		}
+		items, err := store.AncientRange(kind, 6, 4, 0)
+		if err != nil || len(items) != 4 {
+			t.Fatalf("%s: wrong range: have %d items, err %v", kind, len(items), err)
+		}
+	}
+	if has, _ := store.HasAncient("unknown", 0); has {
+		t.Fatal("unknown table reported as available")
+	}
+}
+
+func TestEraStoreVerify(t *testing.T) {
+	dir := t.TempDir()
+	blocks, receipts, td := makeEraChain(8, 1)
+	writeEraFiles(t, dir, blocks, receipts, td, 8)
+
+	// Archive a tampered total difficulty under the name of the original file
+	tampered := t.TempDir()
+	td[3] = new(big.Int).Add(td[3], common.Big1)
+	writeEraFiles(t, tampered, blocks, receipts, td, 8)
+
+	names, _ := era.ReadDir(dir, "mainnet")
+	tamperedNames, _ := era.ReadDir(tampered, "mainnet")
+	if err := os.Rename(filepath.Join(tampered, tamperedNames[0]), filepath.Join(tampered, names[0])); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEraStore(tampered, "mainnet"); err == nil {
+		t.Fatal("opened era1 file not matching its name")
+	}
+	td[3] = new(big.Int).Sub(td[3], common.Big1)
+
+	// Archive an accumulator matching the name of the file but not its headers
+	forged := t.TempDir()
+	f, err := os.CreateTemp(forged, "era1-tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := era.NewBuilder(f)
+	for i, block := range blocks {
+		header, _ := rlp.EncodeToBytes(block.Header())
+		body, _ := rlp.EncodeToBytes(block.Body())
+		rs, _ := rlp.EncodeToBytes(receipts[i])
+		hash := block.Hash()
+		if i == 3 {
+			hash = common.Hash{0xff}
+		}
+		if err := builder.AddRLP(header, body, rs, block.NumberU64(), hash, td[i], block.Difficulty()); err != nil {
+			t.Fatalf("failed to add block %d: %v", i, err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("failed to finalize era1 file: %v", err)
+	}
+	f.Close()
+	if err := os.Rename(f.Name(), filepath.Join(forged, era.Filename("mainnet", 0, root))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEraStore(forged, "mainnet"); err == nil {
+		t.Fatal("opened era1 file with an accumulator not matching its headers")
+	}
+
+	// Archive tampered bodies and receipts, which don't affect the accumulator.
+	// The archives are only rejected on first access.
+	for i, tamper := range []func(blocks []*types.Block, receipts []types.Receipts){
+		func(blocks []*types.Block, receipts []types.Receipts) {
+			blocks[5] = types.NewBlockWithHeader(blocks[5].Header()).WithBody(*blocks[6].Body())
+		},
+		func(blocks []*types.Block, receipts []types.Receipts) {
+			receipts[5] = receipts[6]
+		},
+	} {
+		var (
+			dir      = t.TempDir()
+			blocks   = append([]*types.Block{}, blocks...)
+			receipts = append([]types.Receipts{}, receipts...)
+		)
+		tamper(blocks, receipts)
+		writeEraFiles(t, dir, blocks, receipts, td, 8)
+
+		store, err := NewEraStore(dir, "mainnet")
+		if err != nil {
+			t.Fatalf("case %d: failed to open era store: %v", i, err)
+		}
+		if hash, err := store.hash(7); err != nil || !bytes.Equal(hash, blocks[7].Hash().Bytes()) {
+			t.Fatalf("case %d: wrong unverified hash: %x, err %v", i, hash, err)
+		}
+		for kind := range chainFreezerTableConfigs {
+			if _, err := store.Ancient(kind, 0); err == nil {
+				t.Fatalf("case %d: %s: read item of tampered archive", i, kind)
+			}
+		}
+		store.Close()
+	}
+}
+
+func TestEraStoreLayered(t *testing.T) {
+	var (
+		eradir               = t.TempDir()
+		blocks, receipts, td = makeEraChain(20, 1)
+	)
+	writeEraFiles(t, eradir, blocks, receipts, td, 8, 12)
+
+	db, err := newDatabaseWithFreezer(memorydb.New(), t.TempDir(), eradir, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := WriteAncientBlocks(db, blocks[:16], receipts[:16], td[0]); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	if _, err := db.TruncateTail(10); err != nil {
+		t.Fatalf("failed to truncate tail: %v", err)
+	}
+	// The pruned blocks are served from the archives
+	for _, block := range blocks[:16] {
+		hash, number := block.Hash(), block.NumberU64()
+		if !HasBody(db, hash, number) || !HasReceipts(db, hash, number) {
+			t.Fatalf("block #%d: history missing", number)
+		}
+		if body := ReadBody(db, hash, number); body == nil || body.Transactions[0].Hash() != block.Transactions()[0].Hash() {
+			t.Fatalf("block #%d: wrong body", number)
+		}
+		if have := ReadRawReceipts(db, hash, number); len(have) != 1 || have[0].Logs[0].Data[0] != byte(number) {
+			t.Fatalf("block #%d: wrong receipts", number)
+		}
+	}
+	// Ranges are served up to the freezer tail from the archives
+	if items, err := db.AncientRange(ChainFreezerBodiesTable, 5, 10, 0); err != nil || len(items) != 5 {
+		t.Fatalf("wrong pruned range: have %d items, err %v", len(items), err)
+	}
+	// Blocks above the frozen limit are not served from the archives
+	if has, _ := db.HasAncient(ChainFreezerBodiesTable, 17); has {
+		t.Fatal("unfrozen block served from the archives")
+	}
+	db.Close()
+
+	// Archives of a different chain are rejected
+	otherdir := t.TempDir()
+	other, otherReceipts, otherTd := makeEraChain(20, 2)
+	writeEraFiles(t, otherdir, other, otherReceipts, otherTd, 8, 12)
+
+	if _, err := newDatabaseWithFreezer(memorydb.New(), t.TempDir(), otherdir, "", false); err != nil {
+		t.Fatalf("failed to create database without ancients: %v", err)
+	}
+	ancient := t.TempDir()
+	db, err = NewDatabaseWithFreezer(memorydb.New(), ancient, "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	if _, err := WriteAncientBlocks(db, blocks[:16], receipts[:16], td[0]); err != nil {
+		t.Fatalf("failed to write ancient blocks: %v", err)
+	}
+	db.Close()
+
+	if _, err := newDatabaseWithFreezer(memorydb.New(), ancient, otherdir, "", false); err == nil {
+		t.Fatal("opened era1 archives of a different chain")
+	}
+}
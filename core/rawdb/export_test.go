@@ -1,4 +1,4 @@
-// Copyright 2017 The go-ethereum Authors
+// Copyright 2024 The go-ethereum Authors
 // This file is part of the go-ethereum library.
 //
 // The go-ethereum library is free software: you can redistribute it and/or modify
@@ -14,5 +14,12 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package bloombits implements bloom filtering on batches of data.
-package bloombits
+package rawdb
+
+import "github.com/ethereum/go-ethereum/core/types"
+
+// NewListHasher exposes the list hasher of the era1 archives to the external
+// tests, which unlike the internal ones can import the trie package.
+func NewListHasher() types.TrieHasher {
+	return new(listHasher)
+}
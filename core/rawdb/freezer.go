@@ -63,7 +63,7 @@ const freezerTableSize = 2 * 1000 * 1000 * 1000
 //     of Geth, and thus also GC overhead.
 type Freezer struct {
 	frozen atomic.Uint64 // Number of items already frozen
-	tail   atomic.Uint64 // Number of the first stored item in the freezer
+	tail   atomic.Uint64 // Number of the first stored item in the prunable tables
 
 	// This lock synchronizes writers and the truncate operation, as well as
 	// the "atomic" (batched) read operations.
@@ -71,8 +71,11 @@ type Freezer struct {
 	writeBatch *freezerBatch
 
 	readonly     bool
-	tables       map[string]*freezerTable // Data tables for storing everything
-	instanceLock *flock.Flock             // File-system lock to prevent double opens
+	datadir      string                        // Directory of the data tables
+	tables       map[string]*freezerTable      // Data tables for storing everything
+	configs      map[string]freezerTableConfig // Settings of the data tables
+	backfill     map[string]*freezerTable      // Items restored below the tail, opened on demand
+	instanceLock *flock.Flock                  // File-system lock to prevent double opens
 	closeOnce    sync.Once
 }
 
@@ -80,8 +83,15 @@ type Freezer struct {
 // data according to the given parameters.
 //
 // The 'tables' argument defines the data tables. If the value of a map
-// entry is true, snappy compression is disabled for the table.
+// entry is true, snappy compression is disabled for the table. All the
+// tables are pruned together by TruncateTail.
 func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]bool) (*Freezer, error) {
+	return newFreezer(datadir, namespace, readonly, maxTableSize, prunableTableConfigs(tables))
+}
+
+// newFreezer creates a freezer instance with the given table configs. Tables
+// which are not prunable retain all their items when the tail is truncated.
+func newFreezer(datadir string, namespace string, readonly bool, maxTableSize uint32, tables map[string]freezerTableConfig) (*Freezer, error) {
 	// Create the initial freezer object
 	var (
 		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
@@ -114,13 +124,15 @@ func NewFreezer(datadir string, namespace string, readonly bool, maxTableSize ui
 	// Open all the supported data tables
 	freezer := &Freezer{
 		readonly:     readonly,
+		datadir:      datadir,
 		tables:       make(map[string]*freezerTable),
+		configs:      tables,
 		instanceLock: lock,
 	}
 
 	// Create the tables.
-	for name, disableSnappy := range tables {
-		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, disableSnappy, readonly)
+	for name, config := range tables {
+		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, maxTableSize, config.noSnappy, readonly)
 		if err != nil {
 			for _, table := range freezer.tables {
 				table.Close()
@@ -166,6 +178,9 @@ func (f *Freezer) Close() error {
 				errs = append(errs, err)
 			}
 		}
+		if err := f.closeBackfill(); err != nil {
+			errs = append(errs, err)
+		}
 		if err := f.instanceLock.Unlock(); err != nil {
 			errs = append(errs, err)
 		}
@@ -211,7 +226,8 @@ func (f *Freezer) Ancients() (uint64, error) {
 	return f.frozen.Load(), nil
 }
 
-// Tail returns the number of first stored item in the freezer.
+// Tail returns the number of first stored item in the freezer. Items of the
+// non-prunable tables are retained below the tail.
 func (f *Freezer) Tail() (uint64, error) {
 	return f.tail.Load(), nil
 }
@@ -294,7 +310,8 @@ func (f *Freezer) TruncateHead(items uint64) (uint64, error) {
 	return oitems, nil
 }
 
-// TruncateTail discards any recent data below the provided threshold number.
+// TruncateTail discards any recent data below the provided threshold number in
+// the prunable tables.
 func (f *Freezer) TruncateTail(tail uint64) (uint64, error) {
 	if f.readonly {
 		return 0, errReadOnly
@@ -306,7 +323,10 @@ func (f *Freezer) TruncateTail(tail uint64) (uint64, error) {
 	if old >= tail {
 		return old, nil
 	}
-	for _, table := range f.tables {
+	for name, table := range f.tables {
+		if !f.configs[name].prunable {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return 0, err
 		}
@@ -329,31 +349,44 @@ func (f *Freezer) Sync() error {
 	return nil
 }
 
-// validate checks that every table has the same boundary.
-// Used instead of `repair` in readonly mode.
+// validate checks that every table has the same head, and that all the prunable
+// tables have the same tail. Used instead of `repair` in readonly mode.
 func (f *Freezer) validate() error {
 	if len(f.tables) == 0 {
 		return nil
 	}
 	var (
-		head uint64
-		tail uint64
-		name string
+		head     uint64
+		tail     uint64
+		name     string
+		tailName string
 	)
 	// Hack to get boundary of any table
 	for kind, table := range f.tables {
 		head = table.items.Load()
-		tail = table.itemHidden.Load()
 		name = kind
 		break
 	}
+	for kind, table := range f.tables {
+		if f.configs[kind].prunable {
+			tail = table.itemHidden.Load()
+			tailName = kind
+			break
+		}
+	}
 	// Now check every table against those boundaries.
 	for kind, table := range f.tables {
 		if head != table.items.Load() {
 			return fmt.Errorf("freezer tables %s and %s have differing head: %d != %d", kind, name, table.items.Load(), head)
 		}
+		if !f.configs[kind].prunable {
+			if hidden := table.itemHidden.Load(); hidden != 0 {
+				return fmt.Errorf("non-prunable freezer table %s has non-zero tail: %d", kind, hidden)
+			}
+			continue
+		}
 		if tail != table.itemHidden.Load() {
-			return fmt.Errorf("freezer tables %s and %s have differing tail: %d != %d", kind, name, table.itemHidden.Load(), tail)
+			return fmt.Errorf("freezer tables %s and %s have differing tail: %d != %d", kind, tailName, table.itemHidden.Load(), tail)
 		}
 	}
 	f.frozen.Store(head)
@@ -361,26 +394,37 @@ func (f *Freezer) validate() error {
 	return nil
 }
 
-// repair truncates all data tables to the same length.
+// repair truncates all data tables to the same length, and all the prunable
+// tables to the same tail.
 func (f *Freezer) repair() error {
 	var (
 		head = uint64(math.MaxUint64)
 		tail = uint64(0)
 	)
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		items := table.items.Load()
 		if head > items {
 			head = items
 		}
 		hidden := table.itemHidden.Load()
+		if !f.configs[kind].prunable {
+			// Non-prunable tables must retain all their items
+			if hidden != 0 {
+				return fmt.Errorf("non-prunable freezer table %s has non-zero tail: %d", kind, hidden)
+			}
+			continue
+		}
 		if hidden > tail {
 			tail = hidden
 		}
 	}
-	for _, table := range f.tables {
+	for kind, table := range f.tables {
 		if err := table.truncateHead(head); err != nil {
 			return err
 		}
+		if !f.configs[kind].prunable {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return err
 		}
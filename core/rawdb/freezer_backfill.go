@@ -0,0 +1,343 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// freezerBackfillDir is the directory within the freezer where the items pruned
+// from the tail are restored, before being joined with the retained ones.
+const freezerBackfillDir = "backfill"
+
+// tailBackfiller is implemented by the ancient stores able to restore the items
+// pruned from the tail of their prunable tables.
+//
+// The pruned items are restored in ascending order, starting from the first one,
+// into a separate staging area. Once all the pruned items of every table are
+// restored, they are joined with the retained ones and the tail is re-extended
+// to the first item. Until then, the restored items are not visible.
+type tailBackfiller interface {
+	// backfillProgress returns the number of the first pruned item which is not
+	// restored yet, or the tail if there is none.
+	backfillProgress() (uint64, error)
+
+	// backfillTail runs a write operation restoring the pruned items. Items
+	// already restored or retained by a table are skipped, the others must be
+	// appended in order.
+	backfillTail(fn func(ethdb.AncientWriteOp) error) error
+}
+
+// backfillWriter appends the restored items of a table.
+type backfillWriter interface {
+	Append(item uint64, data interface{}) error
+	AppendRaw(item uint64, blob []byte) error
+}
+
+// backfillTable is the write state of the restored items of a table.
+type backfillTable struct {
+	writer backfillWriter
+	next   uint64 // Next item to restore
+	limit  uint64 // First item retained by the table
+}
+
+// backfillBatch is a write operation restoring the items pruned from the tail of
+// the prunable tables.
+type backfillBatch struct {
+	tables map[string]*backfillTable
+}
+
+// Append adds an RLP-encoded item, unless it's not missing from the table.
+func (b *backfillBatch) Append(kind string, number uint64, item interface{}) error {
+	table, skip, err := b.table(kind, number)
+	if err != nil || skip {
+		return err
+	}
+	if err := table.writer.Append(number, item); err != nil {
+		return err
+	}
+	table.next++
+	return nil
+}
+
+// AppendRaw adds an item without RLP-encoding it, unless it's not missing from
+// the table.
+func (b *backfillBatch) AppendRaw(kind string, number uint64, item []byte) error {
+	table, skip, err := b.table(kind, number)
+	if err != nil || skip {
+		return err
+	}
+	if err := table.writer.AppendRaw(number, item); err != nil {
+		return err
+	}
+	table.next++
+	return nil
+}
+
+// table returns the write state of the given table, and whether the item has to
+// be skipped as it's not missing.
+func (b *backfillBatch) table(kind string, number uint64) (*backfillTable, bool, error) {
+	table := b.tables[kind]
+	if table == nil {
+		return nil, false, fmt.Errorf("%w: %s", errUnknownTable, kind)
+	}
+	return table, number < table.next || number >= table.limit, nil
+}
+
+// backfillProgress returns the number of the first pruned item which is not
+// restored yet, or the tail if there is none.
+func (f *Freezer) backfillProgress() (uint64, error) {
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	if err := f.openBackfill(); err != nil {
+		return 0, err
+	}
+	next := f.tail.Load()
+	for name, table := range f.backfill {
+		if items := table.items.Load(); items < f.tables[name].itemOffset.Load() {
+			next = min(next, items)
+		}
+	}
+	return next, nil
+}
+
+// backfillTail runs a write operation restoring the items pruned from the tail
+// of the prunable tables. The tail is re-extended once all of them are restored.
+func (f *Freezer) backfillTail(fn func(ethdb.AncientWriteOp) error) (err error) {
+	f.writeLock.Lock()
+	defer f.writeLock.Unlock()
+
+	if err := f.openBackfill(); err != nil {
+		return err
+	}
+	var (
+		batch   = &backfillBatch{tables: make(map[string]*backfillTable)}
+		batches = make(map[string]*freezerTableBatch)
+		prev    = make(map[string]uint64)
+	)
+	for name, table := range f.backfill {
+		prev[name] = table.items.Load()
+		batches[name] = table.newBatch()
+		batch.tables[name] = &backfillTable{
+			writer: batches[name],
+			next:   prev[name],
+			limit:  f.tables[name].itemOffset.Load(),
+		}
+	}
+	// Roll back the restored items in case of error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for name, table := range f.backfill {
+			if err := table.truncateHead(prev[name]); err != nil {
+				log.Error("Freezer backfill roll-back failed", "table", name, "index", prev[name], "err", err)
+			}
+		}
+	}()
+	if err := fn(batch); err != nil {
+		return err
+	}
+	for _, tb := range batches {
+		if err := tb.commit(); err != nil {
+			return err
+		}
+	}
+	return f.joinBackfill()
+}
+
+// openBackfill opens the tables holding the restored items, unless they're open
+// already. If the tail was already re-extended, the leftovers are removed. The
+// caller must hold the write lock.
+func (f *Freezer) openBackfill() error {
+	if f.backfill != nil {
+		return nil
+	}
+	if f.readonly {
+		return errReadOnly
+	}
+	dir := filepath.Join(f.datadir, freezerBackfillDir)
+	if f.tail.Load() == 0 {
+		return os.RemoveAll(dir)
+	}
+	tables := make(map[string]*freezerTable)
+	for name, table := range f.tables {
+		if !f.configs[name].prunable {
+			continue
+		}
+		restored, err := newTable(dir, name, metrics.NilMeter{}, metrics.NilMeter{}, metrics.NilGauge{}, table.maxFileSize, table.noCompression, false)
+		if err != nil {
+			for _, table := range tables {
+				table.Close()
+			}
+			return err
+		}
+		tables[name] = restored
+	}
+	f.backfill = tables
+
+	// The restored items might be complete if the join was interrupted
+	return f.joinBackfill()
+}
+
+// joinBackfill joins the restored items with the retained ones if all the pruned
+// items are restored, re-extending the tail to the first item. The caller must
+// hold the write lock.
+func (f *Freezer) joinBackfill() error {
+	for name, table := range f.backfill {
+		if table.items.Load() < f.tables[name].itemOffset.Load() {
+			return nil
+		}
+	}
+	for name, table := range f.backfill {
+		if err := f.tables[name].prepend(table); err != nil {
+			return err
+		}
+	}
+	f.tail.Store(0)
+
+	if err := f.closeBackfill(); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(f.datadir, freezerBackfillDir))
+}
+
+// closeBackfill closes the tables holding the restored items. The caller must
+// hold the write lock.
+func (f *Freezer) closeBackfill() error {
+	var errs []error
+	for _, table := range f.backfill {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	f.backfill = nil
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// memoryBackfillWriter appends the restored items of a memory table.
+type memoryBackfillWriter struct {
+	table *memoryTable
+}
+
+func (w *memoryBackfillWriter) Append(item uint64, data interface{}) error {
+	blob, err := rlp.EncodeToBytes(data)
+	if err != nil {
+		return err
+	}
+	return w.AppendRaw(item, blob)
+}
+
+func (w *memoryBackfillWriter) AppendRaw(item uint64, blob []byte) error {
+	if item != w.table.items {
+		return errOutOrderInsertion
+	}
+	return w.table.commit([][]byte{common.CopyBytes(blob)})
+}
+
+// backfillProgress returns the number of the first pruned item which is not
+// restored yet, or the tail if there is none.
+func (f *MemoryFreezer) backfillProgress() (uint64, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.openBackfill()
+
+	next := f.tail
+	for _, table := range f.backfill {
+		next = min(next, table.items)
+	}
+	return next, nil
+}
+
+// backfillTail runs a write operation restoring the items pruned from the tail
+// of the prunable tables. The tail is re-extended once all of them are restored.
+func (f *MemoryFreezer) backfillTail(fn func(ethdb.AncientWriteOp) error) (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.readonly {
+		return errReadOnly
+	}
+	f.openBackfill()
+
+	var (
+		batch = &backfillBatch{tables: make(map[string]*backfillTable)}
+		prev  = make(map[string]uint64)
+	)
+	for name, table := range f.backfill {
+		prev[name] = table.items
+		batch.tables[name] = &backfillTable{
+			writer: &memoryBackfillWriter{table: table},
+			next:   table.items,
+			limit:  f.tail,
+		}
+	}
+	// Roll back the restored items in case of error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for name, table := range f.backfill {
+			if err := table.truncateHead(prev[name]); err != nil {
+				log.Error("Freezer backfill roll-back failed", "table", name, "index", prev[name], "err", err)
+			}
+		}
+	}()
+	if err := fn(batch); err != nil {
+		return err
+	}
+	// Join the restored items with the retained ones once complete
+	for _, table := range f.backfill {
+		if table.items < f.tail {
+			return nil
+		}
+	}
+	for name, table := range f.backfill {
+		if err := f.tables[name].prepend(table); err != nil {
+			return err
+		}
+	}
+	f.tail, f.backfill = 0, nil
+	return nil
+}
+
+// openBackfill creates the tables holding the restored items, unless they exist
+// already. The caller must hold the lock.
+func (f *MemoryFreezer) openBackfill() {
+	if f.backfill != nil || f.tail == 0 {
+		return
+	}
+	f.backfill = make(map[string]*memoryTable)
+	for name := range f.tables {
+		if f.configs[name].prunable {
+			f.backfill[name] = newMemoryTable(name)
+		}
+	}
+}
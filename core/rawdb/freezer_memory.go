@@ -133,6 +133,25 @@ func (t *memoryTable) commit(batch [][]byte) error {
 	return nil
 }
 
+// prepend restores the items deleted from the tail of the table, joining the
+// given table, which holds exactly the deleted items, in front of the retained
+// ones.
+func (t *memoryTable) prepend(src *memoryTable) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	src.lock.RLock()
+	defer src.lock.RUnlock()
+
+	if src.offset != 0 || src.items != t.offset {
+		return fmt.Errorf("restored items mismatch: have %d-%d, want 0-%d", src.offset, src.items, t.offset)
+	}
+	t.data = append(append(make([][]byte, 0, len(src.data)+len(t.data)), src.data...), t.data...)
+	t.size += src.size
+	t.offset = 0
+	return nil
+}
+
 // memoryBatch is the singleton batch used for ancient write.
 type memoryBatch struct {
 	data map[string][][]byte
@@ -209,24 +228,34 @@ func (b *memoryBatch) commit(freezer *MemoryFreezer) (items uint64, writeSize in
 // MemoryFreezer is an ephemeral ancient store. It implements the ethdb.AncientStore
 // interface and can be used along with ephemeral key-value store.
 type MemoryFreezer struct {
-	items      uint64                  // Number of items stored
-	tail       uint64                  // Number of the first stored item in the freezer
-	readonly   bool                    // Flag if the freezer is only for reading
-	lock       sync.RWMutex            // Lock to protect fields
-	tables     map[string]*memoryTable // Tables for storing everything
-	writeBatch *memoryBatch            // Pre-allocated write batch
+	items      uint64                        // Number of items stored
+	tail       uint64                        // Number of the first stored item in the freezer
+	readonly   bool                          // Flag if the freezer is only for reading
+	lock       sync.RWMutex                  // Lock to protect fields
+	tables     map[string]*memoryTable       // Tables for storing everything
+	configs    map[string]freezerTableConfig // Settings of the tables
+	backfill   map[string]*memoryTable       // Items restored below the tail
+	writeBatch *memoryBatch                  // Pre-allocated write batch
 }
 
-// NewMemoryFreezer initializes an in-memory freezer instance.
+// NewMemoryFreezer initializes an in-memory freezer instance, with all the
+// tables being pruned together by TruncateTail.
 func NewMemoryFreezer(readonly bool, tableName map[string]bool) *MemoryFreezer {
+	return newMemoryFreezer(readonly, prunableTableConfigs(tableName))
+}
+
+// newMemoryFreezer initializes an in-memory freezer instance with the given
+// table configs.
+func newMemoryFreezer(readonly bool, configs map[string]freezerTableConfig) *MemoryFreezer {
 	tables := make(map[string]*memoryTable)
-	for name := range tableName {
+	for name := range configs {
 		tables[name] = newMemoryTable(name)
 	}
 	return &MemoryFreezer{
 		writeBatch: newMemoryBatch(),
 		readonly:   readonly,
 		tables:     tables,
+		configs:    configs,
 	}
 }
 
@@ -368,7 +397,8 @@ func (f *MemoryFreezer) TruncateHead(items uint64) (uint64, error) {
 	return old, nil
 }
 
-// TruncateTail discards any recent data below the provided threshold number.
+// TruncateTail discards any recent data below the provided threshold number in
+// the prunable tables.
 func (f *MemoryFreezer) TruncateTail(tail uint64) (uint64, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
@@ -380,7 +410,10 @@ func (f *MemoryFreezer) TruncateTail(tail uint64) (uint64, error) {
 	if old >= tail {
 		return old, nil
 	}
-	for _, table := range f.tables {
+	for name, table := range f.tables {
+		if !f.configs[name].prunable {
+			continue
+		}
 		if err := table.truncateTail(tail); err != nil {
 			return 0, err
 		}
@@ -422,7 +455,7 @@ func (f *MemoryFreezer) Reset() error {
 	for name := range f.tables {
 		tables[name] = newMemoryTable(name)
 	}
-	f.tables = tables
+	f.tables, f.backfill = tables, nil
 	f.items, f.tail = 0, 0
 	return nil
 }
@@ -590,6 +590,89 @@ func (t *freezerTable) truncateTail(items uint64) error {
 	return nil
 }
 
+// prepend restores the items deleted from the tail of the table, joining the
+// given table, which holds exactly the deleted items, in front of the retained
+// ones. The data files of the given table are hard-linked into the table, so
+// they must be laid out like the deleted ones, ending right before the current
+// tail file. The hidden items are made visible again.
+func (t *freezerTable) prepend(src *freezerTable) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	src.lock.RLock()
+	defer src.lock.RUnlock()
+
+	oldSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	deleted := t.itemOffset.Load()
+	if deleted > 0 {
+		if have := src.items.Load(); have != deleted || src.itemOffset.Load() != 0 {
+			return fmt.Errorf("restored items mismatch: have %d-%d, want 0-%d", src.itemOffset.Load(), have, deleted)
+		}
+		if src.headId+1 != t.tailId {
+			return fmt.Errorf("restored files mismatch: have %d-%d, want 0-%d", src.tailId, src.headId, t.tailId-1)
+		}
+		if err := src.index.Sync(); err != nil {
+			return err
+		}
+		if err := src.head.Sync(); err != nil {
+			return err
+		}
+		// Link the restored data files into the table, replacing the leftovers
+		// of any interrupted attempt.
+		for num := uint32(0); num < t.tailId; num++ {
+			name := t.fileName(num)
+			if err := os.Remove(filepath.Join(t.path, name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.Link(filepath.Join(src.path, src.fileName(num)), filepath.Join(t.path, name)); err != nil {
+				return err
+			}
+		}
+		// Replace the tail index entry with the indices of the restored items.
+		// The metadata is only updated afterwards, so that the items remain
+		// hidden if the operation is interrupted.
+		if err := t.index.Close(); err != nil {
+			return err
+		}
+		err = copyFrom(t.index.Name(), t.index.Name(), indexEntrySize, func(f *os.File) error {
+			_, err := io.Copy(f, io.NewSectionReader(src.index, 0, int64((deleted+1)*indexEntrySize)))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if t.index, err = openFreezerFileForAppend(t.index.Name()); err != nil {
+			return err
+		}
+		if err := t.index.Sync(); err != nil {
+			return err
+		}
+		for num := uint32(0); num < t.tailId; num++ {
+			if _, err := t.openFile(num, openFreezerFileForReadOnly); err != nil {
+				return err
+			}
+		}
+		t.tailId = 0
+		t.itemOffset.Store(0)
+	}
+	t.itemHidden.Store(0)
+	if err := writeMetadata(t.meta, newMetadata(0)); err != nil {
+		return err
+	}
+	if err := t.meta.Sync(); err != nil {
+		return err
+	}
+	newSize, err := t.sizeNolock()
+	if err != nil {
+		return err
+	}
+	t.sizeGauge.Inc(int64(newSize - oldSize))
+	return nil
+}
+
 // Close closes all opened files.
 func (t *freezerTable) Close() error {
 	t.lock.Lock()
@@ -635,13 +718,7 @@ func (t *freezerTable) Close() error {
 func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error)) (f *os.File, err error) {
 	var exist bool
 	if f, exist = t.files[num]; !exist {
-		var name string
-		if t.noCompression {
-			name = fmt.Sprintf("%s.%04d.rdat", t.name, num)
-		} else {
-			name = fmt.Sprintf("%s.%04d.cdat", t.name, num)
-		}
-		f, err = opener(filepath.Join(t.path, name))
+		f, err = opener(filepath.Join(t.path, t.fileName(num)))
 		if err != nil {
 			return nil, err
 		}
@@ -650,6 +727,14 @@ func (t *freezerTable) openFile(num uint32, opener func(string) (*os.File, error
 	return f, err
 }
 
+// fileName returns the name of the data file with the given number.
+func (t *freezerTable) fileName(num uint32) string {
+	if t.noCompression {
+		return fmt.Sprintf("%s.%04d.rdat", t.name, num)
+	}
+	return fmt.Sprintf("%s.%04d.cdat", t.name, num)
+}
+
 // releaseFile closes a file, and removes it from the open file cache.
 // Assumes that the caller holds the write lock
 func (t *freezerTable) releaseFile(num uint32) {
@@ -482,6 +482,156 @@ func TestFreezerCloseSync(t *testing.T) {
 	}
 }
 
+func TestFreezerTruncateTailNonPrunable(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir    = t.TempDir()
+		tables = map[string]freezerTableConfig{
+			"a": {noSnappy: true, prunable: false},
+			"b": {noSnappy: true, prunable: true},
+		}
+	)
+	f, err := newFreezer(dir, "", false, 2049, tables)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := op.AppendRaw("a", i, getChunk(32, int(i))); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("b", i, getChunk(32, int(i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	if _, err := f.TruncateTail(5); err != nil {
+		t.Fatal("truncate tail failed", err)
+	}
+	check := func(f *Freezer) {
+		t.Helper()
+
+		if tail, _ := f.Tail(); tail != 5 {
+			t.Fatalf("wrong tail: have %d, want 5", tail)
+		}
+		for i := uint64(0); i < 10; i++ {
+			if blob, err := f.Ancient("a", i); err != nil || !bytes.Equal(blob, getChunk(32, int(i))) {
+				t.Fatalf("non-prunable item %d: have %x, err %v", i, blob, err)
+			}
+			if has, _ := f.HasAncient("b", i); has != (i >= 5) {
+				t.Fatalf("prunable item %d: availability mismatch: have %v", i, has)
+			}
+		}
+	}
+	check(f)
+	require.NoError(t, f.Close())
+
+	// Reopen the freezer, also in readonly mode, the tails must be retained
+	for _, readonly := range []bool{false, true} {
+		f, err = newFreezer(dir, "", readonly, 2049, tables)
+		if err != nil {
+			t.Fatalf("can't reopen freezer (readonly %v): %v", readonly, err)
+		}
+		check(f)
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestFreezerBackfillTail(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir    = t.TempDir()
+		tables = map[string]freezerTableConfig{
+			"a": {noSnappy: true, prunable: false},
+			"b": {noSnappy: true, prunable: true},
+			"c": {noSnappy: true, prunable: true},
+		}
+		sizes = map[string]int{"a": 32, "b": 32, "c": 20}
+	)
+	f, err := newFreezer(dir, "", false, 100, tables)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	write := func(op ethdb.AncientWriteOp, kinds []string, from, to uint64) error {
+		for i := from; i < to; i++ {
+			for _, kind := range kinds {
+				if err := op.AppendRaw(kind, i, getChunk(sizes[kind], int(i))); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		return write(op, []string{"a", "b", "c"}, 0, 20)
+	})
+	require.NoError(t, err)
+
+	// Prune the tail, deleting some data files of the prunable tables
+	if _, err := f.TruncateTail(13); err != nil {
+		t.Fatal("truncate tail failed", err)
+	}
+	if f.tables["b"].itemOffset.Load() == 0 || f.tables["c"].itemOffset.Load() == 0 {
+		t.Fatal("no data files deleted")
+	}
+	// Restore the first items, which must not be visible yet
+	require.NoError(t, f.backfillTail(func(op ethdb.AncientWriteOp) error {
+		return write(op, []string{"b", "c"}, 0, 7)
+	}))
+	if has, _ := f.HasAncient("b", 0); has {
+		t.Fatal("restored item visible before completion")
+	}
+	require.NoError(t, f.Close())
+
+	// Resume restoring after reopening the freezer
+	f, err = newFreezer(dir, "", false, 100, tables)
+	if err != nil {
+		t.Fatal("can't reopen freezer", err)
+	}
+	if next, err := f.backfillProgress(); err != nil || next != 7 {
+		t.Fatalf("wrong backfill progress: have %d, want 7, err %v", next, err)
+	}
+	if err := f.backfillTail(func(op ethdb.AncientWriteOp) error {
+		return write(op, []string{"b", "c"}, 8, 13)
+	}); err == nil {
+		t.Fatal("restored non-contiguous items")
+	}
+	require.NoError(t, f.backfillTail(func(op ethdb.AncientWriteOp) error {
+		return write(op, []string{"b", "c"}, 7, 13)
+	}))
+	check := func(f *Freezer) {
+		t.Helper()
+
+		if tail, _ := f.Tail(); tail != 0 {
+			t.Fatalf("tail not re-extended: have %d", tail)
+		}
+		for i := uint64(0); i < 20; i++ {
+			for kind, size := range sizes {
+				if blob, err := f.Ancient(kind, i); err != nil || !bytes.Equal(blob, getChunk(size, int(i))) {
+					t.Fatalf("item %d of %s: have %x, err %v", i, kind, blob, err)
+				}
+			}
+		}
+	}
+	check(f)
+	if _, err := os.Stat(filepath.Join(dir, freezerBackfillDir)); !os.IsNotExist(err) {
+		t.Fatalf("restored items not cleaned up: %v", err)
+	}
+	require.NoError(t, f.Close())
+
+	f, err = newFreezer(dir, "", true, 100, tables)
+	if err != nil {
+		t.Fatal("can't reopen freezer", err)
+	}
+	check(f)
+	require.NoError(t, f.Close())
+}
+
 func TestFreezerSuite(t *testing.T) {
 	ancienttest.TestAncientSuite(t, func(kinds []string) ethdb.AncientStore {
 		tables := make(map[string]bool)
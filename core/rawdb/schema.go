@@ -70,6 +70,9 @@ var (
 	// snapshotSyncStatusKey tracks the snapshot sync status across restarts.
 	snapshotSyncStatusKey = []byte("SnapshotSyncStatus")
 
+	// statePruningStatusKey tracks the online state pruning progress across restarts.
+	statePruningStatusKey = []byte("StatePruningStatus")
+
 	// skeletonSyncStatusKey tracks the skeleton sync status across restarts.
 	skeletonSyncStatusKey = []byte("SkeletonSyncStatus")
 
@@ -79,6 +82,10 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// chainHistoryTailKey tracks the oldest block whose body and receipts are
+	// retained, set if the chain history was pruned.
+	chainHistoryTailKey = []byte("ChainHistoryTail")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	// This flag is deprecated, it's kept to avoid reporting errors when inspect
 	// database.
@@ -96,6 +103,12 @@ var (
 	// snapSyncStatusFlagKey flags that status of snap sync.
 	snapSyncStatusFlagKey = []byte("SnapSyncStatus")
 
+	// stateHistoryIndexKey tracks the range of state histories that have been indexed.
+	stateHistoryIndexKey = []byte("StateHistoryIndex")
+
+	// logIndexKey tracks the range of blocks whose logs have been indexed.
+	logIndexKey = []byte("LogIndex")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerTDSuffix     = []byte("t") // headerPrefix + num (uint64 big endian) + hash + headerTDSuffix -> td
@@ -106,7 +119,7 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
-	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
+	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits (legacy)
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
@@ -117,11 +130,20 @@ var (
 	TrieNodeStoragePrefix = []byte("O") // TrieNodeStoragePrefix + accountHash + hexPath -> trie node
 	stateIDPrefix         = []byte("L") // stateIDPrefix + state root -> state id
 
+	// State history indexes of path-based storage scheme.
+	StateHistoryAccountIndexPrefix = []byte("ma") // StateHistoryAccountIndexPrefix + address + last id (uint64 big endian) -> history id list
+	StateHistoryStorageIndexPrefix = []byte("ms") // StateHistoryStorageIndexPrefix + address + slot hash + last id (uint64 big endian) -> history id list
+
+	// Log index of the log search.
+	LogIndexRowPrefix   = []byte("fr") // LogIndexRowPrefix + map index (uint32 big endian) + value hash -> log positions in the map
+	logIndexBlockPrefix = []byte("fb") // logIndexBlockPrefix + num (uint64 big endian) -> first log index (uint64 big endian) + block hash
+
 	PreimagePrefix = []byte("secure-key-")       // PreimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-")  // config prefix for the db
 	genesisPrefix  = []byte("ethereum-genesis-") // genesis state prefix for the db
 
-	// BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	// BloomBitsIndexPrefix is the data table of the legacy bloombits chain indexer
+	// to track its progress
 	BloomBitsIndexPrefix = []byte("iB")
 
 	ChtPrefix           = []byte("chtRootV2-") // ChtPrefix + chtNum (uint64 big endian) -> trie root hash
@@ -226,6 +248,28 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// logIndexRowKey = LogIndexRowPrefix + map index (uint32 big endian) + value hash
+func logIndexRowKey(mapIndex uint32, value common.Hash) []byte {
+	buf := make([]byte, len(LogIndexRowPrefix)+4+common.HashLength)
+	n := copy(buf, LogIndexRowPrefix)
+	binary.BigEndian.PutUint32(buf[n:], mapIndex)
+	copy(buf[n+4:], value.Bytes())
+	return buf
+}
+
+// logIndexMapPrefix = LogIndexRowPrefix + map index (uint32 big endian)
+func logIndexMapPrefix(mapIndex uint32) []byte {
+	buf := make([]byte, len(LogIndexRowPrefix)+4)
+	n := copy(buf, LogIndexRowPrefix)
+	binary.BigEndian.PutUint32(buf[n:], mapIndex)
+	return buf
+}
+
+// logIndexBlockKey = logIndexBlockPrefix + num (uint64 big endian)
+func logIndexBlockKey(number uint64) []byte {
+	return append(logIndexBlockPrefix, encodeBlockNumber(number)...)
+}
+
 // skeletonHeaderKey = skeletonHeaderPrefix + num (uint64 big endian)
 func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
@@ -265,6 +309,30 @@ func stateIDKey(root common.Hash) []byte {
 	return append(stateIDPrefix, root.Bytes()...)
 }
 
+// accountHistoryIndexPrefix = StateHistoryAccountIndexPrefix + address
+func accountHistoryIndexPrefix(address common.Address) []byte {
+	return append(StateHistoryAccountIndexPrefix, address.Bytes()...)
+}
+
+// storageHistoryIndexPrefix = StateHistoryStorageIndexPrefix + address + slot hash
+func storageHistoryIndexPrefix(address common.Address, slotHash common.Hash) []byte {
+	buf := make([]byte, len(StateHistoryStorageIndexPrefix)+common.AddressLength+common.HashLength)
+	n := copy(buf, StateHistoryStorageIndexPrefix)
+	n += copy(buf[n:], address.Bytes())
+	copy(buf[n:], slotHash.Bytes())
+	return buf
+}
+
+// accountHistoryIndexKey = StateHistoryAccountIndexPrefix + address + last id (uint64 big endian)
+func accountHistoryIndexKey(address common.Address, last uint64) []byte {
+	return append(accountHistoryIndexPrefix(address), encodeBlockNumber(last)...)
+}
+
+// storageHistoryIndexKey = StateHistoryStorageIndexPrefix + address + slot hash + last id (uint64 big endian)
+func storageHistoryIndexKey(address common.Address, slotHash common.Hash, last uint64) []byte {
+	return append(storageHistoryIndexPrefix(address, slotHash), encodeBlockNumber(last)...)
+}
+
 // accountTrieNodeKey = TrieNodeAccountPrefix + nodePath.
 func accountTrieNodeKey(path []byte) []byte {
 	return append(TrieNodeAccountPrefix, path...)
@@ -68,6 +68,10 @@ type Database interface {
 
 	// TrieDB returns the underlying trie database for managing trie nodes.
 	TrieDB() *triedb.Database
+
+	// HistoricReader returns a state reader for accessing the historical state
+	// with the specified root, which is no longer directly available.
+	HistoricReader(root common.Hash) (Reader, error)
 }
 
 // Trie is a Ethereum Merkle Patricia trie.
@@ -272,6 +276,20 @@ func (db *cachingDB) TrieDB() *triedb.Database {
 	return db.triedb
 }
 
+// HistoricReader returns a state reader for accessing the historical state with
+// the specified root. It's only supported by path-based scheme with the state
+// history indexing enabled.
+func (db *cachingDB) HistoricReader(root common.Hash) (Reader, error) {
+	if db.triedb.IsVerkle() {
+		return nil, errors.New("historical state is not supported by verkle")
+	}
+	reader, err := db.triedb.HistoricReader(root)
+	if err != nil {
+		return nil, err
+	}
+	return newHistoricReader(db, reader)
+}
+
 // PointCache returns the cache of evaluated curve points.
 func (db *cachingDB) PointCache() *utils.PointCache {
 	return db.pointCache
@@ -0,0 +1,578 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+const (
+	// onlineBloomFile is the filename of the state bloom filter persisted by
+	// the online pruner when the node is stopped mid-cycle.
+	onlineBloomFile = "onlinebloom.bf.gz"
+
+	// onlineBatchSize is the number of stale trie nodes deleted at once by the
+	// online pruner, between two throttling pauses.
+	onlineBatchSize = 10000
+
+	// onlineRecheckInterval is the time to wait between checking whether the
+	// chain progressed enough for the online pruner to continue.
+	onlineRecheckInterval = time.Minute
+
+	// onlineStateLookback is the maximum number of blocks looked back from the
+	// chain head to find the block of a state.
+	onlineStateLookback = 1024
+
+	// onlineIteratorLifetime is the time after which long-running database
+	// iterators are recreated, to allow the underlying compactor to release
+	// the data held by them.
+	onlineIteratorLifetime = 5 * time.Minute
+)
+
+var (
+	// errPrunerStopped is returned by the online pruner when it's interrupted.
+	errPrunerStopped = errors.New("pruner stopped")
+
+	// errPrunerRewound is returned by the online pruner if the chain was rewound
+	// below the state the pruning was relying on.
+	errPrunerRewound = errors.New("chain rewound below the pruning target")
+)
+
+// OnlineConfig includes all the configurations for online pruning.
+type OnlineConfig struct {
+	Datadir   string        // The directory to persist the state bloom into across restarts
+	BloomSize uint64        // The Megabytes of memory allocated to bloom-filter
+	Throttle  time.Duration // The pause between two batches of deletions
+	Interval  time.Duration // The time to wait between two pruning cycles
+}
+
+// DefaultOnlineConfig contains the default settings for online pruning.
+var DefaultOnlineConfig = OnlineConfig{
+	Throttle: 100 * time.Millisecond,
+	Interval: 24 * time.Hour,
+}
+
+// BlockChain defines the chain methods needed by the online pruner.
+type BlockChain interface {
+	// CurrentBlock retrieves the current head header of the canonical chain.
+	CurrentBlock() *types.Header
+
+	// GetHeaderByNumber retrieves a canonical header by number.
+	GetHeaderByNumber(number uint64) *types.Header
+
+	// Snapshots returns the state snapshot tree of the chain.
+	Snapshots() *snapshot.Tree
+
+	// TrieDB returns the trie database the chain persists its state into.
+	TrieDB() *triedb.Database
+}
+
+// onlineStatus is the progress of an online pruning cycle, persisted across
+// restarts.
+type onlineStatus struct {
+	Number  uint64 // Head block number when the tracking of flushed nodes began
+	Target  uint64 // Head block number when the marking finished, zero until then
+	Storage []byte // Account hash to continue marking the storage tries from
+	Cursor  []byte // Database key to continue sweeping the stale nodes from
+}
+
+// OnlinePruner deletes the stale state of a hash-based database in the
+// background, while the node is running. Each pruning cycle consists of:
+//
+//   - tracking every trie node flushed to disk from the beginning of the cycle
+//   - regenerating all trie nodes of the persistent state from the snapshot
+//     once it reflects the state of a block imported after the tracking began
+//   - deleting the trie nodes neither regenerated nor tracked, in throttled
+//     batches, starting once a state newer than the regenerated one is stored
+//
+// Every node of the states imported after the regeneration is either part of
+// the regenerated state or was flushed during the cycle, so these states stay
+// available. Older states are not retained, same as with offline pruning.
+// Contract codes are not pruned.
+//
+// The progress is persisted across restarts, along with the state bloom if the
+// node is stopped gracefully. After a crash, the trie nodes flushed since the
+// last shutdown can't be told apart, so the interrupted cycle is restarted.
+type OnlinePruner struct {
+	config OnlineConfig
+	db     ethdb.Database
+	chain  BlockChain
+
+	bloom  *stateBloom   // Trie nodes to retain, nil if no cycle is in progress
+	status *onlineStatus // Progress of the current cycle, nil if none
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewOnlinePruner creates the online pruner, resuming the pruning cycle which
+// was interrupted by the last shutdown. It must be created before the chain
+// starts importing blocks, in order to keep track of the flushed trie nodes.
+func NewOnlinePruner(db ethdb.Database, chain BlockChain, config OnlineConfig) (*OnlinePruner, error) {
+	if chain.TrieDB().Scheme() != rawdb.HashScheme {
+		return nil, errors.New("online pruning is only supported in hash-based scheme")
+	}
+	// Sanitize the bloom filter size if it's too small.
+	if config.BloomSize < 256 {
+		log.Warn("Sanitizing bloomfilter size", "provided(MB)", config.BloomSize, "updated(MB)", 256)
+		config.BloomSize = 256
+	}
+	if config.Throttle == 0 {
+		config.Throttle = DefaultOnlineConfig.Throttle
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultOnlineConfig.Interval
+	}
+	p := &OnlinePruner{
+		config: config,
+		db:     db,
+		chain:  chain,
+		quit:   make(chan struct{}),
+	}
+	// Resume the interrupted cycle if its state bloom was persisted. The file
+	// is removed right away, as it won't be up-to-date after a crash anymore.
+	var (
+		path = filepath.Join(config.Datadir, onlineBloomFile)
+		blob = rawdb.ReadStatePruningStatus(db)
+	)
+	if len(blob) > 0 {
+		var status onlineStatus
+		if err := rlp.DecodeBytes(blob, &status); err != nil {
+			log.Warn("Failed to decode state pruning status", "err", err)
+		} else if bloom, err := NewStateBloomFromDisk(path); err != nil {
+			log.Warn("Restarting interrupted state pruning", "err", err)
+		} else {
+			p.bloom, p.status = bloom, &status
+		}
+		if p.bloom == nil {
+			rawdb.DeleteStatePruningStatus(db)
+		}
+	}
+	os.Remove(path)
+
+	if p.bloom != nil {
+		if err := p.track(); err != nil {
+			return nil, err
+		}
+		log.Info("Resuming state pruning", "number", p.status.Number, "marked", p.status.Target != 0)
+	}
+	return p, nil
+}
+
+// Start launches the background pruning.
+func (p *OnlinePruner) Start() {
+	p.wg.Add(1)
+	go p.loop()
+}
+
+// Stop terminates the background pruning, persisting the progress of the
+// cycle in progress. It must be called after the chain is stopped, in order
+// to keep track of the trie nodes flushed at shutdown.
+func (p *OnlinePruner) Stop() error {
+	close(p.quit)
+	p.wg.Wait()
+
+	if p.bloom == nil {
+		return nil
+	}
+	if err := p.chain.TrieDB().TrackFlushes(nil); err != nil {
+		return err
+	}
+	path := filepath.Join(p.config.Datadir, onlineBloomFile)
+	if err := p.bloom.Commit(path, path+stateBloomFileTempSuffix); err != nil {
+		return err
+	}
+	blob, err := rlp.EncodeToBytes(p.status)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteStatePruningStatus(p.db, blob)
+	log.Info("Persisted state pruning progress", "number", p.status.Number, "marked", p.status.Target != 0)
+	return nil
+}
+
+// loop runs the pruning cycles until the pruner is stopped.
+func (p *OnlinePruner) loop() {
+	defer p.wg.Done()
+
+	for {
+		err := p.prune()
+		switch {
+		case errors.Is(err, errPrunerStopped):
+			return
+		case err != nil:
+			log.Error("Online state pruning failed", "err", err)
+			p.reset()
+		}
+		select {
+		case <-time.After(p.config.Interval):
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// track starts tracking the trie nodes flushed to disk into the state bloom.
+func (p *OnlinePruner) track() error {
+	bloom := p.bloom
+	return p.chain.TrieDB().TrackFlushes(func(hash common.Hash) {
+		bloom.Put(hash.Bytes(), nil)
+	})
+}
+
+// reset drops the cycle in progress.
+func (p *OnlinePruner) reset() {
+	if err := p.chain.TrieDB().TrackFlushes(nil); err != nil {
+		log.Error("Failed to stop tracking flushed nodes", "err", err)
+	}
+	rawdb.DeleteStatePruningStatus(p.db)
+	p.bloom, p.status = nil, nil
+}
+
+// prune runs or continues a pruning cycle.
+func (p *OnlinePruner) prune() error {
+	start := time.Now()
+
+	// Start a new cycle if none is in progress.
+	if p.bloom == nil {
+		if err := p.begin(); err != nil {
+			return err
+		}
+	}
+	// Regenerate the persistent state from the snapshot once it's newer than
+	// the start of the cycle.
+	if p.status.Target == 0 {
+		if err := p.wait(p.snapshotReady); err != nil {
+			return err
+		}
+		if err := p.mark(); err != nil {
+			return err
+		}
+	}
+	// Sweep the stale trie nodes once a state newer than the regenerated one
+	// is persisted, the node would recover from it after a crash.
+	if err := p.wait(p.stateCommitted); err != nil {
+		return err
+	}
+	if err := p.sweep(); err != nil {
+		return err
+	}
+	log.Info("State pruning successful", "elapsed", common.PrettyDuration(time.Since(start)))
+	p.reset()
+	return nil
+}
+
+// begin starts a new pruning cycle, tracking the flushed nodes from now on.
+// The genesis state is always retained.
+func (p *OnlinePruner) begin() error {
+	bloom, err := newStateBloomWithSize(p.config.BloomSize)
+	if err != nil {
+		return err
+	}
+	p.bloom = bloom
+	p.status = &onlineStatus{Number: p.chain.CurrentBlock().Number.Uint64()}
+	if err := p.track(); err != nil {
+		return err
+	}
+	if err := extractGenesis(p.db, p.bloom); err != nil {
+		return err
+	}
+	log.Info("Started state pruning", "number", p.status.Number)
+	return nil
+}
+
+// wait blocks until the given condition is met, checking it periodically.
+func (p *OnlinePruner) wait(cond func() (bool, error)) error {
+	for {
+		ok, err := cond()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-time.After(onlineRecheckInterval):
+		case <-p.quit:
+			return errPrunerStopped
+		}
+	}
+}
+
+// snapshotReady reports whether the persistent snapshot is fully generated and
+// reflects the state of a block imported after the tracking of the cycle began.
+func (p *OnlinePruner) snapshotReady() (bool, error) {
+	snaps := p.chain.Snapshots()
+	if snaps == nil {
+		return false, errors.New("snapshot is not available")
+	}
+	root := snaps.DiskRoot()
+	it, err := snaps.AccountIterator(root, common.Hash{})
+	if err != nil {
+		return false, nil // Snapshot still being generated
+	}
+	it.Release()
+
+	head := p.chain.CurrentBlock().Number.Uint64()
+	for number := head; number > p.status.Number && head-number < onlineStateLookback; number-- {
+		header := p.chain.GetHeaderByNumber(number)
+		if header == nil {
+			break
+		}
+		if header.Root == root {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stateCommitted reports whether the state of a block imported after the
+// marking finished is persisted, failing if the chain was rewound below.
+func (p *OnlinePruner) stateCommitted() (bool, error) {
+	head := p.chain.CurrentBlock().Number.Uint64()
+	if head < p.status.Target {
+		return false, errPrunerRewound
+	}
+	for number := head; number > p.status.Target; number-- {
+		header := p.chain.GetHeaderByNumber(number)
+		if header == nil {
+			break
+		}
+		if rawdb.HasLegacyTrieNode(p.db, header.Root) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// mark regenerates all trie nodes of the persistent state from the snapshot,
+// committing them into the state bloom, and sets the chain head as the target
+// of the pruning.
+//
+// The snapshot may be updated while iterating over it, so the trie nodes of
+// the ranges spanning over an update won't match any state. It's fine, as the
+// nodes of the later states along these ranges were recreated by the update,
+// hence flushed during the cycle too.
+func (p *OnlinePruner) mark() error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		opened  = time.Now()
+		keyLen  = len(rawdb.SnapshotAccountPrefix) + common.HashLength
+		onNode  = func(path []byte, hash common.Hash, blob []byte) { p.bloom.Put(hash.Bytes(), nil) }
+		acctrie = trie.NewStackTrie(onNode)
+
+		accounts, slots uint64
+	)
+	it := rawdb.NewKeyLengthIterator(p.db.NewIterator(rawdb.SnapshotAccountPrefix, nil), keyLen)
+	defer func() { it.Release() }()
+
+	for it.Next() {
+		hash := common.CopyBytes(it.Key()[len(rawdb.SnapshotAccountPrefix):])
+
+		// Abort if the pruner is stopped, continuing with this account on restart.
+		select {
+		case <-p.quit:
+			p.status.Storage = hash
+			return errPrunerStopped
+		default:
+		}
+		account, err := types.FullAccount(it.Value())
+		if err != nil {
+			return err
+		}
+		// Regenerate the storage trie unless it was done before a restart.
+		if account.Root != types.EmptyRootHash && bytes.Compare(hash, p.status.Storage) >= 0 {
+			n, err := p.markStorage(common.BytesToHash(hash))
+			if err != nil {
+				return err
+			}
+			slots += n
+		}
+		blob, err := rlp.EncodeToBytes(account)
+		if err != nil {
+			return err
+		}
+		if err := acctrie.Update(hash, blob); err != nil {
+			return err
+		}
+		accounts++
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Marking live state", "accounts", accounts, "slots", slots, "at", common.BytesToHash(hash), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		// Recreate the iterator periodically, not to pin the database content.
+		if time.Since(opened) > onlineIteratorLifetime {
+			it.Release()
+			it = rawdb.NewKeyLengthIterator(p.db.NewIterator(rawdb.SnapshotAccountPrefix, append(hash, 0)), keyLen)
+			opened = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	acctrie.Hash()
+
+	// Ensure the snapshot wasn't rebuilt or rewound while marking.
+	if ready, err := p.snapshotReady(); err != nil {
+		return err
+	} else if !ready {
+		return errPrunerRewound
+	}
+	p.status.Target = p.chain.CurrentBlock().Number.Uint64()
+	p.status.Storage = nil
+
+	blob, err := rlp.EncodeToBytes(p.status)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteStatePruningStatus(p.db, blob)
+
+	log.Info("Marked live state", "accounts", accounts, "slots", slots, "target", p.status.Target, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// markStorage regenerates all trie nodes of the storage trie of the given
+// account from the snapshot, committing them into the state bloom.
+func (p *OnlinePruner) markStorage(account common.Hash) (uint64, error) {
+	var (
+		slots uint64
+		stack = trie.NewStackTrie(func(path []byte, hash common.Hash, blob []byte) {
+			p.bloom.Put(hash.Bytes(), nil)
+		})
+		it = rawdb.IterateStorageSnapshots(p.db, account)
+	)
+	defer it.Release()
+
+	for it.Next() {
+		// Abort if the pruner is stopped, continuing with this account on restart.
+		if slots%10000 == 0 {
+			select {
+			case <-p.quit:
+				p.status.Storage = account.Bytes()
+				return 0, errPrunerStopped
+			default:
+			}
+		}
+		if err := stack.Update(it.Key()[len(rawdb.SnapshotStoragePrefix)+common.HashLength:], it.Value()); err != nil {
+			return 0, err
+		}
+		slots++
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+	stack.Hash()
+	return slots, nil
+}
+
+// sweep deletes all trie nodes neither regenerated nor flushed during the cycle
+// from the database, in throttled batches.
+func (p *OnlinePruner) sweep() error {
+	var (
+		count, skipped int
+		start          = time.Now()
+		logged         = time.Now()
+		triedb         = p.chain.TrieDB()
+		hashes         = make([]common.Hash, 0, onlineBatchSize)
+		keep           = func(hash common.Hash) bool { return p.bloom.Contain(hash.Bytes()) }
+	)
+	// flush deletes the collected stale nodes, persisting the position to
+	// continue sweeping from along.
+	flush := func(next []byte) error {
+		p.status.Cursor = next
+		blob, err := rlp.EncodeToBytes(p.status)
+		if err != nil {
+			return err
+		}
+		batch := p.db.NewBatch()
+		rawdb.WriteStatePruningStatus(batch, blob)
+
+		deleted, err := triedb.DeleteNodes(batch, hashes, keep)
+		if err != nil {
+			return err
+		}
+		count += deleted
+		skipped += len(hashes) - deleted
+		hashes = hashes[:0]
+		return nil
+	}
+	it := p.db.NewIterator(nil, p.status.Cursor)
+	defer func() { it.Release() }()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if p.bloom.Contain(key) {
+			skipped++
+			continue
+		}
+		hashes = append(hashes, common.BytesToHash(key))
+		if len(hashes) < onlineBatchSize {
+			continue
+		}
+		// Delete the batch and recreate the iterator after it, in order to
+		// allow the underlying compactor to delete the entries.
+		next := common.CopyBytes(key)
+		next = append(next, 0)
+		if err := flush(next); err != nil {
+			return err
+		}
+		it.Release()
+		it = p.db.NewIterator(nil, next)
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data", "nodes", count, "skipped", skipped, "at", common.BytesToHash(key), "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		// Ensure the retained state is still the one the chain relies on.
+		if p.chain.CurrentBlock().Number.Uint64() <= p.status.Target {
+			return errPrunerRewound
+		}
+		select {
+		case <-time.After(p.config.Throttle):
+		case <-p.quit:
+			return errPrunerStopped
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := flush(nil); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "nodes", count, "skipped", skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// checkState iterates over all trie nodes of the state with the given root,
+// returning an error if any of them is missing.
+func checkState(db ethdb.Database, root common.Hash) error {
+	tdb := triedb.NewDatabase(db, triedb.HashDefaults)
+	tr, err := trie.NewStateTrie(trie.StateTrieID(root), tdb)
+	if err != nil {
+		return err
+	}
+	it, err := tr.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	for it.Next(true) {
+		if !it.Leaf() {
+			continue
+		}
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(it.LeafBlob(), &acc); err != nil {
+			return err
+		}
+		if acc.Root == types.EmptyRootHash {
+			continue
+		}
+		st, err := trie.NewStateTrie(trie.StorageTrieID(root, common.BytesToHash(it.LeafKey()), acc.Root), tdb)
+		if err != nil {
+			return err
+		}
+		sit, err := st.NodeIterator(nil)
+		if err != nil {
+			return err
+		}
+		for sit.Next(true) {
+		}
+		if sit.Error() != nil {
+			return sit.Error()
+		}
+	}
+	return it.Error()
+}
+
+func TestOnlinePruning(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+				// SSTORE(NUMBER, NUMBER)
+				contract: {
+					Code:    []byte{byte(vm.NUMBER), byte(vm.NUMBER), byte(vm.SSTORE)},
+					Storage: map[common.Hash]common.Hash{{0x01}: {0x01}},
+				},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	_, blocks, _ := core.GenerateChainWithGenesis(gspec, ethash.NewFaker(), 30, func(i int, gen *core.BlockGen) {
+		gen.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(addr),
+			To:       &contract,
+			Gas:      50000,
+			GasPrice: gen.BaseFee(),
+		}))
+		gen.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(addr),
+			To:       &common.Address{byte(i + 1)},
+			Value:    big.NewInt(1),
+			Gas:      params.TxGas,
+			GasPrice: gen.BaseFee(),
+		}))
+	})
+	// Persist every state, so that there's plenty of stale nodes to prune.
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, &core.CacheConfig{
+		TrieCleanLimit:    256,
+		TrieDirtyDisabled: true,
+		SnapshotLimit:     256,
+		SnapshotWait:      true,
+		StateScheme:       rawdb.HashScheme,
+	}, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks[:10]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	dir := t.TempDir()
+	pruner, err := NewOnlinePruner(db, chain, OnlineConfig{Datadir: dir})
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	if pruner.config.Throttle != DefaultOnlineConfig.Throttle || pruner.config.Interval != DefaultOnlineConfig.Interval {
+		t.Fatalf("wrong default throttling: have %v/%v", pruner.config.Throttle, pruner.config.Interval)
+	}
+	pruner.config.BloomSize = 1
+	if err := pruner.begin(); err != nil {
+		t.Fatalf("failed to begin pruning: %v", err)
+	}
+	// The snapshot must reflect a block imported after the cycle began.
+	if ready, err := pruner.snapshotReady(); err != nil || ready {
+		t.Fatalf("snapshot ready before the chain progressed: %v, %v", ready, err)
+	}
+	if _, err := chain.InsertChain(blocks[10:20]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if err := chain.Snapshots().Cap(blocks[19].Root(), 0); err != nil {
+		t.Fatalf("failed to flatten snapshot: %v", err)
+	}
+	if ready, err := pruner.snapshotReady(); err != nil || !ready {
+		t.Fatalf("snapshot not ready: %v, %v", ready, err)
+	}
+	if err := pruner.mark(); err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	if pruner.status.Target != 20 {
+		t.Fatalf("wrong pruning target: have %d, want 20", pruner.status.Target)
+	}
+	// The sweep must wait for a state newer than the target.
+	if ok, err := pruner.stateCommitted(); err != nil || ok {
+		t.Fatalf("sweep allowed before the chain progressed: %v, %v", ok, err)
+	}
+	// Interrupt the cycle and resume it after a restart.
+	if err := pruner.Stop(); err != nil {
+		t.Fatalf("failed to stop pruner: %v", err)
+	}
+	pruner, err = NewOnlinePruner(db, chain, OnlineConfig{Datadir: dir})
+	if err != nil {
+		t.Fatalf("failed to recreate pruner: %v", err)
+	}
+	if pruner.status == nil || pruner.status.Target != 20 {
+		t.Fatalf("pruning progress not resumed: %+v", pruner.status)
+	}
+	if _, err := chain.InsertChain(blocks[20:]); err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if ok, err := pruner.stateCommitted(); err != nil || !ok {
+		t.Fatalf("sweep not allowed: %v, %v", ok, err)
+	}
+	if err := pruner.sweep(); err != nil {
+		t.Fatalf("failed to sweep: %v", err)
+	}
+	pruner.reset()
+
+	// The states older than the target are pruned, the genesis and the newer
+	// ones retained, including their nodes flushed during the cycle.
+	for _, block := range blocks[:9] {
+		if rawdb.HasLegacyTrieNode(db, block.Root()) {
+			t.Errorf("state of block %d not pruned", block.NumberU64())
+		}
+	}
+	if err := checkState(db, chain.Genesis().Root()); err != nil {
+		t.Errorf("genesis state corrupted: %v", err)
+	}
+	for _, block := range blocks[20:] {
+		if err := checkState(db, block.Root()); err != nil {
+			t.Errorf("state of block %d corrupted: %v", block.NumberU64(), err)
+		}
+	}
+	if blob := rawdb.ReadStatePruningStatus(db); len(blob) != 0 {
+		t.Error("pruning status not deleted")
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/triedb/pathdb"
+)
+
+// Reader defines the interface for accessing accounts and storage slots
+// associated with a specific state.
+type Reader interface {
+	// Account retrieves the account associated with a particular address.
+	//
+	// - Returns a nil account if it does not exist
+	// - Returns an error only if an unexpected issue occurs
+	// - The returned account is safe to modify after the call
+	Account(addr common.Address) (*types.StateAccount, error)
+
+	// Storage retrieves the storage slot associated with a particular account
+	// address and slot key.
+	//
+	// - Returns an empty slot if it does not exist
+	// - Returns an error only if an unexpected issue occurs
+	Storage(addr common.Address, slot common.Hash) (common.Hash, error)
+}
+
+// historicReader implements the Reader interface, serving the state at a
+// historical point with the indexed state histories. The states not mutated
+// since then are read from the tries of the reference state.
+type historicReader struct {
+	db     Database
+	reader *pathdb.HistoricalStateReader
+	root   common.Hash // The root of the reference state
+	trie   Trie        // The account trie of the reference state
+
+	storages map[common.Address]Trie // The storage tries of the reference state
+	lock     sync.Mutex              // Lock to protect the trie access
+}
+
+// newHistoricReader constructs a reader for the historical state with the
+// given root.
+func newHistoricReader(db Database, reader *pathdb.HistoricalStateReader) (*historicReader, error) {
+	tr, err := db.OpenTrie(reader.Root())
+	if err != nil {
+		return nil, err
+	}
+	return &historicReader{
+		db:       db,
+		reader:   reader,
+		root:     reader.Root(),
+		trie:     tr,
+		storages: make(map[common.Address]Trie),
+	}, nil
+}
+
+// Account implements Reader, retrieving the account at the historical point.
+func (r *historicReader) Account(addr common.Address) (*types.StateAccount, error) {
+	blob, found, err := r.reader.Account(addr)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if len(blob) == 0 {
+			return nil, nil
+		}
+		return types.FullAccount(blob)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.trie.GetAccount(addr)
+}
+
+// Storage implements Reader, retrieving the storage slot at the historical point.
+func (r *historicReader) Storage(addr common.Address, slot common.Hash) (common.Hash, error) {
+	blob, found, err := r.reader.Storage(addr, crypto.Keccak256Hash(slot.Bytes()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var value common.Hash
+	if found {
+		if len(blob) == 0 {
+			return common.Hash{}, nil
+		}
+		_, content, _, err := rlp.Split(blob)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		value.SetBytes(content)
+		return value, nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	tr, ok := r.storages[addr]
+	if !ok {
+		acct, err := r.trie.GetAccount(addr)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if acct == nil {
+			return common.Hash{}, nil
+		}
+		tr, err = r.db.OpenStorageTrie(r.root, addr, acct.Root, r.trie)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		r.storages[addr] = tr
+	}
+	val, err := tr.GetStorage(addr, slot.Bytes())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	value.SetBytes(val)
+	return value, nil
+}
@@ -131,6 +131,16 @@ func (s *stateObject) touch() {
 // subsequent reads to expand the same trie instead of reloading from disk.
 func (s *stateObject) getTrie() (Trie, error) {
 	if s.trie == nil {
+		// The storage trie of the historical state is not available, open
+		// an empty one for tracking the mutations on top.
+		if s.db.reader != nil {
+			tr, err := s.db.db.OpenStorageTrie(types.EmptyRootHash, s.address, types.EmptyRootHash, s.db.trie)
+			if err != nil {
+				return nil, err
+			}
+			s.trie = tr
+			return s.trie, nil
+		}
 		tr, err := s.db.db.OpenStorageTrie(s.db.originalRoot, s.address, s.data.Root, s.db.trie)
 		if err != nil {
 			return nil, err
@@ -194,6 +204,19 @@ func (s *stateObject) GetCommittedState(key common.Hash) common.Hash {
 		s.originStorage[key] = common.Hash{} // track the empty slot as origin value
 		return common.Hash{}
 	}
+	// If the state is historical, resolve the slot with the state reader
+	if s.db.reader != nil {
+		start := time.Now()
+		value, err := s.db.reader.Storage(s.address, key)
+		s.db.StorageReads += time.Since(start)
+
+		if err != nil {
+			s.db.setError(err)
+			return common.Hash{}
+		}
+		s.originStorage[key] = value
+		return value
+	}
 	// If no live objects are available, attempt to use snapshots
 	var (
 		enc   []byte
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -89,8 +90,10 @@ type StateDB struct {
 	trie       Trie
 	hasher     crypto.KeccakState
 	logger     *tracing.Hooks
-	snaps      *snapshot.Tree    // Nil if snapshot is not available
-	snap       snapshot.Snapshot // Nil if snapshot is not available
+	snaps      *snapshot.Tree     // Nil if snapshot is not available
+	snap       snapshot.Snapshot  // Nil if snapshot is not available
+	reader     Reader             // Nil if the state is not historical
+	witness    *stateless.Witness // Nil if the state accesses are not recorded
 
 	// originalRoot is the pre-state root, before any changes were made.
 	// It will be updated when the Commit is called.
@@ -192,6 +195,28 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 	return sdb, nil
 }
 
+// NewHistoric creates a new state for accessing the historical state with the
+// given root, which is no longer available in the trie database but can be
+// resolved from the indexed state histories.
+//
+// The state can be mutated as usual, e.g. for executing calls on top, but the
+// computed state root is meaningless and the state can't be committed.
+func NewHistoric(root common.Hash, db Database) (*StateDB, error) {
+	reader, err := db.HistoricReader(root)
+	if err != nil {
+		return nil, err
+	}
+	// Open an empty trie for tracking the mutations on top, as the
+	// trie of the historical state is not available.
+	sdb, err := New(types.EmptyRootHash, db, nil)
+	if err != nil {
+		return nil, err
+	}
+	sdb.originalRoot = root
+	sdb.reader = reader
+	return sdb, nil
+}
+
 // SetLogger sets the logger for account update hooks.
 func (s *StateDB) SetLogger(l *tracing.Hooks) {
 	s.logger = l
@@ -576,9 +601,23 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 	if _, ok := s.stateObjectsDestruct[addr]; ok {
 		return nil
 	}
-	// If no live objects are available, attempt to use snapshots
+	// If no live objects are available, attempt to use the historical
+	// state reader or snapshots
 	var data *types.StateAccount
-	if s.snap != nil {
+	if s.reader != nil {
+		start := time.Now()
+		acc, err := s.reader.Account(addr)
+		s.AccountReads += time.Since(start)
+
+		if err != nil {
+			s.setError(fmt.Errorf("getDeleteStateObject (%x) error: %w", addr.Bytes(), err))
+			return nil
+		}
+		if acc == nil {
+			return nil
+		}
+		data = acc
+	} else if s.snap != nil {
 		start := time.Now()
 		acc, err := s.snap.Account(crypto.HashData(s.hasher, addr.Bytes()))
 		s.SnapshotAccountReads += time.Since(start)
@@ -692,8 +731,10 @@ func (s *StateDB) Copy() *StateDB {
 		// to the snapshot tree, we need to copy that as well. Otherwise, any
 		// block mined by ourselves will cause gaps in the tree, and force the
 		// miner to operate trie-backed only.
-		snaps: s.snaps,
-		snap:  s.snap,
+		snaps:   s.snaps,
+		snap:    s.snap,
+		reader:  s.reader,
+		witness: s.witness,
 	}
 	// Deep copy cached state objects.
 	for addr, obj := range s.stateObjects {
@@ -0,0 +1,127 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"github.com/ethereum/go-ethereum/triedb/database"
+)
+
+// errWitnessVerkle is returned when attempting to collect a witness of a state
+// stored in a verkle tree, which is not supported.
+var errWitnessVerkle = errors.New("witness collection is not supported for verkle state")
+
+// NewWithWitness creates a new state from a given trie, recording all the trie
+// nodes and contract codes accessed into the witness. Snapshots are not used, as
+// all the state reads need to go through the tries to be recorded.
+func NewWithWitness(root common.Hash, db Database, witness *stateless.Witness) (*StateDB, error) {
+	if db.TrieDB().IsVerkle() {
+		return nil, errWitnessVerkle
+	}
+	sdb, err := New(root, &witnessDatabase{Database: db, witness: witness}, nil)
+	if err != nil {
+		return nil, err
+	}
+	sdb.witness = witness
+	return sdb, nil
+}
+
+// Witness returns the witness the state accesses are recorded into, or nil if
+// the state is not collecting one.
+func (s *StateDB) Witness() *stateless.Witness {
+	return s.witness
+}
+
+// witnessDatabase is a state database recording the trie nodes and contract
+// codes retrieved from it into a witness.
+type witnessDatabase struct {
+	Database
+	witness *stateless.Witness
+}
+
+// OpenTrie opens the main account trie at a specific root hash.
+func (db *witnessDatabase) OpenTrie(root common.Hash) (Trie, error) {
+	return trie.NewStateTrie(trie.StateTrieID(root), &recordingNodeDB{Database: db.TrieDB(), witness: db.witness})
+}
+
+// OpenStorageTrie opens the storage trie of an account.
+func (db *witnessDatabase) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, self Trie) (Trie, error) {
+	id := trie.StorageTrieID(stateRoot, crypto.Keccak256Hash(address.Bytes()), root)
+	return trie.NewStateTrie(id, &recordingNodeDB{Database: db.TrieDB(), witness: db.witness})
+}
+
+// ContractCode retrieves a particular contract's code.
+func (db *witnessDatabase) ContractCode(address common.Address, codeHash common.Hash) ([]byte, error) {
+	code, err := db.Database.ContractCode(address, codeHash)
+	if err != nil {
+		return nil, err
+	}
+	db.witness.AddCode(code)
+	return code, nil
+}
+
+// ContractCodeSize retrieves a particular contracts code's size. The whole code
+// is recorded, as it's needed to derive the size when executing statelessly.
+func (db *witnessDatabase) ContractCodeSize(address common.Address, codeHash common.Hash) (int, error) {
+	code, err := db.ContractCode(address, codeHash)
+	if err != nil {
+		return 0, err
+	}
+	return len(code), nil
+}
+
+// recordingNodeDB is a trie database whose readers record all the resolved trie
+// nodes into a witness.
+type recordingNodeDB struct {
+	*triedb.Database
+	witness *stateless.Witness
+}
+
+// Reader returns a recording node reader associated with the specific state.
+func (db *recordingNodeDB) Reader(stateRoot common.Hash) (database.Reader, error) {
+	reader, err := db.Database.Reader(stateRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingNodeReader{reader: reader, witness: db.witness}, nil
+}
+
+// recordingNodeReader is a trie node reader recording all the resolved nodes
+// into a witness.
+type recordingNodeReader struct {
+	reader  database.Reader
+	witness *stateless.Witness
+}
+
+// Node retrieves the trie node blob with the provided trie identifier, node
+// path and the corresponding node hash.
+func (r *recordingNodeReader) Node(owner common.Hash, path []byte, hash common.Hash) ([]byte, error) {
+	blob, err := r.reader.Node(owner, path, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) > 0 {
+		r.witness.AddState(blob)
+	}
+	return blob, nil
+}
@@ -37,10 +37,17 @@ import (
 // StateProcessor implements Processor.
 type StateProcessor struct {
 	config *params.ChainConfig // Chain configuration options
-	bc     *BlockChain         // Canonical block chain
+	bc     processorChain      // Canonical block chain
 	engine consensus.Engine    // Consensus engine used for block rewards
 }
 
+// processorChain defines the chain access needed by the state processor, served
+// by the canonical chain or, when executing statelessly, by a witness.
+type processorChain interface {
+	ChainContext
+	consensus.ChainHeaderReader
+}
+
 // NewStateProcessor initialises a new StateProcessor.
 func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine) *StateProcessor {
 	return &StateProcessor{
@@ -54,10 +61,11 @@ func NewStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consen
 // the transaction messages using the statedb and applying any rewards to both
 // the processor (coinbase) and any included uncles.
 //
-// Process returns the receipts and logs accumulated during the process and
-// returns the amount of gas that was used in the process. If any of the
-// transactions failed to execute due to insufficient gas it will return an error.
-func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+// Process returns the receipts, logs and execution layer requests accumulated
+// during the process and returns the amount of gas that was used in the process.
+// If any of the transactions failed to execute due to insufficient gas it will
+// return an error.
+func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (*ProcessResult, error) {
 	var (
 		receipts    types.Receipts
 		usedGas     = new(uint64)
@@ -72,25 +80,38 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	context := NewEVMBlockContext(header, p.bc, nil)
+
+	// If a witness is being collected, record the headers of the ancestors
+	// whose hashes are accessed, as they are needed to execute statelessly.
+	if witness := statedb.Witness(); witness != nil {
+		getHash := context.GetHash
+		context.GetHash = func(n uint64) common.Hash {
+			witness.AddBlockHash(n)
+			return getHash(n)
+		}
+	}
 	var (
-		context = NewEVMBlockContext(header, p.bc, nil)
-		vmenv   = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
-		signer  = types.MakeSigner(p.config, header.Number, header.Time)
+		vmenv  = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
+		signer = types.MakeSigner(p.config, header.Number, header.Time)
 	)
 	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
 		ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
 	}
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
 		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
 		statedb.SetTxContext(tx.Hash(), i)
 
 		receipt, err := ApplyTransactionWithEVM(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv)
 		if err != nil {
-			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
@@ -98,12 +119,26 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	// Fail if Shanghai not enabled and len(withdrawals) is non-zero.
 	withdrawals := block.Withdrawals()
 	if len(withdrawals) > 0 && !p.config.IsShanghai(block.Number(), block.Time()) {
-		return nil, nil, 0, errors.New("withdrawals before shanghai")
+		return nil, errors.New("withdrawals before shanghai")
+	}
+	// Collect the execution layer requests after Prague.
+	var requests [][]byte
+	if p.config.IsPrague(block.Number(), block.Time()) {
+		var err error
+		requests, err = ProcessRequests(allLogs, vmenv, statedb)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, statedb, block.Body())
 
-	return receipts, allLogs, *usedGas, nil
+	return &ProcessResult{
+		Receipts: receipts,
+		Requests: requests,
+		Logs:     allLogs,
+		GasUsed:  *usedGas,
+	}, nil
 }
 
 // ApplyTransactionWithEVM attempts to apply a transaction to the given state database
@@ -137,9 +172,14 @@ func ApplyTransactionWithEVM(msg *Message, config *params.ChainConfig, gp *GasPo
 	}
 	*usedGas += result.UsedGas
 
+	return MakeReceipt(evm, result, statedb, blockNumber, blockHash, tx, *usedGas, root), nil
+}
+
+// MakeReceipt generates the receipt object for a transaction given its execution result.
+func MakeReceipt(evm *vm.EVM, result *ExecutionResult, statedb *state.StateDB, blockNumber *big.Int, blockHash common.Hash, tx *types.Transaction, usedGas uint64, root []byte) *types.Receipt {
 	// Create a new receipt for the transaction, storing the intermediate root and gas used
 	// by the tx.
-	receipt = &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: *usedGas}
+	receipt := &types.Receipt{Type: tx.Type(), PostState: root, CumulativeGasUsed: usedGas}
 	if result.Failed() {
 		receipt.Status = types.ReceiptStatusFailed
 	} else {
@@ -154,7 +194,7 @@ func ApplyTransactionWithEVM(msg *Message, config *params.ChainConfig, gp *GasPo
 	}
 
 	// If the transaction created a contract, store the creation address in the receipt.
-	if msg.To == nil {
+	if tx.To() == nil {
 		receipt.ContractAddress = crypto.CreateAddress(evm.TxContext.Origin, tx.Nonce())
 	}
 
@@ -164,7 +204,7 @@ func ApplyTransactionWithEVM(msg *Message, config *params.ChainConfig, gp *GasPo
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, err
+	return receipt
 }
 
 // ApplyTransaction attempts to apply a transaction to the given state database
@@ -209,3 +249,118 @@ func ProcessBeaconBlockRoot(beaconRoot common.Hash, vmenv *vm.EVM, statedb *stat
 	_, _, _ = vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
 	statedb.Finalise(true)
 }
+
+// ProcessParentBlockHash stores the parent block hash in the history storage
+// contract as per EIP-2935.
+func ProcessParentBlockHash(prevHash common.Hash, vmenv *vm.EVM, statedb *state.StateDB) {
+	if vmenv.Config.Tracer != nil && vmenv.Config.Tracer.OnSystemCallStart != nil {
+		vmenv.Config.Tracer.OnSystemCallStart()
+	}
+	if vmenv.Config.Tracer != nil && vmenv.Config.Tracer.OnSystemCallEnd != nil {
+		defer vmenv.Config.Tracer.OnSystemCallEnd()
+	}
+	msg := &Message{
+		From:      params.SystemAddress,
+		GasLimit:  30_000_000,
+		GasPrice:  common.Big0,
+		GasFeeCap: common.Big0,
+		GasTipCap: common.Big0,
+		To:        &params.HistoryStorageAddress,
+		Data:      prevHash.Bytes(),
+	}
+	vmenv.Reset(NewEVMTxContext(msg), statedb)
+	statedb.AddAddressToAccessList(params.HistoryStorageAddress)
+	_, _, _ = vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
+	statedb.Finalise(true)
+}
+
+// ProcessRequests collects the execution layer requests of a block after all
+// transactions are applied, as defined by EIP-7685. The returned list is ordered
+// by request type and only contains the types with non-empty request data.
+func ProcessRequests(logs []*types.Log, vmenv *vm.EVM, statedb *state.StateDB) ([][]byte, error) {
+	requests := [][]byte{}
+
+	// EIP-6110 - deposits are parsed from the logs of the deposit contract
+	if err := ParseDepositLogs(&requests, logs, vmenv.ChainConfig()); err != nil {
+		return nil, err
+	}
+	// EIP-7002 - withdrawal requests are dequeued from the system contract
+	ProcessWithdrawalQueue(&requests, vmenv, statedb)
+
+	// EIP-7251 - consolidation requests are dequeued from the system contract
+	ProcessConsolidationQueue(&requests, vmenv, statedb)
+
+	return requests, nil
+}
+
+// ParseDepositLogs extracts the EIP-6110 deposit requests from the logs emitted
+// by the deposit contract and appends them to the request list.
+func ParseDepositLogs(requests *[][]byte, logs []*types.Log, config *params.ChainConfig) error {
+	deposits := []byte{types.DepositRequestType}
+	for _, log := range logs {
+		if log.Address != config.DepositContractAddress {
+			continue
+		}
+		if len(log.Topics) == 0 || log.Topics[0] != types.DepositEventSignature {
+			continue
+		}
+		request, err := types.DepositLogToRequest(log.Data)
+		if err != nil {
+			return fmt.Errorf("unable to parse deposit data: %v", err)
+		}
+		deposits = append(deposits, request...)
+	}
+	if len(deposits) > 1 {
+		*requests = append(*requests, deposits)
+	}
+	return nil
+}
+
+// ProcessWithdrawalQueue calls the EIP-7002 withdrawal queue contract. It
+// appends the dequeued withdrawal requests to the request list.
+func ProcessWithdrawalQueue(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB) {
+	processRequestsSystemCall(requests, vmenv, statedb, types.WithdrawalRequestType, params.WithdrawalQueueAddress)
+}
+
+// ProcessConsolidationQueue calls the EIP-7251 consolidation queue contract.
+// It appends the dequeued consolidation requests to the request list.
+func ProcessConsolidationQueue(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB) {
+	processRequestsSystemCall(requests, vmenv, statedb, types.ConsolidationRequestType, params.ConsolidationQueueAddress)
+}
+
+// processRequestsSystemCall invokes the given request predeploy contract as
+// the system address and appends the returned data as a typed request. If the
+// contract is not deployed, e.g. on a local network without it in the genesis,
+// no requests are produced.
+func processRequestsSystemCall(requests *[][]byte, vmenv *vm.EVM, statedb *state.StateDB, requestType byte, addr common.Address) {
+	if statedb.GetCodeSize(addr) == 0 {
+		return
+	}
+	if vmenv.Config.Tracer != nil && vmenv.Config.Tracer.OnSystemCallStart != nil {
+		vmenv.Config.Tracer.OnSystemCallStart()
+	}
+	if vmenv.Config.Tracer != nil && vmenv.Config.Tracer.OnSystemCallEnd != nil {
+		defer vmenv.Config.Tracer.OnSystemCallEnd()
+	}
+	msg := &Message{
+		From:      params.SystemAddress,
+		GasLimit:  30_000_000,
+		GasPrice:  common.Big0,
+		GasFeeCap: common.Big0,
+		GasTipCap: common.Big0,
+		To:        &addr,
+	}
+	vmenv.Reset(NewEVMTxContext(msg), statedb)
+	statedb.AddAddressToAccessList(addr)
+	ret, _, _ := vmenv.Call(vm.AccountRef(msg.From), *msg.To, msg.Data, 30_000_000, common.U2560)
+	statedb.Finalise(true)
+
+	if len(ret) == 0 {
+		return // skip empty output
+	}
+	// Prepend the request type to the returned data
+	request := make([]byte, len(ret)+1)
+	request[0] = requestType
+	copy(request[1:], ret)
+	*requests = append(*requests, request)
+}
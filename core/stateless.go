@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// ExecuteStateless runs a stateless execution of the given block based on the
+// state, code and ancestor headers contained in the witness, and validates the
+// result against the block header.
+//
+// The block's consensus fields are not verified, only the execution results,
+// so the caller is responsible for checking the header itself. The block rewards
+// are applied according to the proof-of-work or proof-of-stake rules, which
+// doesn't work for proof-of-authority networks.
+//
+// The computed state and receipt roots are returned alongside any error, even if
+// they don't match the ones in the block.
+func ExecuteStateless(config *params.ChainConfig, block *types.Block, witness *stateless.Witness) (common.Hash, common.Hash, error) {
+	// Make sure the witness is attached to the block's parent, the trustless
+	// source of the pre-state root, and that the ancestor headers are linked.
+	if len(witness.Headers) == 0 {
+		return common.Hash{}, common.Hash{}, errors.New("witness without parent header")
+	}
+	if hash := witness.Headers[0].Hash(); hash != block.ParentHash() {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("witness parent mismatch: have %x, want %x", hash, block.ParentHash())
+	}
+	for i := 1; i < len(witness.Headers); i++ {
+		if witness.Headers[i-1].ParentHash != witness.Headers[i].Hash() {
+			return common.Hash{}, common.Hash{}, fmt.Errorf("witness header %d not linked to its descendant", i)
+		}
+	}
+	// Create the ephemeral state from the witness content. Any state accessed
+	// during execution that is not part of the witness will fail to resolve.
+	db, err := state.New(witness.Root(), state.NewDatabaseWithConfig(witness.MakeHashDB(), triedb.HashDefaults), nil)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	chain := &statelessChain{
+		config:  config,
+		engine:  beacon.New(ethash.NewFaker()),
+		witness: witness,
+	}
+	processor := &StateProcessor{
+		config: config,
+		bc:     chain,
+		engine: chain.engine,
+	}
+	res, err := processor.Process(block, db, vm.Config{})
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	// Compute the roots produced by the execution for the caller, and validate
+	// them along with the rest of the execution results against the header.
+	stateRoot := db.IntermediateRoot(config.IsEIP158(block.Number()))
+	receiptRoot := types.DeriveSha(res.Receipts, trie.NewStackTrie(nil))
+
+	// State read failures are memoized instead of aborting the execution, make
+	// sure the witness was complete.
+	if err := db.Error(); err != nil {
+		return stateRoot, receiptRoot, fmt.Errorf("incomplete witness: %w", err)
+	}
+
+	validator := NewBlockValidator(config, nil, chain.engine)
+	if err := validator.ValidateState(block, db, res); err != nil {
+		return stateRoot, receiptRoot, err
+	}
+	return stateRoot, receiptRoot, nil
+}
+
+// statelessChain is the chain access used for stateless execution, serving the
+// ancestor headers from the witness.
+type statelessChain struct {
+	config  *params.ChainConfig
+	engine  consensus.Engine
+	witness *stateless.Witness
+}
+
+// Config retrieves the chain's fork configuration.
+func (c *statelessChain) Config() *params.ChainConfig { return c.config }
+
+// Engine retrieves the consensus engine used for the execution.
+func (c *statelessChain) Engine() consensus.Engine { return c.engine }
+
+// CurrentHeader retrieves the parent of the block being executed.
+func (c *statelessChain) CurrentHeader() *types.Header { return c.witness.Headers[0] }
+
+// GetHeader retrieves an ancestor header contained in the witness.
+func (c *statelessChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return c.witness.GetHeader(hash, number)
+}
+
+// GetHeaderByNumber retrieves an ancestor header contained in the witness by number.
+func (c *statelessChain) GetHeaderByNumber(number uint64) *types.Header {
+	for _, header := range c.witness.Headers {
+		if header.Number.Uint64() == number {
+			return header
+		}
+	}
+	return nil
+}
+
+// GetHeaderByHash retrieves an ancestor header contained in the witness by hash.
+func (c *statelessChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range c.witness.Headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+
+// GetTd is not available in stateless mode, as the witness carries no total
+// difficulty.
+func (c *statelessChain) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
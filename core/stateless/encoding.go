@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ExtWitness is a witness RLP and JSON encoding for transferring across clients.
+// The codes and trie nodes are sorted to make the encoding deterministic.
+type ExtWitness struct {
+	Headers []*types.Header `json:"headers"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []hexutil.Bytes `json:"state"`
+}
+
+// toExtWitness converts our internal witness representation to the consensus one.
+func (w *Witness) toExtWitness() *ExtWitness {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	ext := &ExtWitness{
+		Headers: w.Headers,
+		Codes:   make([]hexutil.Bytes, 0, len(w.Codes)),
+		State:   make([]hexutil.Bytes, 0, len(w.State)),
+	}
+	for code := range w.Codes {
+		ext.Codes = append(ext.Codes, []byte(code))
+	}
+	for node := range w.State {
+		ext.State = append(ext.State, []byte(node))
+	}
+	slices.SortFunc(ext.Codes, func(a, b hexutil.Bytes) int { return slices.Compare(a, b) })
+	slices.SortFunc(ext.State, func(a, b hexutil.Bytes) int { return slices.Compare(a, b) })
+	return ext
+}
+
+// fromExtWitness converts the consensus witness format into our internal one.
+func (w *Witness) fromExtWitness(ext *ExtWitness) error {
+	if len(ext.Headers) == 0 {
+		return errors.New("witness without parent header")
+	}
+	w.Headers = ext.Headers
+	w.Codes = make(map[string]struct{}, len(ext.Codes))
+	for _, code := range ext.Codes {
+		w.Codes[string(code)] = struct{}{}
+	}
+	w.State = make(map[string]struct{}, len(ext.State))
+	for _, node := range ext.State {
+		w.State[string(node)] = struct{}{}
+	}
+	return nil
+}
+
+// EncodeRLP serializes a witness as RLP.
+func (w *Witness) EncodeRLP(wr io.Writer) error {
+	return rlp.Encode(wr, w.toExtWitness())
+}
+
+// DecodeRLP decodes a witness from RLP.
+func (w *Witness) DecodeRLP(s *rlp.Stream) error {
+	var ext ExtWitness
+	if err := s.Decode(&ext); err != nil {
+		return err
+	}
+	return w.fromExtWitness(&ext)
+}
+
+// MarshalJSON serializes a witness as JSON.
+func (w *Witness) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.toExtWitness())
+}
+
+// UnmarshalJSON decodes a witness from JSON.
+func (w *Witness) UnmarshalJSON(input []byte) error {
+	var ext ExtWitness
+	if err := json.Unmarshal(input, &ext); err != nil {
+		return err
+	}
+	return w.fromExtWitness(&ext)
+}
@@ -0,0 +1,144 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateless implements the execution witness of a block, containing all
+// the data needed to execute it without access to the state database.
+package stateless
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeaderReader is an interface to pull in the headers of the ancestors of the
+// block the witness is collected for.
+type HeaderReader interface {
+	// GetHeader retrieves a block header from the database by hash and number.
+	GetHeader(hash common.Hash, number uint64) *types.Header
+}
+
+// Witness encompasses the state required to apply a set of transactions and
+// derive a post state/receipt root.
+type Witness struct {
+	Headers []*types.Header     // Past headers in reverse order (0=parent, 1=parent's-parent, etc). First *must* be set.
+	Codes   map[string]struct{} // Set of bytecodes ran or accessed
+	State   map[string]struct{} // Set of MPT state trie nodes (account and storage together)
+
+	chain HeaderReader // Chain reader to retrieve past headers from, nil if not collecting
+	lock  sync.Mutex   // Lock to allow concurrent state insertions
+}
+
+// NewWitness creates an empty witness ready for collection for the block with
+// the given header.
+func NewWitness(context *types.Header, chain HeaderReader) (*Witness, error) {
+	// When building witnesses, retrieve the parent header, which will *always*
+	// be included to act as a trustless pre-root hash container
+	if context.Number.Sign() == 0 {
+		return nil, errors.New("cannot collect witness of genesis block")
+	}
+	parent := chain.GetHeader(context.ParentHash, context.Number.Uint64()-1)
+	if parent == nil {
+		return nil, errors.New("failed to retrieve parent header")
+	}
+	return &Witness{
+		Headers: []*types.Header{parent},
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+		chain:   chain,
+	}, nil
+}
+
+// AddBlockHash adds a "blockhash" to the witness with the designated offset from
+// chain head. Under the hood, this method actually pulls in enough headers from
+// the chain to cover the block being added.
+func (w *Witness) AddBlockHash(number uint64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.chain == nil {
+		return
+	}
+	// Keep pulling in headers until this hash is populated
+	for int(w.Headers[0].Number.Uint64()-number) >= len(w.Headers) {
+		tail := w.Headers[len(w.Headers)-1]
+		header := w.chain.GetHeader(tail.ParentHash, tail.Number.Uint64()-1)
+		if header == nil {
+			return
+		}
+		w.Headers = append(w.Headers, header)
+	}
+}
+
+// AddCode adds a bytecode blob to the witness.
+func (w *Witness) AddCode(code []byte) {
+	if len(code) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Codes[string(code)] = struct{}{}
+}
+
+// AddState adds a trie node blob to the witness.
+func (w *Witness) AddState(node []byte) {
+	if len(node) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.State[string(node)] = struct{}{}
+}
+
+// Copy deep-copies the witness object. The copy is detached from the chain, it
+// cannot pull in further headers.
+func (w *Witness) Copy() *Witness {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return &Witness{
+		Headers: slices.Clone(w.Headers),
+		Codes:   maps.Clone(w.Codes),
+		State:   maps.Clone(w.State),
+	}
+}
+
+// Root returns the pre-state root from the first header.
+//
+// Note, this method will panic in case of a bad witness (but RLP decoding will
+// sanitize it and fail before that).
+func (w *Witness) Root() common.Hash {
+	return w.Headers[0].Root
+}
+
+// GetHeader retrieves one of the past headers contained in the witness.
+func (w *Witness) GetHeader(hash common.Hash, number uint64) *types.Header {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, header := range w.Headers {
+		if header.Number.Uint64() == number && header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Tests that a witness collected while processing a block is sufficient for
+// executing it statelessly, and that an incomplete witness is rejected.
+func TestStatelessExecution(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		engine   = beacon.New(ethash.NewFaker())
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr: {Balance: big.NewInt(params.Ether)},
+				// Stores blockhash(number-2) into slot 0 and increments slot 1
+				contract: {Code: []byte{
+					byte(vm.PUSH1), 0x02, byte(vm.NUMBER), byte(vm.SUB), byte(vm.BLOCKHASH), byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+					byte(vm.PUSH1), 0x01, byte(vm.SLOAD), byte(vm.PUSH1), 0x01, byte(vm.ADD), byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+					byte(vm.STOP),
+				}},
+			},
+		}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	// Run the chain in archive mode, so blocks can be generated on top of its
+	// state while it serves the hashes accessed via BLOCKHASH
+	db := rawdb.NewMemoryDatabase()
+	cacheConfig := DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	cacheConfig.TrieDirtyDisabled = true
+	// Attach a live tracer counting the traced transactions and call frames
+	var traced int
+	tracer := &tracing.Hooks{
+		OnTxStart: func(*tracing.VMContext, *types.Transaction, common.Address) { traced++ },
+		OnEnter:   func(int, byte, common.Address, common.Address, []byte, uint64, *big.Int) { traced++ },
+	}
+	chain, err := NewBlockChain(db, cacheConfig, gspec, nil, engine, vm.Config{Tracer: tracer}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	var blocks []*types.Block
+	for i := 0; i < 4; i++ {
+		generated, _ := GenerateChain(gspec.Config, chain.GetBlockByHash(chain.CurrentBlock().Hash()), engine, db, 1, func(_ int, b *BlockGen) {
+			call, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), contract, nil, 100000, b.BaseFee(), nil), signer, key)
+			b.AddTxWithChain(chain, call)
+			transfer, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), common.Address{byte(i + 1)}, big.NewInt(1), params.TxGas, b.BaseFee(), nil), signer, key)
+			b.AddTx(transfer)
+		})
+		blocks = append(blocks, generated...)
+
+		// Leave the last block to be executed with a witness
+		if i < 3 {
+			if _, err := chain.InsertChain(generated); err != nil {
+				t.Fatalf("failed to insert block %d: %v", i+1, err)
+			}
+		}
+	}
+	block := blocks[len(blocks)-1]
+	before := traced
+	witness, err := chain.GenerateWitness(block)
+	if err != nil {
+		t.Fatalf("failed to generate witness: %v", err)
+	}
+	// The replay of the block is not part of the chain, it must not be traced
+	if traced != before {
+		t.Fatalf("witness generation reported to the live tracer: %d events", traced-before)
+	}
+	// The hash of the grandparent was accessed, so it needs to be included
+	if len(witness.Headers) != 2 {
+		t.Fatalf("witness header count mismatch: have %d, want 2", len(witness.Headers))
+	}
+	if len(witness.Codes) != 1 {
+		t.Fatalf("witness code count mismatch: have %d, want 1", len(witness.Codes))
+	}
+	// Execute the block from the witness transferred across clients
+	blob, err := rlp.EncodeToBytes(witness)
+	if err != nil {
+		t.Fatalf("failed to encode witness: %v", err)
+	}
+	decoded := new(stateless.Witness)
+	if err := rlp.DecodeBytes(blob, decoded); err != nil {
+		t.Fatalf("failed to decode witness: %v", err)
+	}
+	stateRoot, receiptRoot, err := ExecuteStateless(gspec.Config, block, decoded)
+	if err != nil {
+		t.Fatalf("failed to execute statelessly: %v", err)
+	}
+	if stateRoot != block.Root() {
+		t.Errorf("state root mismatch: have %x, want %x", stateRoot, block.Root())
+	}
+	if receiptRoot != block.ReceiptHash() {
+		t.Errorf("receipt root mismatch: have %x, want %x", receiptRoot, block.ReceiptHash())
+	}
+	// Drop a trie node or the code from the witness and check execution fails
+	for node := range decoded.State {
+		incomplete := decoded.Copy()
+		delete(incomplete.State, node)
+		if _, _, err := ExecuteStateless(gspec.Config, block, incomplete); err == nil {
+			t.Fatalf("executed with missing trie node %x", node)
+		}
+	}
+	incomplete := decoded.Copy()
+	incomplete.Codes = make(map[string]struct{})
+	if _, _, err := ExecuteStateless(gspec.Config, block, incomplete); err == nil {
+		t.Fatal("executed with missing code")
+	}
+	// Check that the witness can't be used for a different block
+	if _, _, err := ExecuteStateless(gspec.Config, blocks[len(blocks)-2], decoded); err == nil {
+		t.Fatal("executed block with witness of its child")
+	}
+}
@@ -44,7 +44,11 @@ type txIndexer struct {
 	//  * 0: means the entire chain should be indexed
 	//  * N: means the latest N blocks [HEAD-N+1, HEAD] should be indexed
 	//       and all others shouldn't.
-	limit    uint64
+	limit uint64
+
+	// cutoff denotes the block number before which the chain history has
+	// been pruned, the transactions of these blocks can't be indexed.
+	cutoff   uint64
 	db       ethdb.Database
 	progress chan chan TxIndexProgress
 	term     chan chan struct{}
@@ -55,6 +59,7 @@ type txIndexer struct {
 func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	indexer := &txIndexer{
 		limit:    limit,
+		cutoff:   chain.HistoryPruningCutoff(),
 		db:       chain.db,
 		progress: make(chan chan TxIndexProgress),
 		term:     make(chan chan struct{}),
@@ -87,17 +92,19 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 	// and all blocks in the chain (part of them may from ancient store) are
 	// not indexed yet, index the chain according to the configured limit.
 	if tail == nil {
-		from := uint64(0)
+		from := indexer.cutoff
 		if indexer.limit != 0 && head >= indexer.limit {
-			from = head - indexer.limit + 1
+			from = max(from, head-indexer.limit+1)
+		}
+		if from <= head {
+			rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
 		}
-		rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
 		return
 	}
 	// The tail flag is existent (which means indexes in [tail, head] should be
 	// present), while the whole chain are requested for indexing.
 	if indexer.limit == 0 || head < indexer.limit {
-		if *tail > 0 {
+		if *tail > indexer.cutoff {
 			// It can happen when chain is rewound to a historical point which
 			// is even lower than the indexes tail, recap the indexing target
 			// to new head to avoid reading non-existent block bodies.
@@ -105,18 +112,19 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 			if end > head+1 {
 				end = head + 1
 			}
-			rawdb.IndexTransactions(indexer.db, 0, end, stop, true)
+			rawdb.IndexTransactions(indexer.db, indexer.cutoff, end, stop, true)
 		}
 		return
 	}
 	// The tail flag is existent, adjust the index range according to configured
-	// limit and the latest chain head.
-	if head-indexer.limit+1 < *tail {
+	// limit and the latest chain head. The pruned chain history is never indexed.
+	target := max(head-indexer.limit+1, indexer.cutoff)
+	if target < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(indexer.db, head-indexer.limit+1, *tail, stop, true)
+		rawdb.IndexTransactions(indexer.db, target, *tail, stop, true)
 	} else {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(indexer.db, *tail, head-indexer.limit+1, stop, false)
+		rawdb.UnindexTransactions(indexer.db, *tail, target, stop, false)
 	}
 }
 
@@ -180,6 +188,12 @@ func (indexer *txIndexer) report(head uint64, tail *uint64) TxIndexProgress {
 	if indexer.limit == 0 || total > head {
 		total = head + 1 // genesis included
 	}
+	// Blocks before the history pruning cutoff are not indexed.
+	if head+1 < indexer.cutoff {
+		total = 0
+	} else if pruned := head + 1 - indexer.cutoff; total > pruned {
+		total = pruned
+	}
 	var indexed uint64
 	if tail != nil {
 		indexed = head - *tail + 1
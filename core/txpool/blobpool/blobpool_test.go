@@ -222,7 +222,7 @@ func verifyPoolInternals(t *testing.T, pool *BlobPool) {
 			seen[tx.hash] = struct{}{}
 		}
 	}
-	for hash, id := range pool.lookup {
+	for hash, id := range pool.lookup.txIndex {
 		if _, ok := seen[hash]; !ok {
 			t.Errorf("lookup entry missing from transaction index: hash #%x, id %d", hash, id)
 		}
@@ -231,6 +231,32 @@ func verifyPoolInternals(t *testing.T, pool *BlobPool) {
 	for hash := range seen {
 		t.Errorf("indexed transaction hash #%x missing from lookup table", hash)
 	}
+	// Verify that all blobs in the index are present in the blob lookup and nothing more
+	blobs := make(map[common.Hash]map[common.Hash]struct{})
+	for _, txs := range pool.index {
+		for _, tx := range txs {
+			for _, vhash := range tx.vhashes {
+				if blobs[vhash] == nil {
+					blobs[vhash] = make(map[common.Hash]struct{})
+				}
+				blobs[vhash][tx.hash] = struct{}{}
+			}
+		}
+	}
+	for vhash, txs := range pool.lookup.blobIndex {
+		for txhash := range txs {
+			if _, ok := blobs[vhash][txhash]; !ok {
+				t.Errorf("blob lookup entry missing from transaction index: blob hash #%x, tx hash #%x", vhash, txhash)
+			}
+			delete(blobs[vhash], txhash)
+			if len(blobs[vhash]) == 0 {
+				delete(blobs, vhash)
+			}
+		}
+	}
+	for vhash := range blobs {
+		t.Errorf("indexed transaction blob hash #%x missing from blob lookup table", vhash)
+	}
 	// Verify that transactions are sorted per account and contain no nonce gaps
 	for addr, txs := range pool.index {
 		for i := 1; i < len(txs); i++ {
@@ -1313,6 +1339,88 @@ func TestAdd(t *testing.T) {
 	}
 }
 
+// Tests that blobs can be retrieved from the pool by their versioned hashes,
+// and that unknown hashes are reported as misses.
+func TestGetBlobs(t *testing.T) {
+	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(os.Stderr, log.LevelTrace, true)))
+
+	// Create a temporary folder for the persistent backend
+	storage, _ := os.MkdirTemp("", "blobpool-")
+	defer os.RemoveAll(storage)
+
+	os.MkdirAll(filepath.Join(storage, pendingTransactionStore), 0700)
+	store, _ := billy.Open(billy.Options{Path: filepath.Join(storage, pendingTransactionStore)}, newSlotter(), nil)
+
+	// Create a second blob, distinct from the empty one
+	var (
+		blob    = new(kzg4844.Blob)
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+	)
+	blob[1] = 0x01
+	commit, _ := kzg4844.BlobToCommitment(blob)
+	proof, _ := kzg4844.ComputeBlobProof(blob, commit)
+	vhash := kzg4844.CalcBlobHashV1(sha256.New(), &commit)
+
+	tx1 := makeTx(0, 1, 1, 1, key1)
+
+	blobtx := makeUnsignedTx(0, 1, 1, 1)
+	blobtx.BlobHashes = []common.Hash{vhash}
+	blobtx.Sidecar = &types.BlobTxSidecar{
+		Blobs:       []kzg4844.Blob{*blob},
+		Commitments: []kzg4844.Commitment{commit},
+		Proofs:      []kzg4844.Proof{proof},
+	}
+	tx2 := types.MustSignNewTx(key2, types.LatestSigner(testChainConfig), blobtx)
+
+	for _, tx := range []*types.Transaction{tx1, tx2} {
+		blob, _ := rlp.EncodeToBytes(tx)
+		store.Put(blob)
+	}
+	store.Close()
+
+	// Create a blob pool out of the pre-seeded data
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewDatabase(memorydb.New())), nil)
+	statedb.AddBalance(addr1, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.AddBalance(addr2, uint256.NewInt(1_000_000_000), tracing.BalanceChangeUnspecified)
+	statedb.Commit(0, true)
+
+	chain := &testBlockChain{
+		config:  testChainConfig,
+		basefee: uint256.NewInt(params.InitialBaseFee),
+		blobfee: uint256.NewInt(params.BlobTxMinBlobGasprice),
+		statedb: statedb,
+	}
+	pool := New(Config{Datadir: storage}, chain)
+	if err := pool.Init(1, chain.CurrentBlock(), makeAddressReserver()); err != nil {
+		t.Fatalf("failed to create blob pool: %v", err)
+	}
+	defer pool.Close()
+
+	verifyPoolInternals(t, pool)
+
+	// Request a mix of known, unknown and duplicate versioned hashes
+	var (
+		request = []common.Hash{vhash, {0x01}, emptyBlobVHash, vhash}
+		want    = []*kzg4844.Blob{blob, nil, emptyBlob, blob}
+		proofs  = []*kzg4844.Proof{&proof, nil, &emptyBlobProof, &proof}
+	)
+	haveBlobs, haveProofs := pool.GetBlobs(request)
+	if len(haveBlobs) != len(request) || len(haveProofs) != len(request) {
+		t.Fatalf("result length mismatch: have %d/%d, want %d", len(haveBlobs), len(haveProofs), len(request))
+	}
+	for i := range request {
+		if (haveBlobs[i] == nil) != (want[i] == nil) || (want[i] != nil && *haveBlobs[i] != *want[i]) {
+			t.Errorf("blob %d mismatch", i)
+		}
+		if (haveProofs[i] == nil) != (proofs[i] == nil) || (proofs[i] != nil && *haveProofs[i] != *proofs[i]) {
+			t.Errorf("proof %d mismatch", i)
+		}
+	}
+}
+
 // Benchmarks the time it takes to assemble the lazy pending transaction list
 // from the pool contents.
 func BenchmarkPoolPending100Mb(b *testing.B) { benchmarkPoolPending(b, 100_000_000) }
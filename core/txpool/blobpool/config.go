@@ -17,6 +17,7 @@
 package blobpool
 
 import (
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -25,6 +26,8 @@ type Config struct {
 	Datadir   string // Data directory containing the currently executable blobs
 	Datacap   uint64 // Soft-cap of database storage (hard cap is larger due to overhead)
 	PriceBump uint64 // Minimum price bump percentage to replace an already existing nonce
+
+	Policies []txpool.TxPolicy `toml:"-"` // Admission policies transactions must satisfy
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
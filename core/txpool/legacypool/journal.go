@@ -21,6 +21,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -43,15 +44,29 @@ func (*devNull) Close() error                      { return nil }
 
 // journal is a rotating log of transactions with the aim of storing locally
 // created transactions to allow non-executed ones to survive node restarts.
+// It consists of a periodically regenerated snapshot of the pool's content,
+// followed by the transactions added since.
+//
+// A buffered journal only collects the inserted transactions, leaving it to
+// the owner to write them to disk with flush, outside of any hot path. Flushing
+// and rotating must not be done concurrently.
 type journal struct {
-	path   string         // Filesystem path to store the transactions at
-	writer io.WriteCloser // Output stream to write new transactions into
+	kind     string         // Kind of the journaled transactions (local or remote), for logging
+	path     string         // Filesystem path to store the transactions at
+	writer   io.WriteCloser // Output stream to write new transactions into
+	buffered bool           // Whether inserted transactions are only written on flush
+
+	pending types.Transactions // Transactions inserted but not yet flushed
+	written int                // Number of transactions appended since the last rotation
+	lock    sync.Mutex         // Protects the writer, the pending buffer and the counter
 }
 
 // newTxJournal creates a new transaction journal to
-func newTxJournal(path string) *journal {
+func newTxJournal(kind string, path string, buffered bool) *journal {
 	return &journal{
-		path: path,
+		kind:     kind,
+		path:     path,
+		buffered: buffered,
 	}
 }
 
@@ -70,8 +85,8 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 	defer input.Close()
 
 	// Temporarily discard any journal additions (don't double add on load)
-	journal.writer = new(devNull)
-	defer func() { journal.writer = nil }()
+	journal.setWriter(new(devNull))
+	defer journal.setWriter(nil)
 
 	// Inject all transactions from the journal into the pool
 	stream := rlp.NewStream(input, 0)
@@ -112,26 +127,74 @@ func (journal *journal) load(add func([]*types.Transaction) []error) error {
 			batch = batch[:0]
 		}
 	}
-	log.Info("Loaded local transaction journal", "transactions", total, "dropped", dropped)
+	log.Info("Loaded transaction journal", "kind", journal.kind, "transactions", total, "dropped", dropped)
 
 	return failure
 }
 
-// insert adds the specified transaction to the local disk journal.
+// setWriter replaces the output stream of the journal.
+func (journal *journal) setWriter(writer io.WriteCloser) {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	journal.writer = writer
+}
+
+// insert adds the specified transaction to the disk journal, or to the pending
+// buffer if the journal is buffered.
 func (journal *journal) insert(tx *types.Transaction) error {
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
 	if journal.writer == nil {
 		return errNoActiveJournal
 	}
+	if journal.buffered {
+		journal.pending = append(journal.pending, tx)
+		return nil
+	}
 	if err := rlp.Encode(journal.writer, tx); err != nil {
 		return err
 	}
+	journal.written++
 	return nil
 }
 
+// flush writes the buffered transactions to the disk journal, returning the
+// number of transactions appended since the last rotation.
+func (journal *journal) flush() (int, error) {
+	journal.lock.Lock()
+	txs, writer := journal.pending, journal.writer
+	journal.pending = nil
+	journal.lock.Unlock()
+
+	// The disk write is done without holding the lock, so the inserts are
+	// never blocked on it. The writer is only replaced on rotation, which is
+	// never done concurrently.
+	var err error
+	if len(txs) > 0 && writer != nil {
+		for _, tx := range txs {
+			if err = rlp.Encode(writer, tx); err != nil {
+				break
+			}
+		}
+	}
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	journal.written += len(txs)
+	return journal.written, err
+}
+
 // rotate regenerates the transaction journal based on the current contents of
 // the transaction pool.
 func (journal *journal) rotate(all map[common.Address]types.Transactions) error {
-	// Close the current journal (if any is open)
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
+
+	// Close the current journal (if any is open). The buffered transactions
+	// are all contained in the new snapshot, or already dropped from the pool.
+	journal.pending, journal.written = nil, 0
 	if journal.writer != nil {
 		if err := journal.writer.Close(); err != nil {
 			return err
@@ -169,17 +232,22 @@ func (journal *journal) rotate(all map[common.Address]types.Transactions) error
 	if len(all) == 0 {
 		logger = log.Debug
 	}
-	logger("Regenerated local transaction journal", "transactions", journaled, "accounts", len(all))
+	logger("Regenerated transaction journal", "kind", journal.kind, "transactions", journaled, "accounts", len(all))
 
 	return nil
 }
 
 // close flushes the transaction journal contents to disk and closes the file.
 func (journal *journal) close() error {
-	var err error
+	_, err := journal.flush()
+
+	journal.lock.Lock()
+	defer journal.lock.Unlock()
 
 	if journal.writer != nil {
-		err = journal.writer.Close()
+		if cerr := journal.writer.Close(); err == nil {
+			err = cerr
+		}
 		journal.writer = nil
 	}
 	return err
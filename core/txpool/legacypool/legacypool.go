@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -61,8 +62,9 @@ var (
 )
 
 var (
-	evictionInterval    = time.Minute     // Time interval to check for evictable transactions
-	statsReportInterval = 8 * time.Second // Time interval to report transaction pool stats
+	evictionInterval     = time.Minute     // Time interval to check for evictable transactions
+	statsReportInterval  = 8 * time.Second // Time interval to report transaction pool stats
+	journalFlushInterval = time.Second     // Time interval to write the buffered remote transactions to disk
 )
 
 var (
@@ -124,7 +126,9 @@ type Config struct {
 	Locals    []common.Address // Addresses that should be treated by default as local
 	NoLocals  bool             // Whether local transaction handling should be disabled
 	Journal   string           // Journal of local transactions to survive node restarts
-	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
+	Rejournal time.Duration    // Time interval to regenerate the transaction journals
+
+	RemoteJournal string // Journal of remote transactions to survive node restarts, empty to disable
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -135,6 +139,8 @@ type Config struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Policies []txpool.TxPolicy `toml:"-"` // Admission policies transactions must satisfy
 }
 
 // DefaultConfig contains the default configurations for the transaction pool.
@@ -214,6 +220,7 @@ type LegacyPool struct {
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *journal    // Journal of local transaction to back up to disk
+	remotes *journal    // Journal of remote transactions to back up to disk
 
 	reserve txpool.AddressReserver       // Address reserver to ensure exclusivity across subpools
 	pending map[common.Address]*list     // All currently processable transactions
@@ -268,7 +275,10 @@ func New(config Config, chain BlockChain) *LegacyPool {
 	pool.priced = newPricedList(pool.all)
 
 	if !config.NoLocals && config.Journal != "" {
-		pool.journal = newTxJournal(config.Journal)
+		pool.journal = newTxJournal("local", config.Journal, false)
+	}
+	if config.RemoteJournal != "" {
+		pool.remotes = newTxJournal("remote", config.RemoteJournal, true)
 	}
 	return pool
 }
@@ -277,7 +287,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 // pool, specifically, whether it is a Legacy, AccessList or Dynamic transaction.
 func (pool *LegacyPool) Filter(tx *types.Transaction) bool {
 	switch tx.Type() {
-	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, types.SetCodeTxType:
 		return true
 	default:
 		return false
@@ -323,6 +333,19 @@ func (pool *LegacyPool) Init(gasTip uint64, head *types.Header, reserve txpool.A
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If remote transaction journaling is enabled, load from disk. The
+	// transactions are revalidated against the current head, dropping the
+	// ones included or invalidated since the journal was written.
+	if pool.remotes != nil {
+		if err := pool.remotes.load(pool.addRemotesSync); err != nil {
+			log.Warn("Failed to load remote transaction journal", "err", err)
+		}
+		pool.mu.RLock()
+		if err := pool.remotes.rotate(pool.remote()); err != nil {
+			log.Warn("Failed to rotate remote transaction journal", "err", err)
+		}
+		pool.mu.RUnlock()
+	}
 	pool.wg.Add(1)
 	go pool.loop()
 	return nil
@@ -341,10 +364,12 @@ func (pool *LegacyPool) loop() {
 		report  = time.NewTicker(statsReportInterval)
 		evict   = time.NewTicker(evictionInterval)
 		journal = time.NewTicker(pool.config.Rejournal)
+		flush   = time.NewTicker(journalFlushInterval)
 	)
 	defer report.Stop()
 	defer evict.Stop()
 	defer journal.Stop()
+	defer flush.Stop()
 
 	// Notify tests that the init phase is done
 	close(pool.initDoneCh)
@@ -385,7 +410,7 @@ func (pool *LegacyPool) loop() {
 			}
 			pool.mu.Unlock()
 
-		// Handle local transaction journal rotation
+		// Handle transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
 				pool.mu.Lock()
@@ -394,10 +419,39 @@ func (pool *LegacyPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+			if pool.remotes != nil {
+				pool.rotateRemotes()
+			}
+
+		// Handle remote transaction journal flushes
+		case <-flush.C:
+			if pool.remotes == nil {
+				continue
+			}
+			written, err := pool.remotes.flush()
+			if err != nil {
+				log.Warn("Failed to flush remote tx journal", "err", err)
+			}
+			// Regenerate the journal early if more transactions were appended
+			// than the pool can hold, bounding its size between rotations.
+			if written > int(pool.config.GlobalSlots+pool.config.GlobalQueue) {
+				pool.rotateRemotes()
+			}
 		}
 	}
 }
 
+// rotateRemotes regenerates the remote transaction journal based on the current
+// contents of the pool.
+func (pool *LegacyPool) rotateRemotes() {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if err := pool.remotes.rotate(pool.remote()); err != nil {
+		log.Warn("Failed to rotate remote tx journal", "err", err)
+	}
+}
+
 // Close terminates the transaction pool.
 func (pool *LegacyPool) Close() error {
 	// Terminate the pool reorger and return
@@ -407,6 +461,9 @@ func (pool *LegacyPool) Close() error {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.remotes != nil {
+		pool.remotes.close()
+	}
 	log.Info("Transaction pool stopped")
 	return nil
 }
@@ -599,6 +656,24 @@ func (pool *LegacyPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce. The returned transaction set is a copy and can be
+// freely modified by calling code.
+func (pool *LegacyPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], queued.Flatten()...)
+		}
+	}
+	return txs
+}
+
 // validateTxBasics checks whether a transaction is valid according to the consensus
 // rules, but does not check state-dependent validation such as sufficient balance.
 // This check is meant as an early check which only needs to be performed once,
@@ -609,7 +684,8 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
-			1<<types.DynamicFeeTxType,
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
 		MaxSize: txMaxSize,
 		MinTip:  pool.gasTip.Load().ToBig(),
 	}
@@ -653,6 +729,7 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 			}
 			return nil
 		},
+		Policies: pool.config.Policies,
 	}
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
@@ -881,14 +958,22 @@ func (pool *LegacyPool) enqueueTx(hash common.Hash, tx *types.Transaction, local
 }
 
 // journalTx adds the specified transaction to the local disk journal if it is
-// deemed to have been sent from a local account.
+// deemed to have been sent from a local account, or to the remote one otherwise.
 func (pool *LegacyPool) journalTx(from common.Address, tx *types.Transaction) {
-	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	if pool.locals.contains(from) {
+		if pool.journal == nil {
+			return
+		}
+		if err := pool.journal.insert(tx); err != nil {
+			log.Warn("Failed to journal local transaction", "err", err)
+		}
+		return
+	}
+	if pool.remotes == nil {
 		return
 	}
-	if err := pool.journal.insert(tx); err != nil {
-		log.Warn("Failed to journal local transaction", "err", err)
+	if err := pool.remotes.insert(tx); err != nil {
+		log.Warn("Failed to journal remote transaction", "err", err)
 	}
 }
 
@@ -1071,6 +1156,12 @@ func (pool *LegacyPool) Get(hash common.Hash) *types.Transaction {
 	return tx
 }
 
+// GetBlobs is not supported by the legacy transaction pool, it is just here to
+// implement the txpool.SubPool interface.
+func (pool *LegacyPool) GetBlobs(vhashes []common.Hash) ([]*kzg4844.Blob, []*kzg4844.Proof) {
+	return nil, nil
+}
+
 // get returns a transaction if it is contained in the pool and nil otherwise.
 func (pool *LegacyPool) get(hash common.Hash) *types.Transaction {
 	return pool.all.Get(hash)
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -160,12 +161,16 @@ func setupPool() (*LegacyPool, *ecdsa.PrivateKey) {
 }
 
 func setupPoolWithConfig(config *params.ChainConfig) (*LegacyPool, *ecdsa.PrivateKey) {
+	return setupPoolWithPoolConfig(config, testTxPoolConfig)
+}
+
+func setupPoolWithPoolConfig(config *params.ChainConfig, poolConfig Config) (*LegacyPool, *ecdsa.PrivateKey) {
 	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
 	blockchain := newTestBlockChain(config, 10000000, statedb, new(event.Feed))
 
 	key, _ := crypto.GenerateKey()
-	pool := New(testTxPoolConfig, blockchain)
-	if err := pool.Init(testTxPoolConfig.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
+	pool := New(poolConfig, blockchain)
+	if err := pool.Init(poolConfig.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver()); err != nil {
 		panic(err)
 	}
 	// wait for the pool to initialize
@@ -356,6 +361,71 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that the admission policies are consulted both for new transactions and
+// for the ones reinjected after a reorg, and that rejections are typed errors.
+func TestTransactionPolicies(t *testing.T) {
+	t.Parallel()
+
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	var (
+		from      = crypto.PubkeyToAddress(key.PublicKey)
+		otherFrom = crypto.PubkeyToAddress(other.PublicKey)
+		reinject  atomic.Bool
+		config    = testTxPoolConfig
+	)
+	// The policies are installed before the pool starts, the last one is only
+	// toggled on to exercise the reinjection path.
+	config.Policies = []txpool.TxPolicy{
+		txpool.NewSenderAllowlist([]common.Address{from}),
+		txpool.NewFuncPolicy("external", func(tx *types.Transaction, from common.Address) error {
+			if tx.Gas() > 200000 {
+				return errors.New("gas limit too high")
+			}
+			return nil
+		}),
+		txpool.NewFuncPolicy("recipients", func(tx *types.Transaction, from common.Address) error {
+			if reinject.Load() {
+				return errors.New("recipient not allowed")
+			}
+			return nil
+		}),
+	}
+	pool, _ := setupPoolWithPoolConfig(params.TestChainConfig, config)
+	defer pool.Close()
+
+	testAddBalance(pool, from, big.NewInt(1000000000))
+	testAddBalance(pool, otherFrom, big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add allowed transaction: %v", err)
+	}
+	check := func(err error, policy string) {
+		t.Helper()
+		if !errors.Is(err, txpool.ErrPolicyRejected) {
+			t.Fatalf("want %v, have %v", txpool.ErrPolicyRejected, err)
+		}
+		var perr *txpool.PolicyError
+		if !errors.As(err, &perr) || perr.Policy != policy {
+			t.Fatalf("wrong policy error: %v", err)
+		}
+	}
+	check(pool.addRemote(transaction(0, 100000, other)), "senders")
+	check(pool.addRemote(transaction(1, 300000, key)), "external")
+
+	// Reinjected transactions are subject to the policies too
+	reinject.Store(true)
+
+	pool.mu.Lock()
+	errs, _ := pool.addTxsLocked([]*types.Transaction{transaction(1, 100000, key)}, false)
+	pool.mu.Unlock()
+	check(errs[0], "recipients")
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("transactions mismatched: have %d/%d, want %d/%d", pending, queued, 1, 0)
+	}
+}
+
 func TestQueue(t *testing.T) {
 	t.Parallel()
 
@@ -2458,6 +2528,127 @@ func testJournaling(t *testing.T, nolocals bool) {
 	pool.Close()
 }
 
+// Tests that remote transactions are persisted into their own journal, both
+// through the periodic snapshot and the append log, and that they are
+// revalidated against the new head when loaded.
+func TestRemoteJournaling(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.Journal = ""
+	config.RemoteJournal = filepath.Join(t.TempDir(), "remotes.rlp")
+	config.Rejournal = time.Hour
+
+	pool := New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	first, _ := crypto.GenerateKey()
+	second, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(first.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(second.PublicKey), big.NewInt(1000000000))
+
+	// Add a few remote transactions, ending up in the append log only
+	txs := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), first),
+		pricedTransaction(1, 100000, big.NewInt(1), first),
+		pricedTransaction(2, 100000, big.NewInt(1), first),
+		pricedTransaction(0, 100000, big.NewInt(1), second),
+	}
+	for i, err := range pool.addRemotesSync(txs) {
+		if err != nil {
+			t.Fatalf("failed to add remote transaction %d: %v", i, err)
+		}
+	}
+	pool.Close()
+
+	// Restart the pool with the first transaction included in the meantime,
+	// and ensure the rest of them survive
+	statedb.SetNonce(crypto.PubkeyToAddress(first.PublicKey), 1)
+	blockchain = newTestBlockChain(params.TestChainConfig, 1000000, statedb, new(event.Feed))
+
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("transactions mismatched: have %d/%d, want %d/%d", pending, queued, 3, 0)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Add a new transaction on top of the regenerated snapshot and restart
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1), second)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	pool.Close()
+
+	pool = New(config, blockchain)
+	pool.Init(config.PriceLimit, blockchain.CurrentBlock(), makeAddressReserver())
+	defer pool.Close()
+
+	if pending, queued := pool.Stats(); pending != 4 || queued != 0 {
+		t.Fatalf("transactions mismatched: have %d/%d, want %d/%d", pending, queued, 4, 0)
+	}
+	for _, tx := range txs[1:] {
+		if pool.Get(tx.Hash()) == nil {
+			t.Errorf("transaction %x missing after restart", tx.Hash())
+		}
+	}
+	if pool.Get(txs[0].Hash()) != nil {
+		t.Errorf("included transaction %x restored", txs[0].Hash())
+	}
+}
+
+// Tests that the remote journal only writes the inserted transactions to disk
+// when flushed, and that rotations reset the count of appended transactions.
+func TestRemoteJournalBuffering(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "remotes.rlp")
+	journal := newTxJournal("remote", path, true)
+	if err := journal.rotate(nil); err != nil {
+		t.Fatalf("failed to rotate journal: %v", err)
+	}
+	defer journal.close()
+
+	stored := func() int {
+		var count int
+		newTxJournal("remote", path, false).load(func(txs []*types.Transaction) []error {
+			count += len(txs)
+			return make([]error, len(txs))
+		})
+		return count
+	}
+	key, _ := crypto.GenerateKey()
+	txs := types.Transactions{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+	for _, tx := range txs {
+		if err := journal.insert(tx); err != nil {
+			t.Fatalf("failed to insert transaction: %v", err)
+		}
+	}
+	if n := stored(); n != 0 {
+		t.Fatalf("unflushed transactions written: have %d, want 0", n)
+	}
+	if written, err := journal.flush(); err != nil || written != len(txs) {
+		t.Fatalf("failed to flush journal: written %d, err %v", written, err)
+	}
+	if n := stored(); n != len(txs) {
+		t.Fatalf("flushed transactions mismatch: have %d, want %d", n, len(txs))
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	if err := journal.rotate(map[common.Address]types.Transactions{from: txs[:1]}); err != nil {
+		t.Fatalf("failed to rotate journal: %v", err)
+	}
+	if written, err := journal.flush(); err != nil || written != 0 {
+		t.Fatalf("appended transactions not reset on rotation: written %d, err %v", written, err)
+	}
+	if n := stored(); n != 1 {
+		t.Fatalf("rotated transactions mismatch: have %d, want 1", n)
+	}
+}
+
 // TestStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestStatusCheck(t *testing.T) {
@@ -0,0 +1,181 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpool
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// TxPolicy is an admission rule consulted by the subpools before accepting a
+// transaction, both when it's submitted and when it's reinjected after a reorg.
+type TxPolicy interface {
+	// Name returns the identifier of the policy, used in errors and metrics.
+	Name() string
+
+	// Check returns an error if the transaction from the given sender is not
+	// allowed into the pool.
+	Check(tx *types.Transaction, from common.Address) error
+}
+
+// PolicyError is returned if a transaction is rejected by an admission policy.
+// It is surfaced over RPC with a dedicated error code and the policy details.
+type PolicyError struct {
+	Policy string // Name of the policy rejecting the transaction
+	Reason string // Reason given by the policy
+}
+
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v: %s: %s", ErrPolicyRejected, e.Policy, e.Reason)
+}
+
+// Unwrap returns ErrPolicyRejected, allowing callers to use errors.Is.
+func (e *PolicyError) Unwrap() error { return ErrPolicyRejected }
+
+// ErrorCode returns the JSON-RPC error code of policy rejections.
+func (e *PolicyError) ErrorCode() int { return -32003 }
+
+// ErrorData returns the details of the rejection to be sent over JSON-RPC.
+func (e *PolicyError) ErrorData() interface{} {
+	return map[string]string{"policy": e.Policy, "reason": e.Reason}
+}
+
+// ValidatePolicies checks the transaction against the given admission policies,
+// returning a PolicyError from the first one rejecting it.
+func ValidatePolicies(policies []TxPolicy, tx *types.Transaction, from common.Address) error {
+	for _, policy := range policies {
+		err := policy.Check(tx, from)
+		if err == nil {
+			continue
+		}
+		metrics.GetOrRegisterMeter("txpool/policy/"+policy.Name()+"/rejected", nil).Mark(1)
+
+		var perr *PolicyError
+		if errors.As(err, &perr) {
+			return perr
+		}
+		return &PolicyError{Policy: policy.Name(), Reason: err.Error()}
+	}
+	return nil
+}
+
+// senderAllowlist is a policy admitting only the transactions sent by a given
+// set of accounts.
+type senderAllowlist struct {
+	allowed map[common.Address]struct{}
+}
+
+// NewSenderAllowlist creates a policy rejecting the transactions whose sender
+// is not in the given set.
+func NewSenderAllowlist(senders []common.Address) TxPolicy {
+	allowed := make(map[common.Address]struct{}, len(senders))
+	for _, addr := range senders {
+		allowed[addr] = struct{}{}
+	}
+	return &senderAllowlist{allowed: allowed}
+}
+
+func (p *senderAllowlist) Name() string { return "senders" }
+
+func (p *senderAllowlist) Check(tx *types.Transaction, from common.Address) error {
+	if _, ok := p.allowed[from]; !ok {
+		return fmt.Errorf("sender %s not allowed", from)
+	}
+	return nil
+}
+
+// recipientAllowlist is a policy admitting only the transactions calling a given
+// set of accounts.
+type recipientAllowlist struct {
+	allowed map[common.Address]struct{}
+}
+
+// NewRecipientAllowlist creates a policy rejecting the transactions whose
+// recipient is not in the given set. Contract creations are not affected.
+func NewRecipientAllowlist(recipients []common.Address) TxPolicy {
+	allowed := make(map[common.Address]struct{}, len(recipients))
+	for _, addr := range recipients {
+		allowed[addr] = struct{}{}
+	}
+	return &recipientAllowlist{allowed: allowed}
+}
+
+func (p *recipientAllowlist) Name() string { return "recipients" }
+
+func (p *recipientAllowlist) Check(tx *types.Transaction, from common.Address) error {
+	to := tx.To()
+	if to == nil {
+		return nil
+	}
+	if _, ok := p.allowed[*to]; !ok {
+		return fmt.Errorf("recipient %s not allowed", *to)
+	}
+	return nil
+}
+
+// selectorDenylist is a policy rejecting the calls to a given set of function
+// selectors.
+type selectorDenylist struct {
+	denied map[[4]byte]struct{}
+}
+
+// NewSelectorDenylist creates a policy rejecting the transactions whose calldata
+// starts with one of the given 4-byte function selectors. Contract creations are
+// not affected.
+func NewSelectorDenylist(selectors [][4]byte) TxPolicy {
+	denied := make(map[[4]byte]struct{}, len(selectors))
+	for _, sel := range selectors {
+		denied[sel] = struct{}{}
+	}
+	return &selectorDenylist{denied: denied}
+}
+
+func (p *selectorDenylist) Name() string { return "selectors" }
+
+func (p *selectorDenylist) Check(tx *types.Transaction, from common.Address) error {
+	data := tx.Data()
+	if tx.To() == nil || len(data) < 4 {
+		return nil
+	}
+	if _, ok := p.denied[[4]byte(data[:4])]; ok {
+		return fmt.Errorf("selector %s not allowed", hexutil.Encode(data[:4]))
+	}
+	return nil
+}
+
+// funcPolicy is a policy delegating the decision to a callback.
+type funcPolicy struct {
+	name  string
+	check func(tx *types.Transaction, from common.Address) error
+}
+
+// NewFuncPolicy creates a policy delegating the admission decision to the given
+// function, e.g. to consult an external service.
+func NewFuncPolicy(name string, check func(tx *types.Transaction, from common.Address) error) TxPolicy {
+	return &funcPolicy{name: name, check: check}
+}
+
+func (p *funcPolicy) Name() string { return p.name }
+
+func (p *funcPolicy) Check(tx *types.Transaction, from common.Address) error {
+	return p.check(tx, from)
+}
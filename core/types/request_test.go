@@ -0,0 +1,68 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCalcRequestsHash(t *testing.T) {
+	if have := CalcRequestsHash(nil); have != EmptyRequestsHash {
+		t.Fatalf("wrong empty requests hash: have %x, want %x", have, EmptyRequestsHash)
+	}
+	// Requests without data must not contribute to the hash
+	if have := CalcRequestsHash([][]byte{{DepositRequestType}, {WithdrawalRequestType}}); have != EmptyRequestsHash {
+		t.Fatalf("wrong requests hash with empty requests: have %x, want %x", have, EmptyRequestsHash)
+	}
+	var (
+		reqs = [][]byte{{DepositRequestType, 0x01}, {ConsolidationRequestType, 0x02}}
+		want = CalcRequestsHash(reqs)
+	)
+	if have := CalcRequestsHash(append([][]byte{{WithdrawalRequestType}}, reqs...)); have != want {
+		t.Fatalf("wrong requests hash: have %x, want %x", have, want)
+	}
+}
+
+func TestDepositLogToRequest(t *testing.T) {
+	// Assemble the ABI encoding of a DepositEvent with distinguishable fields
+	var (
+		pubkey    = bytes.Repeat([]byte{0x11}, 48)
+		cred      = bytes.Repeat([]byte{0x22}, 32)
+		amount    = bytes.Repeat([]byte{0x33}, 8)
+		signature = bytes.Repeat([]byte{0x44}, 96)
+		index     = bytes.Repeat([]byte{0x55}, 8)
+		data      = make([]byte, 576)
+	)
+	copy(data[192:], pubkey)
+	copy(data[288:], cred)
+	copy(data[352:], amount)
+	copy(data[416:], signature)
+	copy(data[544:], index)
+
+	request, err := DepositLogToRequest(data)
+	if err != nil {
+		t.Fatalf("failed to parse deposit log: %v", err)
+	}
+	want := bytes.Join([][]byte{pubkey, cred, amount, signature, index}, nil)
+	if !bytes.Equal(request, want) {
+		t.Fatalf("wrong deposit request: have %x, want %x", request, want)
+	}
+	if _, err := DepositLogToRequest(data[1:]); err == nil {
+		t.Fatal("expected error for truncated deposit log")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append(common.CopyBytes(DelegationPrefix[:2]), addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+		if tt.want != nil && got != *tt.want {
+			t.Fatalf("wrong delegation address, got %s, want %s", got.Hex(), tt.want.Hex())
+		}
+	}
+}
+
+func TestSetCodeTxCoding(t *testing.T) {
+	var (
+		key, _            = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		authKey, authAddr = defaultTestKey()
+		signer            = NewPragueSigner(big.NewInt(1))
+		recipient         = common.HexToAddress("095e7baea6a6c7c4c2dfeb977efac326af552d87")
+	)
+	auth, err := SignSetCode(authKey, SetCodeAuthorization{
+		ChainID: *uint256.NewInt(1),
+		Address: recipient,
+		Nonce:   7,
+	})
+	if err != nil {
+		t.Fatalf("could not sign authorization: %v", err)
+	}
+	if have, err := auth.Authority(); err != nil || have != authAddr {
+		t.Fatalf("wrong authority, have %v (%v), want %v", have, err, authAddr)
+	}
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:    uint256.NewInt(1),
+		Nonce:      1,
+		GasTipCap:  uint256.NewInt(2),
+		GasFeeCap:  uint256.NewInt(10),
+		Gas:        123457,
+		To:         recipient,
+		Value:      uint256.NewInt(3),
+		Data:       []byte("abcdef"),
+		AccessList: AccessList{{Address: recipient, StorageKeys: []common.Hash{{0}}}},
+		AuthList:   []SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	for _, codec := range []func(*Transaction) (*Transaction, error){encodeDecodeBinary, encodeDecodeJSON} {
+		parsed, err := codec(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := assertEqual(parsed, tx); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(parsed.SetCodeAuthorizations(), tx.SetCodeAuthorizations()) {
+			t.Fatal("authorization list wrong")
+		}
+		from, err := Sender(signer, parsed)
+		if err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatalf("wrong sender, have %v (%v)", from, err)
+		}
+	}
+	// The authority must not be recovered from a tampered authorization
+	auth.Nonce++
+	if have, _ := auth.Authority(); have == authAddr {
+		t.Fatal("authority recovered from a modified authorization")
+	}
+}
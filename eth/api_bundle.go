@@ -0,0 +1,76 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+// BundleAPI provides an API to submit transaction bundles to the miner.
+type BundleAPI struct {
+	e *Ethereum
+}
+
+// NewBundleAPI creates a new BundleAPI instance.
+func NewBundleAPI(e *Ethereum) *BundleAPI {
+	return &BundleAPI{e}
+}
+
+// SendBundleArgs represents the arguments of mev_sendBundle.
+type SendBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	MinTimestamp      *uint64         `json:"minTimestamp"`
+	MaxTimestamp      *uint64         `json:"maxTimestamp"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
+// SendBundleResult is the response of mev_sendBundle.
+type SendBundleResult struct {
+	BundleHash common.Hash `json:"bundleHash"`
+}
+
+// SendBundle submits an ordered list of signed transactions to be included
+// atomically at the top of the target block.
+func (api *BundleAPI) SendBundle(args SendBundleArgs) (*SendBundleResult, error) {
+	bundle := &miner.Bundle{
+		BlockNumber:       uint64(args.BlockNumber),
+		RevertingTxHashes: args.RevertingTxHashes,
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *args.MaxTimestamp
+	}
+	for i, raw := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %v", i, err)
+		}
+		bundle.Txs = append(bundle.Txs, tx)
+	}
+	if err := api.e.Miner().SendBundle(bundle); err != nil {
+		return nil, err
+	}
+	return &SendBundleResult{BundleHash: bundle.Hash()}, nil
+}
@@ -19,6 +19,7 @@ package eth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"runtime"
@@ -29,7 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -81,9 +82,8 @@ type Ethereum struct {
 	engine         consensus.Engine
 	accountManager *accounts.Manager
 
-	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
-	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
-	closeBloomHandler chan struct{}
+	logIndexer  *logindex.Indexer    // Log indexer operating during block imports, nil if disabled
+	statePruner *pruner.OnlinePruner // Background pruner of the stale state, nil if disabled
 
 	APIBackend *EthAPIBackend
 
@@ -151,18 +151,15 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		networkID = chainConfig.ChainID.Uint64()
 	}
 	eth := &Ethereum{
-		config:            config,
-		chainDb:           chainDb,
-		eventMux:          stack.EventMux(),
-		accountManager:    stack.AccountManager(),
-		engine:            engine,
-		closeBloomHandler: make(chan struct{}),
-		networkID:         networkID,
-		gasPrice:          config.Miner.GasPrice,
-		bloomRequests:     make(chan chan *bloombits.Retrieval),
-		bloomIndexer:      core.NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
-		p2pServer:         stack.Server(),
-		shutdownTracker:   shutdowncheck.NewShutdownTracker(chainDb),
+		config:          config,
+		chainDb:         chainDb,
+		eventMux:        stack.EventMux(),
+		accountManager:  stack.AccountManager(),
+		engine:          engine,
+		networkID:       networkID,
+		gasPrice:        config.Miner.GasPrice,
+		p2pServer:       stack.Server(),
+		shutdownTracker: shutdowncheck.NewShutdownTracker(chainDb),
 	}
 	bcVersion := rawdb.ReadDatabaseVersion(chainDb)
 	var dbVer = "<nil>"
@@ -194,6 +191,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
+			StateIndexing:       config.StateIndexing,
 			StateScheme:         scheme,
 		}
 	)
@@ -224,7 +222,23 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if err != nil {
 		return nil, err
 	}
-	eth.bloomIndexer.Start(eth.blockchain)
+	if !config.LogNoHistory {
+		eth.logIndexer = logindex.NewIndexer(chainDb, eth.blockchain, config.LogHistory)
+	}
+	if config.StatePruning {
+		if scheme != rawdb.HashScheme {
+			return nil, errors.New("state pruning is only supported in hash-based scheme")
+		}
+		eth.statePruner, err = pruner.NewOnlinePruner(chainDb, eth.blockchain, pruner.OnlineConfig{
+			Datadir:   stack.ResolvePath(""),
+			BloomSize: config.StatePruningBloomSize,
+			Throttle:  config.StatePruningThrottle,
+			Interval:  config.StatePruningInterval,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if config.BlobPool.Datadir != "" {
 		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
@@ -234,6 +248,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.RemoteJournal != "" {
+		config.TxPool.RemoteJournal = stack.ResolvePath(config.TxPool.RemoteJournal)
+	}
 	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
 
 	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
@@ -256,7 +273,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	eth.miner = miner.New(eth, config.Miner, eth.engine)
+	eth.miner, err = miner.New(eth, config.Miner, eth.engine)
+	if err != nil {
+		return nil, err
+	}
 	eth.miner.SetExtra(makeExtraData(config.Miner.ExtraData))
 
 	eth.APIBackend = &EthAPIBackend{stack.Config().ExtRPCEnabled(), stack.Config().AllowUnprotectedTxs, eth, nil}
@@ -319,6 +339,13 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Bundles are only accepted if explicitly enabled
+	if s.config.Miner.Bundles {
+		apis = append(apis, rpc.API{
+			Namespace: "mev",
+			Service:   NewBundleAPI(s),
+		})
+	}
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -357,7 +384,7 @@ func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downlo
 func (s *Ethereum) Synced() bool                       { return s.handler.synced.Load() }
 func (s *Ethereum) SetSynced()                         { s.handler.enableSyncedFeatures() }
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
-func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
+func (s *Ethereum) LogIndexer() *logindex.Indexer      { return s.logIndexer }
 
 // Protocols returns all the currently configured
 // network protocols to start.
@@ -374,12 +401,14 @@ func (s *Ethereum) Protocols() []p2p.Protocol {
 func (s *Ethereum) Start() error {
 	eth.StartENRUpdater(s.blockchain, s.p2pServer.LocalNode())
 
-	// Start the bloom bits servicing goroutines
-	s.startBloomHandlers(params.BloomBitsBlocks)
-
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
+	// Start pruning the stale state in the background if requested
+	if s.statePruner != nil {
+		s.statePruner.Start()
+	}
+
 	// Figure out a max peers count based on the server limits
 	maxPeers := s.p2pServer.MaxPeers
 	if s.config.LightServ > 0 {
@@ -402,12 +431,20 @@ func (s *Ethereum) Stop() error {
 	s.handler.Stop()
 
 	// Then stop everything else.
-	s.bloomIndexer.Close()
-	close(s.closeBloomHandler)
+	if s.logIndexer != nil {
+		s.logIndexer.Close()
+	}
 	s.txPool.Close()
 	s.blockchain.Stop()
 	s.engine.Close()
 
+	// Stop the state pruner after the chain, to track the nodes flushed on shutdown
+	if s.statePruner != nil {
+		if err := s.statePruner.Stop(); err != nil {
+			log.Error("Failed to persist state pruning progress", "err", err)
+		}
+	}
+
 	// Clean shutdown marker as the last thing before closing db
 	s.shutdownTracker.Stop()
 
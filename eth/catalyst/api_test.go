@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	crand "crypto/rand"
+	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -48,6 +49,7 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
 	"github.com/mattn/go-colorable"
 )
 
@@ -318,7 +320,7 @@ func TestEth2NewBlock(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create the executable data, block %d: %v", i, err)
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -360,7 +362,7 @@ func TestEth2NewBlock(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to create the executable data %v", err)
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -993,7 +995,7 @@ func TestSimultaneousNewBlock(t *testing.T) {
 				t.Fatal(testErr)
 			}
 		}
-		block, err := engine.ExecutableDataToBlock(*execData, nil, nil)
+		block, err := engine.ExecutableDataToBlock(*execData, nil, nil, nil)
 		if err != nil {
 			t.Fatalf("Failed to convert executable data to block %v", err)
 		}
@@ -1555,7 +1557,7 @@ func TestBlockToPayloadWithBlobs(t *testing.T) {
 	}
 
 	block := types.NewBlock(&header, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
-	envelope := engine.BlockToExecutableData(block, nil, sidecars)
+	envelope := engine.BlockToExecutableData(block, nil, sidecars, nil)
 	var want int
 	for _, tx := range txs {
 		want += len(tx.BlobHashes())
@@ -1569,12 +1571,69 @@ func TestBlockToPayloadWithBlobs(t *testing.T) {
 	if got := len(envelope.BlobsBundle.Blobs); got != want {
 		t.Fatalf("invalid number of blobs: got %v, want %v", got, want)
 	}
-	_, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload, make([]common.Hash, 1), nil)
+	_, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload, make([]common.Hash, 1), nil, nil)
 	if err != nil {
 		t.Error(err)
 	}
 }
 
+// TestGetBlobsV1 checks that blobs of pooled transactions can be retrieved by
+// their versioned hashes and that misses are reported as nil.
+func TestGetBlobsV1(t *testing.T) {
+	genesis, _ := generateMergeChain(0, true)
+
+	// Enable cancun from genesis to be able to pool blob transactions
+	time := uint64(0)
+	genesis.Config.ShanghaiTime = &time
+	genesis.Config.CancunTime = &time
+
+	n, ethservice := startEthService(t, genesis, nil)
+	defer n.Close()
+
+	api := NewConsensusAPI(ethservice)
+
+	var (
+		blob      = new(kzg4844.Blob)
+		commit, _ = kzg4844.BlobToCommitment(blob)
+		proof, _  = kzg4844.ComputeBlobProof(blob, commit)
+		vhash     = kzg4844.CalcBlobHashV1(sha256.New(), &commit)
+	)
+	tx := types.MustSignNewTx(testKey, types.LatestSigner(genesis.Config), &types.BlobTx{
+		ChainID:    uint256.MustFromBig(genesis.Config.ChainID),
+		Nonce:      0,
+		GasTipCap:  uint256.NewInt(params.GWei),
+		GasFeeCap:  uint256.NewInt(10 * params.GWei),
+		Gas:        params.TxGas,
+		BlobFeeCap: uint256.NewInt(params.GWei),
+		BlobHashes: []common.Hash{vhash},
+		Sidecar: &types.BlobTxSidecar{
+			Blobs:       []kzg4844.Blob{*blob},
+			Commitments: []kzg4844.Commitment{commit},
+			Proofs:      []kzg4844.Proof{proof},
+		},
+	})
+	if errs := ethservice.TxPool().Add([]*types.Transaction{tx}, true, true); errs[0] != nil {
+		t.Fatalf("failed to add blob transaction: %v", errs[0])
+	}
+	res, err := api.GetBlobsV1([]common.Hash{{0x01}, vhash})
+	if err != nil {
+		t.Fatalf("failed to retrieve blobs: %v", err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("unexpected number of results: have %d, want 2", len(res))
+	}
+	if res[0] != nil {
+		t.Fatalf("unexpected blob for unknown versioned hash")
+	}
+	if res[1] == nil || !bytes.Equal(res[1].Blob, blob[:]) || !bytes.Equal(res[1].Proof, proof[:]) {
+		t.Fatalf("blob and proof mismatch for pooled transaction")
+	}
+	// Requesting too many blobs at once should be rejected
+	if _, err := api.GetBlobsV1(make([]common.Hash, 129)); err == nil {
+		t.Fatalf("expected too large request to be rejected")
+	}
+}
+
 // This checks that beaconRoot is applied to the state from the engine API.
 func TestParentBeaconBlockRoot(t *testing.T) {
 	log.SetDefault(log.NewLogger(log.NewTerminalHandlerWithLevel(colorable.NewColorableStderr(), log.LevelTrace, true)))
@@ -1680,3 +1739,82 @@ func TestGetClientVersion(t *testing.T) {
 		t.Fatalf("client info does match expected, got %s", info.String())
 	}
 }
+
+// TestPraguePayload checks that prague payloads are built and imported through
+// the V4 engine API methods, carrying the execution layer requests.
+func TestPraguePayload(t *testing.T) {
+	genesis, blocks := generateMergeChain(10, true)
+
+	// Set prague time to last block + 5 seconds
+	time := blocks[len(blocks)-1].Time() + 5
+	genesis.Config.ShanghaiTime = &time
+	genesis.Config.CancunTime = &time
+	genesis.Config.PragueTime = &time
+
+	n, ethservice := startEthService(t, genesis, blocks)
+	defer n.Close()
+
+	api := NewConsensusAPI(ethservice)
+
+	parent := ethservice.BlockChain().CurrentHeader()
+	blockParams := engine.PayloadAttributes{
+		Timestamp:   parent.Time + 5,
+		Withdrawals: make([]*types.Withdrawal, 0),
+		BeaconRoot:  &common.Hash{42},
+	}
+	fcState := engine.ForkchoiceStateV1{
+		HeadBlockHash: parent.Hash(),
+	}
+	resp, err := api.ForkchoiceUpdatedV3(fcState, &blockParams)
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err.(*engine.EngineAPIError).ErrorData())
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", resp.PayloadStatus.Status, engine.VALID)
+	}
+	execData, err := api.GetPayloadV4(*resp.PayloadID)
+	if err != nil {
+		t.Fatalf("error getting payload, err=%v", err)
+	}
+	if execData.Requests == nil {
+		t.Fatal("missing execution requests in prague payload")
+	}
+	requests := make([]hexutil.Bytes, len(execData.Requests))
+	for i := range execData.Requests {
+		requests[i] = execData.Requests[i]
+	}
+	// The V3 method must reject prague payloads
+	if _, err := api.NewPayloadV3(*execData.ExecutionPayload, []common.Hash{}, &common.Hash{42}); err == nil {
+		t.Fatal("expected newPayloadV3 to reject prague payload")
+	}
+	// Malformed requests must be rejected
+	if _, err := api.NewPayloadV4(*execData.ExecutionPayload, []common.Hash{}, &common.Hash{42}, []hexutil.Bytes{{0x01}}); err == nil {
+		t.Fatal("expected empty request to be rejected")
+	}
+	if _, err := api.NewPayloadV4(*execData.ExecutionPayload, []common.Hash{}, &common.Hash{42}, []hexutil.Bytes{{0x01, 0x01}, {0x00, 0x01}}); err == nil {
+		t.Fatal("expected unordered requests to be rejected")
+	}
+	// Requests not matching the built block must invalidate it
+	if status, err := api.NewPayloadV4(*execData.ExecutionPayload, []common.Hash{}, &common.Hash{42}, []hexutil.Bytes{{0x00, 0x01}}); err != nil {
+		t.Fatalf("error validating payload: %v", err)
+	} else if status.Status != engine.INVALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", status.Status, engine.INVALID)
+	}
+	if status, err := api.NewPayloadV4(*execData.ExecutionPayload, []common.Hash{}, &common.Hash{42}, requests); err != nil {
+		t.Fatalf("error validating payload: %v", err)
+	} else if status.Status != engine.VALID {
+		t.Fatalf("invalid payload")
+	}
+	fcState.HeadBlockHash = execData.ExecutionPayload.BlockHash
+	resp, err = api.ForkchoiceUpdatedV3(fcState, nil)
+	if err != nil {
+		t.Fatalf("error preparing payload, err=%v", err.(*engine.EngineAPIError).ErrorData())
+	}
+	if resp.PayloadStatus.Status != engine.VALID {
+		t.Fatalf("unexpected status (got: %s, want: %s)", resp.PayloadStatus.Status, engine.VALID)
+	}
+	head := ethservice.BlockChain().CurrentHeader()
+	if head.RequestsHash == nil || *head.RequestsHash != types.CalcRequestsHash(execData.Requests) {
+		t.Fatalf("unexpected requests hash: %v", head.RequestsHash)
+	}
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/beacon/engine"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/forks"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -202,7 +204,15 @@ func (c *SimulatedBeacon) sealBlock(withdrawals []*types.Withdrawal, timestamp u
 		}
 	}
 	// Mark the payload as canon
-	if _, err = c.engineAPI.NewPayloadV3(*payload, blobHashes, &common.Hash{}); err != nil {
+	if c.eth.BlockChain().Config().LatestFork(payload.Timestamp) == forks.Prague {
+		requests := make([]hexutil.Bytes, len(envelope.Requests))
+		for i, req := range envelope.Requests {
+			requests[i] = req
+		}
+		if _, err = c.engineAPI.NewPayloadV4(*payload, blobHashes, &common.Hash{}, requests); err != nil {
+			return err
+		}
+	} else if _, err = c.engineAPI.NewPayloadV3(*payload, blobHashes, &common.Hash{}); err != nil {
 		return err
 	}
 	c.setCurrentState(payload.BlockHash, finalizedHash)
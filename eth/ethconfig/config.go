@@ -52,6 +52,7 @@ var Defaults = Config{
 	NetworkId:          0, // enable auto configuration of networkID == chainID
 	TxLookupLimit:      2350000,
 	TransactionHistory: 2350000,
+	LogHistory:         2350000,
 	StateHistory:       params.FullImmutabilityThreshold,
 	LightPeers:         100,
 	DatabaseCache:      512,
@@ -65,6 +66,7 @@ var Defaults = Config{
 	BlobPool:           blobpool.DefaultConfig,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
+	RPCTraceFilterCap:  1000,
 	GPO:                FullNodeGPO,
 	RPCTxFeeCap:        1, // 1 ether
 }
@@ -93,7 +95,16 @@ type Config struct {
 	// Deprecated, use 'TransactionHistory' instead.
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
+	LogHistory         uint64 `toml:",omitempty"` // The maximum number of blocks from head whose logs are indexed.
+	LogNoHistory       bool   `toml:",omitempty"` // Whether to disable the log index entirely.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	StateIndexing      bool   `toml:",omitempty"` // Whether to index the state histories for serving historical states.
+
+	// State pruning options (hash scheme only)
+	StatePruning          bool          `toml:",omitempty"` // Whether to prune the stale state in the background while running.
+	StatePruningBloomSize uint64        `toml:",omitempty"` // Megabytes of memory allocated to the bloom filter of the state pruning.
+	StatePruningThrottle  time.Duration `toml:",omitempty"` // Pause between two batches of deletions of the state pruning (zero = default).
+	StatePruningInterval  time.Duration `toml:",omitempty"` // Time to wait between two cycles of the state pruning (zero = default).
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -154,6 +165,10 @@ type Config struct {
 	// RPCEVMTimeout is the global timeout for eth-call.
 	RPCEVMTimeout time.Duration
 
+	// RPCTraceFilterCap is the maximum number of blocks trace_filter can trace
+	// in a single request.
+	RPCTraceFilterCap uint64
+
 	// RPCTxFeeCap is the global transaction fee(price * gaslimit) cap for
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
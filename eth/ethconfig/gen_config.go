@@ -26,7 +26,14 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		NoPrefetch              bool
 		TxLookupLimit           uint64                 `toml:",omitempty"`
 		TransactionHistory      uint64                 `toml:",omitempty"`
+		LogHistory              uint64                 `toml:",omitempty"`
+		LogNoHistory            bool                   `toml:",omitempty"`
 		StateHistory            uint64                 `toml:",omitempty"`
+		StateIndexing           bool                   `toml:",omitempty"`
+		StatePruning            bool                   `toml:",omitempty"`
+		StatePruningBloomSize   uint64                 `toml:",omitempty"`
+		StatePruningThrottle    time.Duration          `toml:",omitempty"`
+		StatePruningInterval    time.Duration          `toml:",omitempty"`
 		StateScheme             string                 `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               int                    `toml:",omitempty"`
@@ -55,6 +62,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		DocRoot                 string `toml:"-"`
 		RPCGasCap               uint64
 		RPCEVMTimeout           time.Duration
+		RPCTraceFilterCap       uint64
 		RPCTxFeeCap             float64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
@@ -69,7 +77,14 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.NoPrefetch = c.NoPrefetch
 	enc.TxLookupLimit = c.TxLookupLimit
 	enc.TransactionHistory = c.TransactionHistory
+	enc.LogHistory = c.LogHistory
+	enc.LogNoHistory = c.LogNoHistory
 	enc.StateHistory = c.StateHistory
+	enc.StateIndexing = c.StateIndexing
+	enc.StatePruning = c.StatePruning
+	enc.StatePruningBloomSize = c.StatePruningBloomSize
+	enc.StatePruningThrottle = c.StatePruningThrottle
+	enc.StatePruningInterval = c.StatePruningInterval
 	enc.StateScheme = c.StateScheme
 	enc.RequiredBlocks = c.RequiredBlocks
 	enc.LightServ = c.LightServ
@@ -98,6 +113,7 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.DocRoot = c.DocRoot
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
+	enc.RPCTraceFilterCap = c.RPCTraceFilterCap
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
 	enc.OverrideCancun = c.OverrideCancun
 	enc.OverrideVerkle = c.OverrideVerkle
@@ -116,7 +132,14 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		NoPrefetch              *bool
 		TxLookupLimit           *uint64                `toml:",omitempty"`
 		TransactionHistory      *uint64                `toml:",omitempty"`
+		LogHistory              *uint64                `toml:",omitempty"`
+		LogNoHistory            *bool                  `toml:",omitempty"`
 		StateHistory            *uint64                `toml:",omitempty"`
+		StateIndexing           *bool                  `toml:",omitempty"`
+		StatePruning            *bool                  `toml:",omitempty"`
+		StatePruningBloomSize   *uint64                `toml:",omitempty"`
+		StatePruningThrottle    *time.Duration         `toml:",omitempty"`
+		StatePruningInterval    *time.Duration         `toml:",omitempty"`
 		StateScheme             *string                `toml:",omitempty"`
 		RequiredBlocks          map[uint64]common.Hash `toml:"-"`
 		LightServ               *int                   `toml:",omitempty"`
@@ -145,6 +168,7 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		DocRoot                 *string `toml:"-"`
 		RPCGasCap               *uint64
 		RPCEVMTimeout           *time.Duration
+		RPCTraceFilterCap       *uint64
 		RPCTxFeeCap             *float64
 		OverrideCancun          *uint64 `toml:",omitempty"`
 		OverrideVerkle          *uint64 `toml:",omitempty"`
@@ -180,9 +204,30 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.TransactionHistory != nil {
 		c.TransactionHistory = *dec.TransactionHistory
 	}
+	if dec.LogHistory != nil {
+		c.LogHistory = *dec.LogHistory
+	}
+	if dec.LogNoHistory != nil {
+		c.LogNoHistory = *dec.LogNoHistory
+	}
 	if dec.StateHistory != nil {
 		c.StateHistory = *dec.StateHistory
 	}
+	if dec.StateIndexing != nil {
+		c.StateIndexing = *dec.StateIndexing
+	}
+	if dec.StatePruning != nil {
+		c.StatePruning = *dec.StatePruning
+	}
+	if dec.StatePruningBloomSize != nil {
+		c.StatePruningBloomSize = *dec.StatePruningBloomSize
+	}
+	if dec.StatePruningThrottle != nil {
+		c.StatePruningThrottle = *dec.StatePruningThrottle
+	}
+	if dec.StatePruningInterval != nil {
+		c.StatePruningInterval = *dec.StatePruningInterval
+	}
 	if dec.StateScheme != nil {
 		c.StateScheme = *dec.StateScheme
 	}
@@ -267,6 +312,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCEVMTimeout != nil {
 		c.RPCEVMTimeout = *dec.RPCEVMTimeout
 	}
+	if dec.RPCTraceFilterCap != nil {
+		c.RPCTraceFilterCap = *dec.RPCTraceFilterCap
+	}
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
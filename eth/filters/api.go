@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
@@ -224,12 +225,21 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+//
+// If a cursor is given, the canonical headers following it are replayed before
+// the live ones, allowing a subscriber to resume after a disconnection.
+func (api *FilterAPI) NewHeads(ctx context.Context, cursor *SubscriptionCursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	var point *resumePoint
+	if cursor != nil {
+		var err error
+		if point, err = api.resolveCursor(ctx, cursor); err != nil {
+			return nil, err
+		}
+	}
 	rpcSub := notifier.CreateSubscription()
 
 	go func() {
@@ -237,10 +247,44 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 		headersSub := api.events.SubscribeNewHeads(headers)
 		defer headersSub.Unsubscribe()
 
+		// Replay the history if requested, queueing the live headers meanwhile
+		var (
+			catchup  *headCatchup
+			queue    []*types.Header
+			replayed chan *headCatchup
+		)
+		if point != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			replayed = make(chan *headCatchup, 1)
+			go func() {
+				catchup, err := api.replayHeads(ctx, point, func(h *types.Header) {
+					notifier.Notify(rpcSub.ID, h)
+				})
+				if err != nil {
+					log.Warn("Failed to replay subscription headers", "id", rpcSub.ID, "err", err)
+				}
+				replayed <- catchup
+			}()
+		}
 		for {
 			select {
 			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
+				switch {
+				case replayed != nil:
+					queue = append(queue, h)
+				case catchup == nil || catchup.deliver(h):
+					notifier.Notify(rpcSub.ID, h)
+				}
+			case catchup = <-replayed:
+				replayed = nil
+				for _, h := range queue {
+					if catchup.deliver(h) {
+						notifier.Notify(rpcSub.ID, h)
+					}
+				}
+				queue = nil
 			case <-rpcSub.Err():
 				return
 			}
@@ -251,12 +295,22 @@ func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+//
+// If a cursor is given, the historical logs following it are replayed before
+// the live ones, allowing a subscriber to resume after a disconnection. The
+// starting block of the criteria doesn't trigger a replay.
+func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria, cursor *SubscriptionCursor) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	var point *resumePoint
+	if cursor != nil {
+		var err error
+		if point, err = api.resolveCursor(ctx, cursor); err != nil {
+			return nil, err
+		}
+	}
 	var (
 		rpcSub      = notifier.CreateSubscription()
 		matchedLogs = make(chan []*types.Log)
@@ -269,13 +323,50 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 
 	go func() {
 		defer logsSub.Unsubscribe()
+
+		// Replay the history if requested, queueing the live logs meanwhile
+		var (
+			catchup  *logCatchup
+			queue    [][]*types.Log
+			replayed chan *logCatchup
+		)
+		if point != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			replayed = make(chan *logCatchup, 1)
+			go func() {
+				catchup, err := api.replayLogs(ctx, point, crit, func(l *types.Log) {
+					notifier.Notify(rpcSub.ID, l)
+				})
+				if err != nil {
+					log.Warn("Failed to replay subscription logs", "id", rpcSub.ID, "err", err)
+				}
+				replayed <- catchup
+			}()
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
-				for _, log := range logs {
-					log := log
-					notifier.Notify(rpcSub.ID, &log)
+				if replayed != nil {
+					queue = append(queue, logs)
+					continue
+				}
+				for _, l := range logs {
+					if catchup == nil || catchup.deliver(l) {
+						notifier.Notify(rpcSub.ID, l)
+					}
+				}
+			case catchup = <-replayed:
+				replayed = nil
+				for _, logs := range queue {
+					for _, l := range logs {
+						if catchup.deliver(l) {
+							notifier.Notify(rpcSub.ID, l)
+						}
+					}
 				}
+				queue = nil
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				return
 			}
@@ -18,152 +18,104 @@ package filters
 
 import (
 	"context"
-	"fmt"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/bitutil"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/node"
 )
 
-func BenchmarkBloomBits512(b *testing.B) {
-	benchmarkBloomBits(b, 512)
+// benchChain exposes the canonical chain of an existing database to the log
+// indexer.
+type benchChain struct {
+	db   ethdb.Database
+	feed event.Feed
 }
 
-func BenchmarkBloomBits1k(b *testing.B) {
-	benchmarkBloomBits(b, 1024)
-}
-
-func BenchmarkBloomBits2k(b *testing.B) {
-	benchmarkBloomBits(b, 2048)
-}
-
-func BenchmarkBloomBits4k(b *testing.B) {
-	benchmarkBloomBits(b, 4096)
+func (c *benchChain) CurrentBlock() *types.Header {
+	if block := rawdb.ReadHeadBlock(c.db); block != nil {
+		return block.Header()
+	}
+	return nil
 }
 
-func BenchmarkBloomBits8k(b *testing.B) {
-	benchmarkBloomBits(b, 8192)
+func (c *benchChain) GetCanonicalHash(number uint64) common.Hash {
+	return rawdb.ReadCanonicalHash(c.db, number)
 }
 
-func BenchmarkBloomBits16k(b *testing.B) {
-	benchmarkBloomBits(b, 16384)
+func (c *benchChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
 }
 
-func BenchmarkBloomBits32k(b *testing.B) {
-	benchmarkBloomBits(b, 32768)
+func (c *benchChain) HistoryPruningCutoff() uint64 {
+	if tail := rawdb.ReadChainHistoryTail(c.db); tail != nil {
+		return *tail
+	}
+	return 0
 }
 
 const benchFilterCnt = 2000
 
-func benchmarkBloomBits(b *testing.B, sectionSize uint64) {
+func BenchmarkLogIndex(b *testing.B) {
 	b.Skip("test disabled: this tests presume (and modify) an existing datadir.")
 	benchDataDir := node.DefaultDataDir() + "/geth/chaindata"
-	b.Log("Running bloombits benchmark   section size:", sectionSize)
+	b.Log("Running log index benchmark")
 
 	db, err := rawdb.NewLevelDBDatabase(benchDataDir, 128, 1024, "", false)
 	if err != nil {
 		b.Fatalf("error opening database at %v: %v", benchDataDir, err)
 	}
+	defer db.Close()
+
 	head := rawdb.ReadHeadBlockHash(db)
 	if head == (common.Hash{}) {
 		b.Fatalf("chain data not found at %v", benchDataDir)
 	}
-
-	clearBloomBits(db)
-	b.Log("Generating bloombits data...")
 	headNum := rawdb.ReadHeaderNumber(db, head)
-	if headNum == nil || *headNum < sectionSize+512 {
-		b.Fatalf("not enough blocks for running a benchmark")
-	}
 
+	b.Log("Generating log index...")
 	start := time.Now()
-	cnt := (*headNum - 512) / sectionSize
-	var dataSize, compSize uint64
-	for sectionIdx := uint64(0); sectionIdx < cnt; sectionIdx++ {
-		bc, err := bloombits.NewGenerator(uint(sectionSize))
-		if err != nil {
-			b.Fatalf("failed to create generator: %v", err)
-		}
-		var header *types.Header
-		for i := sectionIdx * sectionSize; i < (sectionIdx+1)*sectionSize; i++ {
-			hash := rawdb.ReadCanonicalHash(db, i)
-			if header = rawdb.ReadHeader(db, hash, i); header == nil {
-				b.Fatalf("Error creating bloomBits data")
-				return
-			}
-			bc.AddBloom(uint(i-sectionIdx*sectionSize), header.Bloom)
-		}
-		sectionHead := rawdb.ReadCanonicalHash(db, (sectionIdx+1)*sectionSize-1)
-		for i := 0; i < types.BloomBitLength; i++ {
-			data, err := bc.Bitset(uint(i))
-			if err != nil {
-				b.Fatalf("failed to retrieve bitset: %v", err)
-			}
-			comp := bitutil.CompressBytes(data)
-			dataSize += uint64(len(data))
-			compSize += uint64(len(comp))
-			rawdb.WriteBloomBits(db, uint(i), sectionIdx, sectionHead, comp)
+	index := logindex.NewIndexer(db, &benchChain{db: db}, 0)
+	defer index.Close()
+
+	for {
+		if _, indexed, ok := index.Range(); ok && indexed >= *headNum {
+			break
 		}
-		//if sectionIdx%50 == 0 {
-		//	b.Log(" section", sectionIdx, "/", cnt)
-		//}
+		time.Sleep(100 * time.Millisecond)
 	}
-
 	d := time.Since(start)
-	b.Log("Finished generating bloombits data")
-	b.Log(" ", d, "total  ", d/time.Duration(cnt*sectionSize), "per block")
-	b.Log(" data size:", dataSize, "  compressed size:", compSize, "  compression ratio:", float64(compSize)/float64(dataSize))
+	b.Log("Finished generating log index")
+	b.Log(" ", d, "total  ", d/time.Duration(*headNum+1), "per block")
 
 	b.Log("Running filter benchmarks...")
 	start = time.Now()
 
-	var (
-		backend *testBackend
-		sys     *FilterSystem
-	)
+	sys := NewFilterSystem(&testBackend{db: db, logIndex: index}, Config{})
 	for i := 0; i < benchFilterCnt; i++ {
-		if i%20 == 0 {
-			db.Close()
-			db, _ = rawdb.NewLevelDBDatabase(benchDataDir, 128, 1024, "", false)
-			backend = &testBackend{db: db, sections: cnt}
-			sys = NewFilterSystem(backend, Config{})
-		}
 		var addr common.Address
 		addr[0] = byte(i)
 		addr[1] = byte(i / 256)
-		filter := sys.NewRangeFilter(0, int64(cnt*sectionSize-1), []common.Address{addr}, nil)
+		filter := sys.NewRangeFilter(0, int64(*headNum), []common.Address{addr}, nil)
 		if _, err := filter.Logs(context.Background()); err != nil {
 			b.Error("filter.Logs error:", err)
 		}
 	}
-
 	d = time.Since(start)
 	b.Log("Finished running filter benchmarks")
-	b.Log(" ", d, "total  ", d/time.Duration(benchFilterCnt), "per address", d*time.Duration(1000000)/time.Duration(benchFilterCnt*cnt*sectionSize), "per million blocks")
-	db.Close()
+	b.Log(" ", d, "total  ", d/time.Duration(benchFilterCnt), "per address", d*time.Duration(1000000)/time.Duration(benchFilterCnt*(*headNum+1)), "per million blocks")
 }
 
-//nolint:unused
-func clearBloomBits(db ethdb.Database) {
-	var bloomBitsPrefix = []byte("bloomBits-")
-	fmt.Println("Clearing bloombits data...")
-	it := db.NewIterator(bloomBitsPrefix, nil)
-	for it.Next() {
-		db.Delete(it.Key())
-	}
-	it.Release()
-}
-
-func BenchmarkNoBloomBits(b *testing.B) {
+func BenchmarkNoLogIndex(b *testing.B) {
 	b.Skip("test disabled: this tests presume (and modify) an existing datadir.")
 	benchDataDir := node.DefaultDataDir() + "/geth/chaindata"
-	b.Log("Running benchmark without bloombits")
+	b.Log("Running benchmark without log index")
 	db, err := rawdb.NewLevelDBDatabase(benchDataDir, 128, 1024, "", false)
 	if err != nil {
 		b.Fatalf("error opening database at %v: %v", benchDataDir, err)
@@ -174,8 +126,6 @@ func BenchmarkNoBloomBits(b *testing.B) {
 	}
 	headNum := rawdb.ReadHeaderNumber(db, head)
 
-	clearBloomBits(db)
-
 	_, sys := newTestFilterSystem(b, db, Config{})
 
 	b.Log("Running filter benchmarks...")
@@ -23,11 +23,15 @@ import (
 	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// logSearchChunk is the maximum number of blocks searched in the log index at
+// once, so that the matches of wide ranges are streamed as they are found.
+const logSearchChunk = 16384
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	sys *FilterSystem
@@ -37,37 +41,13 @@ type Filter struct {
 
 	block      *common.Hash // Block hash if filtering a single block
 	begin, end int64        // Range interval if filtering multiple blocks
-
-	matcher *bloombits.Matcher
 }
 
-// NewRangeFilter creates a new filter which uses a bloom filter on blocks to
-// figure out whether a particular block is interesting or not.
+// NewRangeFilter creates a new filter which uses the log index to figure out
+// whether a particular block is interesting or not.
 func (sys *FilterSystem) NewRangeFilter(begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
-	// Flatten the address and topic filter clauses into a single bloombits filter
-	// system. Since the bloombits are not positional, nil topics are permitted,
-	// which get flattened into a nil byte slice.
-	var filters [][][]byte
-	if len(addresses) > 0 {
-		filter := make([][]byte, len(addresses))
-		for i, address := range addresses {
-			filter[i] = address.Bytes()
-		}
-		filters = append(filters, filter)
-	}
-	for _, topicList := range topics {
-		filter := make([][]byte, len(topicList))
-		for i, topic := range topicList {
-			filter[i] = topic.Bytes()
-		}
-		filters = append(filters, filter)
-	}
-	size, _ := sys.backend.BloomStatus()
-
 	// Create a generic filter and convert it into a range filter
 	filter := newFilter(sys, addresses, topics)
-
-	filter.matcher = bloombits.NewMatcher(size, filters)
 	filter.begin = begin
 	filter.end = end
 
@@ -174,22 +154,21 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
-		var (
-			end            = uint64(f.end)
-			size, sections = f.sys.backend.BloomStatus()
-			err            error
-		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
-			if indexed > end {
-				indexed = end + 1
+		// Scan the blocks below the log index first, search the indexed ones
+		// and finish with the ones not yet indexed.
+		end := uint64(f.end)
+		if index := f.sys.backend.LogIndex(); index != nil {
+			if tail, _, ok := index.Range(); ok && tail > uint64(f.begin) {
+				if err := f.unindexedLogs(ctx, min(tail-1, end), logChan); err != nil {
+					errChan <- err
+					return
+				}
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+			if err := f.indexedLogs(ctx, index, end, logChan); err != nil {
 				errChan <- err
 				return
 			}
 		}
-
 		if err := f.unindexedLogs(ctx, end, logChan); err != nil {
 			errChan <- err
 			return
@@ -201,33 +180,24 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 	return logChan, errChan
 }
 
-// indexedLogs returns the logs matching the filter criteria based on the bloom
-// bits indexed available locally or via the network.
-func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
-	// Create a matcher session and request servicing from the backend
-	matches := make(chan uint64, 64)
-
-	session, err := f.matcher.Start(ctx, uint64(f.begin), end, matches)
-	if err != nil {
-		return err
-	}
-	defer session.Close()
-
-	f.sys.backend.ServiceFilter(ctx, session)
-
-	for {
-		select {
-		case number, ok := <-matches:
-			// Abort if all matches have been fulfilled
-			if !ok {
-				err := session.Error()
-				if err == nil {
-					f.begin = int64(end) + 1
-				}
-				return err
-			}
-			f.begin = int64(number) + 1
-
+// indexedLogs returns the logs matching the filter criteria based on the log
+// index, advancing the start of the filter up to the first block which is not
+// covered by the index.
+func (f *Filter) indexedLogs(ctx context.Context, index *logindex.Indexer, end uint64, logChan chan *types.Log) error {
+	for f.begin <= int64(end) {
+		tail, head, ok := index.Range()
+		if !ok || uint64(f.begin) < tail || uint64(f.begin) > head {
+			return nil
+		}
+		to := min(end, head, uint64(f.begin)+logSearchChunk-1)
+		numbers, err := index.Search(ctx, uint64(f.begin), to, f.addresses, f.topics)
+		if errors.Is(err, logindex.ErrOutOfRange) {
+			continue // index changed in the meantime, retry
+		}
+		if err != nil {
+			return err
+		}
+		for _, number := range numbers {
 			// Retrieve the suggested block and pull any truly matching logs
 			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
 			if header == nil || err != nil {
@@ -238,13 +208,16 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 				return err
 			}
 			for _, log := range found {
-				logChan <- log
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
-
-		case <-ctx.Done():
-			return ctx.Err()
 		}
+		f.begin = int64(to) + 1
 	}
+	return nil
 }
 
 // unindexedLogs returns the logs matching the filter criteria based on raw block
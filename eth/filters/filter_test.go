@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -108,6 +109,18 @@ func BenchmarkFilters(b *testing.B) {
 }
 
 func TestFilters(t *testing.T) {
+	testFilters(t, 0, false)
+}
+
+func TestFiltersIndexed(t *testing.T) {
+	testFilters(t, 0, true)
+}
+
+func TestFiltersPartiallyIndexed(t *testing.T) {
+	testFilters(t, 500, true)
+}
+
+func testFilters(t *testing.T, history uint64, indexed bool) {
 	var (
 		db           = rawdb.NewMemoryDatabase()
 		backend, sys = newTestFilterSystem(t, db, Config{})
@@ -262,6 +275,13 @@ func TestFilters(t *testing.T) {
 	// Set block 998 as Finalized (-3)
 	bc.SetFinalized(chain[998].Header())
 
+	if indexed {
+		backend.logIndex = logindex.NewIndexer(db, bc, history)
+		defer backend.logIndex.Close()
+
+		waitIndexed(t, backend.logIndex, chain[len(chain)-1].NumberU64())
+	}
+
 	// Generate pending block
 	pchain, preceipts := core.GenerateChain(gspec.Config, chain[len(chain)-1], ethash.NewFaker(), db, 1, func(i int, gen *core.BlockGen) {
 		data, err := contractABI.Pack("log1", hash5.Big())
@@ -387,3 +407,14 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+func waitIndexed(t *testing.T, index *logindex.Indexer, head uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, indexed, ok := index.Range(); ok && indexed >= head {
+			return
+		}
+	}
+	t.Fatalf("Logs are not indexed in time")
+}
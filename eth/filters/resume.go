@@ -0,0 +1,249 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// maxCursorReorgDepth is the maximum number of blocks walked back from a
+	// reorged subscription cursor to find the canonical chain.
+	maxCursorReorgDepth = 1024
+
+	// replayBatchBlocks is the number of blocks whose logs are retrieved at once
+	// when replaying the history of a subscription.
+	replayBatchBlocks = 1024
+)
+
+var (
+	errUnknownCursor = errors.New("unknown subscription cursor block")
+	errCursorTooDeep = errors.New("subscription cursor reorged too deep")
+	errCursorTooOld  = errors.New("subscription cursor too far behind the head")
+)
+
+// SubscriptionCursor is the position in the chain from which a subscription is
+// resumed. Historical notifications are replayed from the cursor up to the head
+// of the chain, before switching to live delivery.
+//
+// A cursor with a block hash is the last block delivered by a previous
+// subscription, and optionally the index of the last log delivered within it.
+// The notifications resume right after it. If the block was reorged out of the
+// chain in the meantime, the delivered logs are first replayed as removed up to
+// the common ancestor with the canonical chain.
+//
+// A cursor without block hash, which may also be given as a plain block number,
+// replays the notifications starting at that block.
+type SubscriptionCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	LogIndex    *hexutil.Uint  `json:"logIndex,omitempty"`
+}
+
+// UnmarshalJSON parses a cursor, given either as an object or a block number.
+func (c *SubscriptionCursor) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		*c = SubscriptionCursor{}
+		return json.Unmarshal(input, &c.BlockNumber)
+	}
+	type cursor SubscriptionCursor
+	return json.Unmarshal(input, (*cursor)(c))
+}
+
+// resumePoint is the resolved position of a subscription cursor.
+type resumePoint struct {
+	start   uint64          // First canonical block to replay
+	skip    *uint           // Logs of the start block up to this index are already delivered
+	orphans []*types.Header // Delivered blocks no longer canonical, oldest first
+	limit   *uint           // Index of the last delivered log of the newest orphan
+}
+
+// resolveCursor locates the given cursor in the canonical chain. Cursors which
+// would replay more blocks than the configured limit are rejected.
+func (api *FilterAPI) resolveCursor(ctx context.Context, cursor *SubscriptionCursor) (*resumePoint, error) {
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	if limit := api.sys.cfg.ReplayLimit; uint64(cursor.BlockNumber)+limit <= head {
+		return nil, fmt.Errorf("%w: block #%d, head #%d, limit %d blocks", errCursorTooOld, cursor.BlockNumber, head, limit)
+	}
+	if cursor.BlockHash == (common.Hash{}) {
+		return &resumePoint{start: uint64(cursor.BlockNumber)}, nil
+	}
+	header, err := api.sys.backend.HeaderByHash(ctx, cursor.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil || header.Number.Uint64() != uint64(cursor.BlockNumber) {
+		return nil, errUnknownCursor
+	}
+	point := new(resumePoint)
+	if cursor.LogIndex != nil {
+		index := uint(*cursor.LogIndex)
+		point.skip, point.limit = &index, &index
+	}
+	// Walk back the chain of the cursor until reaching a canonical block
+	for {
+		canon, _ := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Int64()))
+		if canon != nil && canon.Hash() == header.Hash() {
+			break
+		}
+		if len(point.orphans) == maxCursorReorgDepth {
+			return nil, errCursorTooDeep
+		}
+		point.orphans = append([]*types.Header{header}, point.orphans...)
+
+		if header, err = api.sys.backend.HeaderByHash(ctx, header.ParentHash); err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, errUnknownCursor
+		}
+	}
+	switch {
+	case len(point.orphans) > 0:
+		point.start, point.skip = header.Number.Uint64()+1, nil
+	case point.skip != nil:
+		point.start = header.Number.Uint64() // Deliver the rest of the block
+	default:
+		point.start = header.Number.Uint64() + 1
+	}
+	return point, nil
+}
+
+// replayLogs delivers the historical logs matching the criteria from the resume
+// point up to the current head. It returns the state needed to merge the live
+// logs into the replayed ones.
+func (api *FilterAPI) replayLogs(ctx context.Context, point *resumePoint, crit FilterCriteria, notify func(*types.Log)) (*logCatchup, error) {
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 && crit.ToBlock.Uint64() < head {
+		head = crit.ToBlock.Uint64()
+	}
+	catchup := &logCatchup{start: point.start, head: head, delivered: make(map[common.Hash]struct{})}
+
+	// Retract the delivered logs of the blocks reorged out of the chain
+	for i, header := range point.orphans {
+		logs, err := api.sys.NewBlockFilter(header.Hash(), crit.Addresses, crit.Topics).Logs(ctx)
+		if err != nil {
+			return catchup, err
+		}
+		for _, log := range logs {
+			if i == len(point.orphans)-1 && point.limit != nil && log.Index > *point.limit {
+				break
+			}
+			removed := *log
+			removed.Removed = true
+			notify(&removed)
+		}
+	}
+	// Replay the canonical logs in batches
+	for begin := point.start; begin <= head; begin += replayBatchBlocks {
+		end := min(begin+replayBatchBlocks-1, head)
+		logs, err := api.sys.NewRangeFilter(int64(begin), int64(end), crit.Addresses, crit.Topics).Logs(ctx)
+		if err != nil {
+			return catchup, err
+		}
+		for _, log := range logs {
+			catchup.delivered[log.BlockHash] = struct{}{}
+			if point.skip != nil && log.BlockNumber == point.start && log.Index <= *point.skip {
+				continue
+			}
+			notify(log)
+		}
+	}
+	return catchup, nil
+}
+
+// logCatchup filters the live logs received while replaying the history of a
+// subscription, which might have been delivered already.
+type logCatchup struct {
+	start     uint64                   // First replayed block
+	head      uint64                   // Last replayed block
+	delivered map[common.Hash]struct{} // Blocks whose logs were replayed
+	done      bool                     // Whether the live logs went past the replay
+}
+
+// deliver returns whether the live log has to be delivered.
+func (c *logCatchup) deliver(log *types.Log) bool {
+	if c.done {
+		return true
+	}
+	if log.BlockNumber > c.head {
+		c.done, c.delivered = true, nil
+		return true
+	}
+	_, replayed := c.delivered[log.BlockHash]
+	if log.Removed {
+		// Only retract the logs the subscriber has seen
+		return replayed || log.BlockNumber < c.start
+	}
+	return !replayed
+}
+
+// replayHeads delivers the canonical headers from the resume point up to the
+// current head. It returns the hashes of the delivered headers.
+func (api *FilterAPI) replayHeads(ctx context.Context, point *resumePoint, notify func(*types.Header)) (*headCatchup, error) {
+	start := point.start
+	if point.skip != nil {
+		start++ // The cursor block itself was delivered already
+	}
+	head := api.sys.backend.CurrentHeader().Number.Uint64()
+	catchup := &headCatchup{head: head, delivered: make(map[common.Hash]struct{})}
+
+	for number := start; number <= head; number++ {
+		if err := ctx.Err(); err != nil {
+			return catchup, err
+		}
+		header, err := api.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return catchup, err
+		}
+		if header == nil {
+			break // Chain rewound meanwhile
+		}
+		catchup.delivered[header.Hash()] = struct{}{}
+		notify(header)
+	}
+	return catchup, nil
+}
+
+// headCatchup filters the live headers received while replaying the history of
+// a subscription, which might have been delivered already.
+type headCatchup struct {
+	head      uint64                   // Last replayed block
+	delivered map[common.Hash]struct{} // Replayed headers
+	done      bool                     // Whether the live headers went past the replay
+}
+
+// deliver returns whether the live header has to be delivered.
+func (c *headCatchup) deliver(header *types.Header) bool {
+	if c.done {
+		return true
+	}
+	if header.Number.Uint64() > c.head {
+		c.done, c.delivered = true, nil
+		return true
+	}
+	_, replayed := c.delivered[header.Hash()]
+	return !replayed
+}
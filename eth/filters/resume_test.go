@@ -0,0 +1,254 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// resumeTestChains generates a chain of 6 blocks and a fork of it of 8 blocks,
+// diverging after block 3. Every block contains a log whose topic is the block
+// number, doubled in the fork. The first chain is written into the database and
+// then reorged to the fork.
+func resumeTestChains(db ethdb.Database) (contract common.Address, chainA, chainB []*types.Block) {
+	var (
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender = crypto.PubkeyToAddress(key.PublicKey)
+		// Emits a log with the call value as topic
+		logger  = common.Address{0xfe}
+		genesis = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				logger: {Balance: big.NewInt(0), Code: []byte{0x34, 0x60, 0x00, 0x80, 0xa1, 0x00}},
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	generate := func(n int, fork bool) ([]*types.Block, []types.Receipts) {
+		_, blocks, receipts := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), n, func(i int, b *core.BlockGen) {
+			value := int64(i + 1)
+			if fork && i >= 3 {
+				value *= 2
+			}
+			b.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    uint64(i),
+				To:       &logger,
+				Value:    big.NewInt(value),
+				Gas:      100000,
+				GasPrice: b.BaseFee(),
+			}))
+		})
+		return blocks, receipts
+	}
+	chainA, receiptsA := generate(6, false)
+	chainB, receiptsB := generate(8, true)
+
+	write := func(blocks []*types.Block, receipts []types.Receipts) {
+		for i, block := range blocks {
+			rawdb.WriteBlock(db, block)
+			rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+			rawdb.WriteHeadBlockHash(db, block.Hash())
+			rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), receipts[i])
+		}
+	}
+	genesis.MustCommit(db, triedb.NewDatabase(db, triedb.HashDefaults))
+	write(chainA, receiptsA)
+	write(chainB, receiptsB)
+	return logger, chainA, chainB
+}
+
+// startResumeServer serves the filter API of the given database in-process.
+func startResumeServer(t *testing.T, db ethdb.Database, config Config) (*testBackend, *rpc.Client) {
+	backend, sys := newTestFilterSystem(t, db, config)
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", NewFilterAPI(sys)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return backend, client
+}
+
+func TestResumeLogsSubscription(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	contract, chainA, chainB := resumeTestChains(db)
+	backend, client := startResumeServer(t, db, Config{})
+
+	crit := map[string]interface{}{"address": []common.Address{contract}}
+	receive := func(ch chan types.Log, sub *rpc.ClientSubscription) types.Log {
+		t.Helper()
+		select {
+		case log := <-ch:
+			return log
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for log")
+		}
+		return types.Log{}
+	}
+	topic := func(log types.Log) uint64 {
+		return new(big.Int).SetBytes(log.Topics[0][:]).Uint64()
+	}
+	// Resume from the log of block 5 of the reorged chain, the logs of blocks
+	// 4 and 5 have to be retracted, then the fork replayed.
+	var (
+		ch     = make(chan types.Log, 16)
+		cursor = &SubscriptionCursor{BlockNumber: 5, BlockHash: chainA[4].Hash(), LogIndex: new(hexutil.Uint)}
+	)
+	sub, err := client.EthSubscribe(context.Background(), ch, "logs", crit, cursor)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for _, want := range []struct {
+		block   *types.Block
+		removed bool
+		topic   uint64
+	}{
+		{chainA[3], true, 4}, {chainA[4], true, 5},
+		{chainB[3], false, 8}, {chainB[4], false, 10}, {chainB[5], false, 12}, {chainB[6], false, 14}, {chainB[7], false, 16},
+	} {
+		log := receive(ch, sub)
+		if log.BlockHash != want.block.Hash() || log.Removed != want.removed || topic(log) != want.topic {
+			t.Fatalf("wrong log: have block %d removed %t topic %d, want block %d removed %t topic %d",
+				log.BlockNumber, log.Removed, topic(log), want.block.NumberU64(), want.removed, want.topic)
+		}
+	}
+	// Live logs already replayed are skipped, new ones delivered
+	replayed := &types.Log{Address: contract, Topics: []common.Hash{common.BigToHash(big.NewInt(16))}, BlockNumber: 8, BlockHash: chainB[7].Hash()}
+	fresh := &types.Log{Address: contract, Topics: []common.Hash{common.BigToHash(big.NewInt(18))}, BlockNumber: 9, BlockHash: common.Hash{0x09}}
+	for backend.logsFeed.Send([]*types.Log{replayed, fresh}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if log := receive(ch, sub); log.BlockNumber != 9 || topic(log) != 18 {
+		t.Fatalf("wrong live log: block %d topic %d", log.BlockNumber, topic(log))
+	}
+	sub.Unsubscribe()
+
+	// Resume in the middle of a canonical block range, given as block number
+	ch = make(chan types.Log, 16)
+	sub, err = client.EthSubscribe(context.Background(), ch, "logs", crit, "0x6")
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for number := uint64(6); number <= 8; number++ {
+		if log := receive(ch, sub); log.BlockNumber != number || log.Removed || topic(log) != 2*number {
+			t.Fatalf("wrong log: block %d removed %t topic %d", log.BlockNumber, log.Removed, topic(log))
+		}
+	}
+	sub.Unsubscribe()
+
+	// A starting block without cursor doesn't replay the history
+	ch = make(chan types.Log, 16)
+	crit["fromBlock"] = "0x0"
+	sub, err = client.EthSubscribe(context.Background(), ch, "logs", crit)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	for backend.logsFeed.Send([]*types.Log{fresh}) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if log := receive(ch, sub); log.BlockNumber != 9 || topic(log) != 18 {
+		t.Fatalf("wrong live log: block %d topic %d", log.BlockNumber, topic(log))
+	}
+	sub.Unsubscribe()
+	delete(crit, "fromBlock")
+
+	// Unknown cursors are rejected
+	cursor = &SubscriptionCursor{BlockNumber: 5, BlockHash: common.Hash{0x01}}
+	if _, err := client.EthSubscribe(context.Background(), make(chan types.Log), "logs", crit, cursor); err == nil {
+		t.Fatal("subscription with unknown cursor succeeded")
+	}
+}
+
+func TestResumeReplayLimit(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	resumeTestChains(db)
+	_, client := startResumeServer(t, db, Config{ReplayLimit: 2})
+
+	// The head is block 8, replaying from block 7 is within the limit
+	sub, err := client.EthSubscribe(context.Background(), make(chan *types.Header, 16), "newHeads", "0x7")
+	if err != nil {
+		t.Fatalf("failed to subscribe within the replay limit: %v", err)
+	}
+	sub.Unsubscribe()
+
+	for _, kind := range []string{"newHeads", "logs"} {
+		args := []interface{}{kind}
+		if kind == "logs" {
+			args = append(args, map[string]interface{}{})
+		}
+		args = append(args, "0x6")
+		if _, err := client.EthSubscribe(context.Background(), make(chan interface{}), args...); err == nil {
+			t.Fatalf("%s subscription beyond the replay limit succeeded", kind)
+		}
+	}
+}
+
+func TestResumeHeadsSubscription(t *testing.T) {
+	t.Parallel()
+
+	db := rawdb.NewMemoryDatabase()
+	_, chainA, chainB := resumeTestChains(db)
+	_, client := startResumeServer(t, db, Config{})
+
+	// Resume from a reorged block, replaying the fork from the common ancestor
+	ch := make(chan *types.Header, 16)
+	cursor := &SubscriptionCursor{BlockNumber: 5, BlockHash: chainA[4].Hash()}
+	sub, err := client.EthSubscribe(context.Background(), ch, "newHeads", cursor)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for _, want := range chainB[3:] {
+		select {
+		case header := <-ch:
+			if header.Hash() != want.Hash() {
+				t.Fatalf("wrong header: have %d %x, want %d %x", header.Number, header.Hash(), want.Number(), want.Hash())
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for header")
+		}
+	}
+}
@@ -26,7 +26,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -49,10 +48,17 @@ const (
 	// The number is referenced from the size of tx pool.
 	txChanSize = 4096
 
+	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
+	chainHeadChanSize = 10
+
 	// txMaxBroadcastSize is the max size of a transaction that will be broadcasted.
 	// All transactions with a higher size will be announced and need to be fetched
 	// by the peer.
 	txMaxBroadcastSize = 4096
+
+	// blockRangeUpdateInterval is the number of blocks the chain head needs to
+	// progress before the new block range is announced to eth/69 peers.
+	blockRangeUpdateInterval = 32
 )
 
 var syncChallengeTimeout = 15 * time.Second // Time allowance for a node to reply to the sync progress challenge
@@ -96,9 +102,8 @@ type handlerConfig struct {
 }
 
 type handler struct {
-	nodeID     enode.ID
-	networkID  uint64
-	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	nodeID    enode.ID
+	networkID uint64
 
 	snapSync atomic.Bool // Flag whether snap sync is enabled (gets disabled if we already have blocks)
 	synced   atomic.Bool // Flag whether we're considered synchronised (enables transaction processing)
@@ -116,6 +121,9 @@ type handler struct {
 	txsCh    chan core.NewTxsEvent
 	txsSub   event.Subscription
 
+	chainHeadCh  chan core.ChainHeadEvent
+	chainHeadSub event.Subscription
+
 	requiredBlocks map[uint64]common.Hash
 
 	// channels for fetcher, syncer, txsyncLoop
@@ -136,7 +144,6 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	h := &handler{
 		nodeID:         config.NodeID,
 		networkID:      config.Network,
-		forkFilter:     forkid.NewFilter(config.Chain),
 		eventMux:       config.EventMux,
 		database:       config.Database,
 		txpool:         config.TxPool,
@@ -249,15 +256,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 	}
 
 	// Execute the Ethereum handshake
-	var (
-		genesis = h.chain.Genesis()
-		head    = h.chain.CurrentHeader()
-		hash    = head.Hash()
-		number  = head.Number.Uint64()
-		td      = h.chain.GetTd(hash, number)
-	)
-	forkID := forkid.NewID(h.chain.Config(), genesis, number, head.Time)
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if err := peer.Handshake(h.networkID, h.chain, h.blockRange(h.chain.CurrentHeader())); err != nil {
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -428,6 +427,12 @@ func (h *handler) Start(maxPeers int) {
 	h.txsSub = h.txpool.SubscribeTransactions(h.txsCh, false)
 	go h.txBroadcastLoop()
 
+	// announce the available block range to eth/69 peers
+	h.wg.Add(1)
+	h.chainHeadCh = make(chan core.ChainHeadEvent, chainHeadChanSize)
+	h.chainHeadSub = h.chain.SubscribeChainHeadEvent(h.chainHeadCh)
+	go h.blockRangeLoop()
+
 	// start sync handlers
 	h.txFetcher.Start()
 
@@ -437,7 +442,8 @@ func (h *handler) Start(maxPeers int) {
 }
 
 func (h *handler) Stop() {
-	h.txsSub.Unsubscribe() // quits txBroadcastLoop
+	h.txsSub.Unsubscribe()       // quits txBroadcastLoop
+	h.chainHeadSub.Unsubscribe() // quits blockRangeLoop
 	h.txFetcher.Stop()
 	h.downloader.Terminate()
 
@@ -546,6 +552,44 @@ func (h *handler) txBroadcastLoop() {
 	}
 }
 
+// blockRange returns the range of blocks available locally for serving, ending
+// at the given head. The blocks whose history has been pruned are not served.
+func (h *handler) blockRange(head *types.Header) eth.BlockRangeUpdatePacket {
+	return eth.BlockRangeUpdatePacket{
+		EarliestBlock:   min(h.chain.HistoryPruningCutoff(), head.Number.Uint64()),
+		LatestBlock:     head.Number.Uint64(),
+		LatestBlockHash: head.Hash(),
+	}
+}
+
+// blockRangeLoop announces the locally available block range to the connected
+// eth/69 peers whenever the chain head progresses sufficiently or reorgs to a
+// lower block.
+func (h *handler) blockRangeLoop() {
+	defer h.wg.Done()
+
+	last := h.blockRange(h.chain.CurrentHeader())
+	for {
+		select {
+		case event := <-h.chainHeadCh:
+			current := h.blockRange(event.Block.Header())
+			if current.LatestBlock >= last.LatestBlock && current.LatestBlock-last.LatestBlock < blockRangeUpdateInterval {
+				continue
+			}
+			last = current
+			for _, peer := range h.peers.peersWithBlockRange() {
+				go func(peer *ethPeer) {
+					if err := peer.SendBlockRangeUpdate(current); err != nil {
+						peer.Log().Debug("Failed to announce block range", "err", err)
+					}
+				}(peer)
+			}
+		case <-h.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
 // enableSyncedFeatures enables the post-sync functionalities when the initial
 // sync is finished.
 func (h *handler) enableSyncedFeatures() {
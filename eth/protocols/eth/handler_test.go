@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/trie"
 )
 
 var (
@@ -151,6 +152,7 @@ func (b *testBackend) Handle(*Peer, Packet) error {
 
 // Tests that block headers can be retrieved from a remote chain based on user queries.
 func TestGetBlockHeaders68(t *testing.T) { testGetBlockHeaders(t, ETH68) }
+func TestGetBlockHeaders69(t *testing.T) { testGetBlockHeaders(t, ETH69) }
 
 func testGetBlockHeaders(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -336,6 +338,7 @@ func testGetBlockHeaders(t *testing.T, protocol uint) {
 
 // Tests that block contents can be retrieved from a remote chain based on their hashes.
 func TestGetBlockBodies68(t *testing.T) { testGetBlockBodies(t, ETH68) }
+func TestGetBlockBodies69(t *testing.T) { testGetBlockBodies(t, ETH69) }
 
 func testGetBlockBodies(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -430,6 +433,7 @@ func testGetBlockBodies(t *testing.T, protocol uint) {
 
 // Tests that the transaction receipts can be retrieved based on hashes.
 func TestGetBlockReceipts68(t *testing.T) { testGetBlockReceipts(t, ETH68) }
+func TestGetBlockReceipts69(t *testing.T) { testGetBlockReceipts(t, ETH69) }
 
 func testGetBlockReceipts(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -492,10 +496,143 @@ func testGetBlockReceipts(t *testing.T, protocol uint) {
 		RequestId:          123,
 		GetReceiptsRequest: hashes,
 	})
+	if protocol < ETH69 {
+		if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket{
+			RequestId:        123,
+			ReceiptsResponse: receipts,
+		}); err != nil {
+			t.Errorf("receipts mismatch: %v", err)
+		}
+		return
+	}
+	// The eth/69 receipts omit the blooms, make sure they are reconstructed
+	// and hash to the receipt roots of the blocks
+	msg, err := peer.app.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read receipts: %v", err)
+	}
+	res := new(ReceiptsPacket69)
+	if err := msg.Decode(res); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	if res.RequestId != 123 {
+		t.Fatalf("request id mismatch: have %d, want %d", res.RequestId, 123)
+	}
+	decoded, err := res.ReceiptsResponse69.Unpack()
+	if err != nil {
+		t.Fatalf("failed to convert receipts: %v", err)
+	}
+	if len(decoded) != len(hashes) {
+		t.Fatalf("receipt count mismatch: have %d, want %d", len(decoded), len(hashes))
+	}
+	for i, list := range decoded {
+		header := backend.chain.GetHeaderByHash(hashes[i])
+		if root := types.DeriveSha(types.Receipts(list), trie.NewStackTrie(nil)); root != header.ReceiptHash {
+			t.Errorf("block %d: receipt root mismatch: have %x, want %x", i, root, header.ReceiptHash)
+		}
+		for j, receipt := range list {
+			if receipt.Bloom != receipts[i][j].Bloom {
+				t.Errorf("block %d, receipt %d: bloom mismatch", i, j)
+			}
+		}
+	}
+}
+
+// Tests that the bodies and receipts of blocks whose history was pruned are not
+// served, while the retained ones still are.
+func TestServePrunedHistory(t *testing.T) {
+	t.Parallel()
+
+	var (
+		config = *params.TestChainConfig
+		gspec  = &core.Genesis{
+			Config:  &config,
+			Alloc:   types.GenesisAlloc{testAddr: {Balance: big.NewInt(params.Ether)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = beacon.New(ethash.NewFaker())
+		signer = types.LatestSigner(gspec.Config)
+	)
+	addTx := func(i int, gen *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(testAddr), common.Address{0xaa}, big.NewInt(1), params.TxGas, gen.BaseFee(), nil), signer, testKey)
+		gen.AddTx(tx)
+	}
+	genDb, preBlocks, preReceipts := core.GenerateChainWithGenesis(gspec, engine, 8, addTx)
+
+	td := params.GenesisDifficulty.Uint64()
+	for _, block := range preBlocks {
+		td += block.Difficulty().Uint64()
+	}
+	config.TerminalTotalDifficulty = new(big.Int).SetUint64(td)
+	postBlocks, postReceipts := core.GenerateChain(gspec.Config, preBlocks[len(preBlocks)-1], engine, genDb, 4, func(i int, gen *core.BlockGen) {
+		gen.SetPoS()
+		addTx(i, gen)
+	})
+	var (
+		blocks   = append(preBlocks, postBlocks...)
+		receipts = append(preReceipts, postReceipts...)
+		headers  = make([]*types.Header, len(blocks))
+	)
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	// Import the chain into the ancient store and prune the pre-merge history
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), "", "", false)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	chain, _ := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, uint64(len(blocks))); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	chain.Stop()
+
+	cutoff, err := core.PruneChainHistory(db)
+	if err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if chain, err = core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil); err != nil {
+		t.Fatalf("failed to reopen pruned chain: %v", err)
+	}
+	defer chain.Stop()
+
+	backend := &testBackend{db: db, chain: chain}
+	peer, _ := newTestPeer("peer", ETH68, backend)
+	defer peer.close()
+
+	// Request a pruned block, the first retained block and the genesis block
+	var (
+		pruned   = blocks[1]
+		retained = blocks[cutoff-1]
+		genesis  = chain.Genesis()
+		hashes   = []common.Hash{pruned.Hash(), retained.Hash(), genesis.Hash()}
+	)
+	p2p.Send(peer.app, GetBlockBodiesMsg, &GetBlockBodiesPacket{
+		RequestId:             123,
+		GetBlockBodiesRequest: hashes,
+	})
+	if err := p2p.ExpectMsg(peer.app, BlockBodiesMsg, &BlockBodiesPacket{
+		RequestId: 123,
+		BlockBodiesResponse: []*BlockBody{
+			{Transactions: retained.Transactions(), Uncles: retained.Uncles()},
+			{Transactions: genesis.Transactions(), Uncles: genesis.Uncles()},
+		},
+	}); err != nil {
+		t.Fatalf("bodies mismatch: %v", err)
+	}
+	p2p.Send(peer.app, GetReceiptsMsg, &GetReceiptsPacket{
+		RequestId:          124,
+		GetReceiptsRequest: hashes,
+	})
 	if err := p2p.ExpectMsg(peer.app, ReceiptsMsg, &ReceiptsPacket{
-		RequestId:        123,
-		ReceiptsResponse: receipts,
+		RequestId:        124,
+		ReceiptsResponse: [][]*types.Receipt{chain.GetReceiptsByHash(retained.Hash()), {}},
 	}); err != nil {
-		t.Errorf("receipts mismatch: %v", err)
+		t.Fatalf("receipts mismatch: %v", err)
 	}
 }
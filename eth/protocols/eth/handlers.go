@@ -233,19 +233,46 @@ func ServiceGetBlockBodiesQuery(chain *core.BlockChain, query GetBlockBodiesRequ
 	return bodies
 }
 
-func handleGetReceipts(backend Backend, msg Decoder, peer *Peer) error {
+func handleGetReceipts68(backend Backend, msg Decoder, peer *Peer) error {
 	// Decode the block receipts retrieval message
 	var query GetReceiptsPacket
 	if err := msg.Decode(&query); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
-	response := ServiceGetReceiptsQuery(backend.Chain(), query.GetReceiptsRequest)
+	response := ServiceGetReceiptsQuery68(backend.Chain(), query.GetReceiptsRequest)
 	return peer.ReplyReceiptsRLP(query.RequestId, response)
 }
 
-// ServiceGetReceiptsQuery assembles the response to a receipt query. It is
-// exposed to allow external packages to test protocol behavior.
-func ServiceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest) []rlp.RawValue {
+func handleGetReceipts69(backend Backend, msg Decoder, peer *Peer) error {
+	// Decode the block receipts retrieval message
+	var query GetReceiptsPacket
+	if err := msg.Decode(&query); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	response := ServiceGetReceiptsQuery69(backend.Chain(), query.GetReceiptsRequest)
+	return peer.ReplyReceiptsRLP(query.RequestId, response)
+}
+
+// ServiceGetReceiptsQuery68 assembles the eth/68 response to a receipt query.
+// It is exposed to allow external packages to test protocol behavior.
+func ServiceGetReceiptsQuery68(chain *core.BlockChain, query GetReceiptsRequest) []rlp.RawValue {
+	return serviceGetReceiptsQuery(chain, query, func(receipts types.Receipts) any {
+		return receipts
+	})
+}
+
+// ServiceGetReceiptsQuery69 assembles the eth/69 response to a receipt query,
+// omitting the bloom filters from the receipts. It is exposed to allow external
+// packages to test protocol behavior.
+func ServiceGetReceiptsQuery69(chain *core.BlockChain, query GetReceiptsRequest) []rlp.RawValue {
+	return serviceGetReceiptsQuery(chain, query, func(receipts types.Receipts) any {
+		return encodeReceipts69(receipts)
+	})
+}
+
+// serviceGetReceiptsQuery gathers the receipts of the requested blocks, using
+// the given converter to pick the version specific network representation.
+func serviceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest, convert func(types.Receipts) any) []rlp.RawValue {
 	// Gather state data until the fetch or network limits is reached
 	var (
 		bytes    int
@@ -264,7 +291,7 @@ func ServiceGetReceiptsQuery(chain *core.BlockChain, query GetReceiptsRequest) [
 			}
 		}
 		// If known, encode and queue for response packet
-		if encoded, err := rlp.EncodeToBytes(results); err != nil {
+		if encoded, err := rlp.EncodeToBytes(convert(results)); err != nil {
 			log.Error("Failed to encode receipt", "err", err)
 		} else {
 			receipts = append(receipts, encoded)
@@ -331,27 +358,59 @@ func handleBlockBodies(backend Backend, msg Decoder, peer *Peer) error {
 	}, metadata)
 }
 
-func handleReceipts(backend Backend, msg Decoder, peer *Peer) error {
+func handleReceipts68(backend Backend, msg Decoder, peer *Peer) error {
 	// A batch of receipts arrived to one of our previous requests
 	res := new(ReceiptsPacket)
 	if err := msg.Decode(res); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
+	return dispatchReceipts(peer, res.RequestId, res.ReceiptsResponse)
+}
+
+func handleReceipts69(backend Backend, msg Decoder, peer *Peer) error {
+	// A batch of receipts arrived to one of our previous requests
+	res := new(ReceiptsPacket69)
+	if err := msg.Decode(res); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	// Reconstruct the bloom filters omitted from the wire
+	receipts, err := res.ReceiptsResponse69.Unpack()
+	if err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	return dispatchReceipts(peer, res.RequestId, receipts)
+}
+
+// dispatchReceipts delivers a batch of receipts to the request awaiting it.
+func dispatchReceipts(peer *Peer, id uint64, receipts ReceiptsResponse) error {
 	metadata := func() interface{} {
 		hasher := trie.NewStackTrie(nil)
-		hashes := make([]common.Hash, len(res.ReceiptsResponse))
-		for i, receipt := range res.ReceiptsResponse {
+		hashes := make([]common.Hash, len(receipts))
+		for i, receipt := range receipts {
 			hashes[i] = types.DeriveSha(types.Receipts(receipt), hasher)
 		}
 		return hashes
 	}
 	return peer.dispatchResponse(&Response{
-		id:   res.RequestId,
+		id:   id,
 		code: ReceiptsMsg,
-		Res:  &res.ReceiptsResponse,
+		Res:  &receipts,
 	}, metadata)
 }
 
+func handleBlockRangeUpdate(backend Backend, msg Decoder, peer *Peer) error {
+	// A remote peer announced a change in its available block range
+	update := new(BlockRangeUpdatePacket)
+	if err := msg.Decode(update); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if err := update.Validate(); err != nil {
+		return err
+	}
+	peer.setBlockRange(*update)
+	return nil
+}
+
 func handleNewPooledTransactionHashes(backend Backend, msg Decoder, peer *Peer) error {
 	// New transaction announcement arrived, make sure we have
 	// a valid and fresh chain to handle them
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
@@ -35,25 +36,111 @@ const (
 )
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+// network IDs, head and genesis blocks. Depending on the negotiated version,
+// either the total difficulty (eth/68) or the range of available blocks (eth/69)
+// is exchanged too.
+func (p *Peer) Handshake(network uint64, chain *core.BlockChain, rangeMsg BlockRangeUpdatePacket) error {
+	switch p.version {
+	case ETH69:
+		return p.handshake69(network, chain, rangeMsg)
+	case ETH68:
+		return p.handshake68(network, chain)
+	default:
+		return fmt.Errorf("%w: %d", errProtocolVersionMismatch, p.version)
+	}
+}
+
+func (p *Peer) handshake68(network uint64, chain *core.BlockChain) error {
+	var (
+		genesis = chain.Genesis()
+		head    = chain.CurrentHeader()
+		hash    = head.Hash()
+		number  = head.Number.Uint64()
+		td      = chain.GetTd(hash, number)
+		forkID  = forkid.NewID(chain.Config(), genesis, number, head.Time)
+	)
+	var status StatusPacket68 // safe to read after two values have been received from errc
+
+	err := p.exchangeStatus(&StatusPacket68{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		TD:              td,
+		Head:            hash,
+		Genesis:         genesis.Hash(),
+		ForkID:          forkID,
+	}, func() error {
+		if err := p.readStatus(&status); err != nil {
+			return err
+		}
+		return p.checkStatus(network, status.NetworkID, status.ProtocolVersion, genesis.Hash(), status.Genesis, status.ForkID, forkid.NewFilter(chain))
+	})
+	if err != nil {
+		return err
+	}
+	p.td, p.head = status.TD, status.Head
+
+	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
+	// larger, it will still fit within 100 bits
+	if tdlen := p.td.BitLen(); tdlen > 100 {
+		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	}
+	return nil
+}
+
+func (p *Peer) handshake69(network uint64, chain *core.BlockChain, rangeMsg BlockRangeUpdatePacket) error {
+	var (
+		genesis = chain.Genesis()
+		head    = chain.CurrentHeader()
+		forkID  = forkid.NewID(chain.Config(), genesis, head.Number.Uint64(), head.Time)
+	)
+	var status StatusPacket69 // safe to read after two values have been received from errc
+
+	err := p.exchangeStatus(&StatusPacket69{
+		ProtocolVersion: uint32(p.version),
+		NetworkID:       network,
+		Genesis:         genesis.Hash(),
+		ForkID:          forkID,
+		EarliestBlock:   rangeMsg.EarliestBlock,
+		LatestBlock:     rangeMsg.LatestBlock,
+		LatestBlockHash: rangeMsg.LatestBlockHash,
+	}, func() error {
+		if err := p.readStatus(&status); err != nil {
+			return err
+		}
+		if err := p.checkStatus(network, status.NetworkID, status.ProtocolVersion, genesis.Hash(), status.Genesis, status.ForkID, forkid.NewFilter(chain)); err != nil {
+			return err
+		}
+		blockRange := BlockRangeUpdatePacket{
+			EarliestBlock:   status.EarliestBlock,
+			LatestBlock:     status.LatestBlock,
+			LatestBlockHash: status.LatestBlockHash,
+		}
+		return blockRange.Validate()
+	})
+	if err != nil {
+		return err
+	}
+	// Total difficulty is not advertised on eth/69, track the block range instead
+	p.td = new(big.Int)
+	p.setBlockRange(BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	})
+	return nil
+}
+
+// exchangeStatus sends the local status message and concurrently reads and
+// validates the remote one, waiting for both to complete.
+func (p *Peer) exchangeStatus(status Packet, readStatus func() error) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
-	var status StatusPacket // safe to read after two values have been received from errc
-
 	go func() {
-		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
-			ProtocolVersion: uint32(p.version),
-			NetworkID:       network,
-			TD:              td,
-			Head:            head,
-			Genesis:         genesis,
-			ForkID:          forkID,
-		})
+		errc <- p2p.Send(p.rw, StatusMsg, status)
 	}()
 	go func() {
-		errc <- p.readStatus(network, &status, genesis, forkFilter)
+		errc <- readStatus()
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
 	defer timeout.Stop()
@@ -69,18 +156,11 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 			return p2p.DiscReadTimeout
 		}
 	}
-	p.td, p.head = status.TD, status.Head
-
-	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
-	// larger, it will still fit within 100 bits
-	if tdlen := p.td.BitLen(); tdlen > 100 {
-		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
-	}
 	return nil
 }
 
 // readStatus reads the remote handshake message.
-func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
+func (p *Peer) readStatus(status Packet) error {
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
@@ -91,20 +171,26 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if msg.Size > maxMessageSize {
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
-	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
+	// Decode the handshake
+	if err := msg.Decode(status); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
-	if status.NetworkID != network {
-		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
+	return nil
+}
+
+// checkStatus makes sure the fields common to all status message versions
+// match the local chain.
+func (p *Peer) checkStatus(network uint64, remoteNetwork uint64, version uint32, genesis common.Hash, remoteGenesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+	if remoteNetwork != network {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, remoteNetwork, network)
 	}
-	if uint(status.ProtocolVersion) != p.version {
-		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, status.ProtocolVersion, p.version)
+	if uint(version) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, version, p.version)
 	}
-	if status.Genesis != genesis {
-		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
+	if remoteGenesis != genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, remoteGenesis, genesis)
 	}
-	if err := forkFilter(status.ForkID); err != nil {
+	if err := forkFilter(forkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
 	return nil
@@ -28,6 +28,7 @@ import (
 
 // Tests that handshake failures are detected and reported correctly.
 func TestHandshake68(t *testing.T) { testHandshake(t, ETH68) }
+func TestHandshake69(t *testing.T) { testHandshake(t, ETH69) }
 
 func testHandshake(t *testing.T, protocol uint) {
 	t.Parallel()
@@ -41,33 +42,69 @@ func testHandshake(t *testing.T, protocol uint) {
 		head    = backend.chain.CurrentBlock()
 		td      = backend.chain.GetTd(head.Hash(), head.Number.Uint64())
 		forkID  = forkid.NewID(backend.chain.Config(), backend.chain.Genesis(), backend.chain.CurrentHeader().Number.Uint64(), backend.chain.CurrentHeader().Time)
+		latest  = head.Number.Uint64()
 	)
-	tests := []struct {
+	type handshakeTest struct {
 		code uint64
 		data interface{}
 		want error
-	}{
+	}
+	tests := []handshakeTest{
 		{
 			code: TransactionsMsg, data: []interface{}{},
 			want: errNoStatusMsg,
 		},
-		{
-			code: StatusMsg, data: StatusPacket{10, 1, td, head.Hash(), genesis.Hash(), forkID},
-			want: errProtocolVersionMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 999, td, head.Hash(), genesis.Hash(), forkID},
-			want: errNetworkIDMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), common.Hash{3}, forkID},
-			want: errGenesisMismatch,
-		},
-		{
-			code: StatusMsg, data: StatusPacket{uint32(protocol), 1, td, head.Hash(), genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}},
-			want: errForkIDRejected,
-		},
 	}
+	switch protocol {
+	case ETH68:
+		tests = append(tests, []handshakeTest{
+			{
+				code: StatusMsg, data: StatusPacket68{10, 1, td, head.Hash(), genesis.Hash(), forkID},
+				want: errProtocolVersionMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket68{uint32(protocol), 999, td, head.Hash(), genesis.Hash(), forkID},
+				want: errNetworkIDMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket68{uint32(protocol), 1, td, head.Hash(), common.Hash{3}, forkID},
+				want: errGenesisMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket68{uint32(protocol), 1, td, head.Hash(), genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}},
+				want: errForkIDRejected,
+			},
+		}...)
+	case ETH69:
+		tests = append(tests, []handshakeTest{
+			{
+				code: StatusMsg, data: StatusPacket69{10, 1, genesis.Hash(), forkID, 0, latest, head.Hash()},
+				want: errProtocolVersionMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 999, genesis.Hash(), forkID, 0, latest, head.Hash()},
+				want: errNetworkIDMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, common.Hash{3}, forkID, 0, latest, head.Hash()},
+				want: errGenesisMismatch,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkid.ID{Hash: [4]byte{0x00, 0x01, 0x02, 0x03}}, 0, latest, head.Hash()},
+				want: errForkIDRejected,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkID, latest + 1, latest, head.Hash()},
+				want: errInvalidBlockRange,
+			},
+			{
+				code: StatusMsg, data: StatusPacket69{uint32(protocol), 1, genesis.Hash(), forkID, 0, latest, common.Hash{}},
+				want: errInvalidBlockRange,
+			},
+		}...)
+	}
+	rangeMsg := BlockRangeUpdatePacket{EarliestBlock: 0, LatestBlock: latest, LatestBlockHash: head.Hash()}
+
 	for i, test := range tests {
 		// Create the two peers to shake with each other
 		app, net := p2p.MsgPipe()
@@ -80,7 +117,7 @@ func testHandshake(t *testing.T, protocol uint) {
 		// Send the junk test with one peer, check the handshake failure
 		go p2p.Send(app, test.code, test.data)
 
-		err := peer.Handshake(1, td, head.Hash(), genesis.Hash(), forkID, forkid.NewFilter(backend.chain))
+		err := peer.Handshake(1, backend.chain, rangeMsg)
 		if err == nil {
 			t.Errorf("test %d: protocol returned nil error, want %q", i, test.want)
 		} else if !errors.Is(err, test.want) {
@@ -88,3 +125,40 @@ func testHandshake(t *testing.T, protocol uint) {
 		}
 	}
 }
+
+// Tests that a successful eth/69 handshake tracks the remote block range.
+func TestHandshakeBlockRange69(t *testing.T) {
+	t.Parallel()
+
+	backend := newTestBackend(3)
+	defer backend.close()
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	local := NewPeer(ETH69, p2p.NewPeer(enode.ID{1}, "local", nil), net, nil)
+	defer local.Close()
+	remote := NewPeer(ETH69, p2p.NewPeer(enode.ID{2}, "remote", nil), app, nil)
+	defer remote.Close()
+
+	head := backend.chain.CurrentBlock()
+	want := BlockRangeUpdatePacket{EarliestBlock: 1, LatestBlock: head.Number.Uint64(), LatestBlockHash: head.Hash()}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- remote.Handshake(1, backend.chain, want)
+	}()
+	if err := local.Handshake(1, backend.chain, BlockRangeUpdatePacket{LatestBlock: 1, LatestBlockHash: common.Hash{1}}); err != nil {
+		t.Fatalf("local handshake failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("remote handshake failed: %v", err)
+	}
+	if have := local.BlockRange(); have == nil || *have != want {
+		t.Fatalf("block range mismatch: have %v, want %v", have, want)
+	}
+	if have, _ := local.Head(); have != head.Hash() {
+		t.Fatalf("head mismatch: have %x, want %x", have, head.Hash())
+	}
+}
@@ -50,8 +50,9 @@ type Peer struct {
 	rw        p2p.MsgReadWriter // Input/output streams for snap
 	version   uint              // Protocol version negotiated
 
-	head common.Hash // Latest advertised head block hash
-	td   *big.Int    // Latest advertised head block total difficulty
+	head       common.Hash             // Latest advertised head block hash
+	td         *big.Int                // Latest advertised head block total difficulty
+	blockRange *BlockRangeUpdatePacket // Latest advertised block range (eth/69)
 
 	txpool      TxPool             // Transaction pool used by the broadcasters for liveness checks
 	knownTxs    *knownCache        // Set of transaction hashes known to be known by this peer
@@ -126,6 +127,29 @@ func (p *Peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// BlockRange retrieves the latest block range advertised by the peer. It is
+// nil for peers not running eth/69 or newer.
+func (p *Peer) BlockRange() *BlockRangeUpdatePacket {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.blockRange == nil {
+		return nil
+	}
+	blockRange := *p.blockRange
+	return &blockRange
+}
+
+// setBlockRange updates the block range advertised by the peer, along with
+// its head block hash.
+func (p *Peer) setBlockRange(blockRange BlockRangeUpdatePacket) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.blockRange = &blockRange
+	p.head = blockRange.LatestBlockHash
+}
+
 // KnownTransaction returns whether peer is known to already have a transaction.
 func (p *Peer) KnownTransaction(hash common.Hash) bool {
 	return p.knownTxs.Contains(hash)
@@ -231,6 +255,12 @@ func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
 	})
 }
 
+// SendBlockRangeUpdate announces the range of blocks available locally to
+// the remote peer.
+func (p *Peer) SendBlockRangeUpdate(blockRange BlockRangeUpdatePacket) error {
+	return p2p.Send(p.rw, BlockRangeUpdateMsg, &blockRange)
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *Peer) RequestOneHeader(hash common.Hash, sink chan *Response) (*Request, error) {
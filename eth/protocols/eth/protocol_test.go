@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -100,6 +101,7 @@ func TestEmptyMessages(t *testing.T) {
 		// Receipts
 		GetReceiptsPacket{1111, GetReceiptsRequest([]common.Hash{})},
 		ReceiptsPacket{1111, ReceiptsResponse([][]*types.Receipt{})},
+		ReceiptsPacket69{1111, ReceiptsResponse69([][]*Receipt69{})},
 		// Transactions
 		GetPooledTransactionsPacket{1111, GetPooledTransactionsRequest([]common.Hash{})},
 		PooledTransactionsPacket{1111, PooledTransactionsResponse([]*types.Transaction{})},
@@ -228,6 +230,18 @@ func TestMessages(t *testing.T) {
 			ReceiptsRLPPacket{1111, ReceiptsRLPResponse([]rlp.RawValue{receiptsRlp})},
 			common.FromHex("f90172820457f9016cf90169f901668001b9010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000f85ff85d940000000000000000000000000000000000000011f842a0000000000000000000000000000000000000000000000000000000000000deada0000000000000000000000000000000000000000000000000000000000000beef830100ff"),
 		},
+		{
+			ReceiptsPacket69{1111, ReceiptsResponse69([][]*Receipt69{encodeReceipts69(receipts)})},
+			common.FromHex("f86d820457f868f866f864808001f85ff85d940000000000000000000000000000000000000011f842a0000000000000000000000000000000000000000000000000000000000000deada0000000000000000000000000000000000000000000000000000000000000beef830100ff"),
+		},
+		{
+			StatusPacket69{ETH69, 1, hashes[0], forkid.ID{Hash: [4]byte{0x01, 0x02, 0x03, 0x04}, Next: 5}, 1, 3333, hashes[1]},
+			common.FromHex("f84f4501a000000000000000000000000000000000000000000000000000000000deadc0dec684010203040501820d05a000000000000000000000000000000000000000000000000000000000feedbeef"),
+		},
+		{
+			BlockRangeUpdatePacket{1, 3333, hashes[1]},
+			common.FromHex("e501820d05a000000000000000000000000000000000000000000000000000000000feedbeef"),
+		},
 		{
 			GetPooledTransactionsPacket{1111, GetPooledTransactionsRequest(hashes)},
 			common.FromHex("f847820457f842a000000000000000000000000000000000000000000000000000000000deadc0dea000000000000000000000000000000000000000000000000000000000feedbeef"),
@@ -0,0 +1,109 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	receiptStatusFailedRLP     = []byte{}
+	receiptStatusSuccessfulRLP = []byte{0x01}
+)
+
+// Receipt69 is the eth/69 network representation of a transaction receipt.
+// Unlike the consensus encoding, the transaction type is always part of the
+// list and the bloom filter is omitted, since it can be recomputed from the
+// logs by the receiving side.
+type Receipt69 struct {
+	TxType            uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*types.Log
+}
+
+// newReceipt69 converts a receipt into its eth/69 network representation.
+func newReceipt69(r *types.Receipt) *Receipt69 {
+	status := r.PostState
+	if len(status) == 0 {
+		status = receiptStatusSuccessfulRLP
+		if r.Status == types.ReceiptStatusFailed {
+			status = receiptStatusFailedRLP
+		}
+	}
+	logs := r.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return &Receipt69{
+		TxType:            r.Type,
+		PostStateOrStatus: status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              logs,
+	}
+}
+
+// toReceipt converts the network representation back into a receipt with
+// the consensus fields populated, reconstructing the bloom filter locally.
+func (r *Receipt69) toReceipt() (*types.Receipt, error) {
+	receipt := &types.Receipt{
+		Type:              r.TxType,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              r.Logs,
+	}
+	switch {
+	case len(r.PostStateOrStatus) == 0:
+		receipt.Status = types.ReceiptStatusFailed
+	case len(r.PostStateOrStatus) == 1 && r.PostStateOrStatus[0] == 0x01:
+		receipt.Status = types.ReceiptStatusSuccessful
+	case len(r.PostStateOrStatus) == common.HashLength:
+		receipt.PostState = r.PostStateOrStatus
+	default:
+		return nil, fmt.Errorf("invalid receipt status %x", r.PostStateOrStatus)
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
+}
+
+// encodeReceipts69 converts a list of receipts into the eth/69 representation.
+func encodeReceipts69(receipts types.Receipts) []*Receipt69 {
+	list := make([]*Receipt69, len(receipts))
+	for i, receipt := range receipts {
+		list[i] = newReceipt69(receipt)
+	}
+	return list
+}
+
+// Unpack converts the eth/69 representation of the receipts of a batch of
+// blocks into full receipts, reconstructing the omitted bloom filters.
+func (p *ReceiptsResponse69) Unpack() (ReceiptsResponse, error) {
+	blocks := make(ReceiptsResponse, len(*p))
+	for i, list := range *p {
+		blocks[i] = make([]*types.Receipt, len(list))
+		for j, r := range list {
+			receipt, err := r.toReceipt()
+			if err != nil {
+				return nil, err
+			}
+			blocks[i][j] = receipt
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracetest
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+type blockTraceCall struct {
+	Type  string           `json:"type"`
+	From  common.Address   `json:"from"`
+	To    common.Address   `json:"to"`
+	Error string           `json:"error"`
+	Calls []blockTraceCall `json:"calls"`
+}
+
+type blockTraceAccount struct {
+	Balance *struct {
+		From *hexutil.Big `json:"from"`
+		To   *hexutil.Big `json:"to"`
+	} `json:"balance"`
+	Nonce *struct {
+		From hexutil.Uint64 `json:"from"`
+		To   hexutil.Uint64 `json:"to"`
+	} `json:"nonce"`
+	Storage map[common.Hash]struct {
+		From common.Hash `json:"from"`
+		To   common.Hash `json:"to"`
+	} `json:"storage"`
+}
+
+type blockTraceRecord struct {
+	Number       uint64      `json:"number"`
+	Hash         common.Hash `json:"hash"`
+	Skipped      bool        `json:"skipped"`
+	Transactions []struct {
+		Hash      common.Hash                          `json:"hash"`
+		Call      *blockTraceCall                      `json:"call"`
+		StateDiff map[common.Address]blockTraceAccount `json:"stateDiff"`
+	} `json:"transactions"`
+	StateDiff map[common.Address]blockTraceAccount `json:"stateDiff"`
+}
+
+// readBlockTraces reads all the records from the segment files in the given
+// directory, in block order. It also returns the names of the segment files.
+func readBlockTraces(t *testing.T, dir string) ([]blockTraceRecord, []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to list segments: %v", err)
+	}
+	var (
+		records []blockTraceRecord
+		names   []string
+	)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("failed to open segment: %v", err)
+		}
+		var in io.Reader = file
+		if strings.HasSuffix(entry.Name(), ".gz") {
+			if in, err = gzip.NewReader(file); err != nil {
+				t.Fatalf("failed to decompress segment %s: %v", entry.Name(), err)
+			}
+		}
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(nil, 16*1024*1024)
+		for scanner.Scan() {
+			var record blockTraceRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("failed to decode record: %v", err)
+			}
+			records = append(records, record)
+		}
+		file.Close()
+	}
+	return records, names
+}
+
+func TestBlockTraceReorg(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		storer  = common.HexToAddress("0x2222222222222222222222222222222222222222")
+		config  = *params.AllEthashProtocolChanges
+		engine  = ethash.NewFaker()
+		outPath = t.TempDir()
+		genesis = &core.Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				// Stores the call value into the first slot, reverts if it's 2
+				storer: {Balance: big.NewInt(0), Code: []byte{0x34, 0x60, 0x02, 0x14, 0x60, 0x0c, 0x57, 0x34, 0x60, 0x00, 0x55, 0x00, 0x5b, 0x60, 0x00, 0x80, 0xfd}},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	// Generate two chains, the second one forking off the first one after
+	// block 3 and becoming canonical. Every block calls the storer with the
+	// value of the block number, the fork with the doubled value.
+	generate := func(n int, fork bool) []*types.Block {
+		_, blocks, _ := core.GenerateChainWithGenesis(genesis, engine, n, func(i int, b *core.BlockGen) {
+			value := int64(i + 1)
+			if fork && i >= 3 {
+				b.SetCoinbase(common.Address{0x02})
+				value *= 2
+			}
+			tx := types.MustSignNewTx(key, signer, &types.LegacyTx{
+				Nonce:    uint64(i),
+				To:       &storer,
+				Value:    big.NewInt(value),
+				Gas:      100000,
+				GasPrice: b.BaseFee(),
+			})
+			b.AddTx(tx)
+		})
+		return blocks
+	}
+	var (
+		chainA = generate(6, false)
+		chainB = generate(8, true)
+	)
+	tracer, err := tracers.LiveDirectory.New("blocktrace", json.RawMessage(fmt.Sprintf(`{"path":%q,"segmentBlocks":3}`, outPath)))
+	if err != nil {
+		t.Fatalf("failed to create blocktrace tracer: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), core.DefaultCacheConfigWithScheme(rawdb.PathScheme), genesis, nil, engine, vm.Config{Tracer: tracer}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if n, err := chain.InsertChain(chainA); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	if n, err := chain.InsertChain(chainB[3:]); err != nil {
+		t.Fatalf("block %d: failed to insert fork into chain: %v", n, err)
+	}
+	chain.Stop()
+
+	records, names := readBlockTraces(t, outPath)
+	want := []string{"blocks-000000000000.jsonl.gz", "blocks-000000000003.jsonl.gz", "blocks-000000000006.jsonl"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("wrong segment files: have %v, want %v", names, want)
+	}
+	if len(records) != 9 {
+		t.Fatalf("wrong number of records: have %d, want 9", len(records))
+	}
+	for i, record := range records {
+		if record.Number != uint64(i) {
+			t.Fatalf("record %d: wrong block number %d", i, record.Number)
+		}
+		if hash := chain.GetCanonicalHash(uint64(i)); record.Hash != hash {
+			t.Fatalf("record %d: wrong block hash: have %x, want %x", i, record.Hash, hash)
+		}
+		if i == 0 {
+			continue
+		}
+		if len(record.Transactions) != 1 {
+			t.Fatalf("record %d: wrong number of transactions %d", i, len(record.Transactions))
+		}
+		tx := record.Transactions[0]
+		if tx.Call == nil || tx.Call.Type != "CALL" || tx.Call.From != sender || tx.Call.To != storer {
+			t.Fatalf("record %d: wrong call trace %+v", i, tx.Call)
+		}
+		// The call of the second block reverts, the value transfer must not be
+		// recorded as a state modification
+		value := uint64(i)
+		if i > 3 {
+			value *= 2
+		}
+		acc, ok := tx.StateDiff[storer]
+		if value == 2 {
+			if ok || tx.Call.Error == "" {
+				t.Fatalf("record %d: reverted call recorded as %+v, error %q", i, acc, tx.Call.Error)
+			}
+			continue
+		}
+		if slot, ok := acc.Storage[common.Hash{}]; !ok || slot.To != common.BigToHash(new(big.Int).SetUint64(value)) {
+			t.Fatalf("record %d: wrong storage modification %+v", i, acc.Storage)
+		}
+		// The mining reward is recorded outside of the transactions
+		coinbase := common.Address{}
+		if i > 3 {
+			coinbase = common.Address{0x02}
+		}
+		if record.StateDiff[coinbase].Balance == nil {
+			t.Fatalf("record %d: missing block reward", i)
+		}
+	}
+}
+
+func TestBlockTraceRevertedCreate(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sender  = crypto.PubkeyToAddress(key.PublicKey)
+		factory = common.HexToAddress("0x3333333333333333333333333333333333333333")
+		revert  = []byte{0x60, 0x00, 0x80, 0xfd} // PUSH1 0, DUP1, REVERT
+		outPath = t.TempDir()
+		genesis = &core.Genesis{
+			Config: params.AllEthashProtocolChanges,
+			Alloc: types.GenesisAlloc{
+				sender: {Balance: big.NewInt(params.Ether)},
+				// Creates a contract whose init code reverts
+				factory: {Balance: big.NewInt(0), Code: []byte{0x63, 0x60, 0x00, 0x80, 0xfd, 0x60, 0x00, 0x52, 0x60, 0x04, 0x60, 0x1c, 0x60, 0x00, 0xf0, 0x00}},
+			},
+		}
+		signer = types.LatestSigner(genesis.Config)
+	)
+	// A contract creation transaction and a CREATE, both reverting
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 1, func(i int, b *core.BlockGen) {
+		b.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 0, Gas: 100000, GasPrice: b.BaseFee(), Data: revert}))
+		b.AddTx(types.MustSignNewTx(key, signer, &types.LegacyTx{Nonce: 1, To: &factory, Gas: 100000, GasPrice: b.BaseFee()}))
+	})
+	tracer, err := tracers.LiveDirectory.New("blocktrace", json.RawMessage(fmt.Sprintf(`{"path":%q}`, outPath)))
+	if err != nil {
+		t.Fatalf("failed to create blocktrace tracer: %v", err)
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{Tracer: tracer}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	chain.Stop()
+
+	records, _ := readBlockTraces(t, outPath)
+	if len(records) != 2 || len(records[1].Transactions) != 2 {
+		t.Fatalf("wrong records: %+v", records)
+	}
+	// The nonce increments of the creators persist, although the frames revert
+	for i, creator := range []common.Address{sender, factory} {
+		tx := records[1].Transactions[i]
+		call := tx.Call
+		if i == 1 && call != nil && len(call.Calls) == 1 {
+			call = &call.Calls[0]
+		}
+		if call == nil || call.Type != "CREATE" || call.Error == "" {
+			t.Fatalf("tx %d: wrong create trace %+v", i, tx.Call)
+		}
+		if nonce := tx.StateDiff[creator].Nonce; nonce == nil || nonce.From != 0 || nonce.To != 1 {
+			t.Fatalf("tx %d: wrong nonce modification of creator %+v", i, nonce)
+		}
+	}
+}
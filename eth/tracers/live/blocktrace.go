@@ -0,0 +1,421 @@
+package live
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+func init() {
+	tracers.LiveDirectory.Register("blocktrace", newBlockTrace)
+}
+
+// defaultSegmentBlocks is the default number of blocks stored in a segment file.
+const defaultSegmentBlocks = 10000
+
+// valueDiff is the modification of a single value.
+type valueDiff[T any] struct {
+	From T `json:"from"`
+	To   T `json:"to"`
+}
+
+// accountDiff contains the modified fields of an account.
+type accountDiff struct {
+	Balance *valueDiff[*hexutil.Big]                `json:"balance,omitempty"`
+	Nonce   *valueDiff[hexutil.Uint64]              `json:"nonce,omitempty"`
+	Code    *valueDiff[hexutil.Bytes]               `json:"code,omitempty"`
+	Storage map[common.Hash]*valueDiff[common.Hash] `json:"storage,omitempty"`
+}
+
+// stateDiff contains the modified accounts, tracking the value of each field
+// before the first and after the last modification.
+type stateDiff map[common.Address]*accountDiff
+
+func (d stateDiff) account(addr common.Address) *accountDiff {
+	acc := d[addr]
+	if acc == nil {
+		acc = new(accountDiff)
+		d[addr] = acc
+	}
+	return acc
+}
+
+func (d stateDiff) balance(addr common.Address, prev, post *big.Int) {
+	acc := d.account(addr)
+	if acc.Balance == nil {
+		acc.Balance = &valueDiff[*hexutil.Big]{From: (*hexutil.Big)(new(big.Int).Set(prev))}
+	}
+	acc.Balance.To = (*hexutil.Big)(new(big.Int).Set(post))
+}
+
+func (d stateDiff) nonce(addr common.Address, prev, post uint64) {
+	acc := d.account(addr)
+	if acc.Nonce == nil {
+		acc.Nonce = &valueDiff[hexutil.Uint64]{From: hexutil.Uint64(prev)}
+	}
+	acc.Nonce.To = hexutil.Uint64(post)
+}
+
+func (d stateDiff) code(addr common.Address, prev, post []byte) {
+	acc := d.account(addr)
+	if acc.Code == nil {
+		acc.Code = &valueDiff[hexutil.Bytes]{From: common.CopyBytes(prev)}
+	}
+	acc.Code.To = common.CopyBytes(post)
+}
+
+func (d stateDiff) storage(addr common.Address, slot, prev, post common.Hash) {
+	acc := d.account(addr)
+	if acc.Storage == nil {
+		acc.Storage = make(map[common.Hash]*valueDiff[common.Hash])
+	}
+	if acc.Storage[slot] == nil {
+		acc.Storage[slot] = &valueDiff[common.Hash]{From: prev}
+	}
+	acc.Storage[slot].To = post
+}
+
+// merge applies the later modifications of the other diff on top of this one.
+func (d stateDiff) merge(other stateDiff) {
+	for addr, later := range other {
+		acc := d[addr]
+		if acc == nil {
+			d[addr] = later
+			continue
+		}
+		if later.Balance != nil {
+			if acc.Balance == nil {
+				acc.Balance = later.Balance
+			} else {
+				acc.Balance.To = later.Balance.To
+			}
+		}
+		if later.Nonce != nil {
+			if acc.Nonce == nil {
+				acc.Nonce = later.Nonce
+			} else {
+				acc.Nonce.To = later.Nonce.To
+			}
+		}
+		if later.Code != nil {
+			if acc.Code == nil {
+				acc.Code = later.Code
+			} else {
+				acc.Code.To = later.Code.To
+			}
+		}
+		for slot, diff := range later.Storage {
+			if acc.Storage == nil {
+				acc.Storage = make(map[common.Hash]*valueDiff[common.Hash])
+			}
+			if prev := acc.Storage[slot]; prev != nil {
+				prev.To = diff.To
+			} else {
+				acc.Storage[slot] = diff
+			}
+		}
+	}
+}
+
+// prune drops the fields which ended up unmodified, along with the accounts
+// without any modified field.
+func (d stateDiff) prune() {
+	for addr, acc := range d {
+		if acc.Balance != nil && acc.Balance.From.ToInt().Cmp(acc.Balance.To.ToInt()) == 0 {
+			acc.Balance = nil
+		}
+		if acc.Nonce != nil && acc.Nonce.From == acc.Nonce.To {
+			acc.Nonce = nil
+		}
+		if acc.Code != nil && bytes.Equal(acc.Code.From, acc.Code.To) {
+			acc.Code = nil
+		}
+		for slot, diff := range acc.Storage {
+			if diff.From == diff.To {
+				delete(acc.Storage, slot)
+			}
+		}
+		if acc.Balance == nil && acc.Nonce == nil && acc.Code == nil && len(acc.Storage) == 0 {
+			delete(d, addr)
+		}
+	}
+}
+
+// blockTraceCall is a call frame of the traced call tree.
+type blockTraceCall struct {
+	Type    string            `json:"type"`
+	From    common.Address    `json:"from"`
+	To      common.Address    `json:"to"`
+	Value   *hexutil.Big      `json:"value,omitempty"`
+	Gas     hexutil.Uint64    `json:"gas"`
+	GasUsed hexutil.Uint64    `json:"gasUsed"`
+	Input   hexutil.Bytes     `json:"input,omitempty"`
+	Output  hexutil.Bytes     `json:"output,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Calls   []*blockTraceCall `json:"calls,omitempty"`
+
+	diff     stateDiff // State modifications of the frame, dropped if it reverts
+	creating bool      // Whether the nonce increment of the creator is pending
+}
+
+// blockTraceTx is the trace of a single transaction.
+type blockTraceTx struct {
+	Hash      common.Hash     `json:"hash"`
+	GasUsed   hexutil.Uint64  `json:"gasUsed"`
+	Error     string          `json:"error,omitempty"`
+	Call      *blockTraceCall `json:"call,omitempty"`
+	StateDiff stateDiff       `json:"stateDiff,omitempty"`
+}
+
+// blockTraceBlock is the record stored for each processed block. Blocks which
+// were skipped by the chain, since their state was already available, are
+// recorded without any traces.
+type blockTraceBlock struct {
+	Number       uint64          `json:"number"`
+	Hash         common.Hash     `json:"hash"`
+	ParentHash   common.Hash     `json:"parentHash"`
+	Skipped      bool            `json:"skipped,omitempty"`
+	Transactions []*blockTraceTx `json:"transactions,omitempty"`
+	StateDiff    stateDiff       `json:"stateDiff,omitempty"` // Modifications outside of transactions, e.g. rewards
+}
+
+type blockTraceConfig struct {
+	Path          string `json:"path"`          // Path to the directory where the segment files will be stored
+	SegmentBlocks uint64 `json:"segmentBlocks"` // Number of blocks stored in a segment file. It defaults to 10000 blocks.
+}
+
+// blockTrace is a live tracer which records the call tree and the state
+// modifications of every transaction, along with the block level state
+// modifications, into segment files keyed by block number. The segments are
+// compressed once the chain progresses past them. Reorgs are handled by
+// rewinding the segments to the first block of the new chain segment.
+type blockTrace struct {
+	writer *segmentWriter
+	block  *blockTraceBlock  // Block being processed, nil outside of blocks
+	tx     *blockTraceTx     // Transaction being processed, nil outside of transactions
+	calls  []*blockTraceCall // Call stack of the current execution
+}
+
+func newBlockTrace(cfg json.RawMessage) (*tracing.Hooks, error) {
+	var config blockTraceConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %v", err)
+		}
+	}
+	if config.Path == "" {
+		return nil, errors.New("blocktrace tracer output path is required")
+	}
+	if config.SegmentBlocks == 0 {
+		config.SegmentBlocks = defaultSegmentBlocks
+	}
+	writer, err := newSegmentWriter(config.Path, config.SegmentBlocks)
+	if err != nil {
+		return nil, err
+	}
+	t := &blockTrace{writer: writer}
+	return &tracing.Hooks{
+		OnBlockStart:    t.OnBlockStart,
+		OnBlockEnd:      t.OnBlockEnd,
+		OnSkippedBlock:  t.OnSkippedBlock,
+		OnGenesisBlock:  t.OnGenesisBlock,
+		OnTxStart:       t.OnTxStart,
+		OnTxEnd:         t.OnTxEnd,
+		OnEnter:         t.OnEnter,
+		OnExit:          t.OnExit,
+		OnBalanceChange: t.OnBalanceChange,
+		OnNonceChange:   t.OnNonceChange,
+		OnCodeChange:    t.OnCodeChange,
+		OnStorageChange: t.OnStorageChange,
+		OnClose:         t.OnClose,
+	}, nil
+}
+
+func newBlockTraceBlock(b *types.Block) *blockTraceBlock {
+	return &blockTraceBlock{
+		Number:       b.NumberU64(),
+		Hash:         b.Hash(),
+		ParentHash:   b.ParentHash(),
+		Transactions: make([]*blockTraceTx, 0, len(b.Transactions())),
+		StateDiff:    make(stateDiff),
+	}
+}
+
+func (t *blockTrace) OnBlockStart(ev tracing.BlockEvent) {
+	t.block = newBlockTraceBlock(ev.Block)
+	t.tx, t.calls = nil, nil
+}
+
+func (t *blockTrace) OnBlockEnd(err error) {
+	// Invalid blocks are not part of the chain, drop their traces
+	if err == nil && t.block != nil {
+		t.block.StateDiff.prune()
+		t.write(t.block)
+	}
+	t.block, t.tx, t.calls = nil, nil, nil
+}
+
+func (t *blockTrace) OnSkippedBlock(ev tracing.BlockEvent) {
+	block := newBlockTraceBlock(ev.Block)
+	block.Skipped = true
+	t.write(block)
+}
+
+func (t *blockTrace) OnGenesisBlock(b *types.Block, alloc types.GenesisAlloc) {
+	t.write(newBlockTraceBlock(b))
+}
+
+func (t *blockTrace) OnTxStart(vm *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.tx = &blockTraceTx{Hash: tx.Hash(), StateDiff: make(stateDiff)}
+	t.calls = nil
+}
+
+func (t *blockTrace) OnTxEnd(receipt *types.Receipt, err error) {
+	if t.block == nil || t.tx == nil {
+		return
+	}
+	if receipt != nil {
+		t.tx.GasUsed = hexutil.Uint64(receipt.GasUsed)
+	}
+	if err != nil {
+		t.tx.Error = err.Error()
+	}
+	t.tx.StateDiff.prune()
+	t.block.Transactions = append(t.block.Transactions, t.tx)
+	t.tx, t.calls = nil, nil
+}
+
+func (t *blockTrace) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.block == nil {
+		return
+	}
+	call := &blockTraceCall{
+		Type:  vm.OpCode(typ).String(),
+		From:  from,
+		To:    to,
+		Gas:   hexutil.Uint64(gas),
+		Input: common.CopyBytes(input),
+		diff:  make(stateDiff),
+	}
+	if value != nil {
+		call.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	// The nonce of the creator is incremented before the snapshot of the frame
+	// is taken, so it's not rolled back along with the frame.
+	if op := vm.OpCode(typ); op == vm.CREATE || op == vm.CREATE2 {
+		call.creating = true
+	}
+	t.calls = append(t.calls, call)
+}
+
+func (t *blockTrace) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.block == nil || len(t.calls) == 0 {
+		return
+	}
+	call := t.calls[len(t.calls)-1]
+	t.calls = t.calls[:len(t.calls)-1]
+
+	call.GasUsed = hexutil.Uint64(gasUsed)
+	call.Output = common.CopyBytes(output)
+	if err != nil {
+		call.Error = err.Error()
+	}
+	// The state modifications of a reverted frame are rolled back, but the
+	// state hooks are not invoked for the rollback.
+	if !reverted {
+		t.diff().merge(call.diff)
+	}
+	call.diff = nil
+
+	switch {
+	case len(t.calls) > 0:
+		parent := t.calls[len(t.calls)-1]
+		parent.Calls = append(parent.Calls, call)
+	case t.tx != nil:
+		t.tx.Call = call
+	}
+}
+
+// diff returns the state diff collecting the modifications of the current
+// execution scope, nil outside of blocks.
+func (t *blockTrace) diff() stateDiff {
+	switch {
+	case len(t.calls) > 0:
+		return t.calls[len(t.calls)-1].diff
+	case t.tx != nil:
+		return t.tx.StateDiff
+	case t.block != nil:
+		return t.block.StateDiff
+	}
+	return nil
+}
+
+// parentDiff returns the state diff collecting the modifications of the scope
+// enclosing the current call frame.
+func (t *blockTrace) parentDiff() stateDiff {
+	switch {
+	case len(t.calls) > 1:
+		return t.calls[len(t.calls)-2].diff
+	case t.tx != nil:
+		return t.tx.StateDiff
+	}
+	return t.block.StateDiff
+}
+
+func (t *blockTrace) OnBalanceChange(addr common.Address, prev, post *big.Int, reason tracing.BalanceChangeReason) {
+	if diff := t.diff(); diff != nil {
+		diff.balance(addr, prev, post)
+	}
+}
+
+func (t *blockTrace) OnNonceChange(addr common.Address, prev, post uint64) {
+	// Record the nonce increment of a creator in the enclosing scope, as it's
+	// kept even if the creation fails.
+	if n := len(t.calls); n > 0 && t.calls[n-1].creating && t.calls[n-1].From == addr {
+		t.calls[n-1].creating = false
+		t.parentDiff().nonce(addr, prev, post)
+		return
+	}
+	if diff := t.diff(); diff != nil {
+		diff.nonce(addr, prev, post)
+	}
+}
+
+func (t *blockTrace) OnCodeChange(addr common.Address, prevCodeHash common.Hash, prevCode []byte, codeHash common.Hash, code []byte) {
+	if diff := t.diff(); diff != nil {
+		diff.code(addr, prevCode, code)
+	}
+}
+
+func (t *blockTrace) OnStorageChange(addr common.Address, slot common.Hash, prev, post common.Hash) {
+	if diff := t.diff(); diff != nil {
+		diff.storage(addr, slot, prev, post)
+	}
+}
+
+func (t *blockTrace) OnClose() {
+	if err := t.writer.close(); err != nil {
+		log.Warn("Failed to close blocktrace tracer segment", "err", err)
+	}
+}
+
+func (t *blockTrace) write(block *blockTraceBlock) {
+	out, err := json.Marshal(block)
+	if err != nil {
+		log.Warn("Failed to encode blocktrace tracer record", "number", block.Number, "err", err)
+		return
+	}
+	if err := t.writer.write(block.Number, out); err != nil {
+		log.Warn("Failed to write blocktrace tracer record", "number", block.Number, "err", err)
+	}
+}
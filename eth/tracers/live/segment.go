@@ -0,0 +1,277 @@
+package live
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	segmentPrefix     = "blocks-"
+	segmentExt        = ".jsonl"
+	segmentCompressed = ".jsonl.gz"
+)
+
+// segmentWriter stores per-block records into segment files, each holding the
+// records of a fixed range of blocks. The segment being written is kept as a
+// plain jsonl file, and gets compressed once the first block of the next
+// segment is written.
+//
+// Records must be written in block order. Writing a block which doesn't follow
+// the last written one rewinds the segments, dropping the records of the block
+// and all the later ones.
+type segmentWriter struct {
+	dir    string
+	blocks uint64 // Number of blocks in a segment
+
+	file  *os.File // Active segment, nil if not opened yet
+	first uint64   // First block number of the active segment
+	next  uint64   // Number of the next block expected to be written
+}
+
+func newSegmentWriter(dir string, blocks uint64) (*segmentWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &segmentWriter{dir: dir, blocks: blocks}, nil
+}
+
+// segmentPath returns the path of the segment starting at the given block.
+func (w *segmentWriter) segmentPath(first uint64, compressed bool) string {
+	name := fmt.Sprintf("%s%012d", segmentPrefix, first)
+	if compressed {
+		return filepath.Join(w.dir, name+segmentCompressed)
+	}
+	return filepath.Join(w.dir, name+segmentExt)
+}
+
+// segments returns the first block numbers of the segments on disk, along with
+// whether each of them is compressed.
+func (w *segmentWriter) segments() (map[uint64]bool, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+	segments := make(map[uint64]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, segmentPrefix) {
+			continue
+		}
+		var (
+			first      uint64
+			compressed = strings.HasSuffix(name, segmentCompressed)
+		)
+		if !compressed && !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, segmentPrefix), "%012d", &first); err != nil {
+			continue
+		}
+		segments[first] = compressed
+	}
+	return segments, nil
+}
+
+// write appends the record of the given block.
+func (w *segmentWriter) write(number uint64, record []byte) error {
+	switch {
+	case w.file == nil || number != w.next:
+		if err := w.rewind(number); err != nil {
+			return err
+		}
+	case number >= w.first+w.blocks:
+		if err := w.rotate(number); err != nil {
+			return err
+		}
+	}
+	if _, err := w.file.Write(append(record, '\n')); err != nil {
+		return err
+	}
+	w.next = number + 1
+	return nil
+}
+
+// rotate compresses the active segment and opens the one containing the given
+// block.
+func (w *segmentWriter) rotate(number uint64) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+	if err := w.compress(w.first); err != nil {
+		return err
+	}
+	return w.open(number - number%w.blocks)
+}
+
+// rewind drops the records of the given block and all the later ones, then
+// opens the segment containing the block for writing.
+func (w *segmentWriter) rewind(number uint64) error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return err
+		}
+		w.file = nil
+	}
+	first := number - number%w.blocks
+
+	segments, err := w.segments()
+	if err != nil {
+		return err
+	}
+	for start, compressed := range segments {
+		switch {
+		case start > first:
+			if err := os.Remove(w.segmentPath(start, compressed)); err != nil {
+				return err
+			}
+		case start < first && !compressed:
+			// Leftover active segment, e.g. the tracer was stopped and then
+			// restarted on a later block.
+			if err := w.compress(start); err != nil {
+				return err
+			}
+		}
+	}
+	// Reopen the segment of the block, keeping only the earlier records
+	compressed, ok := segments[first]
+	if ok {
+		if err := w.truncate(first, compressed, number); err != nil {
+			return err
+		}
+	}
+	return w.open(first)
+}
+
+// open opens the plain segment starting at the given block for appending.
+func (w *segmentWriter) open(first uint64) error {
+	file, err := os.OpenFile(w.segmentPath(first, false), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	w.file, w.first = file, first
+	return nil
+}
+
+// compress replaces the plain segment starting at the given block with its
+// compressed version.
+func (w *segmentWriter) compress(first uint64) error {
+	var (
+		src = w.segmentPath(first, false)
+		dst = w.segmentPath(first, true)
+	)
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst + ".tmp")
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(dst+".tmp", dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// truncate rewrites the segment starting at the given block as a plain file,
+// containing only the records of the blocks below the limit.
+func (w *segmentWriter) truncate(first uint64, compressed bool, limit uint64) error {
+	src := w.segmentPath(first, compressed)
+	records, err := readSegment(src, compressed)
+	if err != nil {
+		return err
+	}
+	dst := w.segmentPath(first, false)
+	out, err := os.Create(dst + ".tmp")
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(out)
+	for _, record := range records {
+		var block struct {
+			Number uint64 `json:"number"`
+		}
+		if err := json.Unmarshal(record, &block); err != nil {
+			out.Close()
+			return err
+		}
+		if block.Number >= limit {
+			break
+		}
+		buf.Write(record)
+		buf.WriteByte('\n')
+	}
+	if err := buf.Flush(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(dst+".tmp", dst); err != nil {
+		return err
+	}
+	if compressed {
+		return os.Remove(src)
+	}
+	return nil
+}
+
+// close closes the active segment, leaving it uncompressed.
+func (w *segmentWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// readSegment returns the records stored in the given segment file.
+func readSegment(path string, compressed bool) ([][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var in io.Reader = file
+	if compressed {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		in = zr
+	}
+	var (
+		records [][]byte
+		scanner = bufio.NewScanner(in)
+	)
+	scanner.Buffer(nil, 256*1024*1024)
+	for scanner.Scan() {
+		records = append(records, append([]byte(nil), scanner.Bytes()...))
+	}
+	return records, scanner.Err()
+}
@@ -0,0 +1,279 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/internal"
+	"github.com/holiman/uint256"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("vmTracer", newVMTracer, false)
+}
+
+// vmTrace is the parity-style trace of the code executed in a single call frame.
+type vmTrace struct {
+	Code hexutil.Bytes `json:"code"`
+	Ops  []*vmTraceOp  `json:"ops"`
+}
+
+// vmTraceOp is a single executed instruction, along with the trace of the call
+// frame it entered, if any.
+type vmTraceOp struct {
+	Cost uint64         `json:"cost"`
+	Ex   *vmTraceOpExec `json:"ex"`
+	PC   uint64         `json:"pc"`
+	Sub  *vmTrace       `json:"sub"`
+}
+
+// vmTraceOpExec contains the effects of an executed instruction.
+type vmTraceOpExec struct {
+	Mem   *vmTraceMem   `json:"mem"`
+	Push  []string      `json:"push"`
+	Store *vmTraceStore `json:"store"`
+	Used  uint64        `json:"used"`
+}
+
+// vmTraceMem is the memory region written by an instruction.
+type vmTraceMem struct {
+	Data hexutil.Bytes `json:"data"`
+	Off  uint64        `json:"off"`
+}
+
+// vmTraceStore is the storage slot written by an instruction.
+type vmTraceStore struct {
+	Key string `json:"key"`
+	Val string `json:"val"`
+}
+
+// vmTraceFrame tracks the instruction of a call frame whose effects are not
+// known yet, since they are only observable before the next instruction.
+type vmTraceFrame struct {
+	trace   *vmTrace
+	gas     uint64 // Gas available to the frame on entry
+	pending *vmTraceOp
+	op      vm.OpCode
+	memOff  uint64 // Offset of the memory written by the pending instruction
+	memSize uint64 // Size of the memory written by the pending instruction
+}
+
+// vmTracer reports the executed instructions of a transaction in the parity
+// vmTrace format, nesting the instructions of the entered call frames below
+// the instruction entering them.
+type vmTracer struct {
+	env       *tracing.VMContext
+	root      *vmTrace
+	frames    []*vmTraceFrame
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newVMTracer returns a native go tracer which produces parity-style vm traces.
+func newVMTracer(ctx *tracers.Context, _ json.RawMessage) (*tracers.Tracer, error) {
+	t := new(vmTracer)
+	return &tracers.Tracer{
+		Hooks: &tracing.Hooks{
+			OnTxStart: t.OnTxStart,
+			OnEnter:   t.OnEnter,
+			OnExit:    t.OnExit,
+			OnOpcode:  t.OnOpcode,
+		},
+		GetResult: t.GetResult,
+		Stop:      t.Stop,
+	}, nil
+}
+
+func (t *vmTracer) OnTxStart(env *tracing.VMContext, tx *types.Transaction, from common.Address) {
+	t.env = env
+}
+
+// OnEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *vmTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() {
+		return
+	}
+	op := vm.OpCode(typ)
+	if op == vm.SELFDESTRUCT {
+		return
+	}
+	trace := &vmTrace{Ops: []*vmTraceOp{}}
+	if op == vm.CREATE || op == vm.CREATE2 {
+		trace.Code = common.CopyBytes(input)
+	} else {
+		code := t.env.StateDB.GetCode(to)
+		if target, ok := types.ParseDelegation(code); ok {
+			code = t.env.StateDB.GetCode(target)
+		}
+		trace.Code = common.CopyBytes(code)
+	}
+	if len(t.frames) == 0 {
+		t.root = trace
+	} else if parent := t.frames[len(t.frames)-1]; parent.pending != nil {
+		parent.pending.Sub = trace
+	}
+	t.frames = append(t.frames, &vmTraceFrame{trace: trace, gas: gas})
+}
+
+// OnExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *vmTracer) OnExit(depth int, output []byte, gasUsed uint64, err error, reverted bool) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	// Ignore the exits of the self-destructs, which didn't enter any frame
+	frame := t.frames[len(t.frames)-1]
+	if depth != len(t.frames)-1 {
+		return
+	}
+	// The instruction terminating the frame has no observable effects
+	if frame.pending != nil {
+		var used uint64
+		if gasUsed < frame.gas {
+			used = frame.gas - gasUsed
+		}
+		frame.pending.Ex = &vmTraceOpExec{Push: []string{}, Used: used}
+		frame.pending = nil
+	}
+	t.frames = t.frames[:len(t.frames)-1]
+}
+
+// OnOpcode implements the EVMLogger interface to trace a single step of VM execution.
+func (t *vmTracer) OnOpcode(pc uint64, opcode byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+	if t.interrupt.Load() || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	stack := scope.StackData()
+
+	// Finalize the previous instruction of the frame, its effects are visible
+	// in the current stack and memory.
+	if frame.pending != nil {
+		ex := &vmTraceOpExec{Push: []string{}, Used: gas}
+		if n := vmTracePushes(frame.op); n > 0 && n <= len(stack) {
+			for _, item := range stack[len(stack)-n:] {
+				ex.Push = append(ex.Push, item.Hex())
+			}
+		}
+		if frame.memSize > 0 {
+			data, err := internal.GetMemoryCopyPadded(scope.MemoryData(), int64(frame.memOff), int64(frame.memSize))
+			if err == nil {
+				ex.Mem = &vmTraceMem{Data: data, Off: frame.memOff}
+			}
+		}
+		if frame.op == vm.SSTORE && frame.pending.Ex != nil {
+			ex.Store = frame.pending.Ex.Store
+		}
+		frame.pending.Ex = ex
+		frame.pending = nil
+	}
+	op := &vmTraceOp{Cost: cost, PC: pc}
+	frame.trace.Ops = append(frame.trace.Ops, op)
+	frame.pending, frame.op = op, vm.OpCode(opcode)
+	frame.memOff, frame.memSize = vmTraceMemWrite(frame.op, stack)
+
+	// The stored slot is only known before the execution, stash it into the
+	// pending effects until the instruction is finalized.
+	if frame.op == vm.SSTORE && len(stack) >= 2 {
+		op.Ex = &vmTraceOpExec{Store: &vmTraceStore{
+			Key: stack[len(stack)-1].Hex(),
+			Val: stack[len(stack)-2].Hex(),
+		}}
+	}
+}
+
+// GetResult returns the json-encoded vm trace of the transaction, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *vmTracer) GetResult() (json.RawMessage, error) {
+	if t.root == nil {
+		return nil, errors.New("no call frame traced")
+	}
+	res, err := json.Marshal(t.root)
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *vmTracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// vmTracePushes returns the number of stack items reported as pushed by the
+// instruction. Following parity, the duplications and swaps report all the
+// stack items they touched.
+func vmTracePushes(op vm.OpCode) int {
+	switch {
+	case op >= vm.PUSH0 && op <= vm.PUSH32:
+		return 1
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 2
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return 0
+	}
+	switch op {
+	case vm.STOP, vm.POP, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.TSTORE, vm.JUMP, vm.JUMPI, vm.JUMPDEST,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY, vm.MCOPY,
+		vm.RETURN, vm.REVERT, vm.INVALID, vm.SELFDESTRUCT:
+		return 0
+	}
+	return 1
+}
+
+// vmTraceMemWrite returns the memory region written by the instruction, based
+// on the stack before its execution.
+func vmTraceMemWrite(op vm.OpCode, stack []uint256.Int) (uint64, uint64) {
+	arg := func(n int) uint64 {
+		if n >= len(stack) {
+			return 0
+		}
+		v := &stack[len(stack)-1-n]
+		if !v.IsUint64() {
+			return 0
+		}
+		return v.Uint64()
+	}
+	switch op {
+	case vm.MSTORE:
+		return arg(0), 32
+	case vm.MSTORE8:
+		return arg(0), 1
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.MCOPY:
+		return arg(0), arg(2)
+	case vm.EXTCODECOPY:
+		return arg(1), arg(3)
+	case vm.CALL, vm.CALLCODE:
+		return arg(5), arg(6)
+	case vm.DELEGATECALL, vm.STATICCALL:
+		return arg(4), arg(5)
+	}
+	return 0, 0
+}
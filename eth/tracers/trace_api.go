@@ -0,0 +1,554 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Names of the native tracers the trace namespace is built on. They are
+// registered into the DefaultDirectory by the eth/tracers/native package.
+const (
+	flatCallTracerName = "flatCallTracer"
+	prestateTracerName = "prestateTracer"
+	vmTracerName       = "vmTracer"
+)
+
+// Trace types which can be requested from the replay methods.
+const (
+	traceTypeTrace     = "trace"
+	traceTypeStateDiff = "stateDiff"
+	traceTypeVMTrace   = "vmTrace"
+)
+
+// flatCallTracerConfig configures the flat call tracer to report the errors
+// in the parity format.
+var flatCallTracerConfig = json.RawMessage(`{"convertParityErrors":true}`)
+
+// TraceAPI is the collection of parity-style tracing APIs exposed over the
+// trace namespace.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the parity-style tracing methods
+// of the Ethereum service.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// TraceFilterArgs represents the arguments of trace_filter.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`   // First block to trace, defaults to latest
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`     // Last block to trace, defaults to latest
+	FromAddress []common.Address `json:"fromAddress"` // Senders of the traces, empty matches any
+	ToAddress   []common.Address `json:"toAddress"`   // Receivers of the traces, empty matches any
+	After       *uint64          `json:"after"`       // Number of matching traces to skip
+	Count       *uint64          `json:"count"`       // Maximum number of traces to return
+}
+
+// TraceResults is the result of replaying a single transaction with the
+// requested trace types. The fields of the trace types not requested are nil.
+type TraceResults struct {
+	Output          hexutil.Bytes     `json:"output"`
+	StateDiff       json.RawMessage   `json:"stateDiff"`
+	Trace           []json.RawMessage `json:"trace"`
+	VMTrace         json.RawMessage   `json:"vmTrace"`
+	TransactionHash common.Hash       `json:"transactionHash"`
+}
+
+// rewardTrace is the parity-style trace of a block or uncle reward, reported
+// after the transaction traces of pre-merge ethash blocks.
+type rewardTrace struct {
+	Action struct {
+		Author     common.Address `json:"author"`
+		RewardType string         `json:"rewardType"`
+		Value      *hexutil.Big   `json:"value"`
+	} `json:"action"`
+	BlockHash    common.Hash `json:"blockHash"`
+	BlockNumber  uint64      `json:"blockNumber"`
+	Result       *struct{}   `json:"result"`
+	Subtraces    int         `json:"subtraces"`
+	TraceAddress []int       `json:"traceAddress"`
+	Type         string      `json:"type"`
+}
+
+// flatTrace contains the fields of a flat call frame needed to filter and
+// post-process it.
+type flatTrace struct {
+	Action struct {
+		From          *common.Address `json:"from"`
+		To            *common.Address `json:"to"`
+		Address       *common.Address `json:"address"`
+		RefundAddress *common.Address `json:"refundAddress"`
+		Author        *common.Address `json:"author"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+		Code    hexutil.Bytes   `json:"code"`
+		Output  hexutil.Bytes   `json:"output"`
+	} `json:"result"`
+	Type string `json:"type"`
+}
+
+// sender returns the account initiating the traced frame.
+func (t *flatTrace) sender() *common.Address {
+	if t.Type == "suicide" {
+		return t.Action.Address
+	}
+	return t.Action.From
+}
+
+// receiver returns the account receiving the traced frame: the called account,
+// the created contract, the beneficiary of the self-destruct or the rewarded
+// miner.
+func (t *flatTrace) receiver() *common.Address {
+	switch t.Type {
+	case "reward":
+		return t.Action.Author
+	case "create":
+		if t.Result != nil {
+			return t.Result.Address
+		}
+		return nil
+	case "suicide":
+		return t.Action.RefundAddress
+	}
+	return t.Action.To
+}
+
+// matches returns whether the traced frame satisfies the address criteria of
+// the filter. An empty list matches any address.
+func (t *flatTrace) matches(from, to []common.Address) bool {
+	match := func(list []common.Address, addr *common.Address) bool {
+		if len(list) == 0 {
+			return true
+		}
+		return addr != nil && slices.Contains(list, *addr)
+	}
+	return match(from, t.sender()) && match(to, t.receiver())
+}
+
+// Block returns the flat call traces of all the transactions in the block.
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]json.RawMessage, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block)
+}
+
+// Transaction returns the flat call traces of the transaction.
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+	tracer := flatCallTracerName
+	res, err := api.api.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	return decodeTraces(res)
+}
+
+// Filter returns the flat call traces of the transactions in the given block
+// range, which were sent from and to the given addresses.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]json.RawMessage, error) {
+	from, err := api.blockNumber(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.blockNumber(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range #%d-#%d", from, to)
+	}
+	if limit := api.api.backend.RPCTraceFilterCap(); limit != 0 && to-from >= limit {
+		return nil, fmt.Errorf("block range #%d-#%d exceeds the limit of %d blocks", from, to, limit)
+	}
+	// The genesis block has no transactions to trace
+	if from == 0 {
+		from = 1
+	}
+	var (
+		after   uint64
+		results = []json.RawMessage{}
+	)
+	if args.After != nil {
+		after = *args.After
+	}
+	if args.Count != nil && *args.Count == 0 {
+		return results, nil
+	}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.api.blockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		traces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, trace := range traces {
+			var frame flatTrace
+			if err := json.Unmarshal(trace, &frame); err != nil {
+				return nil, err
+			}
+			if !frame.matches(args.FromAddress, args.ToAddress) {
+				continue
+			}
+			if after > 0 {
+				after--
+				continue
+			}
+			results = append(results, trace)
+			if args.Count != nil && uint64(len(results)) >= *args.Count {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// ReplayBlockTransactions replays all the transactions in the block, returning
+// the requested trace types of each: the flat call traces (trace), the state
+// modifications (stateDiff) and the executed instructions (vmTrace).
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, traceTypes []string) ([]*TraceResults, error) {
+	config := map[string]json.RawMessage{
+		flatCallTracerName: flatCallTracerConfig,
+	}
+	var withTrace, withStateDiff, withVMTrace bool
+	for _, typ := range traceTypes {
+		switch typ {
+		case traceTypeTrace:
+			withTrace = true
+		case traceTypeStateDiff:
+			withStateDiff = true
+			config[prestateTracerName] = json.RawMessage(`{"diffMode":true}`)
+		case traceTypeVMTrace:
+			withVMTrace = true
+			config[vmTracerName] = nil
+		default:
+			return nil, fmt.Errorf("invalid trace type %q", typ)
+		}
+	}
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	tracerConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	tracer := "muxTracer"
+	txResults, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: tracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TraceResults, len(txResults))
+	for i, txResult := range txResults {
+		raw, ok := txResult.Result.(json.RawMessage)
+		if !ok {
+			return nil, fmt.Errorf("unexpected trace result of tx %s", txResult.TxHash)
+		}
+		var outputs map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &outputs); err != nil {
+			return nil, err
+		}
+		result := &TraceResults{TransactionHash: txResult.TxHash}
+		traces, err := decodeTraces(outputs[flatCallTracerName])
+		if err != nil {
+			return nil, err
+		}
+		if result.Output, err = traceOutput(traces); err != nil {
+			return nil, err
+		}
+		if withTrace {
+			if result.Trace, err = stripBlockContext(traces); err != nil {
+				return nil, err
+			}
+		}
+		if withStateDiff {
+			if result.StateDiff, err = parityStateDiff(outputs[prestateTracerName]); err != nil {
+				return nil, err
+			}
+		}
+		if withVMTrace {
+			result.VMTrace = outputs[vmTracerName]
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// blockNumber resolves the given block number, defaulting to the latest one.
+func (api *TraceAPI) blockNumber(ctx context.Context, number *rpc.BlockNumber) (uint64, error) {
+	n := rpc.LatestBlockNumber
+	if number != nil {
+		n = *number
+	}
+	if n == rpc.PendingBlockNumber {
+		return 0, errors.New("tracing on top of pending is not supported")
+	}
+	header, err := api.api.backend.HeaderByNumber(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", n)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// traceBlock returns the flat call traces of all the transactions in the block,
+// followed by the traces of the mining rewards.
+func (api *TraceAPI) traceBlock(ctx context.Context, block *types.Block) ([]json.RawMessage, error) {
+	tracer := flatCallTracerName
+	txResults, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer, TracerConfig: flatCallTracerConfig})
+	if err != nil {
+		return nil, err
+	}
+	traces := []json.RawMessage{}
+	for _, txResult := range txResults {
+		txTraces, err := decodeTraces(txResult.Result)
+		if err != nil {
+			return nil, err
+		}
+		traces = append(traces, txTraces...)
+	}
+	rewards, err := api.rewardTraces(block)
+	if err != nil {
+		return nil, err
+	}
+	return append(traces, rewards...), nil
+}
+
+// rewardTraces returns the traces of the block and uncle rewards of the block.
+// Rewards are only credited by ethash, before the transition to proof-of-stake.
+func (api *TraceAPI) rewardTraces(block *types.Block) ([]json.RawMessage, error) {
+	engine := api.api.backend.Engine()
+	if pos, ok := engine.(*beacon.Beacon); ok {
+		if pos.IsPoSHeader(block.Header()) {
+			return nil, nil
+		}
+		engine = pos.InnerEngine()
+	}
+	if _, ok := engine.(*ethash.Ethash); !ok {
+		return nil, nil
+	}
+	reward, uncleRewards := ethash.Rewards(api.api.backend.ChainConfig(), block.Header(), block.Uncles())
+
+	traces := make([]json.RawMessage, 0, 1+len(uncleRewards))
+	add := func(author common.Address, rewardType string, value *big.Int) error {
+		trace := &rewardTrace{
+			BlockHash:    block.Hash(),
+			BlockNumber:  block.NumberU64(),
+			TraceAddress: []int{},
+			Type:         "reward",
+		}
+		trace.Action.Author = author
+		trace.Action.RewardType = rewardType
+		trace.Action.Value = (*hexutil.Big)(value)
+
+		blob, err := json.Marshal(trace)
+		if err != nil {
+			return err
+		}
+		traces = append(traces, blob)
+		return nil
+	}
+	if err := add(block.Coinbase(), "block", reward.ToBig()); err != nil {
+		return nil, err
+	}
+	for i, uncle := range block.Uncles() {
+		if err := add(uncle.Coinbase, "uncle", uncleRewards[i].ToBig()); err != nil {
+			return nil, err
+		}
+	}
+	return traces, nil
+}
+
+// decodeTraces splits the result of the flat call tracer into its frames.
+func decodeTraces(result interface{}) ([]json.RawMessage, error) {
+	raw, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, errors.New("unexpected flat call trace result")
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(raw, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// traceOutput returns the output of the transaction, i.e. the return data of
+// its top level call frame.
+func traceOutput(traces []json.RawMessage) (hexutil.Bytes, error) {
+	if len(traces) == 0 {
+		return hexutil.Bytes{}, nil
+	}
+	var frame flatTrace
+	if err := json.Unmarshal(traces[0], &frame); err != nil {
+		return nil, err
+	}
+	switch {
+	case frame.Result == nil:
+		return hexutil.Bytes{}, nil
+	case frame.Type == "create":
+		return frame.Result.Code, nil
+	default:
+		return frame.Result.Output, nil
+	}
+}
+
+// stripBlockContext removes the block and transaction fields from the flat
+// call traces, which the replay methods report once per transaction instead.
+func stripBlockContext(traces []json.RawMessage) ([]json.RawMessage, error) {
+	stripped := make([]json.RawMessage, len(traces))
+	for i, trace := range traces {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trace, &fields); err != nil {
+			return nil, err
+		}
+		for _, key := range []string{"blockHash", "blockNumber", "transactionHash", "transactionPosition"} {
+			delete(fields, key)
+		}
+		enc, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		stripped[i] = enc
+	}
+	return stripped, nil
+}
+
+// prestateAccount is an account in the diff mode output of the prestate tracer.
+// The unchanged fields are omitted from the post state.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Code    hexutil.Bytes               `json:"code"`
+	Nonce   *uint64                     `json:"nonce"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// parityStateDiff converts the diff mode output of the prestate tracer into the
+// parity stateDiff format. Each field is reported as unchanged ("="), created
+// ("+"), deleted ("-") or modified ("*").
+func parityStateDiff(raw json.RawMessage) (json.RawMessage, error) {
+	var diff struct {
+		Pre  map[common.Address]*prestateAccount `json:"pre"`
+		Post map[common.Address]*prestateAccount `json:"post"`
+	}
+	if err := json.Unmarshal(raw, &diff); err != nil {
+		return nil, err
+	}
+	result := make(map[common.Address]map[string]interface{})
+	born := func(v interface{}) interface{} { return map[string]interface{}{"+": v} }
+	died := func(v interface{}) interface{} { return map[string]interface{}{"-": v} }
+	changed := func(from, to interface{}) interface{} {
+		return map[string]interface{}{"*": map[string]interface{}{"from": from, "to": to}}
+	}
+	for addr, post := range diff.Post {
+		pre := diff.Pre[addr]
+		if pre == nil {
+			// Account created by the transaction, the omitted fields are empty
+			storage := make(map[common.Hash]interface{})
+			for key, val := range post.Storage {
+				storage[key] = born(val)
+			}
+			result[addr] = map[string]interface{}{
+				"balance": born(post.balance()),
+				"code":    born(post.Code),
+				"nonce":   born(post.nonce()),
+				"storage": storage,
+			}
+			continue
+		}
+		entry := map[string]interface{}{
+			"balance": "=",
+			"code":    "=",
+			"nonce":   "=",
+		}
+		if post.Balance != nil {
+			entry["balance"] = changed(pre.balance(), post.Balance)
+		}
+		if post.Code != nil {
+			entry["code"] = changed(pre.Code, post.Code)
+		}
+		if post.Nonce != nil {
+			entry["nonce"] = changed(pre.nonce(), post.nonce())
+		}
+		storage := make(map[common.Hash]interface{})
+		for key, val := range pre.Storage {
+			storage[key] = changed(val, post.Storage[key])
+		}
+		for key, val := range post.Storage {
+			if _, ok := pre.Storage[key]; !ok {
+				storage[key] = changed(common.Hash{}, val)
+			}
+		}
+		entry["storage"] = storage
+		result[addr] = entry
+	}
+	for addr, pre := range diff.Pre {
+		if _, ok := diff.Post[addr]; ok {
+			continue
+		}
+		// Account deleted by the transaction
+		storage := make(map[common.Hash]interface{})
+		for key, val := range pre.Storage {
+			storage[key] = died(val)
+		}
+		result[addr] = map[string]interface{}{
+			"balance": died(pre.balance()),
+			"code":    died(pre.Code),
+			"nonce":   died(pre.nonce()),
+			"storage": storage,
+		}
+	}
+	return json.Marshal(result)
+}
+
+// balance returns the balance of the account, zero if omitted.
+func (acc *prestateAccount) balance() *hexutil.Big {
+	if acc.Balance == nil {
+		return new(hexutil.Big)
+	}
+	return acc.Balance
+}
+
+// nonce returns the nonce of the account, zero if omitted.
+func (acc *prestateAccount) nonce() hexutil.Uint64 {
+	if acc.Nonce == nil {
+		return 0
+	}
+	return hexutil.Uint64(*acc.Nonce)
+}
@@ -0,0 +1,322 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	traceKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	traceSender  = crypto.PubkeyToAddress(traceKey.PublicKey)
+	traceReceipt = common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	// traceStorer sets the first storage slot to one.
+	traceStorer = common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// traceCaller calls the storer without any value or data.
+	traceCaller = common.HexToAddress("0x3333333333333333333333333333333333333333")
+)
+
+// newTraceBackend creates a chain of two blocks: the first one contains a plain
+// transfer and a call to traceCaller, the second one a single plain transfer.
+func newTraceBackend(t *testing.T) (tracers.Backend, []common.Hash) {
+	callerCode := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	callerCode = append(callerCode, traceStorer.Bytes()...)
+	callerCode = append(callerCode, 0x5a, 0xf1, 0x00) // GAS, CALL, STOP
+
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			traceSender: {Balance: big.NewInt(params.Ether)},
+			traceStorer: {Code: []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}}, // SSTORE(0, 1), STOP
+			traceCaller: {Code: callerCode},
+		},
+	}
+	var (
+		hashes []common.Hash
+		signer = types.LatestSigner(genesis.Config)
+		nonce  uint64
+	)
+	send := func(b *core.BlockGen, to common.Address, gas uint64) {
+		tx := types.MustSignNewTx(traceKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &to,
+			Value:    big.NewInt(1000),
+			Gas:      gas,
+			GasPrice: b.BaseFee(),
+		})
+		b.AddTx(tx)
+		hashes = append(hashes, tx.Hash())
+		nonce++
+	}
+	backend := tracers.NewTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		send(b, traceReceipt, params.TxGas)
+		if i == 0 {
+			send(b, traceCaller, 100000)
+		}
+	})
+	return backend, hashes
+}
+
+type testFlatTrace struct {
+	Action struct {
+		From       *common.Address `json:"from"`
+		To         *common.Address `json:"to"`
+		Author     *common.Address `json:"author"`
+		RewardType string          `json:"rewardType"`
+		Value      *hexutil.Big    `json:"value"`
+	} `json:"action"`
+	BlockNumber     uint64      `json:"blockNumber"`
+	TraceAddress    []int       `json:"traceAddress"`
+	TransactionHash common.Hash `json:"transactionHash"`
+	Type            string      `json:"type"`
+}
+
+func decodeFlatTraces(t *testing.T, traces []json.RawMessage) []testFlatTrace {
+	t.Helper()
+
+	frames := make([]testFlatTrace, len(traces))
+	for i, trace := range traces {
+		if err := json.Unmarshal(trace, &frames[i]); err != nil {
+			t.Fatalf("failed to decode trace %d: %v", i, err)
+		}
+	}
+	return frames
+}
+
+func TestTraceBlockAndTransaction(t *testing.T) {
+	t.Parallel()
+
+	backend, hashes := newTraceBackend(t)
+	api := tracers.NewTraceAPI(backend)
+
+	traces, err := api.Transaction(context.Background(), hashes[1])
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	frames := decodeFlatTraces(t, traces)
+	if len(frames) != 2 {
+		t.Fatalf("wrong number of traces: have %d, want 2", len(frames))
+	}
+	if *frames[0].Action.To != traceCaller || *frames[1].Action.From != traceCaller || *frames[1].Action.To != traceStorer {
+		t.Fatalf("wrong call traces: %+v", frames)
+	}
+	if !slices.Equal(frames[1].TraceAddress, []int{0}) || frames[1].TransactionHash != hashes[1] {
+		t.Fatalf("wrong nested call trace: %+v", frames[1])
+	}
+
+	traces, err = api.Block(context.Background(), rpc.BlockNumber(1))
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	frames = decodeFlatTraces(t, traces)
+	if len(frames) != 4 {
+		t.Fatalf("wrong number of traces: have %d, want 4", len(frames))
+	}
+	for i, want := range []common.Hash{hashes[0], hashes[1], hashes[1]} {
+		if frames[i].TransactionHash != want || frames[i].BlockNumber != 1 {
+			t.Fatalf("trace %d: wrong transaction context %+v", i, frames[i])
+		}
+	}
+	// The block reward is reported after the transactions
+	reward := frames[3]
+	if reward.Type != "reward" || reward.Action.RewardType != "block" || reward.BlockNumber != 1 {
+		t.Fatalf("wrong reward trace: %+v", reward)
+	}
+	if *reward.Action.Author != (common.Address{}) || reward.Action.Value.ToInt().Cmp(ethash.ConstantinopleBlockReward.ToBig()) != 0 {
+		t.Fatalf("wrong block reward: %+v", reward.Action)
+	}
+}
+
+func TestTraceFilter(t *testing.T) {
+	t.Parallel()
+
+	backend, hashes := newTraceBackend(t)
+	api := tracers.NewTraceAPI(backend)
+
+	var (
+		earliest = rpc.EarliestBlockNumber
+		latest   = rpc.LatestBlockNumber
+		one      = uint64(1)
+	)
+	tests := []struct {
+		args tracers.TraceFilterArgs
+		want []common.Hash
+	}{
+		// Full range, all traces including the block rewards
+		{tracers.TraceFilterArgs{FromBlock: &earliest, ToBlock: &latest}, []common.Hash{hashes[0], hashes[1], hashes[1], {}, hashes[2], {}}},
+		// Filtered by sender
+		{tracers.TraceFilterArgs{FromBlock: &earliest, FromAddress: []common.Address{traceSender}}, []common.Hash{hashes[0], hashes[1], hashes[2]}},
+		{tracers.TraceFilterArgs{FromBlock: &earliest, FromAddress: []common.Address{traceCaller}}, []common.Hash{hashes[1]}},
+		// Filtered by receiver
+		{tracers.TraceFilterArgs{FromBlock: &earliest, ToAddress: []common.Address{traceReceipt}}, []common.Hash{hashes[0], hashes[2]}},
+		{tracers.TraceFilterArgs{FromBlock: &earliest, FromAddress: []common.Address{traceSender}, ToAddress: []common.Address{traceStorer}}, nil},
+		// Filtered by rewarded miner
+		{tracers.TraceFilterArgs{FromBlock: &earliest, ToAddress: []common.Address{{}}}, []common.Hash{{}, {}}},
+		// Default range is the latest block
+		{tracers.TraceFilterArgs{}, []common.Hash{hashes[2], {}}},
+		// Pagination
+		{tracers.TraceFilterArgs{FromBlock: &earliest, ToAddress: []common.Address{traceReceipt}, After: &one}, []common.Hash{hashes[2]}},
+		{tracers.TraceFilterArgs{FromBlock: &earliest, ToAddress: []common.Address{traceReceipt}, Count: &one}, []common.Hash{hashes[0]}},
+	}
+	for i, test := range tests {
+		traces, err := api.Filter(context.Background(), test.args)
+		if err != nil {
+			t.Fatalf("test %d: failed to filter traces: %v", i, err)
+		}
+		var have []common.Hash
+		for _, frame := range decodeFlatTraces(t, traces) {
+			have = append(have, frame.TransactionHash)
+		}
+		if !slices.Equal(have, test.want) {
+			t.Fatalf("test %d: wrong traces: have %v, want %v", i, have, test.want)
+		}
+	}
+}
+
+// limitedBackend caps the block range of trace_filter.
+type limitedBackend struct {
+	tracers.Backend
+	limit uint64
+}
+
+func (b *limitedBackend) RPCTraceFilterCap() uint64 { return b.limit }
+
+func TestTraceFilterCap(t *testing.T) {
+	t.Parallel()
+
+	backend, _ := newTraceBackend(t)
+	api := tracers.NewTraceAPI(&limitedBackend{Backend: backend, limit: 2})
+
+	var (
+		earliest = rpc.EarliestBlockNumber
+		one      = rpc.BlockNumber(1)
+	)
+	if _, err := api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: &one}); err != nil {
+		t.Fatalf("failed to filter traces within the limit: %v", err)
+	}
+	if _, err := api.Filter(context.Background(), tracers.TraceFilterArgs{FromBlock: &earliest}); err == nil {
+		t.Fatal("expected error for block range exceeding the limit")
+	}
+}
+
+func TestTraceReplayBlockTransactions(t *testing.T) {
+	t.Parallel()
+
+	backend, hashes := newTraceBackend(t)
+	api := tracers.NewTraceAPI(backend)
+
+	if _, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(1), []string{"invalid"}); err == nil {
+		t.Fatal("expected error for invalid trace type")
+	}
+	results, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(1), []string{"trace"})
+	if err != nil {
+		t.Fatalf("failed to replay block: %v", err)
+	}
+	if len(results) != 2 || results[1].TransactionHash != hashes[1] {
+		t.Fatalf("wrong replay results: %+v", results)
+	}
+	if len(results[1].Trace) != 2 || results[1].StateDiff != nil || results[1].VMTrace != nil {
+		t.Fatalf("wrong trace types replayed: %+v", results[1])
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(results[1].Trace[1], &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["transactionHash"]; ok {
+		t.Fatal("replayed trace contains the transaction context")
+	}
+
+	results, err = api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(1), []string{"stateDiff", "vmTrace"})
+	if err != nil {
+		t.Fatalf("failed to replay block: %v", err)
+	}
+	if results[1].Trace != nil {
+		t.Fatal("unrequested call traces replayed")
+	}
+	// Check the storage modification and the nonce increment of the sender
+	var diff map[common.Address]struct {
+		Nonce   json.RawMessage `json:"nonce"`
+		Storage map[common.Hash]struct {
+			Changed struct {
+				From common.Hash `json:"from"`
+				To   common.Hash `json:"to"`
+			} `json:"*"`
+		} `json:"storage"`
+	}
+	if err := json.Unmarshal(results[1].StateDiff, &diff); err != nil {
+		t.Fatalf("failed to decode state diff: %v", err)
+	}
+	if slot := diff[traceStorer].Storage[common.Hash{}].Changed; slot.From != (common.Hash{}) || slot.To != common.BigToHash(big.NewInt(1)) {
+		t.Fatalf("wrong storage diff: %+v", slot)
+	}
+	if have, want := string(diff[traceSender].Nonce), `{"*":{"from":"0x1","to":"0x2"}}`; have != want {
+		t.Fatalf("wrong nonce diff: have %s, want %s", have, want)
+	}
+	// Check the nested execution of the storer
+	type vmTrace struct {
+		Code string `json:"code"`
+		Ops  []struct {
+			Cost uint64 `json:"cost"`
+			Ex   struct {
+				Push  []string `json:"push"`
+				Store *struct {
+					Key string `json:"key"`
+					Val string `json:"val"`
+				} `json:"store"`
+			} `json:"ex"`
+			PC  uint64           `json:"pc"`
+			Sub *json.RawMessage `json:"sub"`
+		} `json:"ops"`
+	}
+	var outer vmTrace
+	if err := json.Unmarshal(results[1].VMTrace, &outer); err != nil {
+		t.Fatalf("failed to decode vm trace: %v", err)
+	}
+	if len(outer.Ops) != 9 || outer.Ops[7].Sub == nil {
+		t.Fatalf("wrong outer vm trace: %+v", outer)
+	}
+	if !slices.Equal(outer.Ops[0].Ex.Push, []string{"0x0"}) || !slices.Equal(outer.Ops[7].Ex.Push, []string{"0x1"}) {
+		t.Fatalf("wrong pushed items: %+v", outer.Ops)
+	}
+	var inner vmTrace
+	if err := json.Unmarshal(*outer.Ops[7].Sub, &inner); err != nil {
+		t.Fatalf("failed to decode nested vm trace: %v", err)
+	}
+	if inner.Code != "0x600160005500" || len(inner.Ops) != 4 {
+		t.Fatalf("wrong nested vm trace: %+v", inner)
+	}
+	if store := inner.Ops[2].Ex.Store; store == nil || store.Key != "0x0" || store.Val != "0x1" {
+		t.Fatalf("wrong storage write: %+v", store)
+	}
+}
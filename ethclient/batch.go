@@ -0,0 +1,273 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBatchSize is the default maximum number of requests sent to the server
+// in a single batch.
+const DefaultBatchSize = 100
+
+var (
+	errBatchNotExecuted = errors.New("batch not executed")
+	errBatchExecuted    = errors.New("batch already executed")
+)
+
+// Batch collects typed requests to be sent to the server in as few round trips as
+// possible. Requests are queued by the methods of the batch, each returning a
+// handle to retrieve the decoded result after the batch was executed.
+//
+// A batch exceeding the maximum size is split into multiple round trips. Batches
+// rejected by the server for being too large are retried with a smaller size, and
+// requests dropped because the response exceeded the server's size limit are
+// retried in a subsequent batch.
+type Batch struct {
+	client   *Client
+	size     int
+	elems    []rpc.BatchElem
+	decoders []func(error) // Decode the result of the request, given its error
+	executed bool
+}
+
+// BatchResult is the result of a request queued in a batch.
+type BatchResult[T any] struct {
+	value T
+	err   error
+}
+
+// Result returns the decoded result of the request, or the error returned by
+// the server for it.
+func (r *BatchResult[T]) Result() (T, error) {
+	return r.value, r.err
+}
+
+// NewBatch creates a new batch of requests with the default maximum size.
+func (ec *Client) NewBatch() *Batch {
+	return &Batch{client: ec, size: DefaultBatchSize}
+}
+
+// SetMaxSize sets the maximum number of requests sent in a single round trip,
+// which should not exceed the batch request limit of the server.
+func (b *Batch) SetMaxSize(size int) *Batch {
+	b.size = max(size, 1)
+	return b
+}
+
+// Len returns the number of requests queued in the batch.
+func (b *Batch) Len() int {
+	return len(b.elems)
+}
+
+// queue adds a request to the batch, whose raw result is converted by the given
+// function once received.
+func queue[R any, T any](b *Batch, convert func(*R) (T, error), method string, args ...interface{}) *BatchResult[T] {
+	var (
+		raw = new(R)
+		res = &BatchResult[T]{err: errBatchNotExecuted}
+	)
+	b.elems = append(b.elems, rpc.BatchElem{Method: method, Args: args, Result: raw})
+	b.decoders = append(b.decoders, func(err error) {
+		if err != nil {
+			res.err = err
+			return
+		}
+		res.value, res.err = convert(raw)
+	})
+	return res
+}
+
+// ChainID queues the retrieval of the chain ID.
+func (b *Batch) ChainID() *BatchResult[*big.Int] {
+	return queue(b, func(r *hexutil.Big) (*big.Int, error) {
+		return (*big.Int)(r), nil
+	}, "eth_chainId")
+}
+
+// BlockNumber queues the retrieval of the most recent block number.
+func (b *Batch) BlockNumber() *BatchResult[uint64] {
+	return queue(b, func(r *hexutil.Uint64) (uint64, error) {
+		return uint64(*r), nil
+	}, "eth_blockNumber")
+}
+
+// HeaderByHash queues the retrieval of the block header with the given hash.
+func (b *Batch) HeaderByHash(hash common.Hash) *BatchResult[*types.Header] {
+	return queue(b, notFoundIfNil[types.Header], "eth_getBlockByHash", hash, false)
+}
+
+// HeaderByNumber queues the retrieval of a block header from the current canonical
+// chain. If number is nil, the latest known header is retrieved.
+func (b *Batch) HeaderByNumber(number *big.Int) *BatchResult[*types.Header] {
+	return queue(b, notFoundIfNil[types.Header], "eth_getBlockByNumber", toBlockNumArg(number), false)
+}
+
+// TransactionByHash queues the retrieval of the transaction with the given hash.
+func (b *Batch) TransactionByHash(hash common.Hash) *BatchResult[*types.Transaction] {
+	return queue(b, func(raw **rpcTransaction) (*types.Transaction, error) {
+		json := *raw
+		if json == nil {
+			return nil, ethereum.NotFound
+		} else if _, r, _ := json.tx.RawSignatureValues(); r == nil {
+			return nil, errors.New("server returned transaction without signature")
+		}
+		if json.From != nil && json.BlockHash != nil {
+			setSenderFromServer(json.tx, *json.From, *json.BlockHash)
+		}
+		return json.tx, nil
+	}, "eth_getTransactionByHash", hash)
+}
+
+// TransactionReceipt queues the retrieval of the receipt of a transaction.
+func (b *Batch) TransactionReceipt(txHash common.Hash) *BatchResult[*types.Receipt] {
+	return queue(b, notFoundIfNil[types.Receipt], "eth_getTransactionReceipt", txHash)
+}
+
+// BalanceAt queues the retrieval of the wei balance of the given account. The
+// block number can be nil, in which case the balance is taken from the latest
+// known block.
+func (b *Batch) BalanceAt(account common.Address, blockNumber *big.Int) *BatchResult[*big.Int] {
+	return queue(b, func(r *hexutil.Big) (*big.Int, error) {
+		return (*big.Int)(r), nil
+	}, "eth_getBalance", account, toBlockNumArg(blockNumber))
+}
+
+// NonceAt queues the retrieval of the nonce of the given account. The block
+// number can be nil, in which case the nonce is taken from the latest known block.
+func (b *Batch) NonceAt(account common.Address, blockNumber *big.Int) *BatchResult[uint64] {
+	return queue(b, func(r *hexutil.Uint64) (uint64, error) {
+		return uint64(*r), nil
+	}, "eth_getTransactionCount", account, toBlockNumArg(blockNumber))
+}
+
+// CodeAt queues the retrieval of the contract code of the given account. The
+// block number can be nil, in which case the code is taken from the latest known
+// block.
+func (b *Batch) CodeAt(account common.Address, blockNumber *big.Int) *BatchResult[[]byte] {
+	return queue(b, bytesResult, "eth_getCode", account, toBlockNumArg(blockNumber))
+}
+
+// StorageAt queues the retrieval of the value of key in the contract storage of
+// the given account. The block number can be nil, in which case the value is
+// taken from the latest known block.
+func (b *Batch) StorageAt(account common.Address, key common.Hash, blockNumber *big.Int) *BatchResult[[]byte] {
+	return queue(b, bytesResult, "eth_getStorageAt", account, key, toBlockNumArg(blockNumber))
+}
+
+// CallContract queues the execution of a message call. The block number can be
+// nil, in which case the call runs on the latest known block.
+func (b *Batch) CallContract(msg ethereum.CallMsg, blockNumber *big.Int) *BatchResult[[]byte] {
+	return queue(b, bytesResult, "eth_call", toCallArg(msg), toBlockNumArg(blockNumber))
+}
+
+// EstimateGas queues the estimation of the gas needed to execute a message call
+// on the pending state.
+func (b *Batch) EstimateGas(msg ethereum.CallMsg) *BatchResult[uint64] {
+	return queue(b, func(r *hexutil.Uint64) (uint64, error) {
+		return uint64(*r), nil
+	}, "eth_estimateGas", toCallArg(msg))
+}
+
+func notFoundIfNil[T any](r **T) (*T, error) {
+	if *r == nil {
+		return nil, ethereum.NotFound
+	}
+	return *r, nil
+}
+
+func bytesResult(r *hexutil.Bytes) ([]byte, error) {
+	return *r, nil
+}
+
+// Execute sends the queued requests to the server. The returned error is only set
+// if the requests couldn't be sent. Errors specific to a request are reported by
+// its result.
+//
+// A batch can only be executed once.
+func (b *Batch) Execute(ctx context.Context) error {
+	if b.executed {
+		return errBatchExecuted
+	}
+	var (
+		size    = b.size
+		pending = make([]int, len(b.elems)) // Indices of the requests to send
+	)
+	for i := range pending {
+		pending[i] = i
+	}
+	for len(pending) > 0 {
+		var (
+			n     = min(size, len(pending))
+			elems = make([]rpc.BatchElem, n)
+		)
+		for i, index := range pending[:n] {
+			elems[i] = b.elems[index]
+			elems[i].Error = nil
+		}
+		if err := b.client.c.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		// If the whole batch was rejected, retry with a smaller size
+		if n > 1 && isBatchTooLarge(elems[0].Error) {
+			size = n / 2
+			continue
+		}
+		// Retry the requests dropped because the response exceeded the server's
+		// size limit in a subsequent batch. The server always answers the first
+		// request, so progress is guaranteed.
+		var retry []int
+		for i, index := range pending[:n] {
+			if i > 0 && isResponseTooLarge(elems[i].Error) {
+				retry = append(retry, index)
+				continue
+			}
+			b.decoders[index](elems[i].Error)
+		}
+		pending = append(retry, pending[n:]...)
+	}
+	b.executed = true
+	return nil
+}
+
+// isBatchTooLarge returns whether the error reports a batch exceeding the server's
+// request limit.
+func isBatchTooLarge(err error) bool {
+	return isServerError(err, rpc.ErrBatchTooLarge)
+}
+
+// isResponseTooLarge returns whether the error reports a response exceeding the
+// server's size limit. Both the code and the message are checked, as the code
+// is shared with other errors, e.g. transactions rejected by the pool.
+func isResponseTooLarge(err error) bool {
+	return isServerError(err, rpc.ErrResponseTooLarge)
+}
+
+// isServerError returns whether the error received from the server is the given
+// one, having the same code and message.
+func isServerError(err error, want rpc.Error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == want.ErrorCode() && rpcErr.Error() == want.Error()
+}
@@ -439,19 +439,24 @@ func (ec *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]typ
 }
 
 // SubscribeFilterLogs subscribes to the results of a streaming filter query.
+//
+// If the connection to the server drops, the subscription is resumed after the
+// client reconnects, replaying the logs missed in the meantime. Logs removed by
+// a reorg may be retracted more than once when resuming.
 func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
 	arg, err := toFilterArg(q)
 	if err != nil {
 		return nil, err
 	}
-	sub, err := ec.c.EthSubscribe(ctx, ch, "logs", arg)
+	logs := make(chan types.Log)
+	sub, err := ec.c.EthSubscribe(ctx, logs, "logs", arg)
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
 		// of letting default golang behavior wrap it with non-nil interface that stores
 		// nil concrete type value.
 		return nil, err
 	}
-	return sub, nil
+	return newLogSubscription(ec.c, arg, sub, logs, ch), nil
 }
 
 func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
@@ -22,11 +22,13 @@ import (
 	"errors"
 	"math/big"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -294,6 +296,9 @@ func TestEthClient(t *testing.T) {
 		"TransactionSender": {
 			func(t *testing.T) { testTransactionSender(t, client) },
 		},
+		"Batch": {
+			func(t *testing.T) { testBatch(t, chain, client) },
+		},
 	}
 
 	t.Parallel()
@@ -760,3 +765,116 @@ func sendTransaction(ec *Client) error {
 	}
 	return ec.SendTransaction(context.Background(), tx)
 }
+
+func testBatch(t *testing.T, chain []*types.Block, client *rpc.Client) {
+	ec := NewClient(client)
+
+	var (
+		batch    = ec.NewBatch()
+		header   = batch.HeaderByNumber(big.NewInt(1))
+		missing  = batch.HeaderByNumber(big.NewInt(1000))
+		balance  = batch.BalanceAt(testAddr, big.NewInt(0))
+		nonce    = batch.NonceAt(testAddr, nil)
+		receipt  = batch.TransactionReceipt(testTx1.Hash())
+		tx       = batch.TransactionByHash(testTx2.Hash())
+		call     = batch.CallContract(ethereum.CallMsg{From: testAddr, To: &common.Address{}, Gas: 21000, Value: big.NewInt(1)}, nil)
+		reverted = batch.EstimateGas(ethereum.CallMsg{From: common.Address{1}, To: &common.Address{}, Value: big.NewInt(1)})
+	)
+	if _, err := header.Result(); err == nil {
+		t.Fatal("result available before execution")
+	}
+	if err := batch.Execute(context.Background()); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if h, err := header.Result(); err != nil || h.Hash() != chain[1].Hash() {
+		t.Fatalf("wrong header: %v %v", h, err)
+	}
+	if _, err := missing.Result(); err != ethereum.NotFound {
+		t.Fatalf("wrong error for missing header: %v", err)
+	}
+	if b, err := balance.Result(); err != nil || b.Cmp(testBalance) != 0 {
+		t.Fatalf("wrong balance: %v %v", b, err)
+	}
+	if n, err := nonce.Result(); err != nil || n != 2 {
+		t.Fatalf("wrong nonce: %v %v", n, err)
+	}
+	if r, err := receipt.Result(); err != nil || r.TxHash != testTx1.Hash() || r.BlockHash != chain[2].Hash() {
+		t.Fatalf("wrong receipt: %v %v", r, err)
+	}
+	if tx, err := tx.Result(); err != nil || tx.Hash() != testTx2.Hash() {
+		t.Fatalf("wrong transaction: %v %v", tx, err)
+	}
+	if _, err := call.Result(); err != nil {
+		t.Fatalf("unexpected call error: %v", err)
+	}
+	if _, err := reverted.Result(); err == nil {
+		t.Fatal("expected estimation error for unfunded sender")
+	}
+	if err := batch.Execute(context.Background()); err == nil {
+		t.Fatal("batch executed twice")
+	}
+}
+
+type batchLimitService struct {
+	rejected atomic.Int32 // Number of transactions rejected
+}
+
+func (s *batchLimitService) GetCode(account common.Address, block string) hexutil.Bytes {
+	return make(hexutil.Bytes, 100)
+}
+
+func (s *batchLimitService) EstimateGas(args map[string]interface{}) (hexutil.Uint64, error) {
+	s.rejected.Add(1)
+	return 0, new(txRejectedError)
+}
+
+// txRejectedError has the same code as the errors of responses exceeding the
+// server's size limit.
+type txRejectedError struct{}
+
+func (e *txRejectedError) Error() string  { return "transaction rejected" }
+func (e *txRejectedError) ErrorCode() int { return -32003 }
+
+func TestBatchLimits(t *testing.T) {
+	t.Parallel()
+
+	// Serve batches of at most 3 requests, answering 2 of them at most
+	server := rpc.NewServer()
+	defer server.Stop()
+	server.SetBatchLimits(3, 250)
+	service := new(batchLimitService)
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	var (
+		batch   = client.NewBatch()
+		results []*BatchResult[[]byte]
+	)
+	for i := 0; i < 7; i++ {
+		results = append(results, batch.CodeAt(common.Address{byte(i)}, nil))
+	}
+	if err := batch.Execute(context.Background()); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	for i, res := range results {
+		if code, err := res.Result(); err != nil || len(code) != 100 {
+			t.Errorf("request %d: wrong result: %d bytes, error %v", i, len(code), err)
+		}
+	}
+	// Errors sharing the code of oversized responses are not retried
+	batch = client.NewBatch()
+	batch.CodeAt(common.Address{}, nil)
+	rejected := batch.EstimateGas(ethereum.CallMsg{})
+	if err := batch.Execute(context.Background()); err != nil {
+		t.Fatalf("failed to execute batch: %v", err)
+	}
+	if _, err := rejected.Result(); err == nil || err.Error() != "transaction rejected" {
+		t.Fatalf("wrong error for rejected transaction: %v", err)
+	}
+	if n := service.rejected.Load(); n != 1 {
+		t.Fatalf("rejected transaction sent %d times", n)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// resubscribeAttempts is the number of times a dropped log subscription is
+	// attempted to be resumed before giving up.
+	resubscribeAttempts = 5
+
+	// resubscribeBackoff is the delay before retrying a failed resubscription,
+	// doubled after every failed attempt.
+	resubscribeBackoff = time.Second
+)
+
+// logCursor is the position of the last log delivered by a log subscription,
+// used to resume it after a disconnection.
+type logCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+// logSubscription is a log subscription which transparently resumes after the
+// connection to the server drops, replaying the logs missed in the meantime.
+type logSubscription struct {
+	client *rpc.Client
+	arg    interface{} // Filter criteria of the subscription
+	sub    *rpc.ClientSubscription
+	logs   chan types.Log // Logs delivered by the active server subscription
+	out    chan<- types.Log
+	cursor *logCursor // Last delivered log, nil if none yet
+}
+
+func newLogSubscription(client *rpc.Client, arg interface{}, sub *rpc.ClientSubscription, logs chan types.Log, out chan<- types.Log) ethereum.Subscription {
+	s := &logSubscription{
+		client: client,
+		arg:    arg,
+		sub:    sub,
+		logs:   logs,
+		out:    out,
+	}
+	return event.NewSubscription(s.run)
+}
+
+// run forwards the logs to the subscriber until unsubscribed, resubscribing
+// whenever the server subscription fails.
+func (s *logSubscription) run(quit <-chan struct{}) error {
+	defer func() {
+		if s.sub != nil {
+			s.sub.Unsubscribe()
+		}
+	}()
+	for {
+		select {
+		case log := <-s.logs:
+			// Removed logs are retracted again when resuming from the last
+			// delivered one, so there's no need to track them.
+			if !log.Removed {
+				s.cursor = &logCursor{
+					BlockNumber: hexutil.Uint64(log.BlockNumber),
+					BlockHash:   log.BlockHash,
+					LogIndex:    hexutil.Uint(log.Index),
+				}
+			}
+			select {
+			case s.out <- log:
+			case <-quit:
+				return nil
+			}
+		case err := <-s.sub.Err():
+			if err == nil {
+				return nil // Client closed
+			}
+			s.sub.Unsubscribe()
+			s.sub = nil
+			if err := s.resubscribe(quit, err); err != nil {
+				return err
+			}
+			if s.sub == nil {
+				return nil // Unsubscribed while resubscribing
+			}
+		case <-quit:
+			return nil
+		}
+	}
+}
+
+// resubscribe reestablishes the server subscription after it failed with the
+// given error, resuming from the last delivered log. The original error is
+// returned if the subscription cannot be resumed.
+func (s *logSubscription) resubscribe(quit <-chan struct{}, failure error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	args := []interface{}{"logs", s.arg}
+	if s.cursor != nil {
+		args = append(args, s.cursor)
+	}
+	backoff := resubscribeBackoff
+	for i := 0; i < resubscribeAttempts; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-quit:
+				return nil
+			}
+			backoff *= 2
+		}
+		sub, err := s.client.Subscribe(ctx, "eth", s.logs, args...)
+		if err == nil {
+			s.sub = sub
+			return nil
+		}
+		// Errors returned by the server won't go away by retrying, e.g. the
+		// server doesn't support resuming subscriptions.
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) || ctx.Err() != nil {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return failure
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// resumeLogsService serves a log subscription delivering the logs of blocks 1
+// and 2, or the log of block 3 when resumed from a cursor.
+type resumeLogsService struct {
+	cursors chan json.RawMessage
+}
+
+func (s *resumeLogsService) Logs(ctx context.Context, crit json.RawMessage, cursor *json.RawMessage) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+
+	numbers := []uint64{1, 2}
+	if cursor != nil {
+		s.cursors <- *cursor
+		numbers = []uint64{3}
+	}
+	for _, number := range numbers {
+		notifier.Notify(sub.ID, &types.Log{BlockNumber: number, BlockHash: common.Hash{byte(number)}, Topics: []common.Hash{}})
+	}
+	return sub, nil
+}
+
+// dropProxy forwards TCP connections to a server, allowing to drop them.
+type dropProxy struct {
+	listener net.Listener
+	target   string
+	lock     sync.Mutex
+	conns    []net.Conn
+}
+
+func newDropProxy(t *testing.T, target string) *dropProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &dropProxy{listener: listener, target: target}
+	go p.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		p.drop()
+	})
+	return p
+}
+
+func (p *dropProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", p.target)
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		p.lock.Lock()
+		p.conns = append(p.conns, conn, upstream)
+		p.lock.Unlock()
+
+		go io.Copy(conn, upstream)
+		go io.Copy(upstream, conn)
+	}
+}
+
+// drop closes all the proxied connections.
+func (p *dropProxy) drop() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for _, conn := range p.conns {
+		conn.Close()
+	}
+	p.conns = nil
+}
+
+func TestSubscribeFilterLogsResume(t *testing.T) {
+	t.Parallel()
+
+	service := &resumeLogsService{cursors: make(chan json.RawMessage, 1)}
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("eth", service); err != nil {
+		t.Fatal(err)
+	}
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	proxy := newDropProxy(t, strings.TrimPrefix(httpsrv.URL, "http://"))
+	client, err := Dial("ws://" + proxy.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	logs := make(chan types.Log)
+	sub, err := client.SubscribeFilterLogs(context.Background(), ethereum.FilterQuery{}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	for number := uint64(1); number <= 3; number++ {
+		select {
+		case log := <-logs:
+			if log.BlockNumber != number {
+				t.Fatalf("wrong log: have block %d, want %d", log.BlockNumber, number)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for log of block %d", number)
+		}
+		if number == 2 {
+			proxy.drop()
+		}
+	}
+	cursor := <-service.cursors
+	if want := `{"blockNumber":"0x2","blockHash":"0x0200000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0"}`; string(cursor) != want {
+		t.Fatalf("wrong resume cursor: have %s, want %s", cursor, want)
+	}
+}
@@ -154,6 +154,65 @@ func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
 	return types.NewBlockWithHeader(&header).WithBody(body), nil
 }
 
+// GetRawHeaderByNumber returns the RLP encoded header of the given block.
+func (e *Era) GetRawHeaderByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 0, TypeCompressedHeader)
+}
+
+// GetRawBodyByNumber returns the RLP encoded body of the given block.
+func (e *Era) GetRawBodyByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 1, TypeCompressedBody)
+}
+
+// GetRawReceiptsByNumber returns the RLP encoded receipts of the given block.
+func (e *Era) GetRawReceiptsByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 2, TypeCompressedReceipts)
+}
+
+// GetTotalDifficultyByNumber returns the total difficulty of the given block.
+func (e *Era) GetTotalDifficultyByNumber(num uint64) (*big.Int, error) {
+	r, err := e.recordReader(num, 3, TypeTotalDifficulty)
+	if err != nil {
+		return nil, err
+	}
+	rawTd, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(reverseOrder(rawTd)), nil
+}
+
+// readCompressedRecord reads and decompresses the value of a record of the
+// given block.
+func (e *Era) readCompressedRecord(num uint64, skip int, expectedType uint16) ([]byte, error) {
+	r, err := e.recordReader(num, skip, expectedType)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(snappy.NewReader(r))
+}
+
+// recordReader returns a reader over the value of a record of the given block,
+// skipping the given number of records preceding it in the block's entries.
+func (e *Era) recordReader(num uint64, skip int, expectedType uint16) (io.Reader, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < skip; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := e.s.ReaderAt(expectedType, off)
+	return r, err
+}
+
 // Accumulator reads the accumulator entry in the Era1 file.
 func (e *Era) Accumulator() (common.Hash, error) {
 	entry, err := e.s.Find(TypeAccumulator)
@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package erae
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+// Builder is used to create EraE archives of post-merge block data.
+//
+// EraE files succeed Era1 files for the blocks produced after the transition to
+// proof-of-stake. They are e2store files too, following the structure of Era1
+// files, but the blocks no longer carry a total difficulty and are anchored to
+// the beacon chain instead of the header accumulator:
+//
+//	erae := Version | block-tuple* | other-entries* | Accumulator | BlockIndex
+//	block-tuple :=  CompressedHeader | CompressedBody | CompressedReceipts | BlockProof
+//
+// Each basic element is its own entry:
+//
+//	Version            = { type: [0x65, 0x32], data: nil }
+//	CompressedHeader   = { type: [0x03, 0x00], data: snappyFramed(rlp(header)) }
+//	CompressedBody     = { type: [0x04, 0x00], data: snappyFramed(rlp(body)) }
+//	CompressedReceipts = { type: [0x05, 0x00], data: snappyFramed(rlp(receipts)) }
+//	BlockProof         = { type: [0x08, 0x00], data: block-proof }
+//	AccumulatorRoot    = { type: [0x09, 0x00], data: accumulator-root }
+//	BlockIndex         = { type: [0x32, 0x66], data: block-index }
+//
+// The headers and bodies are encoded as in the chain, so they include all the
+// fields added after the merge, like the withdrawals and the blob gas fields.
+//
+// BlockProof is a merkle proof of the block hash in the execution payload of the
+// beacon block including it. The generalized index of the block hash depends on
+// the fork of the beacon block:
+//
+//	block-proof := beacon-root: Bytes32 | index: Uint64 | branch: Bytes32*
+//
+// The accumulator is the SSZ hash_tree_root of the list of beacon roots the
+// blocks are anchored to, tying the whole archive to the beacon chain:
+//
+//	accumulator := hash_tree_root([]Bytes32, 8192)
+//
+// BlockIndex has the same format as in Era1 files, storing the offsets of the
+// block tuples relative to the beginning of the index entry:
+//
+//	block-index := starting-number | index | index | index ... | count
+type Builder struct {
+	w        *e2store.Writer
+	startNum *uint64
+	indexes  []uint64
+	roots    []common.Hash
+	written  int
+
+	buf    *bytes.Buffer
+	snappy *snappy.Writer
+}
+
+// NewBuilder returns a new Builder instance.
+func NewBuilder(w io.Writer) *Builder {
+	buf := bytes.NewBuffer(nil)
+	return &Builder{
+		w:      e2store.NewWriter(w),
+		buf:    buf,
+		snappy: snappy.NewBufferedWriter(buf),
+	}
+}
+
+// Add writes the compressed block and receipts entries, followed by the proof
+// of the block, to the underlying e2store file.
+func (b *Builder) Add(block *types.Block, receipts types.Receipts, proof *BlockProof) error {
+	if err := proof.Verify(block.Hash()); err != nil {
+		return fmt.Errorf("invalid proof of block %d: %w", block.NumberU64(), err)
+	}
+	eh, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		return err
+	}
+	eb, err := rlp.EncodeToBytes(block.Body())
+	if err != nil {
+		return err
+	}
+	er, err := rlp.EncodeToBytes(receipts)
+	if err != nil {
+		return err
+	}
+	return b.AddRLP(eh, eb, er, block.NumberU64(), proof)
+}
+
+// AddRLP writes the compressed block and receipts entries, followed by the
+// proof of the block, to the underlying e2store file.
+func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, proof *BlockProof) error {
+	// Write EraE version entry before first block.
+	if b.startNum == nil {
+		n, err := b.w.Write(TypeVersion, nil)
+		if err != nil {
+			return err
+		}
+		startNum := number
+		b.startNum = &startNum
+		b.written += n
+	}
+	if len(b.indexes) >= MaxEraESize {
+		return fmt.Errorf("exceeds maximum batch size of %d", MaxEraESize)
+	}
+	b.indexes = append(b.indexes, uint64(b.written))
+	b.roots = append(b.roots, proof.BeaconRoot)
+
+	// Write block data.
+	if err := b.snappyWrite(TypeCompressedHeader, header); err != nil {
+		return err
+	}
+	if err := b.snappyWrite(TypeCompressedBody, body); err != nil {
+		return err
+	}
+	if err := b.snappyWrite(TypeCompressedReceipts, receipts); err != nil {
+		return err
+	}
+	// Also write the block proof, but don't snappy encode.
+	enc, err := proof.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	n, err := b.w.Write(TypeBlockProof, enc)
+	b.written += n
+	return err
+}
+
+// Finalize computes the accumulator and block index values, then writes the
+// corresponding e2store entries.
+func (b *Builder) Finalize() (common.Hash, error) {
+	if b.startNum == nil {
+		return common.Hash{}, errors.New("finalize called on empty builder")
+	}
+	// Compute accumulator root and write entry.
+	root, err := ComputeAccumulator(b.roots)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error calculating accumulator root: %w", err)
+	}
+	n, err := b.w.Write(TypeAccumulator, root[:])
+	b.written += n
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error writing accumulator: %w", err)
+	}
+	// Construct block index, with offsets relative to the beginning of its entry.
+	var (
+		base  = int64(b.written)
+		count = len(b.indexes)
+		index = make([]byte, 16+count*8)
+	)
+	binary.LittleEndian.PutUint64(index, *b.startNum)
+	for i, offset := range b.indexes {
+		relative := int64(offset) - base
+		binary.LittleEndian.PutUint64(index[8+i*8:], uint64(relative))
+	}
+	binary.LittleEndian.PutUint64(index[8+count*8:], uint64(count))
+
+	// Finally, write the block index entry.
+	if _, err := b.w.Write(TypeBlockIndex, index); err != nil {
+		return common.Hash{}, fmt.Errorf("unable to write block index: %w", err)
+	}
+	return root, nil
+}
+
+// snappyWrite is a small helper to take care snappy encoding and writing an e2store entry.
+func (b *Builder) snappyWrite(typ uint16, in []byte) error {
+	b.buf.Reset()
+	b.snappy.Reset(b.buf)
+	if _, err := b.snappy.Write(in); err != nil {
+		return fmt.Errorf("error snappy encoding: %w", err)
+	}
+	if err := b.snappy.Flush(); err != nil {
+		return fmt.Errorf("error flushing snappy encoding: %w", err)
+	}
+	n, err := b.w.Write(typ, b.buf.Bytes())
+	b.written += n
+	if err != nil {
+		return fmt.Errorf("error writing e2store entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// Package erae implements the EraE archive format, storing the execution layer
+// history produced after the merge.
+//
+// Each block is stored along with a proof of the inclusion of its hash in the
+// execution payload of a beacon block, starting with the Bellatrix blocks right
+// after the merge.
+package erae
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/era"
+	"github.com/ethereum/go-ethereum/internal/era/e2store"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/golang/snappy"
+)
+
+var (
+	TypeVersion            uint16 = 0x3265
+	TypeCompressedHeader   uint16 = 0x03
+	TypeCompressedBody     uint16 = 0x04
+	TypeCompressedReceipts uint16 = 0x05
+	TypeBlockProof         uint16 = 0x08
+	TypeAccumulator        uint16 = 0x09
+	TypeBlockIndex         uint16 = 0x3266
+
+	MaxEraESize = 8192
+)
+
+// Filename returns a recognizable EraE-formatted file name for the specified
+// epoch and network.
+func Filename(network string, epoch int, root common.Hash) string {
+	return fmt.Sprintf("%s-%05d-%s.erae", network, epoch, root.Hex()[2:10])
+}
+
+// ReadDir reads all the EraE files in a directory for a given network. Unlike
+// Era1 files, the first epoch is the one containing the merge block, so the
+// epochs are only required to be contiguous.
+// Format: <network>-<epoch>-<hexroot>.erae
+func ReadDir(dir, network string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+	var (
+		next uint64
+		eras []string
+	)
+	for _, entry := range entries {
+		if path.Ext(entry.Name()) != ".erae" {
+			continue
+		}
+		parts := strings.Split(entry.Name(), "-")
+		if len(parts) != 3 || parts[0] != network {
+			// invalid erae filename, skip
+			continue
+		}
+		if epoch, err := strconv.ParseUint(parts[1], 10, 64); err != nil {
+			return nil, fmt.Errorf("malformed erae filename: %s", entry.Name())
+		} else if len(eras) > 0 && epoch != next {
+			return nil, fmt.Errorf("missing epoch %d", next)
+		} else {
+			next = epoch + 1
+		}
+		eras = append(eras, entry.Name())
+	}
+	return eras, nil
+}
+
+// Era reads an EraE file.
+type Era struct {
+	f   era.ReadAtSeekCloser // backing erae file
+	s   *e2store.Reader      // e2store reader over f
+	m   metadata             // start, count, length info
+	mu  *sync.Mutex          // lock for buf
+	buf [8]byte              // buffer reading entry offsets
+}
+
+// From returns an Era backed by f.
+func From(f era.ReadAtSeekCloser) (*Era, error) {
+	m, err := readMetadata(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Era{
+		f:  f,
+		s:  e2store.NewReader(f),
+		m:  m,
+		mu: new(sync.Mutex),
+	}, nil
+}
+
+// Open returns an Era backed by the given filename.
+func Open(filename string) (*Era, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return From(f)
+}
+
+func (e *Era) Close() error {
+	return e.f.Close()
+}
+
+// GetBlockByNumber returns the block with the given number.
+func (e *Era) GetBlockByNumber(num uint64) (*types.Block, error) {
+	rawHeader, err := e.GetRawHeaderByNumber(num)
+	if err != nil {
+		return nil, err
+	}
+	rawBody, err := e.GetRawBodyByNumber(num)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		header types.Header
+		body   types.Body
+	)
+	if err := rlp.DecodeBytes(rawHeader, &header); err != nil {
+		return nil, err
+	}
+	if err := rlp.DecodeBytes(rawBody, &body); err != nil {
+		return nil, err
+	}
+	return types.NewBlockWithHeader(&header).WithBody(body), nil
+}
+
+// GetRawHeaderByNumber returns the RLP encoded header of the given block.
+func (e *Era) GetRawHeaderByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 0, TypeCompressedHeader)
+}
+
+// GetRawBodyByNumber returns the RLP encoded body of the given block.
+func (e *Era) GetRawBodyByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 1, TypeCompressedBody)
+}
+
+// GetRawReceiptsByNumber returns the RLP encoded receipts of the given block.
+func (e *Era) GetRawReceiptsByNumber(num uint64) ([]byte, error) {
+	return e.readCompressedRecord(num, 2, TypeCompressedReceipts)
+}
+
+// GetProofByNumber returns the proof anchoring the given block to the beacon
+// chain.
+func (e *Era) GetProofByNumber(num uint64) (*BlockProof, error) {
+	r, err := e.recordReader(num, 3, TypeBlockProof)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	proof := new(BlockProof)
+	if err := proof.UnmarshalBinary(enc); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// readCompressedRecord reads and decompresses the value of a record of the
+// given block.
+func (e *Era) readCompressedRecord(num uint64, skip int, expectedType uint16) ([]byte, error) {
+	r, err := e.recordReader(num, skip, expectedType)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(snappy.NewReader(r))
+}
+
+// recordReader returns a reader over the value of a record of the given block,
+// skipping the given number of records preceding it in the block's entries.
+func (e *Era) recordReader(num uint64, skip int, expectedType uint16) (io.Reader, error) {
+	if e.m.start > num || e.m.start+e.m.count <= num {
+		return nil, errors.New("out-of-bounds")
+	}
+	off, err := e.readOffset(num)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < skip; i++ {
+		length, err := e.s.LengthAt(off)
+		if err != nil {
+			return nil, err
+		}
+		off += length
+	}
+	r, _, err := e.s.ReaderAt(expectedType, off)
+	return r, err
+}
+
+// Accumulator reads the accumulator entry in the EraE file.
+func (e *Era) Accumulator() (common.Hash, error) {
+	entry, err := e.s.Find(TypeAccumulator)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(entry.Value), nil
+}
+
+// Start returns the listed start block.
+func (e *Era) Start() uint64 {
+	return e.m.start
+}
+
+// Count returns the total number of blocks in the EraE.
+func (e *Era) Count() uint64 {
+	return e.m.count
+}
+
+// readOffset reads a specific block's offset from the block index. The value n
+// is the absolute block number desired.
+func (e *Era) readOffset(n uint64) (int64, error) {
+	var (
+		blockIndexRecordOffset = e.m.length - 24 - int64(e.m.count)*8 // skips start, count, and header
+		firstIndex             = blockIndexRecordOffset + 16          // first index after header / start-num
+		indexOffset            = int64(n-e.m.start) * 8               // desired index * size of indexes
+		offOffset              = firstIndex + indexOffset             // offset of block offset
+	)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.buf[:])
+	if _, err := e.f.ReadAt(e.buf[:], offOffset); err != nil {
+		return 0, err
+	}
+	// The block offset is relative to the start of the block index record.
+	return blockIndexRecordOffset + int64(binary.LittleEndian.Uint64(e.buf[:])), nil
+}
+
+// newSnappyReader returns a snappy.Reader for the e2store entry value at off.
+func newSnappyReader(e *e2store.Reader, expectedType uint16, off int64) (io.Reader, int64, error) {
+	r, n, err := e.ReaderAt(expectedType, off)
+	if err != nil {
+		return nil, 0, err
+	}
+	return snappy.NewReader(r), int64(n), err
+}
+
+// metadata wraps the metadata in the block index.
+type metadata struct {
+	start  uint64
+	count  uint64
+	length int64
+}
+
+// readMetadata reads the metadata stored in an EraE file's block index.
+func readMetadata(f era.ReadAtSeekCloser) (m metadata, err error) {
+	// Determine length of reader.
+	if m.length, err = f.Seek(0, io.SeekEnd); err != nil {
+		return
+	}
+	b := make([]byte, 16)
+	// Read count. It's the last 8 bytes of the file.
+	if _, err = f.ReadAt(b[:8], m.length-8); err != nil {
+		return
+	}
+	m.count = binary.LittleEndian.Uint64(b)
+	// Read start. It's at the offset -sizeof(m.count) -
+	// count*sizeof(indexEntry) - sizeof(m.start)
+	if _, err = f.ReadAt(b[8:], m.length-16-int64(m.count*8)); err != nil {
+		return
+	}
+	m.start = binary.LittleEndian.Uint64(b[8:])
+	return
+}
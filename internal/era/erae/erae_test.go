@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package erae
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// makeProof creates a proof anchoring the hash to a made up beacon root.
+func makeProof(hash common.Hash, seed byte) *BlockProof {
+	var (
+		index  = uint64(params.BlockIndexExecBlockHashDeneb)
+		node   = merkle.Value(hash)
+		branch merkle.Values
+	)
+	for i := index; i > 1; i /= 2 {
+		sibling := merkle.Value{seed, byte(len(branch))}
+		if i&1 == 0 {
+			node = sha256.Sum256(append(node[:], sibling[:]...))
+		} else {
+			node = sha256.Sum256(append(sibling[:], node[:]...))
+		}
+		branch = append(branch, sibling)
+	}
+	return &BlockProof{BeaconRoot: common.Hash(node), Index: index, Branch: branch}
+}
+
+func TestEraEBuilder(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "erae-test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		builder  = NewBuilder(f)
+		blocks   []*types.Block
+		receipts []types.Receipts
+		proofs   []*BlockProof
+		parent   common.Hash
+	)
+	for i := 0; i < 128; i++ {
+		var (
+			blobGas = uint64(i) * 131072
+			beacon  = common.Hash{byte(i)}
+		)
+		header := &types.Header{
+			ParentHash:       parent,
+			Number:           big.NewInt(int64(1000 + i)),
+			Difficulty:       common.Big0,
+			BaseFee:          big.NewInt(7),
+			WithdrawalsHash:  &types.EmptyWithdrawalsHash,
+			BlobGasUsed:      &blobGas,
+			ExcessBlobGas:    new(uint64),
+			ParentBeaconRoot: &beacon,
+		}
+		body := types.Body{
+			Transactions: []*types.Transaction{types.NewTx(&types.LegacyTx{Nonce: uint64(i), GasPrice: big.NewInt(7)})},
+			Withdrawals:  []*types.Withdrawal{{Index: uint64(i), Validator: 1, Address: common.Address{byte(i)}, Amount: 100}},
+		}
+		block := types.NewBlockWithHeader(header).WithBody(body)
+		blockReceipts := types.Receipts{{
+			Type:              types.LegacyTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: 21000,
+			Logs:              []*types.Log{{Address: common.Address{byte(i)}, Data: []byte{byte(i)}}},
+		}}
+		blocks, receipts = append(blocks, block), append(receipts, blockReceipts)
+		proofs = append(proofs, makeProof(block.Hash(), byte(i)))
+		parent = block.Hash()
+
+		if err := builder.Add(block, blockReceipts, proofs[i]); err != nil {
+			t.Fatalf("error adding entry: %v", err)
+		}
+	}
+	// Blocks not matching their proofs are rejected
+	if err := builder.Add(blocks[1], receipts[1], proofs[0]); err == nil {
+		t.Fatal("added block with invalid proof")
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("error finalizing erae: %v", err)
+	}
+	roots := make([]common.Hash, len(proofs))
+	for i, proof := range proofs {
+		roots[i] = proof.BeaconRoot
+	}
+	if want, _ := ComputeAccumulator(roots); root != want {
+		t.Fatalf("wrong accumulator: have %x, want %x", root, want)
+	}
+
+	// Verify EraE contents.
+	e, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("failed to open erae: %v", err)
+	}
+	defer e.Close()
+
+	if e.Start() != 1000 || e.Count() != 128 {
+		t.Fatalf("wrong range: have %d+%d, want 1000+128", e.Start(), e.Count())
+	}
+	if acc, err := e.Accumulator(); err != nil || acc != root {
+		t.Fatalf("wrong stored accumulator: have %x, want %x (err %v)", acc, root, err)
+	}
+	it, err := NewIterator(e)
+	if err != nil {
+		t.Fatalf("failed to make iterator: %v", err)
+	}
+	for i := range blocks {
+		if !it.Next() {
+			t.Fatalf("expected more entries")
+		}
+		if it.Error() != nil {
+			t.Fatalf("unexpected error %v", it.Error())
+		}
+		block, have, err := it.BlockAndReceipts()
+		if err != nil {
+			t.Fatalf("error reading block %d: %v", i, err)
+		}
+		if block.Hash() != blocks[i].Hash() {
+			t.Fatalf("block %d: hash mismatch: have %x, want %x", i, block.Hash(), blocks[i].Hash())
+		}
+		if len(block.Withdrawals()) != 1 || *block.BlobGasUsed() != *blocks[i].BlobGasUsed() {
+			t.Fatalf("block %d: post-merge fields missing", i)
+		}
+		want, _ := rlp.EncodeToBytes(receipts[i])
+		if enc, _ := rlp.EncodeToBytes(have); string(enc) != string(want) {
+			t.Fatalf("block %d: receipts mismatch", i)
+		}
+		proof, err := it.Proof()
+		if err != nil {
+			t.Fatalf("error reading proof %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(proof, proofs[i]) {
+			t.Fatalf("block %d: proof mismatch", i)
+		}
+		if err := proof.Verify(block.Hash()); err != nil {
+			t.Fatalf("block %d: invalid proof: %v", i, err)
+		}
+	}
+	if it.Next() {
+		t.Fatal("expected no more entries")
+	}
+	// Check random access to the entries.
+	for i, want := range blocks {
+		number := want.NumberU64()
+		if block, err := e.GetBlockByNumber(number); err != nil || block.Hash() != want.Hash() {
+			t.Fatalf("block %d: random access mismatch (err %v)", i, err)
+		}
+		if proof, err := e.GetProofByNumber(number); err != nil || !reflect.DeepEqual(proof, proofs[i]) {
+			t.Fatalf("block %d: random access proof mismatch (err %v)", i, err)
+		}
+	}
+	if _, err := e.GetBlockByNumber(999); err == nil {
+		t.Fatal("expected error reading out-of-bounds block")
+	}
+}
+
+func TestBlockProofVerify(t *testing.T) {
+	hash := common.Hash{0x01}
+	if err := makeProof(hash, 1).Verify(hash); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if err := makeProof(hash, 1).Verify(common.Hash{0x02}); err == nil {
+		t.Fatal("proof of different block accepted")
+	}
+	// Proofs of other fields of the beacon block are rejected
+	proof := makeProof(hash, 1)
+	proof.Index, proof.Branch = proof.Index/2, proof.Branch[1:]
+	proof.BeaconRoot = common.Hash(sha256.Sum256(append(append([]byte{}, proof.BeaconRoot[:]...), hash[:]...)))
+	if err := proof.Verify(hash); err == nil {
+		t.Fatal("proof with invalid index accepted")
+	}
+}
+
+func TestEraEFilename(t *testing.T) {
+	if have, want := Filename("mainnet", 1897, common.Hash{1}), "mainnet-01897-01000000.erae"; have != want {
+		t.Errorf("invalid filename: have %s, want %s", have, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package erae
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/beacon/merkle"
+	"github.com/ethereum/go-ethereum/beacon/params"
+	"github.com/ethereum/go-ethereum/common"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// BlockProof is a merkle proof of the hash of an execution block, included in
+// the payload of the beacon block with the given root.
+type BlockProof struct {
+	BeaconRoot common.Hash   // Root of the beacon block including the block
+	Index      uint64        // Generalized index of the block hash in the beacon block
+	Branch     merkle.Values // Merkle branch from the block hash to the beacon root
+}
+
+// Verify checks that the proof anchors the given block hash to its beacon root.
+func (p *BlockProof) Verify(hash common.Hash) error {
+	// The index of the block hash is shared by Bellatrix and Capella blocks.
+	switch p.Index {
+	case params.BlockIndexExecBlockHashBellatrix, params.BlockIndexExecBlockHashDeneb:
+	default:
+		return fmt.Errorf("invalid block hash index %d", p.Index)
+	}
+	return merkle.VerifyProof(p.BeaconRoot, p.Index, p.Branch, merkle.Value(hash))
+}
+
+// MarshalBinary encodes the proof as the beacon root, followed by the little
+// endian index and the nodes of the branch.
+func (p *BlockProof) MarshalBinary() ([]byte, error) {
+	enc := make([]byte, 40+32*len(p.Branch))
+	copy(enc, p.BeaconRoot[:])
+	binary.LittleEndian.PutUint64(enc[32:], p.Index)
+	for i, node := range p.Branch {
+		copy(enc[40+32*i:], node[:])
+	}
+	return enc, nil
+}
+
+// UnmarshalBinary decodes a proof encoded by MarshalBinary.
+func (p *BlockProof) UnmarshalBinary(enc []byte) error {
+	if len(enc) < 40 || (len(enc)-40)%32 != 0 {
+		return errors.New("invalid block proof length")
+	}
+	p.BeaconRoot = common.BytesToHash(enc[:32])
+	p.Index = binary.LittleEndian.Uint64(enc[32:40])
+	p.Branch = make(merkle.Values, (len(enc)-40)/32)
+	for i := range p.Branch {
+		copy(p.Branch[i][:], enc[40+32*i:])
+	}
+	return nil
+}
+
+// ComputeAccumulator calculates the SSZ hash tree root of the list of beacon
+// roots the blocks of an archive are anchored to.
+func ComputeAccumulator(roots []common.Hash) (common.Hash, error) {
+	if len(roots) > MaxEraESize {
+		return common.Hash{}, fmt.Errorf("too many records: have %d, max %d", len(roots), MaxEraESize)
+	}
+	hh := ssz.NewHasher()
+	for _, root := range roots {
+		hh.Append(root[:])
+	}
+	hh.MerkleizeWithMixin(0, uint64(len(roots)), uint64(MaxEraESize))
+	return hh.HashRoot()
+}
@@ -44,7 +44,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/logindex"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -617,10 +617,7 @@ func (b testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent)
 func (b testBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	panic("implement me")
 }
-func (b testBackend) BloomStatus() (uint64, uint64) { panic("implement me") }
-func (b testBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
-	panic("implement me")
-}
+func (b testBackend) LogIndex() *logindex.Indexer { panic("implement me") }
 
 func TestEstimateGas(t *testing.T) {
 	t.Parallel()
@@ -975,6 +972,165 @@ func TestCall(t *testing.T) {
 	}
 }
 
+func TestSimulateV1(t *testing.T) {
+	t.Parallel()
+	// Initialize test accounts
+	var (
+		accounts = newAccounts(3)
+		genesis  = &core.Genesis{
+			Config: params.MergedTestChainConfig,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+		genBlocks = 10
+		signer    = types.HomesteadSigner{}
+	)
+	api := NewBlockChainAPI(newTestBackend(t, genBlocks, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: uint64(i), To: &accounts[1].addr, Value: big.NewInt(1000), Gas: params.TxGas, GasPrice: b.BaseFee(), Data: nil}), signer, accounts[0].key)
+		b.AddTx(tx)
+		b.SetPoS()
+	}))
+	var (
+		logger   = common.Address{0xc0, 0xff, 0xee}
+		hashRead = common.Address{0xc0, 0xff, 0xef}
+		latest   = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	)
+	var testSuite = []struct {
+		name      string
+		opts      simOpts
+		expectErr int
+		check     func(t *testing.T, res []map[string]interface{})
+	}{
+		{
+			name:      "empty input",
+			opts:      simOpts{},
+			expectErr: errCodeInvalidParams,
+		},
+		{
+			name: "transfer with traced ether movement",
+			opts: simOpts{
+				TraceTransfers: true,
+				BlockStateCalls: []simBlock{{
+					Calls: []TransactionArgs{{
+						From:  &accounts[0].addr,
+						To:    &accounts[2].addr,
+						Value: (*hexutil.Big)(big.NewInt(1000)),
+					}},
+				}},
+			},
+			check: func(t *testing.T, res []map[string]interface{}) {
+				calls := res[0]["calls"].([]simCallResult)
+				if len(calls) != 1 || calls[0].Status != hexutil.Uint64(types.ReceiptStatusSuccessful) {
+					t.Fatalf("unexpected call results: %+v", calls)
+				}
+				logs := calls[0].Logs
+				if len(logs) != 1 || logs[0].Address != transferAddress || logs[0].Topics[0] != transferTopic {
+					t.Fatalf("missing transfer log: %+v", logs)
+				}
+				if logs[0].BlockHash != res[0]["hash"].(common.Hash) {
+					t.Errorf("log block hash mismatch: have %x, want %x", logs[0].BlockHash, res[0]["hash"])
+				}
+				if have := res[0]["number"].(*hexutil.Big).ToInt().Int64(); have != int64(genBlocks+1) {
+					t.Errorf("block number mismatch: have %d, want %d", have, genBlocks+1)
+				}
+			},
+		},
+		{
+			name: "gap filling and logs across blocks",
+			opts: simOpts{
+				BlockStateCalls: []simBlock{
+					{
+						StateOverrides: &StateOverride{
+							// LOG0 with empty data, stop
+							logger: OverrideAccount{Code: hex2Bytes("60006000a000")},
+						},
+						Calls: []TransactionArgs{{From: &accounts[0].addr, To: &logger}},
+					},
+					{
+						BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(int64(genBlocks + 4)))},
+						StateOverrides: &StateOverride{
+							// BLOCKHASH(NUMBER-2), return it
+							hashRead: OverrideAccount{Code: hex2Bytes("600243034060005260206000f3")},
+						},
+						Calls: []TransactionArgs{{From: &accounts[0].addr, To: &hashRead}},
+					},
+				},
+			},
+			check: func(t *testing.T, res []map[string]interface{}) {
+				if len(res) != 4 {
+					t.Fatalf("wrong number of blocks: have %d, want 4", len(res))
+				}
+				if logs := res[0]["calls"].([]simCallResult)[0].Logs; len(logs) != 1 || logs[0].Address != logger {
+					t.Errorf("missing contract log: %+v", logs)
+				}
+				for i := 1; i < len(res); i++ {
+					if res[i]["parentHash"] != res[i-1]["hash"] {
+						t.Errorf("block %d: parent hash mismatch", i)
+					}
+				}
+				ret := res[3]["calls"].([]simCallResult)[0].ReturnValue
+				if common.BytesToHash(ret) != res[1]["hash"].(common.Hash) {
+					t.Errorf("blockhash of simulated block mismatch: have %x, want %x", ret, res[1]["hash"])
+				}
+			},
+		},
+		{
+			name: "block numbers out of order",
+			opts: simOpts{
+				BlockStateCalls: []simBlock{
+					{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(int64(genBlocks + 2)))}},
+					{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(int64(genBlocks + 1)))}},
+				},
+			},
+			expectErr: errCodeBlockNumberInvalid,
+		},
+		{
+			name: "too many blocks",
+			opts: simOpts{
+				BlockStateCalls: []simBlock{
+					{BlockOverrides: &BlockOverrides{Number: (*hexutil.Big)(big.NewInt(int64(genBlocks + maxSimulateBlocks + 1)))}},
+				},
+			},
+			expectErr: errCodeClientLimitExceeded,
+		},
+		{
+			name: "nonce too low in validation mode",
+			opts: simOpts{
+				Validation: true,
+				BlockStateCalls: []simBlock{{
+					Calls: []TransactionArgs{{
+						From:         &accounts[0].addr,
+						To:           &accounts[2].addr,
+						Nonce:        new(hexutil.Uint64),
+						MaxFeePerGas: (*hexutil.Big)(big.NewInt(params.GWei)),
+					}},
+				}},
+			},
+			expectErr: errCodeNonceTooLow,
+		},
+	}
+	for _, tc := range testSuite {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := api.SimulateV1(context.Background(), tc.opts, &latest)
+			if tc.expectErr != 0 {
+				var rpcErr rpc.Error
+				if !errors.As(err, &rpcErr) {
+					t.Fatalf("want error code %d, have %v", tc.expectErr, err)
+				}
+				if rpcErr.ErrorCode() != tc.expectErr {
+					t.Fatalf("error code mismatch: have %d (%v), want %d", rpcErr.ErrorCode(), err, tc.expectErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tc.check(t, res)
+		})
+	}
+}
+
 func TestSignTransaction(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -67,6 +67,9 @@ type TransactionArgs struct {
 	BlobFeeCap *hexutil.Big  `json:"maxFeePerBlobGas"`
 	BlobHashes []common.Hash `json:"blobVersionedHashes,omitempty"`
 
+	// For SetCodeTxType
+	AuthorizationList []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+
 	// For BlobTxType transactions with blob sidecar
 	Blobs       []kzg4844.Blob       `json:"blobs"`
 	Commitments []kzg4844.Commitment `json:"commitments"`
@@ -131,6 +134,9 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 		if args.BlobHashes != nil {
 			return errors.New(`missing "to" in blob transaction`)
 		}
+		if args.AuthorizationList != nil {
+			return errors.New(`missing "to" in set code transaction`)
+		}
 		if len(args.data()) == 0 {
 			return errors.New(`contract creation without any data provided`)
 		}
@@ -158,6 +164,7 @@ func (args *TransactionArgs) setDefaults(ctx context.Context, b Backend, skipGas
 				AccessList:           args.AccessList,
 				BlobFeeCap:           args.BlobFeeCap,
 				BlobHashes:           args.BlobHashes,
+				AuthorizationList:    args.AuthorizationList,
 			}
 			latestBlockNr := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
 			estimated, err := DoEstimateGas(ctx, b, callArgs, latestBlockNr, nil, b.RPCGasCap())
@@ -464,6 +471,8 @@ func (args *TransactionArgs) ToMessage(baseFee *big.Int) *core.Message {
 		BlobGasFeeCap:     (*big.Int)(args.BlobFeeCap),
 		BlobHashes:        args.BlobHashes,
 		SkipAccountChecks: true,
+
+		SetCodeAuthorizations: args.AuthorizationList,
 	}
 }
 
@@ -498,6 +507,24 @@ func (args *TransactionArgs) ToTransaction() *types.Transaction {
 			}
 		}
 
+	case args.AuthorizationList != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		data = &types.SetCodeTx{
+			To:         *args.To,
+			ChainID:    uint256.MustFromBig((*big.Int)(args.ChainID)),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasFeeCap:  uint256.MustFromBig((*big.Int)(args.MaxFeePerGas)),
+			GasTipCap:  uint256.MustFromBig((*big.Int)(args.MaxPriorityFeePerGas)),
+			Value:      uint256.MustFromBig((*big.Int)(args.Value)),
+			Data:       args.data(),
+			AccessList: al,
+			AuthList:   args.AuthorizationList,
+		}
+
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
@@ -23,11 +23,13 @@ var Modules = map[string]string{
 	"ethash":   EthashJs,
 	"debug":    DebugJs,
 	"eth":      EthJs,
+	"mev":      MevJs,
 	"miner":    MinerJs,
 	"net":      NetJs,
 	"personal": PersonalJs,
 	"rpc":      RpcJs,
 	"txpool":   TxpoolJs,
+	"trace":    TraceJs,
 	"les":      LESJs,
 	"vflux":    VfluxJs,
 	"dev":      DevJs,
@@ -448,6 +450,12 @@ web3._extend({
 			call: 'debug_getBadBlocks',
 			params: 0,
 		}),
+		new web3._extend.Method({
+			name: 'executionWitness',
+			call: 'debug_executionWitness',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 		new web3._extend.Method({
 			name: 'storageRangeAt',
 			call: 'debug_storageRangeAt',
@@ -785,6 +793,37 @@ web3._extend({
 });
 `
 
+const TraceJs = `
+web3._extend({
+	property: 'trace',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'block',
+			call: 'trace_block',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'transaction',
+			call: 'trace_transaction',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'filter',
+			call: 'trace_filter',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'replayBlockTransactions',
+			call: 'trace_replayBlockTransactions',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+	]
+});
+`
+
 const LESJs = `
 web3._extend({
 	property: 'les',
@@ -839,6 +878,19 @@ web3._extend({
 });
 `
 
+const MevJs = `
+web3._extend({
+	property: 'mev',
+	methods: [
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'mev_sendBundle',
+			params: 1
+		}),
+	]
+});
+`
+
 const VfluxJs = `
 web3._extend({
 	property: 'vflux',
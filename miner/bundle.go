@@ -0,0 +1,291 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxBundlesPerBlock is the maximum number of bundles accepted for a
+	// single target block.
+	maxBundlesPerBlock = 256
+
+	// maxBundleTxs is the maximum number of transactions in a bundle.
+	maxBundleTxs = 64
+
+	// maxBundleFutureBlocks is the maximum distance of the target block of a
+	// bundle from the current head.
+	maxBundleFutureBlocks = 8
+)
+
+var (
+	errEmptyBundle        = errors.New("bundle without transactions")
+	errBundleTooLarge     = errors.New("bundle contains too many transactions")
+	errBundleBlobTx       = errors.New("blob transactions are not supported in bundles")
+	errBundleTimestamp    = errors.New("bundle timestamp range is empty")
+	errBundleStale        = errors.New("bundle target block already mined")
+	errBundleFuture       = errors.New("bundle target block too far in the future")
+	errBundlePoolFull     = errors.New("too many bundles for target block")
+	errBundleReverted     = errors.New("bundle transaction reverted")
+	errBundleUnprofitable = errors.New("bundle below the minimum gas price")
+)
+
+// Bundle is an ordered list of transactions which must be included atomically
+// at the top of the target block: either all of them are included in the given
+// order, or none of them.
+type Bundle struct {
+	Txs          types.Transactions // Transactions to include, in order
+	BlockNumber  uint64             // Number of the block the bundle targets
+	MinTimestamp uint64             // Minimum block timestamp, zero if unrestricted
+	MaxTimestamp uint64             // Maximum block timestamp, zero if unrestricted
+
+	// RevertingTxHashes lists the transactions which are allowed to revert
+	// without invalidating the bundle.
+	RevertingTxHashes []common.Hash
+}
+
+// Hash returns the identifier of the bundle, the hash of the concatenated
+// transaction hashes.
+func (b *Bundle) Hash() common.Hash {
+	hashes := make([]byte, 0, len(b.Txs)*common.HashLength)
+	for _, tx := range b.Txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return crypto.Keccak256Hash(hashes)
+}
+
+// validate checks the static constraints of the bundle.
+func (b *Bundle) validate() error {
+	switch {
+	case len(b.Txs) == 0:
+		return errEmptyBundle
+	case len(b.Txs) > maxBundleTxs:
+		return errBundleTooLarge
+	case b.MaxTimestamp != 0 && b.MaxTimestamp < b.MinTimestamp:
+		return errBundleTimestamp
+	}
+	for _, tx := range b.Txs {
+		if tx.Type() == types.BlobTxType {
+			return errBundleBlobTx
+		}
+	}
+	return nil
+}
+
+// eligible returns whether the bundle can be included in the given block.
+func (b *Bundle) eligible(header *types.Header) bool {
+	if b.BlockNumber != header.Number.Uint64() {
+		return false
+	}
+	if b.MinTimestamp != 0 && header.Time < b.MinTimestamp {
+		return false
+	}
+	if b.MaxTimestamp != 0 && header.Time > b.MaxTimestamp {
+		return false
+	}
+	return true
+}
+
+// bundlePool stores the submitted bundles until their target block is mined.
+type bundlePool struct {
+	bundles map[uint64][]*Bundle // Bundles grouped by target block number
+	lock    sync.Mutex
+}
+
+func newBundlePool() *bundlePool {
+	return &bundlePool{bundles: make(map[uint64][]*Bundle)}
+}
+
+// add inserts the bundle into the pool. Bundles targeting an already mined
+// block, i.e. at or below the given head, or a block too far ahead of it are
+// rejected.
+func (p *bundlePool) add(bundle *Bundle, head uint64) error {
+	if bundle.BlockNumber <= head {
+		return errBundleStale
+	}
+	if bundle.BlockNumber > head+maxBundleFutureBlocks {
+		return errBundleFuture
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(head)
+
+	hash := bundle.Hash()
+	for _, b := range p.bundles[bundle.BlockNumber] {
+		if b.Hash() == hash {
+			return nil // Resubmission, ignore
+		}
+	}
+	if len(p.bundles[bundle.BlockNumber]) >= maxBundlesPerBlock {
+		return errBundlePoolFull
+	}
+	p.bundles[bundle.BlockNumber] = append(p.bundles[bundle.BlockNumber], bundle)
+	return nil
+}
+
+// pending returns the bundles which can be included in the given block.
+func (p *bundlePool) pending(header *types.Header) []*Bundle {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(header.Number.Uint64() - 1)
+
+	var bundles []*Bundle
+	for _, b := range p.bundles[header.Number.Uint64()] {
+		if b.eligible(header) {
+			bundles = append(bundles, b)
+		}
+	}
+	return bundles
+}
+
+// prune drops the bundles targeting the blocks at or below the given head.
+func (p *bundlePool) prune(head uint64) {
+	for number := range p.bundles {
+		if number <= head {
+			delete(p.bundles, number)
+		}
+	}
+}
+
+// SendBundle submits a bundle for inclusion in its target block.
+func (miner *Miner) SendBundle(bundle *Bundle) error {
+	if err := bundle.validate(); err != nil {
+		return err
+	}
+	return miner.bundles.add(bundle, miner.chain.CurrentHeader().Number.Uint64())
+}
+
+// bundleResult is the outcome of applying a bundle on top of an environment.
+type bundleResult struct {
+	bundle   *Bundle
+	state    *state.StateDB
+	gasPool  core.GasPool
+	gasUsed  uint64
+	receipts []*types.Receipt
+	price    *big.Int // Coinbase profit per unit of gas used
+}
+
+// applyBundle executes the bundle on a copy of the environment state, leaving
+// the environment intact. An error is returned if any of the transactions fails,
+// or reverts without being allowed to.
+func (miner *Miner) applyBundle(env *environment, bundle *Bundle) (*bundleResult, error) {
+	res := &bundleResult{
+		bundle:  bundle,
+		state:   env.state.Copy(),
+		gasPool: *env.gasPool,
+		gasUsed: env.header.GasUsed,
+	}
+	balance := res.state.GetBalance(env.coinbase).ToBig()
+
+	var used uint64
+	for i, tx := range bundle.Txs {
+		if tx.Protected() && !miner.chainConfig.IsEIP155(env.header.Number) {
+			return nil, fmt.Errorf("replay protected transaction %s before EIP155", tx.Hash())
+		}
+		res.state.SetTxContext(tx.Hash(), env.tcount+i)
+		receipt, err := core.ApplyTransaction(miner.chainConfig, miner.chain, &env.coinbase, &res.gasPool, res.state, env.header, tx, &res.gasUsed, vm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", tx.Hash(), err)
+		}
+		if receipt.Status == types.ReceiptStatusFailed && !slices.Contains(bundle.RevertingTxHashes, tx.Hash()) {
+			return nil, fmt.Errorf("%w: %s", errBundleReverted, tx.Hash())
+		}
+		res.receipts = append(res.receipts, receipt)
+		used += receipt.GasUsed
+	}
+	profit := new(big.Int).Sub(res.state.GetBalance(env.coinbase).ToBig(), balance)
+	res.price = new(big.Int).Div(profit, new(big.Int).SetUint64(used))
+	return res, nil
+}
+
+// commitBundles simulates the bundles targeting the sealing block and commits
+// the ones paying at least the minimum gas price to the coinbase, ahead of
+// the regular transactions. The bundles are committed atomically, in the order
+// of their simulated profitability.
+func (miner *Miner) commitBundles(env *environment, minTip *big.Int, interrupt *atomic.Int32) error {
+	bundles := miner.bundles.pending(env.header)
+	if len(bundles) == 0 {
+		return nil
+	}
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	if minTip == nil {
+		minTip = new(big.Int)
+	}
+	// Simulate all the bundles on top of the empty block to rank them
+	var simulated []*bundleResult
+	for _, bundle := range bundles {
+		res, err := miner.applyBundle(env, bundle)
+		if err != nil {
+			log.Debug("Bundle simulation failed", "hash", bundle.Hash(), "err", err)
+			continue
+		}
+		if res.price.Cmp(minTip) < 0 {
+			log.Debug("Bundle simulation unprofitable", "hash", bundle.Hash(), "price", res.price, "min", minTip)
+			continue
+		}
+		simulated = append(simulated, res)
+	}
+	slices.SortStableFunc(simulated, func(a, b *bundleResult) int {
+		return b.price.Cmp(a.price)
+	})
+	// Commit the bundles one by one, re-executing them on top of the
+	// previously committed ones as they might conflict.
+	for i, sim := range simulated {
+		if interrupt != nil {
+			if signal := interrupt.Load(); signal != commitInterruptNone {
+				return signalToErr(signal)
+			}
+		}
+		res := sim
+		if i > 0 {
+			var err error
+			if res, err = miner.applyBundle(env, sim.bundle); err != nil {
+				log.Debug("Bundle failed on top of other bundles", "hash", sim.bundle.Hash(), "err", err)
+				continue
+			}
+			if res.price.Cmp(minTip) < 0 {
+				log.Debug("Bundle unprofitable on top of other bundles", "hash", sim.bundle.Hash(), "err", errBundleUnprofitable)
+				continue
+			}
+		}
+		env.state = res.state
+		*env.gasPool = res.gasPool
+		env.header.GasUsed = res.gasUsed
+		env.txs = append(env.txs, res.bundle.Txs...)
+		env.receipts = append(env.receipts, res.receipts...)
+		env.tcount += len(res.bundle.Txs)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newBundleTx(nonce uint64, gasPrice *big.Int) *types.Transaction {
+	return types.MustSignNewTx(testBankKey, types.LatestSigner(params.TestChainConfig), &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1000),
+		Gas:      params.TxGas,
+		GasPrice: gasPrice,
+	})
+}
+
+func TestBundlePool(t *testing.T) {
+	var (
+		pool     = newBundlePool()
+		gasPrice = big.NewInt(params.InitialBaseFee)
+		tx       = newBundleTx(0, gasPrice)
+	)
+	invalid := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{&Bundle{BlockNumber: 2}, errEmptyBundle},
+		{&Bundle{BlockNumber: 2, Txs: make(types.Transactions, maxBundleTxs+1)}, errBundleTooLarge},
+		{&Bundle{BlockNumber: 2, Txs: types.Transactions{tx}, MinTimestamp: 10, MaxTimestamp: 5}, errBundleTimestamp},
+		{&Bundle{BlockNumber: 2, Txs: types.Transactions{types.NewTx(&types.BlobTx{})}}, errBundleBlobTx},
+	}
+	for i, test := range invalid {
+		if err := test.bundle.validate(); !errors.Is(err, test.err) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+	if err := pool.add(&Bundle{BlockNumber: 1, Txs: types.Transactions{tx}}, 1); !errors.Is(err, errBundleStale) {
+		t.Fatalf("stale bundle: have %v, want %v", err, errBundleStale)
+	}
+	if err := pool.add(&Bundle{BlockNumber: 2 + maxBundleFutureBlocks, Txs: types.Transactions{tx}}, 1); !errors.Is(err, errBundleFuture) {
+		t.Fatalf("future bundle: have %v, want %v", err, errBundleFuture)
+	}
+	bundles := []*Bundle{
+		{BlockNumber: 2, Txs: types.Transactions{tx}},
+		{BlockNumber: 2, Txs: types.Transactions{newBundleTx(1, gasPrice)}, MinTimestamp: 100},
+		{BlockNumber: 2, Txs: types.Transactions{newBundleTx(2, gasPrice)}, MaxTimestamp: 90},
+		{BlockNumber: 3, Txs: types.Transactions{tx}},
+	}
+	for i, bundle := range bundles {
+		if err := pool.add(bundle, 1); err != nil {
+			t.Fatalf("bundle %d: failed to add: %v", i, err)
+		}
+	}
+	// Resubmissions are deduplicated
+	if err := pool.add(&Bundle{BlockNumber: 2, Txs: types.Transactions{tx}}, 1); err != nil {
+		t.Fatalf("failed to resubmit bundle: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(2), Time: 80}
+	if have := pool.pending(header); len(have) != 2 || have[0] != bundles[0] || have[1] != bundles[2] {
+		t.Fatalf("wrong eligible bundles: %v", have)
+	}
+	header.Time = 120
+	if have := pool.pending(header); len(have) != 2 || have[0] != bundles[0] || have[1] != bundles[1] {
+		t.Fatalf("wrong eligible bundles: %v", have)
+	}
+	// Bundles of mined blocks are dropped
+	if have := pool.pending(&types.Header{Number: big.NewInt(3)}); len(have) != 1 || have[0] != bundles[3] {
+		t.Fatalf("wrong eligible bundles: %v", have)
+	}
+	if len(pool.bundles) != 1 {
+		t.Fatalf("stale bundles not pruned: %d blocks left", len(pool.bundles))
+	}
+}
+
+func TestBuildPayloadWithBundles(t *testing.T) {
+	var (
+		baseFee   = big.NewInt(params.InitialBaseFee)
+		highPrice = new(big.Int).Mul(baseFee, big.NewInt(10))
+	)
+	tests := []struct {
+		name   string
+		bundle []*types.Transaction
+		tip    *big.Int
+		want   []common.Hash
+	}{
+		{
+			// The bundle is committed ahead of the pool transactions, which
+			// conflict with it.
+			name:   "included",
+			bundle: []*types.Transaction{newBundleTx(0, highPrice), newBundleTx(1, highPrice)},
+			want:   []common.Hash{newBundleTx(0, highPrice).Hash(), newBundleTx(1, highPrice).Hash()},
+		},
+		{
+			// The bundle is dropped atomically if any of its transactions fails.
+			name:   "failing",
+			bundle: []*types.Transaction{newBundleTx(0, highPrice), newBundleTx(5, highPrice)},
+			want:   []common.Hash{pendingTxs[0].Hash()},
+		},
+		{
+			// The bundle is dropped if it doesn't pay the minimum gas price.
+			name:   "unprofitable",
+			bundle: []*types.Transaction{newBundleTx(0, highPrice)},
+			tip:    new(big.Int).Mul(highPrice, big.NewInt(2)),
+			want:   []common.Hash{pendingTxs[0].Hash()},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w, b := newTestWorker(t, params.TestChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), 0)
+			defer b.chain.Stop()
+			if test.tip != nil {
+				w.SetGasTip(test.tip)
+			}
+			if err := w.SendBundle(&Bundle{BlockNumber: 1, Txs: test.bundle}); err != nil {
+				t.Fatalf("failed to send bundle: %v", err)
+			}
+			payload, err := w.buildPayload(&BuildPayloadArgs{
+				Parent:    b.chain.CurrentBlock().Hash(),
+				Timestamp: uint64(time.Now().Unix()),
+			})
+			if err != nil {
+				t.Fatalf("failed to build payload: %v", err)
+			}
+			txs := payload.ResolveFull().ExecutionPayload.Transactions
+			if len(txs) != len(test.want) {
+				t.Fatalf("wrong number of transactions: have %d, want %d", len(txs), len(test.want))
+			}
+			for i, enc := range txs {
+				var tx types.Transaction
+				if err := tx.UnmarshalBinary(enc); err != nil {
+					t.Fatal(err)
+				}
+				if tx.Hash() != test.want[i] {
+					t.Fatalf("transaction %d: have %x, want %x", i, tx.Hash(), test.want[i])
+				}
+			}
+		})
+	}
+}
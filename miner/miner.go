@@ -48,6 +48,12 @@ type Config struct {
 	GasCeil             uint64         // Target gas ceiling for mined blocks.
 	GasPrice            *big.Int       // Minimum gas price for mining a transaction
 	Recommit            time.Duration  // The time interval for miner to re-create mining work.
+
+	Ordering  string             `toml:",omitempty"` // Transaction ordering policy (price, fifo or deterministic)
+	SenderCap int                `toml:",omitempty"` // Maximum number of transactions per sender in a block, zero for unlimited
+	Orderer   TransactionOrderer `toml:"-"`          // Custom transaction ordering, overrides Ordering and SenderCap
+
+	Bundles bool `toml:",omitempty"` // Whether to accept transaction bundles over the mev namespace
 }
 
 // DefaultConfig contains default settings for miner.
@@ -73,10 +79,20 @@ type Miner struct {
 	chain       *core.BlockChain
 	pending     *pending
 	pendingMu   sync.Mutex // Lock protects the pending block
+	bundles     *bundlePool
+	orderer     TransactionOrderer
 }
 
-// New creates a new miner with provided config.
-func New(eth Backend, config Config, engine consensus.Engine) *Miner {
+// New creates a new miner with provided config. An error is returned if the
+// configured transaction ordering is invalid.
+func New(eth Backend, config Config, engine consensus.Engine) (*Miner, error) {
+	orderer := config.Orderer
+	if orderer == nil {
+		var err error
+		if orderer, err = NewTransactionOrderer(config.Ordering, config.SenderCap); err != nil {
+			return nil, err
+		}
+	}
 	return &Miner{
 		config:      &config,
 		chainConfig: eth.BlockChain().Config(),
@@ -84,7 +100,9 @@ func New(eth Backend, config Config, engine consensus.Engine) *Miner {
 		txpool:      eth.TxPool(),
 		chain:       eth.BlockChain(),
 		pending:     &pending{},
-	}
+		bundles:     newBundlePool(),
+		orderer:     orderer,
+	}, nil
 }
 
 // Pending returns the currently pending block and associated receipts, logs
@@ -164,6 +164,9 @@ func createMiner(t *testing.T) *Miner {
 
 	// Create Miner
 	backend := NewMockBackend(bc, txpool)
-	miner := New(backend, config, engine)
+	miner, err := New(backend, config, engine)
+	if err != nil {
+		t.Fatalf("can't create new miner: %v", err)
+	}
 	return miner
 }
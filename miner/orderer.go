@@ -0,0 +1,158 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// Names of the built-in transaction orderings.
+const (
+	OrderingPrice         = "price"         // Highest miner tip first, then arrival time
+	OrderingFIFO          = "fifo"          // Arrival time
+	OrderingDeterministic = "deterministic" // Highest miner tip first, then sender address
+)
+
+// TransactionSet is a set of pending transactions, retrieved one by one in the
+// order they should be included into a block. The transactions of an account
+// must be retrieved in nonce order.
+type TransactionSet interface {
+	// Peek returns the next transaction to include along with its effective
+	// miner tip, or nil if the set is empty.
+	Peek() (*txpool.LazyTransaction, *uint256.Int)
+
+	// Shift replaces the current transaction with the next one from the same
+	// account.
+	Shift()
+
+	// Pop removes the current transaction along with all the remaining ones
+	// from the same account.
+	Pop()
+
+	// Empty returns whether the set is exhausted.
+	Empty() bool
+
+	// Clear removes the entire content of the set.
+	Clear()
+}
+
+// TransactionOrderer defines the policy used by the miner to order the pending
+// transactions in the blocks it builds.
+type TransactionOrderer interface {
+	// Order creates the transaction set to fill a block from. The input map
+	// contains the nonce-sorted transactions of each account, and is reowned
+	// by the orderer.
+	Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet
+
+	// Less reports whether the next transaction of set a should be included
+	// before the next transaction of set b. Both sets are non-empty and created
+	// by the same orderer. It is used to interleave the plain and blob
+	// transactions of a block.
+	Less(a, b TransactionSet) bool
+}
+
+// heapOrderer is a transaction orderer picking the next account head from a
+// heap, sorted by the given less function.
+type heapOrderer struct {
+	less func(a, b *txWithMinerFee) bool
+}
+
+// Order implements TransactionOrderer.
+func (o *heapOrderer) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	return newOrderedTransactions(signer, txs, baseFee, o.less)
+}
+
+// Less implements TransactionOrderer.
+func (o *heapOrderer) Less(a, b TransactionSet) bool {
+	return o.less(a.(*transactionsByPriceAndNonce).head(), b.(*transactionsByPriceAndNonce).head())
+}
+
+// NewPriceOrderer creates the default transaction orderer, including the
+// transactions paying the highest miner tip first. Transactions with equal tips
+// are ordered by the time they were first seen.
+func NewPriceOrderer() TransactionOrderer {
+	return &heapOrderer{less: byPriceAndTime}
+}
+
+// NewFIFOOrderer creates a transaction orderer including the transactions in
+// the order they were first seen, regardless of the tips they pay.
+func NewFIFOOrderer() TransactionOrderer {
+	return &heapOrderer{less: byTime}
+}
+
+// NewDeterministicOrderer creates a transaction orderer including the
+// transactions paying the highest miner tip first, breaking ties by the sender
+// address. The resulting blocks only depend on the set of pending transactions,
+// which makes it suitable for building reproducible blocks, e.g. in tests.
+func NewDeterministicOrderer() TransactionOrderer {
+	return &heapOrderer{less: byPriceAndSender}
+}
+
+// senderCapOrderer wraps a transaction orderer, limiting the number of
+// transactions of each account included in a block.
+type senderCapOrderer struct {
+	orderer TransactionOrderer
+	limit   int
+}
+
+// NewSenderCapOrderer wraps the given orderer, including at most limit
+// transactions from each sender into a block.
+func NewSenderCapOrderer(orderer TransactionOrderer, limit int) TransactionOrderer {
+	return &senderCapOrderer{orderer: orderer, limit: limit}
+}
+
+// Order implements TransactionOrderer.
+func (o *senderCapOrderer) Order(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) TransactionSet {
+	for from, accTxs := range txs {
+		if len(accTxs) > o.limit {
+			txs[from] = accTxs[:o.limit]
+		}
+	}
+	return o.orderer.Order(signer, txs, baseFee)
+}
+
+// Less implements TransactionOrderer.
+func (o *senderCapOrderer) Less(a, b TransactionSet) bool {
+	return o.orderer.Less(a, b)
+}
+
+// NewTransactionOrderer creates one of the built-in transaction orderers by
+// name. A positive sender cap limits the number of transactions included from
+// each account.
+func NewTransactionOrderer(name string, senderCap int) (TransactionOrderer, error) {
+	var orderer TransactionOrderer
+	switch name {
+	case "", OrderingPrice:
+		orderer = NewPriceOrderer()
+	case OrderingFIFO:
+		orderer = NewFIFOOrderer()
+	case OrderingDeterministic:
+		orderer = NewDeterministicOrderer()
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering %q", name)
+	}
+	if senderCap > 0 {
+		orderer = NewSenderCapOrderer(orderer, senderCap)
+	}
+	return orderer, nil
+}
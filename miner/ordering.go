@@ -54,41 +54,71 @@ func newTxWithMinerFee(tx *txpool.LazyTransaction, from common.Address, baseFee
 	}, nil
 }
 
-// txByPriceAndTime implements both the sort and the heap interface, making it useful
+// txHeap implements both the sort and the heap interface, making it useful
 // for all at once sorting as well as individually adding and removing elements.
-type txByPriceAndTime []*txWithMinerFee
-
-func (s txByPriceAndTime) Len() int { return len(s) }
-func (s txByPriceAndTime) Less(i, j int) bool {
-	// If the prices are equal, use the time the transaction was first seen for
-	// deterministic sorting
-	cmp := s[i].fees.Cmp(s[j].fees)
-	if cmp == 0 {
-		return s[i].tx.Time.Before(s[j].tx.Time)
-	}
-	return cmp > 0
+// The order of the elements is defined by the less function.
+type txHeap struct {
+	items []*txWithMinerFee
+	less  func(a, b *txWithMinerFee) bool
 }
-func (s txByPriceAndTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-func (s *txByPriceAndTime) Push(x interface{}) {
-	*s = append(*s, x.(*txWithMinerFee))
+func (s *txHeap) Len() int           { return len(s.items) }
+func (s *txHeap) Less(i, j int) bool { return s.less(s.items[i], s.items[j]) }
+func (s *txHeap) Swap(i, j int)      { s.items[i], s.items[j] = s.items[j], s.items[i] }
+
+func (s *txHeap) Push(x interface{}) {
+	s.items = append(s.items, x.(*txWithMinerFee))
 }
 
-func (s *txByPriceAndTime) Pop() interface{} {
-	old := *s
+func (s *txHeap) Pop() interface{} {
+	old := s.items
 	n := len(old)
 	x := old[n-1]
 	old[n-1] = nil
-	*s = old[0 : n-1]
+	s.items = old[0 : n-1]
 	return x
 }
 
+// byPriceAndTime orders the transactions by their effective miner tip. If the
+// tips are equal, the time the transactions were first seen is used.
+func byPriceAndTime(a, b *txWithMinerFee) bool {
+	cmp := a.fees.Cmp(b.fees)
+	if cmp == 0 {
+		return a.tx.Time.Before(b.tx.Time)
+	}
+	return cmp > 0
+}
+
+// byTime orders the transactions by the time they were first seen. If the times
+// are equal, the sender address is used.
+func byTime(a, b *txWithMinerFee) bool {
+	if !a.tx.Time.Equal(b.tx.Time) {
+		return a.tx.Time.Before(b.tx.Time)
+	}
+	return a.from.Cmp(b.from) < 0
+}
+
+// byPriceAndSender orders the transactions by their effective miner tip. If the
+// tips are equal, the sender address is used. Contrary to the other orderings,
+// it only depends on the content of the transactions, not on when the local node
+// received them.
+func byPriceAndSender(a, b *txWithMinerFee) bool {
+	cmp := a.fees.Cmp(b.fees)
+	if cmp == 0 {
+		return a.from.Cmp(b.from) < 0
+	}
+	return cmp > 0
+}
+
 // transactionsByPriceAndNonce represents a set of transactions that can return
 // transactions in a profit-maximizing sorted order, while supporting removing
 // entire batches of transactions for non-executable accounts.
+//
+// The order of the account heads can be customized, the default being by price
+// and arrival time.
 type transactionsByPriceAndNonce struct {
 	txs     map[common.Address][]*txpool.LazyTransaction // Per account nonce-sorted list of transactions
-	heads   txByPriceAndTime                             // Next transaction for each unique account (price heap)
+	heads   *txHeap                                      // Next transaction for each unique account
 	signer  types.Signer                                 // Signer for the set of transactions
 	baseFee *uint256.Int                                 // Current base fee
 }
@@ -99,23 +129,32 @@ type transactionsByPriceAndNonce struct {
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
 func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int) *transactionsByPriceAndNonce {
+	return newOrderedTransactions(signer, txs, baseFee, byPriceAndTime)
+}
+
+// newOrderedTransactions creates a transaction set that retrieves the account
+// heads in the order defined by the less function, in a nonce-honouring way.
+func newOrderedTransactions(signer types.Signer, txs map[common.Address][]*txpool.LazyTransaction, baseFee *big.Int, less func(a, b *txWithMinerFee) bool) *transactionsByPriceAndNonce {
 	// Convert the basefee from header format to uint256 format
 	var baseFeeUint *uint256.Int
 	if baseFee != nil {
 		baseFeeUint = uint256.MustFromBig(baseFee)
 	}
-	// Initialize a price and received time based heap with the head transactions
-	heads := make(txByPriceAndTime, 0, len(txs))
+	// Initialize a heap with the head transactions
+	heads := &txHeap{
+		items: make([]*txWithMinerFee, 0, len(txs)),
+		less:  less,
+	}
 	for from, accTxs := range txs {
 		wrapped, err := newTxWithMinerFee(accTxs[0], from, baseFeeUint)
 		if err != nil {
 			delete(txs, from)
 			continue
 		}
-		heads = append(heads, wrapped)
+		heads.items = append(heads.items, wrapped)
 		txs[from] = accTxs[1:]
 	}
-	heap.Init(&heads)
+	heap.Init(heads)
 
 	// Assemble and return the transaction set
 	return &transactionsByPriceAndNonce{
@@ -128,39 +167,44 @@ func newTransactionsByPriceAndNonce(signer types.Signer, txs map[common.Address]
 
 // Peek returns the next transaction by price.
 func (t *transactionsByPriceAndNonce) Peek() (*txpool.LazyTransaction, *uint256.Int) {
-	if len(t.heads) == 0 {
+	if t.heads.Len() == 0 {
 		return nil, nil
 	}
-	return t.heads[0].tx, t.heads[0].fees
+	return t.heads.items[0].tx, t.heads.items[0].fees
+}
+
+// head returns the next transaction along with its sender and miner tip.
+func (t *transactionsByPriceAndNonce) head() *txWithMinerFee {
+	return t.heads.items[0]
 }
 
 // Shift replaces the current best head with the next one from the same account.
 func (t *transactionsByPriceAndNonce) Shift() {
-	acc := t.heads[0].from
+	acc := t.heads.items[0].from
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
 		if wrapped, err := newTxWithMinerFee(txs[0], acc, t.baseFee); err == nil {
-			t.heads[0], t.txs[acc] = wrapped, txs[1:]
-			heap.Fix(&t.heads, 0)
+			t.heads.items[0], t.txs[acc] = wrapped, txs[1:]
+			heap.Fix(t.heads, 0)
 			return
 		}
 	}
-	heap.Pop(&t.heads)
+	heap.Pop(t.heads)
 }
 
 // Pop removes the best transaction, *not* replacing it with the next one from
 // the same account. This should be used when a transaction cannot be executed
 // and hence all subsequent ones should be discarded from the same account.
 func (t *transactionsByPriceAndNonce) Pop() {
-	heap.Pop(&t.heads)
+	heap.Pop(t.heads)
 }
 
 // Empty returns if the price heap is empty. It can be used to check it simpler
 // than calling peek and checking for nil return.
 func (t *transactionsByPriceAndNonce) Empty() bool {
-	return len(t.heads) == 0
+	return t.heads.Len() == 0
 }
 
 // Clear removes the entire content of the heap.
 func (t *transactionsByPriceAndNonce) Clear() {
-	t.heads, t.txs = nil, nil
+	t.heads.items, t.txs = nil, nil
 }
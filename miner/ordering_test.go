@@ -194,3 +194,114 @@ func TestTransactionTimeSort(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the built-in transaction orderers retrieve the transactions in
+// their expected order, while honouring the nonces of each account.
+func TestTransactionOrderers(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := types.HomesteadSigner{}
+
+	// Every account has two transactions, the accounts paying more having sent
+	// their transactions later. The last account pays the same as the first one.
+	prices := []int64{1, 2, 1}
+	groups := func(reverseTime bool) map[common.Address][]*txpool.LazyTransaction {
+		groups := map[common.Address][]*txpool.LazyTransaction{}
+		for i, key := range keys {
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			for nonce := uint64(0); nonce < 2; nonce++ {
+				tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(100), 100, big.NewInt(prices[i]), nil), signer, key)
+				seen := int64(i*2) + int64(nonce)
+				if reverseTime {
+					seen = -seen
+				}
+				tx.SetTime(time.Unix(seen, 0))
+
+				groups[addr] = append(groups[addr], &txpool.LazyTransaction{
+					Hash:      tx.Hash(),
+					Tx:        tx,
+					Time:      tx.Time(),
+					GasFeeCap: uint256.MustFromBig(tx.GasFeeCap()),
+					GasTipCap: uint256.MustFromBig(tx.GasTipCap()),
+					Gas:       tx.Gas(),
+				})
+			}
+		}
+		return groups
+	}
+	order := func(orderer TransactionOrderer, reverseTime bool) []*types.Transaction {
+		var (
+			txs   []*types.Transaction
+			txset = orderer.Order(signer, groups(reverseTime), nil)
+		)
+		for tx, _ := txset.Peek(); tx != nil; tx, _ = txset.Peek() {
+			txs = append(txs, tx.Tx)
+			txset.Shift()
+		}
+		return txs
+	}
+	// The FIFO orderer ignores the prices
+	txs := order(NewFIFOOrderer(), false)
+	if len(txs) != 6 {
+		t.Fatalf("fifo: expected 6 transactions, found %d", len(txs))
+	}
+	for i := 1; i < len(txs); i++ {
+		if txs[i-1].Time().After(txs[i].Time()) {
+			t.Errorf("fifo: invalid received time ordering at tx #%d", i)
+		}
+	}
+	// Separate sets, e.g. the plain and blob transactions, are interleaved by
+	// the same ordering
+	split := func(orderer TransactionOrderer) (TransactionSet, TransactionSet) {
+		var (
+			all   = groups(false)
+			cheap = crypto.PubkeyToAddress(keys[0].PublicKey)
+			pricy = crypto.PubkeyToAddress(keys[1].PublicKey)
+		)
+		return orderer.Order(signer, map[common.Address][]*txpool.LazyTransaction{cheap: all[cheap]}, nil),
+			orderer.Order(signer, map[common.Address][]*txpool.LazyTransaction{pricy: all[pricy]}, nil)
+	}
+	if cheap, pricy := split(NewFIFOOrderer()); !NewFIFOOrderer().Less(cheap, pricy) {
+		t.Error("fifo: earlier transaction not preferred across sets")
+	}
+	if cheap, pricy := split(NewPriceOrderer()); !NewPriceOrderer().Less(pricy, cheap) {
+		t.Error("price: higher tip not preferred across sets")
+	}
+	// The deterministic orderer doesn't depend on the arrival times
+	var (
+		first  = order(NewDeterministicOrderer(), false)
+		second = order(NewDeterministicOrderer(), true)
+	)
+	if len(first) != 6 || len(second) != 6 {
+		t.Fatalf("deterministic: expected 6 transactions, found %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].Hash() != second[i].Hash() {
+			t.Fatalf("deterministic: tx #%d mismatch: %x != %x", i, first[i].Hash(), second[i].Hash())
+		}
+		if i > 0 && first[i-1].GasPrice().Cmp(first[i].GasPrice()) < 0 {
+			t.Errorf("deterministic: invalid gasprice ordering at tx #%d", i)
+		}
+	}
+	// The sender cap limits the transactions of each account
+	capped, err := NewTransactionOrderer(OrderingPrice, 1)
+	if err != nil {
+		t.Fatalf("failed to create orderer: %v", err)
+	}
+	txs = order(capped, false)
+	if len(txs) != len(keys) {
+		t.Fatalf("capped: expected %d transactions, found %d", len(keys), len(txs))
+	}
+	for i, tx := range txs {
+		if tx.Nonce() != 0 {
+			t.Errorf("capped: tx #%d has nonce %d", i, tx.Nonce())
+		}
+	}
+	if _, err := NewTransactionOrderer("random", 0); err == nil {
+		t.Error("expected error for unknown ordering")
+	}
+}
@@ -142,7 +142,10 @@ func (b *testWorkerBackend) TxPool() *txpool.TxPool       { return b.txPool }
 func newTestWorker(t *testing.T, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, blocks int) (*Miner, *testWorkerBackend) {
 	backend := newTestWorkerBackend(t, chainConfig, engine, db, blocks)
 	backend.txPool.Add(pendingTxs, true, true)
-	w := New(backend, testConfig, engine)
+	w, err := New(backend, testConfig, engine)
+	if err != nil {
+		t.Fatalf("failed to create miner: %v", err)
+	}
 	return w, backend
 }
 
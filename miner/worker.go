@@ -73,6 +73,7 @@ type newPayloadResult struct {
 	sidecars []*types.BlobTxSidecar // collected blobs of blob transactions
 	stateDB  *state.StateDB         // StateDB after executing the transactions
 	receipts []*types.Receipt       // Receipts collected during construction
+	requests [][]byte               // Consensus layer requests collected during block construction
 }
 
 // generateParams wraps various settings for generating sealing task.
@@ -106,6 +107,23 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 		}
 	}
 	body := types.Body{Transactions: work.txs, Withdrawals: params.withdrawals}
+
+	// Collect consensus-layer requests if Prague is enabled.
+	var requests [][]byte
+	if miner.chainConfig.IsPrague(work.header.Number, work.header.Time) {
+		var allLogs []*types.Log
+		for _, r := range work.receipts {
+			allLogs = append(allLogs, r.Logs...)
+		}
+		context := core.NewEVMBlockContext(work.header, miner.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, work.state, miner.chainConfig, vm.Config{})
+		requests, err = core.ProcessRequests(allLogs, vmenv, work.state)
+		if err != nil {
+			return &newPayloadResult{err: err}
+		}
+		reqHash := types.CalcRequestsHash(requests)
+		work.header.RequestsHash = &reqHash
+	}
 	block, err := miner.engine.FinalizeAndAssemble(miner.chain, work.header, work.state, &body, work.receipts)
 	if err != nil {
 		return &newPayloadResult{err: err}
@@ -116,6 +134,7 @@ func (miner *Miner) generateWork(params *generateParams) *newPayloadResult {
 		sidecars: work.sidecars,
 		stateDB:  work.state,
 		receipts: work.receipts,
+		requests: requests,
 	}
 }
 
@@ -200,6 +219,11 @@ func (miner *Miner) prepareWork(genParams *generateParams) (*environment, error)
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, miner.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
+	if miner.chainConfig.IsPrague(header.Number, header.Time) {
+		context := core.NewEVMBlockContext(header, miner.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, miner.chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(header.ParentHash, vmenv, env.state)
+	}
 	return env, nil
 }
 
@@ -273,7 +297,7 @@ func (miner *Miner) applyTransaction(env *environment, tx *types.Transaction) (*
 	return receipt, err
 }
 
-func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *transactionsByPriceAndNonce, interrupt *atomic.Int32) error {
+func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs TransactionSet, interrupt *atomic.Int32) error {
 	gasLimit := env.header.GasLimit
 	if env.gasPool == nil {
 		env.gasPool = new(core.GasPool).AddGas(gasLimit)
@@ -300,10 +324,10 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		// Retrieve the next transaction and abort if all done.
 		var (
 			ltx *txpool.LazyTransaction
-			txs *transactionsByPriceAndNonce
+			txs TransactionSet
 		)
-		pltx, ptip := plainTxs.Peek()
-		bltx, btip := blobTxs.Peek()
+		pltx, _ := plainTxs.Peek()
+		bltx, _ := blobTxs.Peek()
 
 		switch {
 		case pltx == nil:
@@ -311,7 +335,7 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 		case bltx == nil:
 			txs, ltx = plainTxs, pltx
 		default:
-			if ptip.Lt(btip) {
+			if miner.orderer.Less(blobTxs, plainTxs) {
 				txs, ltx = blobTxs, bltx
 			} else {
 				txs, ltx = plainTxs, pltx
@@ -374,13 +398,16 @@ func (miner *Miner) commitTransactions(env *environment, plainTxs, blobTxs *tran
 }
 
 // fillTransactions retrieves the pending transactions from the txpool and fills them
-// into the given sealing block. The transaction selection and ordering strategy can
-// be customized with the plugin in the future.
+// into the given sealing block, in the order defined by the configured orderer.
 func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment) error {
 	miner.confMu.RLock()
 	tip := miner.config.GasPrice
 	miner.confMu.RUnlock()
 
+	// Commit the profitable bundles ahead of the regular transactions
+	if err := miner.commitBundles(env, tip, interrupt); err != nil {
+		return err
+	}
 	// Retrieve the pending transactions pre-filtered by the 1559/4844 dynamic fees
 	filter := txpool.PendingFilter{
 		MinTip: uint256.MustFromBig(tip),
@@ -413,16 +440,16 @@ func (miner *Miner) fillTransactions(interrupt *atomic.Int32, env *environment)
 	}
 	// Fill the block with all available pending transactions.
 	if len(localPlainTxs) > 0 || len(localBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, localPlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, localBlobTxs, env.header.BaseFee)
+		plainTxs := miner.orderer.Order(env.signer, localPlainTxs, env.header.BaseFee)
+		blobTxs := miner.orderer.Order(env.signer, localBlobTxs, env.header.BaseFee)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
 		}
 	}
 	if len(remotePlainTxs) > 0 || len(remoteBlobTxs) > 0 {
-		plainTxs := newTransactionsByPriceAndNonce(env.signer, remotePlainTxs, env.header.BaseFee)
-		blobTxs := newTransactionsByPriceAndNonce(env.signer, remoteBlobTxs, env.header.BaseFee)
+		plainTxs := miner.orderer.Order(env.signer, remotePlainTxs, env.header.BaseFee)
+		blobTxs := miner.orderer.Order(env.signer, remoteBlobTxs, env.header.BaseFee)
 
 		if err := miner.commitTransactions(env, plainTxs, blobTxs, interrupt); err != nil {
 			return err
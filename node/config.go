@@ -204,6 +204,9 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCRateLimit configures the per-client rate limiting of the RPC endpoints.
+	RPCRateLimit RateLimitConfig `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
@@ -211,6 +214,11 @@ type Config struct {
 	EnablePersonal bool `toml:"-"`
 
 	DBEngine string `toml:",omitempty"`
+
+	// EraDirectory is the directory of era1 archives serving the chain history
+	// pruned from the ancient store. The archives are only read, so the directory
+	// can be shared between nodes.
+	EraDirectory string `toml:",omitempty"`
 }
 
 // IPCEndpoint resolves an IPC endpoint based on a configured value, taking into
@@ -17,6 +17,7 @@
 package node
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
@@ -75,6 +76,10 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 	case time.Until(claims.IssuedAt.Time) > jwtExpiryTimeout:
 		http.Error(out, "future token", http.StatusUnauthorized)
 	default:
+		// Expose the subject to the handlers, e.g. to key rate limits
+		if claims.Subject != "" {
+			r = r.WithContext(context.WithValue(r.Context(), jwtSubjectKey{}, claims.Subject))
+		}
 		handler.next.ServeHTTP(out, r)
 	}
 }
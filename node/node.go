@@ -32,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
@@ -402,9 +403,11 @@ func (n *Node) startRPC() error {
 		openAPIs, allAPIs = n.getAPIs()
 	)
 
+	limiter := newRateLimiter(n.config.RPCRateLimit, mclock.System{})
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		rateLimiter:            limiter,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -453,6 +456,9 @@ func (n *Node) startRPC() error {
 			batchResponseSizeLimit: engineAPIBatchResponseSizeLimit,
 			httpBodyLimit:          engineAPIBodyLimit,
 		}
+		if n.config.RPCRateLimit.Auth {
+			sharedConfig.rateLimiter = limiter
+		}
 		err := server.enableRPC(allAPIs, httpConfig{
 			CorsAllowedOrigins: DefaultAuthCors,
 			Vhosts:             n.config.AuthVirtualHosts,
@@ -759,6 +765,7 @@ func (n *Node) OpenDatabaseWithFreezer(name string, cache, handles int, ancient
 			Type:              n.config.DBEngine,
 			Directory:         n.ResolvePath(name),
 			AncientsDirectory: n.ResolveAncient(name, ancient),
+			EraDirectory:      n.config.EraDirectory,
 			Namespace:         namespace,
 			Cache:             cache,
 			Handles:           handles,
@@ -0,0 +1,207 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// rateLimitErrorCode is the JSON-RPC error code returned to clients exceeding
+// their rate limit.
+const rateLimitErrorCode = -32005
+
+// rateLimitSweepInterval is the interval of dropping the idle clients from the
+// rate limiter.
+const rateLimitSweepInterval = time.Minute
+
+var (
+	rateLimitedMeter = metrics.NewRegisteredMeter("rpc/ratelimit/rejected", nil)
+	rateClientsGauge = metrics.NewRegisteredGauge("rpc/ratelimit/clients", nil)
+)
+
+// RateLimitConfig configures the per-client rate limiting of the RPC endpoints.
+// Every client owns a token bucket, refilled at a constant rate up to its burst
+// size. Serving a method call costs tokens, depending on the method.
+type RateLimitConfig struct {
+	// Rate is the number of tokens replenished per second for every client.
+	// Rate limiting is disabled if zero.
+	Rate float64 `toml:",omitempty"`
+
+	// Burst is the maximum number of tokens a client can accumulate. It defaults
+	// to the rate if unset.
+	Burst float64 `toml:",omitempty"`
+
+	// MethodCosts maps method names to the number of tokens a call to them costs.
+	// Names ending with '*' match all the methods with the given prefix, e.g.
+	// "debug_trace*". Methods not listed cost one token.
+	MethodCosts map[string]float64 `toml:",omitempty"`
+
+	// Auth enables the rate limiting of the authenticated endpoints too.
+	Auth bool `toml:",omitempty"`
+}
+
+// rateLimitError is returned to clients exceeding their rate limit.
+type rateLimitError struct {
+	method string
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s", e.method)
+}
+
+func (e *rateLimitError) ErrorCode() int { return rateLimitErrorCode }
+
+// tokenBucket is the rate limiting state of a single client.
+type tokenBucket struct {
+	tokens float64
+	last   mclock.AbsTime
+}
+
+// rateLimiter enforces the configured limits on the method calls, keying the
+// clients by their JWT subject if authenticated, or their IP address otherwise.
+type rateLimiter struct {
+	rate     float64
+	burst    float64
+	costs    map[string]float64
+	prefixes map[string]float64
+	clock    mclock.Clock
+
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep mclock.AbsTime
+}
+
+// newRateLimiter creates a rate limiter for the given configuration, or returns
+// nil if rate limiting is disabled.
+func newRateLimiter(config RateLimitConfig, clock mclock.Clock) *rateLimiter {
+	if config.Rate <= 0 {
+		return nil
+	}
+	l := &rateLimiter{
+		rate:     config.Rate,
+		burst:    config.Burst,
+		costs:    make(map[string]float64),
+		prefixes: make(map[string]float64),
+		clock:    clock,
+		buckets:  make(map[string]*tokenBucket),
+	}
+	if l.burst <= 0 {
+		l.burst = l.rate
+	}
+	for method, cost := range config.MethodCosts {
+		if prefix, ok := strings.CutSuffix(method, "*"); ok {
+			l.prefixes[prefix] = cost
+		} else {
+			l.costs[method] = cost
+		}
+	}
+	l.lastSweep = clock.Now()
+	return l
+}
+
+// cost returns the number of tokens a call to the given method costs. Exact
+// matches take precedence over prefixes, and longer prefixes over shorter ones.
+func (l *rateLimiter) cost(method string) float64 {
+	if cost, ok := l.costs[method]; ok {
+		return cost
+	}
+	var (
+		cost    = 1.0
+		longest = -1
+	)
+	for prefix, c := range l.prefixes {
+		if len(prefix) > longest && strings.HasPrefix(method, prefix) {
+			cost, longest = c, len(prefix)
+		}
+	}
+	return cost
+}
+
+// allow charges the client the cost of the method call, returning an error if
+// the client doesn't have enough tokens.
+func (l *rateLimiter) allow(ctx context.Context, method string) error {
+	var (
+		key  = rateLimitKey(ctx)
+		cost = l.cost(method)
+	)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	l.sweep(now)
+
+	bucket := l.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+		rateClientsGauge.Update(int64(len(l.buckets)))
+	}
+	bucket.tokens += time.Duration(now-bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+
+	if bucket.tokens < cost {
+		// The method name is client supplied, only meter the total not to
+		// register an unbounded number of metrics.
+		rateLimitedMeter.Mark(1)
+		return &rateLimitError{method: method}
+	}
+	bucket.tokens -= cost
+	return nil
+}
+
+// sweep drops the clients whose buckets have been refilled, as they are in the
+// same state as unknown clients.
+func (l *rateLimiter) sweep(now mclock.AbsTime) {
+	if time.Duration(now-l.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if bucket.tokens+time.Duration(now-bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	rateClientsGauge.Update(int64(len(l.buckets)))
+}
+
+type jwtSubjectKey struct{}
+
+// rateLimitKey returns the identifier of the client making the call: its JWT
+// subject if it has been authenticated with one, its IP address otherwise.
+func rateLimitKey(ctx context.Context) string {
+	if subject, ok := ctx.Value(jwtSubjectKey{}).(string); ok && subject != "" {
+		return "jwt:" + subject
+	}
+	addr := rpc.PeerInfoFromContext(ctx).RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return "ip:" + host
+	}
+	return "ip:" + addr
+}
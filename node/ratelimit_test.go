@@ -0,0 +1,173 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterCosts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		clock   = new(mclock.Simulated)
+		limiter = newRateLimiter(RateLimitConfig{
+			Rate:  1,
+			Burst: 10,
+			MethodCosts: map[string]float64{
+				"eth_getLogs":    5,
+				"debug_*":        2,
+				"debug_trace*":   8,
+				"debug_traceTx2": 3,
+			},
+		}, clock)
+	)
+	for method, want := range map[string]float64{
+		"eth_call":           1,
+		"eth_getLogs":        5,
+		"debug_getBadBlocks": 2,
+		"debug_traceBlock":   8,
+		"debug_traceTx2":     3,
+	} {
+		if have := limiter.cost(method); have != want {
+			t.Errorf("method %s: cost mismatch: have %v, want %v", method, have, want)
+		}
+	}
+	var (
+		first  = context.Background()
+		second = context.WithValue(context.Background(), jwtSubjectKey{}, "second")
+	)
+	// Exhaust the bucket of the first client
+	for i := 0; i < 2; i++ {
+		if err := limiter.allow(first, "eth_getLogs"); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+	}
+	err := limiter.allow(first, "eth_call")
+	var rerr *rateLimitError
+	if !errors.As(err, &rerr) || rerr.ErrorCode() != rateLimitErrorCode {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	// Other clients are not affected
+	if err := limiter.allow(second, "eth_getLogs"); err != nil {
+		t.Fatalf("unexpected error for other client: %v", err)
+	}
+	// Tokens are replenished over time
+	clock.Run(3 * time.Second)
+	for i := 0; i < 3; i++ {
+		if err := limiter.allow(first, "eth_call"); err != nil {
+			t.Fatalf("call %d after refill: unexpected error: %v", i, err)
+		}
+	}
+	if err := limiter.allow(first, "eth_call"); err == nil {
+		t.Fatal("expected rate limit error after spending the refill")
+	}
+	// Idle clients are eventually dropped
+	clock.Run(rateLimitSweepInterval)
+	limiter.allow(first, "eth_call")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("idle clients not dropped: %d buckets", len(limiter.buckets))
+	}
+}
+
+// Tests that the rate limits are enforced over HTTP and WebSocket, and that
+// authenticated clients are keyed by their JWT subject.
+func TestRPCRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		secret  = []byte("secret")
+		limiter = newRateLimiter(RateLimitConfig{
+			Rate:        1e-9,
+			Burst:       3,
+			MethodCosts: map[string]float64{"test_greet": 2},
+		}, mclock.System{})
+	)
+	cfg := rpcEndpointConfig{rateLimiter: limiter}
+	srv := createAndStartServer(t, &httpConfig{rpcEndpointConfig: cfg}, false, nil, nil)
+	defer srv.stop()
+
+	// Plain HTTP clients are keyed by IP address
+	url := fmt.Sprintf("http://%v", srv.listenAddr())
+	for i, want := range []int{0, rateLimitErrorCode} {
+		resp := rpcRequest(t, url, "test_greet")
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		var res struct {
+			Error *struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &res); err != nil {
+			t.Fatalf("call %d: invalid response %s: %v", i, body, err)
+		}
+		switch {
+		case want == 0 && res.Error != nil:
+			t.Fatalf("call %d: unexpected error code %d", i, res.Error.Code)
+		case want != 0 && (res.Error == nil || res.Error.Code != want):
+			t.Fatalf("call %d: expected error code %d, got %s", i, want, body)
+		}
+	}
+	// Authenticated WebSocket clients are keyed by JWT subject
+	wsSrv := newHTTPServer(testlog.Logger(t, log.LvlDebug), rpc.DefaultHTTPTimeouts)
+	assert.NoError(t, wsSrv.enableWS(apis(), wsConfig{
+		Origins:           []string{"*"},
+		rpcEndpointConfig: rpcEndpointConfig{jwtSecret: secret, rateLimiter: limiter},
+	}))
+	assert.NoError(t, wsSrv.setListenAddr("localhost", 0))
+	assert.NoError(t, wsSrv.start())
+	defer wsSrv.stop()
+
+	dial := func(subject string) *rpc.Client {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaim{"iat": time.Now().Unix(), "sub": subject}).SignedString(secret)
+		client, err := rpc.DialOptions(context.Background(), fmt.Sprintf("ws://%v", wsSrv.listenAddr()), rpc.WithHeader("Authorization", "Bearer "+token))
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		return client
+	}
+	alice, bob := dial("alice"), dial("bob")
+	defer alice.Close()
+	defer bob.Close()
+
+	var res string
+	if err := alice.Call(&res, "test_greet"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := alice.Call(&res, "test_greet")
+	var rerr rpc.Error
+	if !errors.As(err, &rerr) || rerr.ErrorCode() != rateLimitErrorCode {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if err := bob.Call(&res, "test_greet"); err != nil {
+		t.Fatalf("unexpected error for other subject: %v", err)
+	}
+}
@@ -58,6 +58,7 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	rateLimiter            *rateLimiter // optional per-client rate limiter
 }
 
 type rpcHandler struct {
@@ -309,6 +310,9 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if config.rateLimiter != nil {
+		srv.SetCallFilter(config.rateLimiter.allow)
+	}
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
@@ -344,6 +348,9 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
+	if config.rateLimiter != nil {
+		srv.SetCallFilter(config.rateLimiter.allow)
+	}
 	if err := RegisterApis(apis, config.Modules, srv); err != nil {
 		return err
 	}
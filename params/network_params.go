@@ -20,18 +20,6 @@ package params
 // aren't necessarily consensus related.
 
 const (
-	// BloomBitsBlocks is the number of blocks a single bloom bit section vector
-	// contains on the server side.
-	BloomBitsBlocks uint64 = 4096
-
-	// BloomBitsBlocksClient is the number of blocks a single bloom bit section vector
-	// contains on the light client side
-	BloomBitsBlocksClient uint64 = 32768
-
-	// BloomConfirms is the number of confirmation blocks before a bloom section is
-	// considered probably final and its rotated bits are calculated.
-	BloomConfirms = 256
-
 	// CHTFrequency is the block frequency for creating CHTs
 	CHTFrequency = 32768
 
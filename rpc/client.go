@@ -88,6 +88,8 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	connCtx              context.Context
+	callFilter           CallFilter
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -116,10 +118,10 @@ type clientConn struct {
 }
 
 func (c *Client) newClientConn(conn ServerCodec) *clientConn {
-	ctx := context.Background()
-	ctx = context.WithValue(ctx, clientContextKey{}, c)
+	ctx := context.WithValue(c.connCtx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler.callFilter = c.callFilter
 	return &clientConn{conn, handler}
 }
 
@@ -247,6 +249,8 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		connCtx:              cfg.connCtx,
+		callFilter:           cfg.callFilter,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
@@ -263,6 +267,9 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 	if c.idgen == nil {
 		c.idgen = randomIDGenerator()
 	}
+	if c.connCtx == nil {
+		c.connCtx = context.Background()
+	}
 
 	// Launch the main loop.
 	if !isHTTP {
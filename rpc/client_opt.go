@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -41,6 +42,10 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+
+	// Server-side connection options
+	connCtx    context.Context
+	callFilter CallFilter
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -73,6 +73,18 @@ const (
 	errMsgBatchTooLarge    = "batch too large"
 )
 
+// Errors returned by the server for batches exceeding its limits. Clients can
+// recognize them by comparing the code and message of the received errors.
+var (
+	// ErrBatchTooLarge is returned if a batch contains more requests than the
+	// server's limit.
+	ErrBatchTooLarge Error = &invalidRequestError{errMsgBatchTooLarge}
+
+	// ErrResponseTooLarge is returned for the requests of a batch left unanswered
+	// because the response exceeded the server's size limit.
+	ErrResponseTooLarge Error = &internalServerError{errcodeResponseTooLarge, errMsgResponseTooLarge}
+)
+
 type methodNotFoundError struct{ method string }
 
 func (e *methodNotFoundError) ErrorCode() int { return -32601 }
@@ -62,6 +62,7 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	callFilter           CallFilter // optional filter consulted before serving calls
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -229,8 +230,7 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 			if resp != nil && h.batchResponseMaxSize != 0 {
 				responseBytes += len(resp.Result)
 				if responseBytes > h.batchResponseMaxSize {
-					err := &internalServerError{errcodeResponseTooLarge, errMsgResponseTooLarge}
-					callBuffer.respondWithError(cp.ctx, h.conn, err)
+					callBuffer.respondWithError(cp.ctx, h.conn, ErrResponseTooLarge)
 					break
 				}
 			}
@@ -248,7 +248,7 @@ func (h *handler) handleBatch(msgs []*jsonrpcMessage) {
 }
 
 func (h *handler) respondWithBatchTooLarge(cp *callProc, batch []*jsonrpcMessage) {
-	resp := errorMessage(&invalidRequestError{errMsgBatchTooLarge})
+	resp := errorMessage(ErrBatchTooLarge)
 	// Find the first call and add its "id" field to the error.
 	// This is the best we can do, given that the protocol doesn't have a way
 	// of reporting an error for the entire batch.
@@ -491,6 +491,11 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 
 // handleCall processes method calls.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if h.callFilter != nil && !msg.isUnsubscribe() {
+		if err := h.callFilter(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
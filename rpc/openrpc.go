@@ -0,0 +1,306 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// discoverMethod is the method name defined by the OpenRPC specification to
+	// retrieve the service discovery document.
+	discoverMethod = "rpc.discover"
+
+	// openRPCVersion is the version of the OpenRPC specification the discovery
+	// document conforms to.
+	openRPCVersion = "1.2.6"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+
+	// schemaNameRegexp matches the characters not allowed in component names.
+	schemaNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9.\-_]`)
+)
+
+// OpenRPCDocument is an OpenRPC service description document.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo contains the metadata of the API.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCComponents holds the reusable schemas referenced by the methods.
+type OpenRPCComponents struct {
+	Schemas map[string]*JSONSchema `json:"schemas"`
+}
+
+// OpenRPCMethod describes a single RPC method.
+type OpenRPCMethod struct {
+	Name           string                      `json:"name"`
+	Params         []*OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor   `json:"result,omitempty"`
+	ParamStructure string                      `json:"paramStructure"`
+
+	// Subscriptions lists the subscriptions available through a subscribe
+	// method, along with their parameters. This is an extension of the
+	// specification, which has no notion of subscriptions.
+	Subscriptions []*OpenRPCMethod `json:"x-subscriptions,omitempty"`
+}
+
+// OpenRPCContentDescriptor describes a method parameter or result.
+type OpenRPCContentDescriptor struct {
+	Name     string      `json:"name"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *JSONSchema `json:"schema"`
+}
+
+// JSONSchema is the subset of JSON Schema used to describe the RPC values.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// Discover returns the OpenRPC document describing the methods and subscriptions
+// exposed by the server. It is available as rpc.discover, the method name defined
+// by the OpenRPC specification.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	s.server.services.mu.Lock()
+	defer s.server.services.mu.Unlock()
+
+	gen := &schemaGenerator{
+		schemas: make(map[string]*JSONSchema),
+		names:   make(map[reflect.Type]string),
+	}
+	doc := &OpenRPCDocument{
+		OpenRPC: openRPCVersion,
+		Info:    OpenRPCInfo{Title: "JSON-RPC API", Version: "1.0"},
+	}
+	for name, svc := range s.server.services.services {
+		for method, cb := range svc.callbacks {
+			doc.Methods = append(doc.Methods, gen.method(name+serviceMethodSeparator+method, cb))
+		}
+		if len(svc.subscriptions) == 0 {
+			continue
+		}
+		subscribe := &OpenRPCMethod{
+			Name:           name + serviceMethodSeparator + subscribeMethodSuffix[1:],
+			Result:         &OpenRPCContentDescriptor{Name: "subscriptionID", Schema: &JSONSchema{Type: "string"}},
+			ParamStructure: "by-position",
+		}
+		var names []string
+		for sub, cb := range svc.subscriptions {
+			names = append(names, sub)
+			subscribe.Subscriptions = append(subscribe.Subscriptions, gen.method(sub, cb))
+		}
+		slices.Sort(names)
+		slices.SortFunc(subscribe.Subscriptions, func(a, b *OpenRPCMethod) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		subscribe.Params = []*OpenRPCContentDescriptor{{
+			Name:     "subscription",
+			Required: true,
+			Schema:   &JSONSchema{Type: "string", Enum: names},
+		}}
+		unsubscribe := &OpenRPCMethod{
+			Name:           name + serviceMethodSeparator + unsubscribeMethodSuffix[1:],
+			Params:         []*OpenRPCContentDescriptor{{Name: "subscriptionID", Required: true, Schema: &JSONSchema{Type: "string"}}},
+			Result:         &OpenRPCContentDescriptor{Name: "result", Schema: &JSONSchema{Type: "boolean"}},
+			ParamStructure: "by-position",
+		}
+		doc.Methods = append(doc.Methods, subscribe, unsubscribe)
+	}
+	slices.SortFunc(doc.Methods, func(a, b *OpenRPCMethod) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	doc.Components.Schemas = gen.schemas
+	return doc
+}
+
+// schemaGenerator creates the JSON schemas of Go types. Named struct types are
+// stored as reusable components, allowing recursive types.
+type schemaGenerator struct {
+	schemas map[string]*JSONSchema  // Component schemas by name
+	names   map[reflect.Type]string // Component names of the named struct types
+}
+
+// method describes the given callback.
+func (g *schemaGenerator) method(name string, cb *callback) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name:           name,
+		Params:         make([]*OpenRPCContentDescriptor, len(cb.argTypes)),
+		ParamStructure: "by-position",
+	}
+	// Trailing pointer arguments are optional, see parsePositionalArguments
+	required := len(cb.argTypes)
+	for required > 0 && cb.argTypes[required-1].Kind() == reflect.Ptr {
+		required--
+	}
+	for i, typ := range cb.argTypes {
+		m.Params[i] = &OpenRPCContentDescriptor{
+			Name:     fmt.Sprintf("arg%d", i),
+			Required: i < required,
+			Schema:   g.schema(typ),
+		}
+	}
+	if !cb.isSubscribe {
+		fntype := cb.fn.Type()
+		for i := 0; i < fntype.NumOut(); i++ {
+			if i != cb.errPos {
+				m.Result = &OpenRPCContentDescriptor{Name: "result", Schema: g.schema(fntype.Out(i))}
+			}
+		}
+	}
+	return m
+}
+
+// schema returns the JSON schema of the values of the given type, as encoded by
+// the encoding/json package.
+func (g *schemaGenerator) schema(typ reflect.Type) *JSONSchema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	// Custom encodings can't be inspected, only text ones are known to be strings
+	switch {
+	case typ == bigIntType:
+		return &JSONSchema{Type: "integer"}
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		if !typ.Implements(jsonMarshalerType) && !reflect.PointerTo(typ).Implements(jsonMarshalerType) {
+			return &JSONSchema{Title: typ.String(), Type: "string"}
+		}
+		return &JSONSchema{Title: typ.String()}
+	case typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType):
+		return &JSONSchema{Title: typ.String()}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string"} // base64 encoded
+		}
+		return &JSONSchema{Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Array:
+		return &JSONSchema{Type: "array", Items: g.schema(typ.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: g.schema(typ.Elem())}
+	case reflect.Struct:
+		return g.structSchema(typ)
+	default:
+		return &JSONSchema{} // interfaces and anything else, any value
+	}
+}
+
+// structSchema returns the schema of a struct type. Named types are stored as
+// components and referenced.
+func (g *schemaGenerator) structSchema(typ reflect.Type) *JSONSchema {
+	var name string
+	if typ.Name() != "" {
+		if name, ok := g.names[typ]; ok {
+			return &JSONSchema{Ref: "#/components/schemas/" + name}
+		}
+		name = g.componentName(typ)
+	}
+	schema := &JSONSchema{Title: typ.String(), Type: "object", Properties: make(map[string]*JSONSchema)}
+	if name != "" {
+		// Register before descending to terminate on recursive types
+		g.schemas[name] = schema
+		g.names[typ] = name
+	}
+	g.addFields(schema, typ)
+	if name != "" {
+		return &JSONSchema{Ref: "#/components/schemas/" + name}
+	}
+	return schema
+}
+
+// componentName returns an unused component name for the named type. Types are
+// named after their package and name, e.g. types.Header, falling back to the
+// full package path if another type of another package has the same name.
+func (g *schemaGenerator) componentName(typ reflect.Type) string {
+	name := schemaNameRegexp.ReplaceAllString(typ.String(), "_")
+	if _, ok := g.schemas[name]; !ok {
+		return name
+	}
+	qualified := schemaNameRegexp.ReplaceAllString(typ.PkgPath()+"."+typ.Name(), "_")
+	name = qualified
+	for i := 2; ; i++ {
+		if _, ok := g.schemas[name]; !ok {
+			return name
+		}
+		// Types declared in functions share their package path and name
+		name = fmt.Sprintf("%s_%d", qualified, i)
+	}
+}
+
+// addFields adds the JSON encoded fields of the struct type to the schema,
+// flattening the embedded structs.
+func (g *schemaGenerator) addFields(schema *JSONSchema, typ reflect.Type) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(schema, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+	}
+}
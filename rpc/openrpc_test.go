@@ -0,0 +1,122 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc.discover"); err != nil {
+		t.Fatal("can't call rpc.discover:", err)
+	}
+	if doc.OpenRPC != openRPCVersion {
+		t.Fatalf("wrong openrpc version %q", doc.OpenRPC)
+	}
+	methods := make(map[string]*OpenRPCMethod)
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+	}
+	for _, name := range []string{"rpc_modules", "rpc_discover", "test_echo", "nftest_subscribe", "nftest_unsubscribe"} {
+		if methods[name] == nil {
+			t.Fatalf("method %s missing from document", name)
+		}
+	}
+	if !slices.IsSortedFunc(doc.Methods, func(a, b *OpenRPCMethod) int { return strings.Compare(a.Name, b.Name) }) {
+		t.Fatal("methods are not sorted")
+	}
+	// Check the parameters and result of a plain method, the trailing pointer
+	// argument being optional.
+	echo := methods["test_echo"]
+	if len(echo.Params) != 3 {
+		t.Fatalf("wrong number of test_echo params: %d", len(echo.Params))
+	}
+	wantTypes := []string{"string", "integer", ""}
+	wantRequired := []bool{true, true, false}
+	for i, p := range echo.Params {
+		if p.Schema.Type != wantTypes[i] || p.Required != wantRequired[i] {
+			t.Errorf("test_echo param %d: have type %q required %t, want %q %t", i, p.Schema.Type, p.Required, wantTypes[i], wantRequired[i])
+		}
+	}
+	if ref := echo.Params[2].Schema.Ref; ref != "#/components/schemas/rpc.echoArgs" {
+		t.Errorf("wrong test_echo args reference %q", ref)
+	}
+	if echo.Result == nil || echo.Result.Schema.Ref != "#/components/schemas/rpc.echoResult" {
+		t.Fatalf("wrong test_echo result %+v", echo.Result)
+	}
+	result := doc.Components.Schemas["rpc.echoResult"]
+	if result == nil {
+		t.Fatal("missing echoResult schema")
+	}
+	wantProps := map[string]*JSONSchema{
+		"String": {Type: "string"},
+		"Int":    {Type: "integer"},
+		"Args":   {Ref: "#/components/schemas/rpc.echoArgs"},
+	}
+	if !reflect.DeepEqual(result.Properties, wantProps) {
+		t.Errorf("wrong echoResult properties: %+v", result.Properties)
+	}
+	// Methods returning only an error have no result.
+	if methods["test_returnError"].Result != nil {
+		t.Error("test_returnError has a result")
+	}
+	// Check the subscriptions are listed.
+	sub := methods["nftest_subscribe"]
+	if want := []string{"hangSubscription", "someSubscription"}; !slices.Equal(sub.Params[0].Schema.Enum, want) {
+		t.Errorf("wrong subscription names: have %v, want %v", sub.Params[0].Schema.Enum, want)
+	}
+	if len(sub.Subscriptions) != 2 || sub.Subscriptions[1].Name != "someSubscription" || len(sub.Subscriptions[1].Params) != 2 {
+		t.Errorf("wrong subscription descriptions %+v", sub.Subscriptions)
+	}
+}
+
+func TestDiscoverNameCollision(t *testing.T) {
+	gen := &schemaGenerator{
+		schemas: make(map[string]*JSONSchema),
+		names:   make(map[reflect.Type]string),
+	}
+	global := gen.schema(reflect.TypeOf(echoArgs{}))
+
+	// A different type with the same package and name must not replace the
+	// schema of the first one.
+	type echoArgs struct {
+		Other int
+	}
+	local := gen.schema(reflect.TypeOf(echoArgs{}))
+	if global.Ref == local.Ref {
+		t.Fatalf("colliding types share the schema %q", global.Ref)
+	}
+	if len(gen.schemas) != 2 {
+		t.Fatalf("wrong number of schemas: have %d, want 2", len(gen.schemas))
+	}
+	if again := gen.schema(reflect.TypeOf(echoArgs{})); again.Ref != local.Ref {
+		t.Fatalf("wrong reference of known type: have %q, want %q", again.Ref, local.Ref)
+	}
+	if props := gen.schemas[strings.TrimPrefix(local.Ref, "#/components/schemas/")].Properties; props["Other"] == nil {
+		t.Fatalf("wrong schema of the local type: %+v", props)
+	}
+}
@@ -52,8 +52,14 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	callFilter         CallFilter
 }
 
+// CallFilter is consulted before serving a method call. If it returns an error, the
+// call is not executed and the error is sent to the client instead. The context is
+// the one of the call, carrying the connection information.
+type CallFilter func(ctx context.Context, method string) error
+
 // NewServer creates a new server instance with no registered handlers.
 func NewServer() *Server {
 	server := &Server{
@@ -80,6 +86,15 @@ func (s *Server) SetBatchLimits(itemLimit, maxResponseSize int) {
 	s.batchResponseLimit = maxResponseSize
 }
 
+// SetCallFilter sets a function consulted before serving every method call, e.g. to
+// apply rate limits.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetCallFilter(filter CallFilter) {
+	s.callFilter = filter
+}
+
 // SetHTTPBodyLimit sets the size limit for HTTP requests.
 //
 // This method should be called before processing any requests via ServeHTTP.
@@ -101,6 +116,11 @@ func (s *Server) RegisterName(name string, receiver interface{}) error {
 //
 // Note that codec options are no longer supported.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
+	s.serveCodec(context.Background(), codec)
+}
+
+// serveCodec is like ServeCodec, deriving the contexts of the calls from connCtx.
+func (s *Server) serveCodec(connCtx context.Context, codec ServerCodec) {
 	defer codec.close()
 
 	if !s.trackCodec(codec) {
@@ -112,6 +132,8 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		connCtx:            connCtx,
+		callFilter:         s.callFilter,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -147,6 +169,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.callFilter = s.callFilter
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
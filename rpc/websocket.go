@@ -61,7 +61,7 @@ func (s *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			return
 		}
 		codec := newWebsocketCodec(conn, r.Host, r.Header, wsDefaultReadLimit)
-		s.ServeCodec(codec, 0)
+		s.serveCodec(context.WithoutCancel(r.Context()), codec)
 	})
 }
 
@@ -257,6 +257,32 @@ func (db *Database) Dereference(root common.Hash) error {
 	return nil
 }
 
+// TrackFlushes installs a callback notified of the hash of every trie node right
+// before it is flushed to disk, or stops the tracking if the callback is nil.
+//
+// It's only supported by hash-based database and will return an error for others.
+func (db *Database) TrackFlushes(onFlush func(hash common.Hash)) error {
+	hdb, ok := db.backend.(*hashdb.Database)
+	if !ok {
+		return errors.New("not supported")
+	}
+	hdb.TrackFlushes(onFlush)
+	return nil
+}
+
+// DeleteNodes adds the deletion of the given trie nodes to the batch, skipping
+// the ones retained by the keep callback, and writes the batch to disk. No trie
+// node can be flushed between the check and the deletion.
+//
+// It's only supported by hash-based database and will return an error for others.
+func (db *Database) DeleteNodes(batch ethdb.Batch, hashes []common.Hash, keep func(hash common.Hash) bool) (int, error) {
+	hdb, ok := db.backend.(*hashdb.Database)
+	if !ok {
+		return 0, errors.New("not supported")
+	}
+	return hdb.DeleteNodes(batch, hashes, keep)
+}
+
 // Recover rollbacks the database to a specified historical point. The state is
 // supported as the rollback destination only if it's canonical state and the
 // corresponding trie histories are existent. It's only supported by path-based
@@ -276,6 +302,16 @@ func (db *Database) Recover(target common.Hash) error {
 	return pdb.Recover(target, loader)
 }
 
+// HistoricReader constructs a reader for accessing the historical state with
+// the specified root. It's only supported by path-based database.
+func (db *Database) HistoricReader(root common.Hash) (*pathdb.HistoricalStateReader, error) {
+	pdb, ok := db.backend.(*pathdb.Database)
+	if !ok {
+		return nil, errors.New("not supported")
+	}
+	return pdb.HistoricReader(root)
+}
+
 // Recoverable returns the indicator if the specified state is enabled to be
 // recovered. It's only supported by path-based database and will return an
 // error for others.
@@ -102,6 +102,8 @@ type Database struct {
 	dirtiesSize  common.StorageSize // Storage size of the dirty node cache (exc. metadata)
 	childrenSize common.StorageSize // Storage size of the external children tracking
 
+	onFlush func(hash common.Hash) // Callback notified of the nodes flushed to disk
+
 	lock sync.RWMutex
 }
 
@@ -350,6 +352,9 @@ func (db *Database) Cap(limit common.StorageSize) error {
 	for size > limit && oldest != (common.Hash{}) {
 		// Fetch the oldest referenced node and push into the batch
 		node := db.dirties[oldest]
+		if db.onFlush != nil {
+			db.onFlush(oldest)
+		}
 		rawdb.WriteLegacyTrieNode(batch, oldest, node.node)
 
 		// If we exceeded the ideal batch size, commit and reset
@@ -473,6 +478,9 @@ func (db *Database) commit(hash common.Hash, batch ethdb.Batch, uncacher *cleane
 		return err
 	}
 	// If we've reached an optimal batch size, commit and start over
+	if db.onFlush != nil {
+		db.onFlush(hash)
+	}
 	rawdb.WriteLegacyTrieNode(batch, hash, node.node)
 	if batch.ValueSize() >= ethdb.IdealBatchSize {
 		if err := batch.Write(); err != nil {
@@ -540,6 +548,39 @@ func (c *cleaner) Delete(key []byte) error {
 	panic("not implemented")
 }
 
+// TrackFlushes installs a callback notified of the hash of every trie node right
+// before it is flushed to disk, replacing the previous one. A nil callback stops
+// the tracking. The callback is invoked with the database lock held, so it must
+// not call back into the database.
+func (db *Database) TrackFlushes(onFlush func(hash common.Hash)) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	db.onFlush = onFlush
+}
+
+// DeleteNodes adds the deletion of the given trie nodes to the batch, skipping
+// the ones retained by the keep callback, and writes the batch to disk. The
+// callback is evaluated with the database lock held, so no node can be flushed
+// between the check and the deletion. The number of deleted nodes is returned.
+func (db *Database) DeleteNodes(batch ethdb.Batch, hashes []common.Hash, keep func(hash common.Hash) bool) (int, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	var deleted int
+	for _, hash := range hashes {
+		if keep(hash) {
+			continue
+		}
+		rawdb.DeleteLegacyTrieNode(batch, hash)
+		deleted++
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
 // Initialized returns an indicator if state data is already initialized
 // in hash-based scheme by checking the presence of genesis state.
 func (db *Database) Initialized(genesisRoot common.Hash) bool {
@@ -90,10 +90,11 @@ type layer interface {
 
 // Config contains the settings for database.
 type Config struct {
-	StateHistory   uint64 // Number of recent blocks to maintain state history for
-	CleanCacheSize int    // Maximum memory allowance (in bytes) for caching clean nodes
-	DirtyCacheSize int    // Maximum memory allowance (in bytes) for caching dirty nodes
-	ReadOnly       bool   // Flag whether the database is opened in read only mode.
+	StateHistory        uint64 // Number of recent blocks to maintain state history for
+	EnableStateIndexing bool   // Flag whether the state histories are indexed for historical state access
+	CleanCacheSize      int    // Maximum memory allowance (in bytes) for caching clean nodes
+	DirtyCacheSize      int    // Maximum memory allowance (in bytes) for caching dirty nodes
+	ReadOnly            bool   // Flag whether the database is opened in read only mode.
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -140,6 +141,7 @@ type Database struct {
 	diskdb     ethdb.Database               // Persistent storage for matured trie nodes
 	tree       *layerTree                   // The group for all known layers
 	freezer    ethdb.ResettableAncientStore // Freezer for storing trie histories, nil possible in tests
+	indexer    *historyIndexer              // Background indexer of state histories, nil if indexing is disabled
 	lock       sync.RWMutex                 // Lock to prevent mutations from happening at the same time
 }
 
@@ -168,6 +170,17 @@ func New(diskdb ethdb.Database, config *Config, isVerkle bool) *Database {
 	if err := db.repairHistory(); err != nil {
 		log.Crit("Failed to repair pathdb", "err", err)
 	}
+	// Start indexing the state histories if it's enabled, or drop the leftover
+	// index otherwise as it won't be maintained.
+	if db.freezer != nil && !db.readOnly {
+		if config.EnableStateIndexing {
+			db.indexer = newHistoryIndexer(diskdb, db.freezer)
+		} else if rawdb.ReadStateHistoryIndexMetadata(diskdb) != nil {
+			if err := purgeHistoryIndex(diskdb); err != nil {
+				log.Crit("Failed to purge state history index", "err", err)
+			}
+		}
+	}
 	// Disable database in case node is still in the initial state sync stage.
 	if rawdb.ReadSnapSyncStatusFlag(diskdb) == rawdb.StateSyncRunning && !db.readOnly {
 		if err := db.Disable(); err != nil {
@@ -212,10 +225,21 @@ func (db *Database) repairHistory() error {
 			}
 			log.Info("Truncated extraneous state history")
 		}
+		if !db.readOnly && rawdb.ReadStateHistoryIndexMetadata(db.diskdb) != nil {
+			if err := purgeHistoryIndex(db.diskdb); err != nil {
+				log.Crit("Failed to purge state history index", "err", err)
+			}
+		}
 		return nil
 	}
 	// Truncate the extra state histories above in freezer in case it's not
 	// aligned with the disk layer. It might happen after a unclean shutdown.
+	// The index of these histories must be removed beforehand.
+	if !db.readOnly {
+		if err := unindexHead(db.diskdb, db.freezer, id); err != nil {
+			log.Crit("Failed to unindex extra state histories", "err", err)
+		}
+	}
 	pruned, err := truncateFromHead(db.diskdb, db.freezer, id)
 	if err != nil {
 		log.Crit("Failed to truncate extra state histories", "err", err)
@@ -327,9 +351,20 @@ func (db *Database) Enable(root common.Hash) error {
 	// mappings can be huge and might take a while to clear
 	// them, just leave them in disk and wait for overwriting.
 	if db.freezer != nil {
+		// Stop the indexer before resetting the freezer, and purge
+		// the index of the dropped histories.
+		if db.indexer != nil {
+			db.indexer.close()
+		}
 		if err := db.freezer.Reset(); err != nil {
 			return err
 		}
+		if db.indexer != nil {
+			if err := purgeHistoryIndex(db.diskdb); err != nil {
+				return err
+			}
+			db.indexer = newHistoryIndexer(db.diskdb, db.freezer)
+		}
 	}
 	// Re-construct a new disk layer backed by persistent state
 	// with **empty clean cache and node buffer**.
@@ -381,7 +416,7 @@ func (db *Database) Recover(root common.Hash, loader triestate.TrieLoader) error
 		db.tree.reset(dl)
 	}
 	rawdb.DeleteTrieJournal(db.diskdb)
-	_, err := truncateFromHead(db.diskdb, db.freezer, dl.stateID())
+	_, err := db.truncateHead(dl.stateID())
 	if err != nil {
 		return err
 	}
@@ -438,6 +473,11 @@ func (db *Database) Close() error {
 	if db.freezer == nil {
 		return nil
 	}
+	// Terminate the background indexing before closing the freezer.
+	if db.indexer != nil {
+		db.indexer.close()
+		db.indexer = nil
+	}
 	return db.freezer.Close()
 }
 
@@ -483,6 +523,24 @@ func (db *Database) SetBufferSize(size int) error {
 	return db.tree.bottom().setBufferSize(db.bufferSize)
 }
 
+// truncateHead removes the state histories above the given id, along with
+// their index if indexing is enabled.
+func (db *Database) truncateHead(nhead uint64) (int, error) {
+	if db.indexer != nil {
+		return db.indexer.truncateHead(nhead)
+	}
+	return truncateFromHead(db.diskdb, db.freezer, nhead)
+}
+
+// truncateTail removes the state histories not above the given id, along with
+// their index if indexing is enabled.
+func (db *Database) truncateTail(ntail uint64) (int, error) {
+	if db.indexer != nil {
+		return db.indexer.truncateTail(ntail)
+	}
+	return truncateFromTail(db.diskdb, db.freezer, ntail)
+}
+
 // modifyAllowed returns the indicator if mutation is allowed. This function
 // assumes the db.lock is already held.
 func (db *Database) modifyAllowed() error {
@@ -0,0 +1,302 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// The state history index maps each piece of state (an account or a storage
+// slot) to the list of state history ids in which the state was mutated.
+// The list is split into fixed-capacity blocks stored in the key-value store:
+//
+//   - a sealed block is keyed by the largest history id it contains
+//   - the unsealed block at the end of the list (if any) is keyed by MaxUint64
+//
+// This layout allows to locate the first history mutating the state after a
+// given id with a single iterator seek: the first block whose key is not
+// less than the (id+1) is guaranteed to contain it, if any exists.
+const (
+	// indexBlockCapacity is the maximum number of history ids that can be
+	// grouped in a single index block.
+	indexBlockCapacity = 2048
+
+	// indexBlockOpen is the key suffix of the unsealed index block.
+	indexBlockOpen = math.MaxUint64
+)
+
+// stateIdent represents the identifier of a piece of state, either an
+// account or a storage slot.
+type stateIdent struct {
+	account     bool
+	address     common.Address
+	storageHash common.Hash // the hash of the raw storage slot key
+}
+
+// newAccountIdent constructs the identifier of an account.
+func newAccountIdent(address common.Address) stateIdent {
+	return stateIdent{account: true, address: address}
+}
+
+// newStorageIdent constructs the identifier of a storage slot.
+func newStorageIdent(address common.Address, storageHash common.Hash) stateIdent {
+	return stateIdent{address: address, storageHash: storageHash}
+}
+
+// String returns the human-readable representation of the identifier.
+func (ident stateIdent) String() string {
+	if ident.account {
+		return ident.address.Hex()
+	}
+	return ident.address.Hex() + ident.storageHash.Hex()
+}
+
+// encodeIDs packs the list of history ids into a byte stream.
+func encodeIDs(ids []uint64) []byte {
+	buf := make([]byte, 8*len(ids))
+	for i, id := range ids {
+		binary.BigEndian.PutUint64(buf[8*i:], id)
+	}
+	return buf
+}
+
+// decodeIDs unpacks the list of history ids from the byte stream.
+func decodeIDs(blob []byte) ([]uint64, error) {
+	if len(blob)%8 != 0 {
+		return nil, fmt.Errorf("invalid index block, size: %d", len(blob))
+	}
+	ids := make([]uint64, len(blob)/8)
+	for i := range ids {
+		ids[i] = binary.BigEndian.Uint64(blob[8*i:])
+	}
+	return ids, nil
+}
+
+// readIndexBlock retrieves the index block with the given key suffix.
+func readIndexBlock(db ethdb.KeyValueReader, ident stateIdent, last uint64) ([]uint64, error) {
+	var blob []byte
+	if ident.account {
+		blob = rawdb.ReadAccountHistoryIndex(db, ident.address, last)
+	} else {
+		blob = rawdb.ReadStorageHistoryIndex(db, ident.address, ident.storageHash, last)
+	}
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	return decodeIDs(blob)
+}
+
+// writeIndexBlock stores the index block with the given key suffix.
+func writeIndexBlock(db ethdb.KeyValueWriter, ident stateIdent, last uint64, ids []uint64) {
+	if ident.account {
+		rawdb.WriteAccountHistoryIndex(db, ident.address, last, encodeIDs(ids))
+	} else {
+		rawdb.WriteStorageHistoryIndex(db, ident.address, ident.storageHash, last, encodeIDs(ids))
+	}
+}
+
+// deleteIndexBlock removes the index block with the given key suffix.
+func deleteIndexBlock(db ethdb.KeyValueWriter, ident stateIdent, last uint64) {
+	if ident.account {
+		rawdb.DeleteAccountHistoryIndex(db, ident.address, last)
+	} else {
+		rawdb.DeleteStorageHistoryIndex(db, ident.address, ident.storageHash, last)
+	}
+}
+
+// iterateIndexBlocks opens an iterator over the index blocks of the state
+// whose key suffix is not less than the given one.
+func iterateIndexBlocks(db ethdb.Iteratee, ident stateIdent, start uint64) ethdb.Iterator {
+	if ident.account {
+		return rawdb.IterateAccountHistoryIndex(db, ident.address, start)
+	}
+	return rawdb.IterateStorageHistoryIndex(db, ident.address, ident.storageHash, start)
+}
+
+// indexBlock is a decoded index block along with its key suffix.
+type indexBlock struct {
+	last uint64
+	ids  []uint64
+}
+
+// readGreaterThan returns the id of the first state history after the given
+// id in which the specified state was mutated. The flag reports whether such
+// a history exists.
+func readGreaterThan(db ethdb.Iteratee, ident stateIdent, id uint64) (uint64, bool, error) {
+	if id == math.MaxUint64 {
+		return 0, false, nil
+	}
+	it := iterateIndexBlocks(db, ident, id+1)
+	defer it.Release()
+
+	if !it.Next() {
+		return 0, false, it.Error()
+	}
+	ids, err := decodeIDs(it.Value())
+	if err != nil {
+		return 0, false, err
+	}
+	pos := sort.Search(len(ids), func(i int) bool { return ids[i] > id })
+	if pos == len(ids) {
+		return 0, false, nil
+	}
+	return ids[pos], true, nil
+}
+
+// appendIndex appends the given history ids at the end of the state index.
+// The ids must be sorted in ascending order and all of them must be larger
+// than the ones already indexed.
+func appendIndex(reader ethdb.KeyValueReader, writer ethdb.KeyValueWriter, ident stateIdent, ids []uint64) error {
+	open, err := readIndexBlock(reader, ident, indexBlockOpen)
+	if err != nil {
+		return err
+	}
+	if len(open) > 0 && open[len(open)-1] >= ids[0] {
+		return fmt.Errorf("unordered history index, state: %s, last: %d, next: %d", ident, open[len(open)-1], ids[0])
+	}
+	all := append(open, ids...)
+	for len(all) >= indexBlockCapacity {
+		writeIndexBlock(writer, ident, all[indexBlockCapacity-1], all[:indexBlockCapacity])
+		all = all[indexBlockCapacity:]
+	}
+	if len(all) == 0 {
+		if len(open) > 0 {
+			deleteIndexBlock(writer, ident, indexBlockOpen)
+		}
+		return nil
+	}
+	writeIndexBlock(writer, ident, indexBlockOpen, all)
+	return nil
+}
+
+// removeIndex removes the given history ids from the state index. The ids
+// must be sorted in ascending order. The ids which are not indexed are silently
+// ignored, so that an interrupted removal can be safely retried.
+func removeIndex(db ethdb.Iteratee, writer ethdb.KeyValueWriter, ident stateIdent, ids []uint64) error {
+	// Load all the index blocks covering the given id range
+	var blocks []indexBlock
+	it := iterateIndexBlocks(db, ident, ids[0])
+	for it.Next() {
+		key := it.Key()
+		list, err := decodeIDs(it.Value())
+		if err != nil {
+			it.Release()
+			return err
+		}
+		block := indexBlock{last: binary.BigEndian.Uint64(key[len(key)-8:]), ids: list}
+		blocks = append(blocks, block)
+		if block.last >= ids[len(ids)-1] {
+			break
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	// Remove the ids from the loaded blocks and rewrite them
+	for _, block := range blocks {
+		remain := slices.DeleteFunc(block.ids, func(id uint64) bool {
+			_, found := slices.BinarySearch(ids, id)
+			return found
+		})
+		if block.last == indexBlockOpen {
+			if len(remain) == 0 {
+				deleteIndexBlock(writer, ident, indexBlockOpen)
+			} else {
+				writeIndexBlock(writer, ident, indexBlockOpen, remain)
+			}
+			continue
+		}
+		deleteIndexBlock(writer, ident, block.last)
+		if len(remain) > 0 {
+			writeIndexBlock(writer, ident, remain[len(remain)-1], remain)
+		}
+	}
+	return nil
+}
+
+// batchIndexer is responsible for (un)indexing a batch of state histories,
+// aggregating the mutated states so that each state index is only touched
+// once per batch.
+type batchIndexer struct {
+	accounts map[common.Address][]uint64
+	storages map[common.Address]map[common.Hash][]uint64
+	counter  int
+	delete   bool
+}
+
+// newBatchIndexer constructs the batch indexer with the supplied mode.
+func newBatchIndexer(delete bool) *batchIndexer {
+	return &batchIndexer{
+		accounts: make(map[common.Address][]uint64),
+		storages: make(map[common.Address]map[common.Hash][]uint64),
+		delete:   delete,
+	}
+}
+
+// process accumulates the mutated states of the given history. The histories
+// must be fed in ascending order of their ids.
+func (b *batchIndexer) process(h *history, id uint64) {
+	for _, address := range h.accountList {
+		b.accounts[address] = append(b.accounts[address], id)
+		b.counter++
+
+		for _, slotHash := range h.storageList[address] {
+			if _, ok := b.storages[address]; !ok {
+				b.storages[address] = make(map[common.Hash][]uint64)
+			}
+			b.storages[address][slotHash] = append(b.storages[address][slotHash], id)
+			b.counter++
+		}
+	}
+}
+
+// empty returns an indicator if no state has been accumulated.
+func (b *batchIndexer) empty() bool {
+	return b.counter == 0
+}
+
+// commit writes the accumulated index mutations into the provided batch.
+func (b *batchIndexer) commit(db ethdb.KeyValueStore, batch ethdb.Batch) error {
+	apply := func(ident stateIdent, ids []uint64) error {
+		if b.delete {
+			return removeIndex(db, batch, ident, ids)
+		}
+		return appendIndex(db, batch, ident, ids)
+	}
+	for address, ids := range b.accounts {
+		if err := apply(newAccountIdent(address), ids); err != nil {
+			return err
+		}
+	}
+	for address, slots := range b.storages {
+		for slotHash, ids := range slots {
+			if err := apply(newStorageIdent(address, slotHash), ids); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/testrand"
+)
+
+func checkGreaterThan(t *testing.T, db ethdb.Iteratee, ident stateIdent, ids []uint64, max uint64) {
+	t.Helper()
+
+	for x := uint64(0); x <= max; x++ {
+		var (
+			want  uint64
+			exist bool
+		)
+		for _, id := range ids {
+			if id > x {
+				want, exist = id, true
+				break
+			}
+		}
+		got, found, err := readGreaterThan(db, ident, x)
+		if err != nil {
+			t.Fatalf("Failed to read index, %v", err)
+		}
+		if found != exist || got != want {
+			t.Fatalf("Unexpected index lookup for %d, want: (%d, %t), got: (%d, %t)", x, want, exist, got, found)
+		}
+	}
+}
+
+func TestHistoryIndexAppendRemove(t *testing.T) {
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		ident = newStorageIdent(testrand.Address(), testrand.Hash())
+		ids   []uint64
+	)
+	// Append the ids in multiple rounds, spanning over several index blocks
+	for id, round := uint64(1), 0; round < 10; round++ {
+		var batch []uint64
+		for i := 0; i < 3*indexBlockCapacity/5; i++ {
+			batch = append(batch, id)
+			id += 2
+		}
+		if err := appendIndex(db, db, ident, batch); err != nil {
+			t.Fatalf("Failed to append index, %v", err)
+		}
+		ids = append(ids, batch...)
+	}
+	checkGreaterThan(t, db, ident, ids, ids[len(ids)-1]+1)
+
+	// Appending the unordered ids should be rejected
+	if err := appendIndex(db, db, ident, []uint64{ids[len(ids)-1]}); err == nil {
+		t.Fatal("Unordered index append is not rejected")
+	}
+	// Remove the ids from the tail, the first block is emptied
+	if err := removeIndex(db, db, ident, ids[:indexBlockCapacity+10]); err != nil {
+		t.Fatalf("Failed to remove index, %v", err)
+	}
+	ids = ids[indexBlockCapacity+10:]
+	checkGreaterThan(t, db, ident, ids, ids[len(ids)-1]+1)
+
+	// Remove the ids from the head, the sealed block becomes the last one
+	if err := removeIndex(db, db, ident, ids[len(ids)-2*indexBlockCapacity:]); err != nil {
+		t.Fatalf("Failed to remove index, %v", err)
+	}
+	ids = ids[:len(ids)-2*indexBlockCapacity]
+	checkGreaterThan(t, db, ident, ids, ids[len(ids)-1]+1)
+
+	// Continue appending after the removal
+	next := []uint64{ids[len(ids)-1] + 1, ids[len(ids)-1] + 2}
+	if err := appendIndex(db, db, ident, next); err != nil {
+		t.Fatalf("Failed to append index, %v", err)
+	}
+	ids = append(ids, next...)
+	checkGreaterThan(t, db, ident, ids, ids[len(ids)-1]+1)
+
+	// Remove everything, the index should be purged completely
+	if err := removeIndex(db, db, ident, ids); err != nil {
+		t.Fatalf("Failed to remove index, %v", err)
+	}
+	it := db.NewIterator(rawdb.StateHistoryStorageIndexPrefix, nil)
+	defer it.Release()
+	if it.Next() {
+		t.Fatalf("Unexpected index block left, %x", it.Key())
+	}
+}
+
+func TestHistoryIndexRange(t *testing.T) {
+	var (
+		hs         = makeHistories(10)
+		db         = rawdb.NewMemoryDatabase()
+		freezer, _ = rawdb.NewStateFreezer(t.TempDir(), false)
+	)
+	defer freezer.Close()
+
+	for i := 0; i < len(hs); i++ {
+		accountData, storageData, accountIndex, storageIndex := hs[i].encode()
+		rawdb.WriteStateHistory(freezer, uint64(i+1), hs[i].meta.encode(), accountIndex, storageIndex, accountData, storageData)
+		rawdb.WriteStateID(db, hs[i].meta.root, uint64(i+1))
+	}
+	indexer := newHistoryIndexer(db, freezer)
+	defer indexer.close()
+
+	waitIndexed(t, indexer, uint64(len(hs)))
+
+	check := func(from, to uint64, exist bool) {
+		t.Helper()
+		for id := from; id <= to; id++ {
+			h := hs[id-1]
+			for _, addr := range h.accountList {
+				got, found, err := readGreaterThan(db, newAccountIdent(addr), id-1)
+				if err != nil {
+					t.Fatalf("Failed to read index, %v", err)
+				}
+				if found != exist || (exist && got != id) {
+					t.Fatalf("Unexpected account index, want: (%d, %t), got: (%d, %t)", id, exist, got, found)
+				}
+				for _, slot := range h.storageList[addr] {
+					got, found, err := readGreaterThan(db, newStorageIdent(addr, slot), id-1)
+					if err != nil {
+						t.Fatalf("Failed to read index, %v", err)
+					}
+					if found != exist || (exist && got != id) {
+						t.Fatalf("Unexpected storage index, want: (%d, %t), got: (%d, %t)", id, exist, got, found)
+					}
+				}
+			}
+		}
+	}
+	check(1, 10, true)
+
+	if _, err := indexer.truncateHead(7); err != nil {
+		t.Fatalf("Failed to truncate head, %v", err)
+	}
+	check(1, 7, true)
+	check(8, 10, false)
+
+	if _, err := indexer.truncateTail(3); err != nil {
+		t.Fatalf("Failed to truncate tail, %v", err)
+	}
+	check(1, 3, false)
+	check(4, 7, true)
+
+	tail, last := indexer.progress()
+	if tail != 3 || last != 7 {
+		t.Fatalf("Unexpected index range, want: (3, 7), got: (%d, %d)", tail, last)
+	}
+}
+
+func waitIndexed(t *testing.T, indexer *historyIndexer, last uint64) {
+	t.Helper()
+
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		if _, indexed := indexer.progress(); indexed >= last {
+			return
+		}
+	}
+	t.Fatalf("State histories are not indexed in time")
+}
+
+func TestHistoricalStateReader(t *testing.T) {
+	// Redefine the diff layer depth allowance for faster testing.
+	maxDiffLayers = 4
+	defer func() {
+		maxDiffLayers = 128
+	}()
+
+	tester := newTester(t, 0, true)
+	defer tester.release()
+
+	bottom := tester.bottomIndex()
+	waitIndexed(t, tester.db.indexer, uint64(bottom+1))
+
+	var (
+		diskRoot     = tester.roots[bottom]
+		diskAccounts = tester.snapAccounts[diskRoot]
+		diskStorages = tester.snapStorages[diskRoot]
+	)
+	for i := 0; i < bottom; i++ {
+		root := tester.roots[i]
+		reader, err := tester.db.HistoricReader(root)
+		if err != nil {
+			t.Fatalf("Failed to open historical reader, %v", err)
+		}
+		if reader.Root() != diskRoot {
+			t.Fatalf("Unexpected reference root, want: %x, got: %x", diskRoot, reader.Root())
+		}
+		var (
+			accounts = tester.snapAccounts[root]
+			storages = tester.snapStorages[root]
+			hashes   = make(map[common.Hash]struct{})
+		)
+		for addrHash := range accounts {
+			hashes[addrHash] = struct{}{}
+		}
+		for addrHash := range diskAccounts {
+			hashes[addrHash] = struct{}{}
+		}
+		for addrHash := range hashes {
+			addr := tester.preimages[addrHash]
+			blob, found, err := reader.Account(addr)
+			if err != nil {
+				t.Fatalf("Failed to read account, %v", err)
+			}
+			if !found {
+				blob = diskAccounts[addrHash]
+			}
+			if !bytes.Equal(blob, accounts[addrHash]) {
+				t.Fatalf("Unexpected account %x at %d, want: %x, got: %x", addr, i, accounts[addrHash], blob)
+			}
+			slots := make(map[common.Hash]struct{})
+			for slot := range storages[addrHash] {
+				slots[slot] = struct{}{}
+			}
+			for slot := range diskStorages[addrHash] {
+				slots[slot] = struct{}{}
+			}
+			for slot := range slots {
+				blob, found, err := reader.Storage(addr, slot)
+				if err != nil {
+					t.Fatalf("Failed to read storage, %v", err)
+				}
+				if !found {
+					blob = diskStorages[addrHash][slot]
+				}
+				if !bytes.Equal(blob, storages[addrHash][slot]) {
+					t.Fatalf("Unexpected storage %x:%x at %d, want: %x, got: %x", addr, slot, i, storages[addrHash][slot], blob)
+				}
+			}
+		}
+	}
+	// The state above the disk layer is not historical
+	if _, err := tester.db.HistoricReader(tester.roots[len(tester.roots)-1]); err == nil {
+		t.Fatal("Historical reader is unexpectedly opened for the live state")
+	}
+	// The unknown state should be rejected
+	if _, err := tester.db.HistoricReader(crypto.Keccak256Hash(testrand.Bytes(32))); err == nil {
+		t.Fatal("Historical reader is unexpectedly opened for the unknown state")
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// stateIndexVersion is the version of the state history index structure.
+	stateIndexVersion = uint8(0)
+
+	// indexerBatchSize is the maximum number of state histories that can be
+	// (un)indexed in a single database batch.
+	indexerBatchSize = 1000
+)
+
+// indexMetadata describes the range of state histories that have been indexed.
+type indexMetadata struct {
+	Version uint8  // Version tag of the index structure
+	Tail    uint64 // The id of the last history which is not indexed at the tail
+	Last    uint64 // The id of the last indexed history
+}
+
+// loadIndexMetadata reads the metadata of the state history index, nil is
+// returned if it's not available or corrupted.
+func loadIndexMetadata(db ethdb.KeyValueReader) *indexMetadata {
+	blob := rawdb.ReadStateHistoryIndexMetadata(db)
+	if len(blob) == 0 {
+		return nil
+	}
+	var m indexMetadata
+	if err := rlp.DecodeBytes(blob, &m); err != nil {
+		log.Error("Failed to decode state history index metadata", "err", err)
+		return nil
+	}
+	return &m
+}
+
+// storeIndexMetadata writes the metadata of the state history index.
+func storeIndexMetadata(db ethdb.KeyValueWriter, m *indexMetadata) {
+	blob, err := rlp.EncodeToBytes(m)
+	if err != nil {
+		log.Crit("Failed to encode state history index metadata", "err", err)
+	}
+	rawdb.WriteStateHistoryIndexMetadata(db, blob)
+}
+
+// purgeHistoryIndex removes the entire state history index from the database.
+func purgeHistoryIndex(db ethdb.KeyValueStore) error {
+	var (
+		start = time.Now()
+		batch = db.NewBatch()
+	)
+	for _, prefix := range [][]byte{rawdb.StateHistoryAccountIndexPrefix, rawdb.StateHistoryStorageIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		for it.Next() {
+			batch.Delete(it.Key())
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	rawdb.DeleteStateHistoryIndexMetadata(batch)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Purged state history index", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// processHistories (un)indexes the state histories in range [from, to] and
+// stores the given index metadata atomically along with the index mutations.
+func processHistories(db ethdb.KeyValueStore, freezer ethdb.AncientReader, from, to uint64, delete bool, m *indexMetadata) error {
+	b := newBatchIndexer(delete)
+	for id := from; id <= to; id++ {
+		h, err := readHistory(freezer, id)
+		if err != nil {
+			return err
+		}
+		b.process(h, id)
+	}
+	batch := db.NewBatch()
+	if err := b.commit(db, batch); err != nil {
+		return err
+	}
+	storeIndexMetadata(batch, m)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if delete {
+		historyUnindexMeter.Mark(int64(to - from + 1))
+	} else {
+		historyIndexMeter.Mark(int64(to - from + 1))
+	}
+	return nil
+}
+
+// unindexHead removes the state histories above the given id from the index.
+// It must be invoked before truncating the histories from the freezer.
+func unindexHead(db ethdb.KeyValueStore, freezer ethdb.AncientReader, nhead uint64) error {
+	m := loadIndexMetadata(db)
+	if m == nil || m.Last <= nhead {
+		return nil
+	}
+	lower := max(nhead, m.Tail)
+	for m.Last > lower {
+		var (
+			to   = m.Last
+			from = lower + 1
+		)
+		if to-from+1 > indexerBatchSize {
+			from = to - indexerBatchSize + 1
+		}
+		m.Last = from - 1
+		if err := processHistories(db, freezer, from, to, true, m); err != nil {
+			return err
+		}
+	}
+	// The truncation target is even below the indexed tail, reset the
+	// index range to be empty.
+	if m.Last > nhead {
+		m.Tail, m.Last = nhead, nhead
+		storeIndexMetadata(db, m)
+	}
+	return nil
+}
+
+// unindexTail removes the state histories not above the given id from the
+// index. It must be invoked before truncating the histories from the freezer.
+func unindexTail(db ethdb.KeyValueStore, freezer ethdb.AncientReader, ntail uint64) error {
+	m := loadIndexMetadata(db)
+	if m == nil || m.Tail >= ntail {
+		return nil
+	}
+	upper := min(ntail, m.Last)
+	for m.Tail < upper {
+		var (
+			from = m.Tail + 1
+			to   = min(upper, m.Tail+indexerBatchSize)
+		)
+		m.Tail = to
+		if err := processHistories(db, freezer, from, to, true, m); err != nil {
+			return err
+		}
+	}
+	// The histories which are not yet indexed are truncated as well, move
+	// the indexing progress forward.
+	if m.Tail < ntail {
+		m.Tail, m.Last = ntail, ntail
+		storeIndexMetadata(db, m)
+	}
+	return nil
+}
+
+// historyIndexer is responsible for indexing the state histories in background,
+// building the mapping between each piece of state and the histories in which
+// it was mutated.
+type historyIndexer struct {
+	disk    ethdb.KeyValueStore
+	freezer ethdb.AncientStore
+	lock    sync.Mutex // Lock to serialize the index mutations and history truncations
+
+	trigger chan struct{}
+	closed  chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newHistoryIndexer constructs the history indexer and starts indexing the
+// state histories in background.
+func newHistoryIndexer(disk ethdb.KeyValueStore, freezer ethdb.AncientStore) *historyIndexer {
+	// Purge the stale index if it's not compatible with the stored histories.
+	if m := loadIndexMetadata(disk); m != nil {
+		head, err := freezer.Ancients()
+		if err != nil {
+			log.Crit("Failed to retrieve head of state history", "err", err)
+		}
+		if m.Version != stateIndexVersion || m.Last > head {
+			if err := purgeHistoryIndex(disk); err != nil {
+				log.Crit("Failed to purge state history index", "err", err)
+			}
+		}
+	} else if rawdb.ReadStateHistoryIndexMetadata(disk) != nil {
+		if err := purgeHistoryIndex(disk); err != nil {
+			log.Crit("Failed to purge state history index", "err", err)
+		}
+	}
+	indexer := &historyIndexer{
+		disk:    disk,
+		freezer: freezer,
+		trigger: make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	indexer.wg.Add(1)
+	go indexer.loop()
+	return indexer
+}
+
+// loop is the main event loop of the indexer, which indexes the newly written
+// state histories whenever it's notified.
+func (i *historyIndexer) loop() {
+	defer i.wg.Done()
+
+	for {
+		if err := i.run(); err != nil {
+			log.Error("Failed to index state histories", "err", err)
+		}
+		select {
+		case <-i.trigger:
+		case <-i.closed:
+			return
+		}
+	}
+}
+
+// run indexes all the unindexed state histories batch by batch, until the
+// indexer is closed.
+func (i *historyIndexer) run() error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		indexed uint64
+	)
+	for {
+		select {
+		case <-i.closed:
+			return nil
+		default:
+		}
+		n, last, done, err := i.index()
+		if err != nil {
+			return err
+		}
+		indexed += n
+		if done {
+			if indexed > 0 {
+				log.Debug("Indexed state histories", "count", indexed, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			}
+			return nil
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing state histories", "count", indexed, "last", last, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+}
+
+// index indexes the next batch of state histories. It returns the number of
+// indexed histories, the id of the last indexed one and the flag whether all
+// the available histories have been indexed.
+func (i *historyIndexer) index() (uint64, uint64, bool, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	head, err := i.freezer.Ancients()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	tail, err := i.freezer.Tail()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	m := loadIndexMetadata(i.disk)
+	if m == nil {
+		m = &indexMetadata{Version: stateIndexVersion, Tail: tail, Last: tail}
+	}
+	if m.Last >= head {
+		return 0, m.Last, true, nil
+	}
+	var (
+		from = m.Last + 1
+		to   = min(head, m.Last+indexerBatchSize)
+	)
+	m.Last = to
+	if err := processHistories(i.disk, i.freezer, from, to, false, m); err != nil {
+		return 0, 0, false, err
+	}
+	return to - from + 1, to, to == head, nil
+}
+
+// progress returns the range of indexed state histories, all the histories
+// in range (tail, last] are indexed.
+func (i *historyIndexer) progress() (uint64, uint64) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	m := loadIndexMetadata(i.disk)
+	if m == nil {
+		return 0, 0
+	}
+	return m.Tail, m.Last
+}
+
+// notify wakes up the indexer to index the newly written state histories.
+func (i *historyIndexer) notify() {
+	select {
+	case i.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// truncateHead removes the state histories above the given id from both the
+// index and the freezer.
+func (i *historyIndexer) truncateHead(nhead uint64) (int, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if err := unindexHead(i.disk, i.freezer, nhead); err != nil {
+		return 0, err
+	}
+	return truncateFromHead(i.disk, i.freezer, nhead)
+}
+
+// truncateTail removes the state histories not above the given id from both
+// the index and the freezer.
+func (i *historyIndexer) truncateTail(ntail uint64) (int, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if err := unindexTail(i.disk, i.freezer, ntail); err != nil {
+		return 0, err
+	}
+	return truncateFromTail(i.disk, i.freezer, ntail)
+}
+
+// close terminates the background indexing and waits until it's stopped.
+func (i *historyIndexer) close() {
+	close(i.closed)
+	i.wg.Wait()
+}
@@ -0,0 +1,265 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pathdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// maxUnindexedHistories is the maximum number of state histories not yet
+// indexed that the historical state reader is willing to scan linearly.
+const maxUnindexedHistories = 128
+
+// readAccountIndex locates the index of the specified account in the state
+// history with the given id. The flag reports whether the account is present.
+func readAccountIndex(reader ethdb.AncientReader, address common.Address, id uint64) (accountIndex, bool, error) {
+	blob := rawdb.ReadStateAccountIndex(reader, id)
+	if len(blob) == 0 {
+		return accountIndex{}, false, fmt.Errorf("state history not found %d", id)
+	}
+	if len(blob)%accountIndexSize != 0 {
+		return accountIndex{}, false, fmt.Errorf("invalid account index, len: %d", len(blob))
+	}
+	var (
+		n   = len(blob) / accountIndexSize
+		pos = sort.Search(n, func(i int) bool {
+			start := i * accountIndexSize
+			return bytes.Compare(blob[start:start+common.AddressLength], address.Bytes()) >= 0
+		})
+	)
+	if pos == n {
+		return accountIndex{}, false, nil
+	}
+	var index accountIndex
+	index.decode(blob[pos*accountIndexSize : (pos+1)*accountIndexSize])
+	if index.address != address {
+		return accountIndex{}, false, nil
+	}
+	return index, true, nil
+}
+
+// readAccountFromHistory retrieves the original value of the specified account
+// from the state history with the given id, namely the value before the state
+// transition. The flag reports whether the account is mutated in the history.
+func readAccountFromHistory(reader ethdb.AncientReader, address common.Address, id uint64) ([]byte, bool, error) {
+	index, found, err := readAccountIndex(reader, address, id)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	data := rawdb.ReadStateAccountHistory(reader, id)
+	last := index.offset + uint32(index.length)
+	if uint32(len(data)) < last {
+		return nil, false, errors.New("account data buffer is corrupted")
+	}
+	return common.CopyBytes(data[index.offset:last]), true, nil
+}
+
+// readStorageFromHistory retrieves the original value of the specified storage
+// slot from the state history with the given id, namely the value before the
+// state transition. The flag reports whether the slot is mutated in the history.
+func readStorageFromHistory(reader ethdb.AncientReader, address common.Address, slotHash common.Hash, id uint64) ([]byte, bool, error) {
+	accIndex, found, err := readAccountIndex(reader, address, id)
+	if err != nil || !found || accIndex.storageSlots == 0 {
+		return nil, false, err
+	}
+	var (
+		blob  = rawdb.ReadStateStorageIndex(reader, id)
+		start = int(accIndex.storageOffset) * slotIndexSize
+		end   = int(accIndex.storageOffset+accIndex.storageSlots) * slotIndexSize
+	)
+	if len(blob) < end {
+		return nil, false, errors.New("storage index buffer is corrupted")
+	}
+	blob = blob[start:end]
+
+	var (
+		n   = int(accIndex.storageSlots)
+		pos = sort.Search(n, func(i int) bool {
+			start := i * slotIndexSize
+			return bytes.Compare(blob[start:start+common.HashLength], slotHash.Bytes()) >= 0
+		})
+	)
+	if pos == n {
+		return nil, false, nil
+	}
+	var index slotIndex
+	index.decode(blob[pos*slotIndexSize : (pos+1)*slotIndexSize])
+	if index.hash != slotHash {
+		return nil, false, nil
+	}
+	data := rawdb.ReadStateStorageHistory(reader, id)
+	last := index.offset + uint32(index.length)
+	if uint32(len(data)) < last {
+		return nil, false, errors.New("storage data buffer is corrupted")
+	}
+	return common.CopyBytes(data[index.offset:last]), true, nil
+}
+
+// HistoricalStateReader provides access to the state at a historical point,
+// which is no longer available in the layer tree. It resolves the states by
+// looking up the first state history in which the requested state was mutated
+// after the target point, and retrieving the original value recorded in it.
+//
+// If the requested state has not been mutated since the target point, it's
+// not resolvable from the histories. The caller should then read it from the
+// reference state instead, which is the disk layer at the time when the reader
+// was constructed.
+type HistoricalStateReader struct {
+	id      uint64      // The state id of the target state
+	ref     uint64      // The state id of the reference state
+	refRoot common.Hash // The state root of the reference state
+	db      *Database
+}
+
+// HistoricReader constructs a reader for accessing the state at the given root.
+// The state must be older than the disk layer and covered by the indexed state
+// histories.
+func (db *Database) HistoricReader(root common.Hash) (*HistoricalStateReader, error) {
+	if db.indexer == nil {
+		return nil, errors.New("state history indexing is not enabled")
+	}
+	root = types.TrieRootHash(root)
+	id := rawdb.ReadStateID(db.diskdb, root)
+	if id == nil {
+		return nil, fmt.Errorf("state %#x is not available", root)
+	}
+	dl := db.tree.bottom()
+	if *id > dl.stateID() {
+		return nil, fmt.Errorf("state %#x is not historical, id: %d, disk: %d", root, *id, dl.stateID())
+	}
+	tail, err := db.freezer.Tail()
+	if err != nil {
+		return nil, err
+	}
+	indexTail, _ := db.indexer.progress()
+	if *id < max(tail, indexTail) {
+		return nil, fmt.Errorf("state history of %#x is pruned, id: %d, tail: %d", root, *id, max(tail, indexTail))
+	}
+	return &HistoricalStateReader{
+		id:      *id,
+		ref:     dl.stateID(),
+		refRoot: dl.rootHash(),
+		db:      db,
+	}, nil
+}
+
+// Root returns the root of the reference state, from which the states not
+// mutated since the target point should be read.
+func (r *HistoricalStateReader) Root() common.Hash {
+	return r.refRoot
+}
+
+// lookup finds the id of the first state history in range (target, reference]
+// in which the specified state was mutated. The flag reports whether such a
+// history exists.
+func (r *HistoricalStateReader) lookup(ident stateIdent, contains func(id uint64) (bool, error)) (uint64, bool, error) {
+	tail, err := r.db.freezer.Tail()
+	if err != nil {
+		return 0, false, err
+	}
+	if r.id < tail {
+		return 0, false, fmt.Errorf("state history is pruned, id: %d, tail: %d", r.id, tail)
+	}
+	indexTail, last := r.db.indexer.progress()
+	if r.id < indexTail {
+		return 0, false, fmt.Errorf("state history is unindexed, id: %d, tail: %d", r.id, indexTail)
+	}
+	last = min(last, r.ref)
+	if last > r.id {
+		id, found, err := readGreaterThan(r.db.diskdb, ident, r.id)
+		if err != nil {
+			return 0, false, err
+		}
+		if found && id <= last {
+			return id, true, nil
+		}
+	}
+	// The state was not mutated in the indexed histories, scan the ones
+	// not yet indexed.
+	start := max(last, r.id)
+	if r.ref-start > maxUnindexedHistories {
+		return 0, false, fmt.Errorf("state history is not fully indexed, indexed: %d, required: %d", last, r.ref)
+	}
+	for id := start + 1; id <= r.ref; id++ {
+		found, err := contains(id)
+		if err != nil {
+			return 0, false, err
+		}
+		if found {
+			return id, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Account retrieves the slim-format RLP encoded account at the target state.
+// The flag reports whether the account is resolved from the state histories;
+// if not, the account should be read from the reference state. An empty value
+// with the flag set means the account was not present.
+func (r *HistoricalStateReader) Account(address common.Address) ([]byte, bool, error) {
+	defer historicalAccountTimer.UpdateSince(time.Now())
+
+	id, found, err := r.lookup(newAccountIdent(address), func(id uint64) (bool, error) {
+		_, found, err := readAccountIndex(r.db.freezer, address, id)
+		return found, err
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+	blob, found, err := readAccountFromHistory(r.db.freezer, address, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, fmt.Errorf("account %#x is not found in state history %d", address, id)
+	}
+	return blob, true, nil
+}
+
+// Storage retrieves the RLP encoded storage slot at the target state, with the
+// slot identified by the hash of the raw slot key. The flag reports whether the
+// slot is resolved from the state histories; if not, the slot should be read
+// from the reference state. An empty value with the flag set means the slot was
+// not present.
+func (r *HistoricalStateReader) Storage(address common.Address, slotHash common.Hash) ([]byte, bool, error) {
+	defer historicalStorageTimer.UpdateSince(time.Now())
+
+	id, found, err := r.lookup(newStorageIdent(address, slotHash), func(id uint64) (bool, error) {
+		_, found, err := readStorageFromHistory(r.db.freezer, address, slotHash, id)
+		return found, err
+	})
+	if err != nil || !found {
+		return nil, false, err
+	}
+	blob, found, err := readStorageFromHistory(r.db.freezer, address, slotHash, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if !found {
+		return nil, false, fmt.Errorf("storage %#x:%#x is not found in state history %d", address, slotHash, id)
+	}
+	return blob, true, nil
+}
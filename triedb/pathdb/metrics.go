@@ -48,4 +48,9 @@ var (
 	historyBuildTimeMeter  = metrics.NewRegisteredTimer("pathdb/history/time", nil)
 	historyDataBytesMeter  = metrics.NewRegisteredMeter("pathdb/history/bytes/data", nil)
 	historyIndexBytesMeter = metrics.NewRegisteredMeter("pathdb/history/bytes/index", nil)
+
+	historyIndexMeter      = metrics.NewRegisteredMeter("pathdb/history/index", nil)
+	historyUnindexMeter    = metrics.NewRegisteredMeter("pathdb/history/unindex", nil)
+	historicalAccountTimer = metrics.NewRegisteredTimer("pathdb/history/read/account", nil)
+	historicalStorageTimer = metrics.NewRegisteredTimer("pathdb/history/read/storage", nil)
 )